@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"time"
@@ -22,8 +23,14 @@ import (
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v2"
 
+	"github.com/jodydadescott/shelly-client/cmd/cover"
+	"github.com/jodydadescott/shelly-client/cmd/emulate"
+	"github.com/jodydadescott/shelly-client/cmd/energy"
+	"github.com/jodydadescott/shelly-client/cmd/kvs"
 	"github.com/jodydadescott/shelly-client/cmd/light"
 	"github.com/jodydadescott/shelly-client/cmd/mqtt"
+	"github.com/jodydadescott/shelly-client/cmd/script"
+	"github.com/jodydadescott/shelly-client/cmd/serve"
 	"github.com/jodydadescott/shelly-client/cmd/switchx"
 	"github.com/jodydadescott/shelly-client/cmd/types"
 	"github.com/jodydadescott/shelly-client/cmd/util"
@@ -56,6 +63,16 @@ type Cmd struct {
 	debugLevelArg     string
 	unifiArg          string
 	openhabArg        string
+	transportArg      string
+	schemeArg         string
+	caFileArg         string
+	certFileArg       string
+	keyFileArg        string
+	serverNameArg     string
+	pinArg            []string
+	insecureArg       bool
+	recordArg         string
+	replayArg         string
 	rebootForceArg    bool
 	setConfigForceArg bool
 }
@@ -111,7 +128,10 @@ func NewCmd() *Cmd {
 				return fmt.Errorf("one and only one hostname is required for this command")
 			}
 
-			client := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+			client, err := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+			if err != nil {
+				return err
+			}
 			defer client.Close()
 
 			result, err := client.GetConfig(ctx, false)
@@ -140,7 +160,10 @@ func NewCmd() *Cmd {
 				return fmt.Errorf("one and only one hostname is required for this command")
 			}
 
-			client := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+			client, err := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+			if err != nil {
+				return err
+			}
 			defer client.Close()
 
 			runningConfig, err := client.GetConfig(ctx, false)
@@ -235,7 +258,10 @@ func NewCmd() *Cmd {
 				return fmt.Errorf("one and only one hostname is required for this command")
 			}
 
-			client := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+			client, err := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+			if err != nil {
+				return err
+			}
 			defer client.Close()
 
 			shellyConfig, err := sdk_client.GetConfig(ctx, client)
@@ -272,7 +298,10 @@ func NewCmd() *Cmd {
 			}
 
 			if len(config.Hostnames) == 1 {
-				client := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+				client, err := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+				if err != nil {
+					return err
+				}
 				defer client.Close()
 
 				result, err := client.GetStatus(ctx)
@@ -286,7 +315,10 @@ func NewCmd() *Cmd {
 			var results []*ShellyStatus
 
 			for _, hostname := range config.Hostnames {
-				client := util.NewShellyClient(config, util.CleanupHostname(hostname))
+				client, err := util.NewShellyClient(config, util.CleanupHostname(hostname))
+				if err != nil {
+					return err
+				}
 				defer client.Close()
 				result, err := client.GetStatus(ctx)
 				if err != nil {
@@ -317,7 +349,10 @@ func NewCmd() *Cmd {
 			}
 
 			if len(config.Hostnames) == 1 {
-				client := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+				client, err := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+				if err != nil {
+					return err
+				}
 				defer client.Close()
 
 				result, err := client.GetDeviceInfo(ctx)
@@ -331,7 +366,10 @@ func NewCmd() *Cmd {
 			var results []*ShellyDeviceInfo
 
 			for _, hostname := range config.Hostnames {
-				client := util.NewShellyClient(config, util.CleanupHostname(hostname))
+				client, err := util.NewShellyClient(config, util.CleanupHostname(hostname))
+				if err != nil {
+					return err
+				}
 				defer client.Close()
 				result, err := client.GetDeviceInfo(ctx)
 				if err != nil {
@@ -362,7 +400,10 @@ func NewCmd() *Cmd {
 			}
 
 			if len(config.Hostnames) == 1 {
-				client := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+				client, err := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+				if err != nil {
+					return err
+				}
 				defer client.Close()
 
 				result, err := client.ListMethods(ctx)
@@ -376,7 +417,10 @@ func NewCmd() *Cmd {
 			var results []*ShellyRPCMethods
 
 			for _, hostname := range config.Hostnames {
-				client := util.NewShellyClient(config, util.CleanupHostname(hostname))
+				client, err := util.NewShellyClient(config, util.CleanupHostname(hostname))
+				if err != nil {
+					return err
+				}
 				defer client.Close()
 				result, err := client.ListMethods(ctx)
 				if err != nil {
@@ -414,7 +458,10 @@ func NewCmd() *Cmd {
 			}
 
 			if len(config.Hostnames) == 1 {
-				client := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+				client, err := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+				if err != nil {
+					return err
+				}
 				defer client.Close()
 
 				result, err := client.CheckForUpdate(ctx)
@@ -428,7 +475,10 @@ func NewCmd() *Cmd {
 			var results []*ShellyUpdateReport
 
 			for _, hostname := range config.Hostnames {
-				client := util.NewShellyClient(config, util.CleanupHostname(hostname))
+				client, err := util.NewShellyClient(config, util.CleanupHostname(hostname))
+				if err != nil {
+					return err
+				}
 				defer client.Close()
 				result, err := client.CheckForUpdate(ctx)
 				if err != nil {
@@ -909,10 +959,20 @@ func NewCmd() *Cmd {
 	rootCmd.PersistentFlags().StringVarP(&t.passwordArg, "password", "p", "", fmt.Sprintf("Password; optionally use env var '%s'", ShellyPasswordEnvVar))
 	rootCmd.PersistentFlags().StringVar(&t.passwordArg, "update-url", "", fmt.Sprintf("Password; optionally use env var '%s'", ShellyURLEnvVar))
 	rootCmd.PersistentFlags().StringVarP(&t.outputArg, "output", "o", ShellyOutputDefault, fmt.Sprintf("Output format. One of: prettyjson | json | jsonpath | yaml ; Optionally use env var '%s'", ShellyOutputEnvVar))
-	rootCmd.PersistentFlags().StringVarP(&t.timeoutArg, "timeout", "t", "", "The timeout in seconds for the websocket call to the device")
+	rootCmd.PersistentFlags().StringVarP(&t.timeoutArg, "timeout", "t", "", "Timeout for each call to the device such as 5s; overrides the built-in method timeouts")
+	rootCmd.PersistentFlags().StringVar(&t.transportArg, "transport", "", fmt.Sprintf("Transport used to communicate with the device. One of: ws | http | mqtt | udp ; Optionally use env var '%s'", ShellyTransportEnvVar))
+	rootCmd.PersistentFlags().StringVar(&t.schemeArg, "scheme", "", fmt.Sprintf("Scheme used to connect to the device. One of: ws | wss for the ws transport or http | https for the http transport ; Optionally use env var '%s'", ShellySchemeEnvVar))
+	rootCmd.PersistentFlags().StringVar(&t.caFileArg, "ca-file", "", "PEM CA bundle used to verify the server when the scheme is wss or https")
+	rootCmd.PersistentFlags().StringVar(&t.certFileArg, "cert-file", "", "PEM client certificate used when the scheme is wss or https")
+	rootCmd.PersistentFlags().StringVar(&t.keyFileArg, "key-file", "", "PEM private key of the client certificate")
+	rootCmd.PersistentFlags().StringVar(&t.serverNameArg, "server-name", "", "Server name sent with SNI and used to verify the server certificate")
+	rootCmd.PersistentFlags().StringSliceVar(&t.pinArg, "pin", []string{}, "Base64 SHA-256 hash of the SubjectPublicKeyInfo of a certificate in the server chain; may be repeated")
+	rootCmd.PersistentFlags().BoolVar(&t.insecureArg, "insecure-skip-verify", false, "Do not verify the server certificate chain and name; pins are still verified")
+	rootCmd.PersistentFlags().StringVar(&t.recordArg, "record", "", "Append each request and response to this cassette file")
+	rootCmd.PersistentFlags().StringVar(&t.replayArg, "replay", "", "Serve responses from this cassette file instead of the device")
 	rootCmd.PersistentFlags().StringVar(&t.unifiArg, "unifi", "", "Use Unifi controller to get hostname(s). Must be enable, disable, true, or false")
 	rootCmd.PersistentFlags().StringVar(&t.openhabArg, "openhab", "", "Use Openhab controller to get hostname(s). Must be enable, disable, true, or false")
-	rootCmd.AddCommand(configCmd, infoCmd, resetCmd, firmwareCmd, listHostnamesCmd, diffHostnamesCmd, light.New(t), switchx.New(t), mqtt.New(t))
+	rootCmd.AddCommand(configCmd, infoCmd, resetCmd, firmwareCmd, listHostnamesCmd, diffHostnamesCmd, light.New(t), switchx.New(t), cover.New(t), energy.New(t), script.New(t), kvs.New(t), mqtt.New(t), serve.New(t), emulate.New(t))
 	t.Command = rootCmd
 
 	return t
@@ -1182,6 +1242,22 @@ func (t *Cmd) GetConfig(ctx context.Context) (*Config, error) {
 		return initFromBytes(content)
 	}
 
+	loadScripts := func(dir string) error {
+
+		if config.Shelly == nil {
+			return nil
+		}
+
+		for name, shellyConfig := range config.Shelly.ShellyConfigs {
+			err := shellyConfig.LoadScripts(dir)
+			if err != nil {
+				return fmt.Errorf("shelly config %s; %w", name, err)
+			}
+		}
+
+		return nil
+	}
+
 	compareSlices := func(a, b []string) bool {
 
 		has := func(x string, y []string) bool {
@@ -1369,6 +1445,142 @@ func (t *Cmd) GetConfig(ctx context.Context) (*Config, error) {
 
 	}
 
+	loadTransport := func() error {
+
+		x := t.transportArg
+		if x != "" {
+			zap.L().Debug(fmt.Sprintf("Transport is %s from args", x))
+		} else {
+			x = os.Getenv(ShellyTransportEnvVar)
+			if x != "" {
+				zap.L().Debug(fmt.Sprintf("Transport is %s from envvar %s", x, ShellyTransportEnvVar))
+			}
+		}
+
+		if x != "" {
+			if config.Shelly.Transport != "" && config.Shelly.Transport != x {
+				zap.L().Debug(fmt.Sprintf("Transport %s in config overwritten by %s", config.Shelly.Transport, x))
+			}
+			config.Shelly.Transport = strings.ToLower(x)
+		}
+
+		switch config.Shelly.Transport {
+
+		case "":
+			zap.L().Debug(fmt.Sprintf("Transport not set in arg, envvar %s or config", ShellyTransportEnvVar))
+			return nil
+
+		case sdk_types.TransportWS, sdk_types.TransportHTTP:
+			return nil
+
+		case sdk_types.TransportMQTT:
+			if config.Shelly.MqttTransport == nil {
+				return fmt.Errorf("transport %s requires shelly.mqttTransport config", config.Shelly.Transport)
+			}
+			return nil
+
+		case sdk_types.TransportUDP:
+			if config.Shelly.UDPTransport == nil {
+				return fmt.Errorf("transport %s requires shelly.udpTransport config", config.Shelly.Transport)
+			}
+			return nil
+
+		}
+
+		return fmt.Errorf("transport value of %s is not valid, expecting %s, %s, %s or %s", config.Shelly.Transport, sdk_types.TransportWS, sdk_types.TransportHTTP, sdk_types.TransportMQTT, sdk_types.TransportUDP)
+	}
+
+	loadTLS := func() error {
+
+		x := t.schemeArg
+		if x != "" {
+			zap.L().Debug(fmt.Sprintf("Scheme is %s from args", x))
+		} else {
+			x = os.Getenv(ShellySchemeEnvVar)
+			if x != "" {
+				zap.L().Debug(fmt.Sprintf("Scheme is %s from envvar %s", x, ShellySchemeEnvVar))
+			}
+		}
+
+		if x != "" {
+			if config.Shelly.Scheme != "" && config.Shelly.Scheme != x {
+				zap.L().Debug(fmt.Sprintf("Scheme %s in config overwritten by %s", config.Shelly.Scheme, x))
+			}
+			config.Shelly.Scheme = strings.ToLower(x)
+		}
+
+		if t.caFileArg != "" || t.certFileArg != "" || t.keyFileArg != "" || t.serverNameArg != "" || len(t.pinArg) > 0 || t.insecureArg {
+
+			if config.Shelly.TLS == nil {
+				config.Shelly.TLS = &sdk_types.TLSConfig{}
+			}
+
+			if t.caFileArg != "" {
+				config.Shelly.TLS.CAFile = t.caFileArg
+			}
+
+			if t.certFileArg != "" {
+				config.Shelly.TLS.CertFile = t.certFileArg
+			}
+
+			if t.keyFileArg != "" {
+				config.Shelly.TLS.KeyFile = t.keyFileArg
+			}
+
+			if t.serverNameArg != "" {
+				config.Shelly.TLS.ServerName = t.serverNameArg
+			}
+
+			if len(t.pinArg) > 0 {
+				config.Shelly.TLS.Pins = t.pinArg
+			}
+
+			if t.insecureArg {
+				config.Shelly.TLS.InsecureSkipVerify = true
+			}
+		}
+
+		switch config.Shelly.Scheme {
+
+		case "":
+			return nil
+
+		case sdk_types.SchemeWS, sdk_types.SchemeWSS:
+			if config.Shelly.Transport != "" && config.Shelly.Transport != sdk_types.TransportWS {
+				return fmt.Errorf("scheme %s is not valid for transport %s", config.Shelly.Scheme, config.Shelly.Transport)
+			}
+			return nil
+
+		case sdk_types.SchemeHTTP, sdk_types.SchemeHTTPS:
+			if config.Shelly.Transport != sdk_types.TransportHTTP {
+				return fmt.Errorf("scheme %s requires transport %s", config.Shelly.Scheme, sdk_types.TransportHTTP)
+			}
+			return nil
+
+		}
+
+		return fmt.Errorf("scheme value of %s is not valid, expecting %s, %s, %s or %s", config.Shelly.Scheme, sdk_types.SchemeWS, sdk_types.SchemeWSS, sdk_types.SchemeHTTP, sdk_types.SchemeHTTPS)
+	}
+
+	loadCassette := func() error {
+
+		if t.recordArg != "" {
+			zap.L().Debug(fmt.Sprintf("RecordFile is %s from args", t.recordArg))
+			config.Shelly.RecordFile = t.recordArg
+		}
+
+		if t.replayArg != "" {
+			zap.L().Debug(fmt.Sprintf("ReplayFile is %s from args", t.replayArg))
+			config.Shelly.ReplayFile = t.replayArg
+		}
+
+		if config.Shelly.RecordFile != "" && config.Shelly.ReplayFile != "" {
+			return fmt.Errorf("record and replay can not both be set")
+		}
+
+		return nil
+	}
+
 	loadUpdateURL := func() {
 
 		x := t.urlArg
@@ -1588,6 +1800,10 @@ func (t *Cmd) GetConfig(ctx context.Context) (*Config, error) {
 		return nil, err
 	}
 
+	// scriptDir is the directory the files of the scripts are relative to; the directory
+	// of the config file or the working directory if the config is read from stdin
+	scriptDir := ""
+
 	fi, err := os.Stdin.Stat()
 	if err != nil {
 		return nil, err
@@ -1604,10 +1820,18 @@ func (t *Cmd) GetConfig(ctx context.Context) (*Config, error) {
 		}
 
 	} else {
-		err = initFromFile(getConfigFile())
+		configFile := getConfigFile()
+		err = initFromFile(configFile)
 		if err != nil {
 			return nil, err
 		}
+		if configFile != "" {
+			scriptDir = filepath.Dir(configFile)
+		}
+	}
+
+	if err := loadScripts(scriptDir); err != nil {
+		return nil, err
 	}
 
 	if err := loadBase(); err != nil {
@@ -1616,6 +1840,19 @@ func (t *Cmd) GetConfig(ctx context.Context) (*Config, error) {
 
 	loadHostnames()
 	loadShelly()
+
+	if err := loadTransport(); err != nil {
+		return nil, err
+	}
+
+	if err := loadTLS(); err != nil {
+		return nil, err
+	}
+
+	if err := loadCassette(); err != nil {
+		return nil, err
+	}
+
 	loadOutput()
 	loadUpdateURL()
 
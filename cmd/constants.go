@@ -14,12 +14,14 @@ const (
 
 	DebugEnvVar = "DEBUG"
 
-	ShellyConfigEnvVar   = "SHELLY_CONFIG"
-	ShellyHostnameEnvVar = "SHELLY_HOST"
-	ShellyPasswordEnvVar = "SHELLY_PASS"
-	ShellyOutputEnvVar   = "SHELLY_OUTPUT"
-	ShellyTimeoutEnvVar  = "SHELLY_TIMEOUT"
-	ShellyURLEnvVar      = "SHELLY_URL"
+	ShellyConfigEnvVar    = "SHELLY_CONFIG"
+	ShellyHostnameEnvVar  = "SHELLY_HOST"
+	ShellyPasswordEnvVar  = "SHELLY_PASS"
+	ShellyOutputEnvVar    = "SHELLY_OUTPUT"
+	ShellyTimeoutEnvVar   = "SHELLY_TIMEOUT"
+	ShellyURLEnvVar       = "SHELLY_URL"
+	ShellyTransportEnvVar = "SHELLY_TRANSPORT"
+	ShellySchemeEnvVar    = "SHELLY_SCHEME"
 
 	ShellyOutputDefault = "prettyjson"
 )
@@ -0,0 +1,182 @@
+package cover
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+
+	"github.com/jodydadescott/shelly-client/cmd/types"
+	"github.com/jodydadescott/shelly-client/cmd/util"
+	sdk_client "github.com/jodydadescott/shelly-client/sdk/client"
+	sdk_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	shelly_types "github.com/jodydadescott/shelly-client/sdk/shelly/types"
+)
+
+type Config = types.Config
+
+type ShellyClient = sdk_client.Client
+
+type ShellyDeviceInfo = shelly_types.DeviceInfo
+type ShellyDeviceStatus = shelly_types.Status
+type ShellyConfig = sdk_types.Config
+
+type callback interface {
+	GetConfig(context.Context) (*Config, error)
+	GetCTX() (context.Context, context.CancelFunc)
+	WriteStdout(input any) error
+}
+
+func New(t callback) *cobra.Command {
+
+	var durationArg float64
+
+	getIds := func(ctx context.Context, shellyClient *ShellyClient, args []string) ([]int, error) {
+
+		if len(args) == 0 {
+			return nil, fmt.Errorf("one or more IDs is required. They can be space of comma delineated. You can also use 'all'")
+		}
+
+		var results []int
+
+		if len(args) == 1 {
+			if strings.ToLower(args[0]) == "all" {
+
+				shellyConfig, err := shellyClient.GetConfig(ctx, false)
+				if err != nil {
+					return nil, err
+				}
+				for _, coverConfig := range shellyConfig.Cover {
+					results = append(results, *coverConfig.ID)
+				}
+				return results, nil
+			}
+		}
+
+		var errors *multierror.Error
+
+		for _, arg := range args {
+			for _, sub := range strings.Split((strings.TrimSpace(arg)), ",") {
+				id, err := strconv.Atoi(sub)
+				if err != nil {
+					errors = multierror.Append(errors, err)
+				} else {
+					results = append(results, id)
+				}
+			}
+		}
+
+		return results, errors.ErrorOrNil()
+	}
+
+	getDuration := func() *float64 {
+		if durationArg > 0 {
+			return &durationArg
+		}
+		return nil
+	}
+
+	// run calls fn for each cover ID in args on each device
+	run := func(action string, args []string, fn func(ctx context.Context, client *ShellyClient, id int) error) error {
+
+		ctx, cancel := t.GetCTX()
+		defer cancel()
+
+		config, err := t.GetConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		do := func(ctx context.Context, hostname string, client *ShellyClient, deviceInfo *ShellyDeviceInfo, deviceStatus *ShellyDeviceStatus) error {
+
+			ids, err := getIds(ctx, client, args)
+			if err != nil {
+				return err
+			}
+
+			var errors *multierror.Error
+
+			for _, id := range ids {
+				err := fn(ctx, client, id)
+				if err != nil {
+					t.WriteStdout(fmt.Sprintf("hostname %s, deviceID %s, deviceApp %s, coverID %d: [%s] failed with error %s", hostname, *deviceInfo.ID, *deviceInfo.App, id, action, err.Error()))
+					errors = multierror.Append(errors, err)
+				} else {
+					t.WriteStdout(fmt.Sprintf("hostname %s, deviceID %s, deviceApp %s, coverID %d: [%s] completed", hostname, *deviceInfo.ID, *deviceInfo.App, id, action))
+				}
+			}
+
+			return errors.ErrorOrNil()
+		}
+
+		return util.Process(ctx, config, action, false, do)
+	}
+
+	rootCmd := &cobra.Command{
+		Use:   "cover",
+		Short: "Open, close, stop or position cover (roller shutter)",
+	}
+
+	openCmd := &cobra.Command{
+		Use:   "open",
+		Short: "Open cover",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run("open", args, func(ctx context.Context, client *ShellyClient, id int) error {
+				return client.Cover().Open(ctx, id, getDuration())
+			})
+		},
+	}
+
+	closeCmd := &cobra.Command{
+		Use:   "close",
+		Short: "Close cover",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run("close", args, func(ctx context.Context, client *ShellyClient, id int) error {
+				return client.Cover().Close(ctx, id, getDuration())
+			})
+		},
+	}
+
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop cover",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run("stop", args, func(ctx context.Context, client *ShellyClient, id int) error {
+				return client.Cover().Stop(ctx, id)
+			})
+		},
+	}
+
+	posCmd := &cobra.Command{
+		Use:   "pos <pct>",
+		Short: "Move cover to position in percent where 0 is closed and 100 is open. The cover must be calibrated",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			if len(args) == 0 {
+				return fmt.Errorf("position is required")
+			}
+
+			pos, err := strconv.Atoi(strings.TrimSuffix(args[0], "%"))
+			if err != nil {
+				return fmt.Errorf("position %s is not valid; %w", args[0], err)
+			}
+
+			if pos < 0 || pos > 100 {
+				return fmt.Errorf("position %d must be between 0 and 100", pos)
+			}
+
+			return run(fmt.Sprintf("pos %d", pos), args[1:], func(ctx context.Context, client *ShellyClient, id int) error {
+				return client.Cover().GoToPosition(ctx, id, pos)
+			})
+		},
+	}
+
+	openCmd.PersistentFlags().Float64Var(&durationArg, "duration", 0, "Seconds to move; if not set the cover moves until fully open")
+	closeCmd.PersistentFlags().Float64Var(&durationArg, "duration", 0, "Seconds to move; if not set the cover moves until fully closed")
+
+	rootCmd.AddCommand(openCmd, closeCmd, stopCmd, posCmd)
+	return rootCmd
+}
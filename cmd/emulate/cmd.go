@@ -0,0 +1,118 @@
+package emulate
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+
+	"github.com/jodydadescott/shelly-client/sdk/emulator"
+)
+
+type EmulatorConfig = emulator.Config
+
+type callback interface {
+	GetCTX() (context.Context, context.CancelFunc)
+	WriteStderr(input any) error
+}
+
+func New(t callback) *cobra.Command {
+
+	var modelArg string
+	var countArg int
+	var listenArg string
+	var passwordArg string
+
+	emulateCmd := &cobra.Command{
+		Use:   "emulate",
+		Short: "Runs emulated devices that serve RPC over HTTP and websocket",
+		Long: fmt.Sprintf("Runs emulated devices that serve RPC over HTTP and websocket. Each device listens on the next port after the previous device. Supported models are %s.",
+			strings.Join(emulator.Models(), ", ")),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			ctx, cancel := t.GetCTX()
+			defer cancel()
+
+			if countArg <= 0 {
+				return fmt.Errorf("count must be greater than zero")
+			}
+
+			host, portString, err := net.SplitHostPort(listenArg)
+			if err != nil {
+				return err
+			}
+
+			port, err := strconv.Atoi(portString)
+			if err != nil {
+				return fmt.Errorf("listen port %s is not valid", portString)
+			}
+
+			var listeners []net.Listener
+			var emulators []*emulator.Emulator
+
+			closeListeners := func() {
+				for _, listener := range listeners {
+					listener.Close()
+				}
+			}
+
+			for i := 0; i < countArg; i++ {
+
+				deviceConfig, err := emulator.NewDeviceConfig(modelArg, i)
+				if err != nil {
+					closeListeners()
+					return err
+				}
+
+				deviceConfig.Password = passwordArg
+
+				listenAddress := net.JoinHostPort(host, strconv.Itoa(port+i))
+
+				listener, err := net.Listen("tcp", listenAddress)
+				if err != nil {
+					closeListeners()
+					return err
+				}
+
+				listeners = append(listeners, listener)
+				emulators = append(emulators, emulator.New(&EmulatorConfig{
+					ListenAddress: listenAddress,
+					Device:        deviceConfig,
+				}))
+
+				t.WriteStderr(fmt.Sprintf("deviceID %s, deviceApp %s: [listening] %s", deviceConfig.ID, deviceConfig.App, listener.Addr().String()))
+			}
+
+			errs := make(chan error, len(emulators))
+
+			for i, e := range emulators {
+				go func(e *emulator.Emulator, listener net.Listener) {
+					errs <- e.Serve(ctx, listener)
+				}(e, listeners[i])
+			}
+
+			var result *multierror.Error
+
+			for range emulators {
+				err := <-errs
+				if err != nil {
+					result = multierror.Append(result, err)
+					cancel()
+				}
+			}
+
+			return result.ErrorOrNil()
+		},
+	}
+
+	emulateCmd.PersistentFlags().StringVar(&modelArg, "model", "Plus1PM", "Model to emulate")
+	emulateCmd.PersistentFlags().IntVar(&countArg, "count", 1, "Number of devices to emulate")
+	emulateCmd.PersistentFlags().StringVar(&listenArg, "listen", "127.0.0.1:8080", "Address the first device listens on")
+	emulateCmd.PersistentFlags().StringVar(&passwordArg, "password", "", "If set the devices require authentication")
+
+	return emulateCmd
+}
@@ -0,0 +1,260 @@
+package energy
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/jodydadescott/shelly-client/cmd/types"
+	"github.com/jodydadescott/shelly-client/cmd/util"
+	sdk_client "github.com/jodydadescott/shelly-client/sdk/client"
+	emdata_types "github.com/jodydadescott/shelly-client/sdk/emdata/types"
+	shelly_types "github.com/jodydadescott/shelly-client/sdk/shelly/types"
+)
+
+type Config = types.Config
+
+type ShellyClient = sdk_client.Client
+
+type ShellyDeviceInfo = shelly_types.DeviceInfo
+type ShellyDeviceStatus = shelly_types.Status
+
+type Data = emdata_types.Data
+
+type callback interface {
+	GetConfig(context.Context) (*Config, error)
+	GetCTX() (context.Context, context.CancelFunc)
+	WriteStdout(input any) error
+}
+
+// Record a record of a device
+type Record struct {
+	// Device the ID of the device
+	Device string `json:"device" yaml:"device"`
+	// Component the key of the component such as emdata:0
+	Component string `json:"component" yaml:"component"`
+	// Ts Unix timestamp of the start of the period
+	Ts int64 `json:"ts" yaml:"ts"`
+	// Period the period in seconds
+	Period int64 `json:"period" yaml:"period"`
+	// Values the values keyed by name such as a_total_act_energy
+	Values map[string]float64 `json:"values,omitempty" yaml:"values,omitempty"`
+}
+
+func New(t callback) *cobra.Command {
+
+	var fromArg string
+	var toArg string
+	var formatArg string
+
+	// parseTime parses RFC3339, a date, a date and time in local time or a Unix timestamp
+	parseTime := func(name, value string) (time.Time, error) {
+
+		if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return time.Unix(ts, 0), nil
+		}
+
+		if v, err := time.Parse(time.RFC3339, value); err == nil {
+			return v, nil
+		}
+
+		for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+			if v, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				return v, nil
+			}
+		}
+
+		return time.Time{}, fmt.Errorf("%s %s is not valid; use RFC3339, YYYY-MM-DD, YYYY-MM-DDTHH:MM or a Unix timestamp", name, value)
+	}
+
+	rootCmd := &cobra.Command{
+		Use:   "energy",
+		Short: "Export the energy data stored by energy meters such as the Pro 3EM and Pro EM",
+	}
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Downloads the stored EMData and EM1Data records of each device and merges them in order of time",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			if fromArg == "" {
+				return fmt.Errorf("from is required")
+			}
+
+			from, err := parseTime("from", fromArg)
+			if err != nil {
+				return err
+			}
+
+			to := time.Now()
+			if toArg != "" {
+				to, err = parseTime("to", toArg)
+				if err != nil {
+					return err
+				}
+			}
+
+			if !to.After(from) {
+				return fmt.Errorf("to must be after from")
+			}
+
+			format := strings.ToLower(formatArg)
+			if format != "csv" && format != "json" {
+				return fmt.Errorf("format %s is not valid; must be csv or json", formatArg)
+			}
+
+			ctx, cancel := t.GetCTX()
+			defer cancel()
+
+			config, err := t.GetConfig(ctx)
+			if err != nil {
+				return err
+			}
+
+			var mutex sync.Mutex
+			var records []*Record
+
+			add := func(deviceID, component string, data *Data) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				for _, v := range data.Records() {
+					records = append(records, &Record{
+						Device:    deviceID,
+						Component: component,
+						Ts:        v.Ts,
+						Period:    v.Period,
+						Values:    v.Values,
+					})
+				}
+			}
+
+			do := func(ctx context.Context, hostname string, client *ShellyClient, deviceInfo *ShellyDeviceInfo, deviceStatus *ShellyDeviceStatus) error {
+
+				if len(deviceStatus.EMData) == 0 && len(deviceStatus.EM1Data) == 0 {
+					zap.L().Debug(fmt.Sprintf("hostname %s, deviceID %s does not store energy data", hostname, *deviceInfo.ID))
+					return nil
+				}
+
+				for id := range deviceStatus.EMData {
+					data, err := client.EMData().GetAllData(ctx, id, from, to)
+					if err != nil {
+						return err
+					}
+					add(*deviceInfo.ID, fmt.Sprintf("emdata:%d", id), data)
+				}
+
+				for id := range deviceStatus.EM1Data {
+					data, err := client.EM1Data().GetAllData(ctx, id, from, to)
+					if err != nil {
+						return err
+					}
+					add(*deviceInfo.ID, fmt.Sprintf("em1data:%d", id), data)
+				}
+
+				return nil
+			}
+
+			err = util.Process(ctx, config, "energy export", true, do)
+			if err != nil {
+				return err
+			}
+
+			sortRecords(records)
+
+			if format == "json" {
+				return t.WriteStdout(records)
+			}
+
+			output, err := toCSV(records)
+			if err != nil {
+				return err
+			}
+
+			return t.WriteStdout(output)
+		},
+	}
+
+	exportCmd.PersistentFlags().StringVar(&fromArg, "from", "", "Start of the export as RFC3339, YYYY-MM-DD, YYYY-MM-DDTHH:MM in local time or a Unix timestamp; required")
+	exportCmd.PersistentFlags().StringVar(&toArg, "to", "", "End of the export in the same formats as from; default is now")
+	exportCmd.PersistentFlags().StringVar(&formatArg, "format", "csv", "Output format. One of: csv | json ; json is written in the format of --output")
+
+	rootCmd.AddCommand(exportCmd)
+	return rootCmd
+}
+
+// sortRecords sorts the records of all devices by time and then by device and component
+func sortRecords(records []*Record) {
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Ts != b.Ts {
+			return a.Ts < b.Ts
+		}
+		if a.Device != b.Device {
+			return a.Device < b.Device
+		}
+		return a.Component < b.Component
+	})
+}
+
+// toCSV returns the records as CSV with a column for each value name that any record has.
+// A record without a value has an empty cell.
+func toCSV(records []*Record) (string, error) {
+
+	seen := make(map[string]bool)
+	var keys []string
+
+	for _, record := range records {
+		for key := range record.Values {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+
+	err := w.Write(append([]string{"time", "ts", "period", "device", "component"}, keys...))
+	if err != nil {
+		return "", err
+	}
+
+	for _, record := range records {
+
+		row := []string{
+			time.Unix(record.Ts, 0).UTC().Format(time.RFC3339),
+			strconv.FormatInt(record.Ts, 10),
+			strconv.FormatInt(record.Period, 10),
+			record.Device,
+			record.Component,
+		}
+
+		for _, key := range keys {
+			if value, ok := record.Values[key]; ok {
+				row = append(row, strconv.FormatFloat(value, 'f', -1, 64))
+			} else {
+				row = append(row, "")
+			}
+		}
+
+		err := w.Write(row)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n"), w.Error()
+}
@@ -0,0 +1,59 @@
+package energy
+
+import (
+	"testing"
+)
+
+func TestExportCSV(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		records []*Record
+		want    string
+	}{
+		{
+			name: "empty",
+			want: "time,ts,period,device,component",
+		},
+		{
+			name: "sorted by time, device and component",
+			records: []*Record{
+				{Device: "shellypro3em-b", Component: "emdata:0", Ts: 120, Period: 60, Values: map[string]float64{"a_total_act_energy": 2}},
+				{Device: "shellypro3em-a", Component: "emdata:0", Ts: 120, Period: 60, Values: map[string]float64{"a_total_act_energy": 1.5}},
+				{Device: "shellypro3em-a", Component: "emdata:0", Ts: 60, Period: 60, Values: map[string]float64{"a_total_act_energy": 1}},
+			},
+			want: "time,ts,period,device,component,a_total_act_energy\n" +
+				"1970-01-01T00:01:00Z,60,60,shellypro3em-a,emdata:0,1\n" +
+				"1970-01-01T00:02:00Z,120,60,shellypro3em-a,emdata:0,1.5\n" +
+				"1970-01-01T00:02:00Z,120,60,shellypro3em-b,emdata:0,2",
+		},
+		{
+			name: "merged columns",
+			records: []*Record{
+				{Device: "shellyproem50-a", Component: "em1data:1", Ts: 60, Period: 60, Values: map[string]float64{"total_act_energy": 3}},
+				{Device: "shellyproem50-a", Component: "em1data:0", Ts: 60, Period: 60, Values: map[string]float64{"total_act_energy": 4}},
+				{Device: "shellypro3em-a", Component: "emdata:0", Ts: 60, Period: 60, Values: map[string]float64{"a_total_act_energy": 1}},
+			},
+			want: "time,ts,period,device,component,a_total_act_energy,total_act_energy\n" +
+				"1970-01-01T00:01:00Z,60,60,shellypro3em-a,emdata:0,1,\n" +
+				"1970-01-01T00:01:00Z,60,60,shellyproem50-a,em1data:0,,4\n" +
+				"1970-01-01T00:01:00Z,60,60,shellyproem50-a,em1data:1,,3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			sortRecords(tt.records)
+
+			got, err := toCSV(tt.records)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("csv\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package kvs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jodydadescott/shelly-client/cmd/types"
+	"github.com/jodydadescott/shelly-client/cmd/util"
+	sdk_client "github.com/jodydadescott/shelly-client/sdk/client"
+	shelly_types "github.com/jodydadescott/shelly-client/sdk/shelly/types"
+)
+
+type Config = types.Config
+
+type ShellyClient = sdk_client.Client
+
+type ShellyDeviceInfo = shelly_types.DeviceInfo
+type ShellyDeviceStatus = shelly_types.Status
+
+type callback interface {
+	GetConfig(context.Context) (*Config, error)
+	GetCTX() (context.Context, context.CancelFunc)
+	WriteStdout(input any) error
+}
+
+func New(t callback) *cobra.Command {
+
+	var etagArg string
+	var matchArg string
+
+	// single calls fn with a client for the one and only hostname
+	single := func(fn func(ctx context.Context, client *ShellyClient) (any, error)) error {
+
+		ctx, cancel := t.GetCTX()
+		defer cancel()
+
+		config, err := t.GetConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(config.Hostnames) != 1 {
+			return fmt.Errorf("one and only one hostname is required for this command")
+		}
+
+		client, err := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		result, err := fn(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		return t.WriteStdout(result)
+	}
+
+	// run calls fn on each device
+	run := func(action string, fn func(ctx context.Context, client *ShellyClient) error) error {
+
+		ctx, cancel := t.GetCTX()
+		defer cancel()
+
+		config, err := t.GetConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		do := func(ctx context.Context, hostname string, client *ShellyClient, deviceInfo *ShellyDeviceInfo, deviceStatus *ShellyDeviceStatus) error {
+
+			err := fn(ctx, client)
+			if err != nil {
+				t.WriteStdout(fmt.Sprintf("hostname %s, deviceID %s, deviceApp %s: [%s] failed with error %s", hostname, *deviceInfo.ID, *deviceInfo.App, action, err.Error()))
+				return err
+			}
+
+			t.WriteStdout(fmt.Sprintf("hostname %s, deviceID %s, deviceApp %s: [%s] completed", hostname, *deviceInfo.ID, *deviceInfo.App, action))
+			return nil
+		}
+
+		return util.Process(ctx, config, action, false, do)
+	}
+
+	rootCmd := &cobra.Command{
+		Use:   "kvs",
+		Short: "Get, set, list and delete keys of the key-value store (KVS)",
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Returns the value and etag of the key",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			if len(args) != 1 {
+				return fmt.Errorf("one key is required")
+			}
+
+			return single(func(ctx context.Context, client *ShellyClient) (any, error) {
+				return client.KVS().Get(ctx, args[0])
+			})
+		},
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Sets the value of the key. The value is parsed as JSON; if it is not valid JSON it is set as a string",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			if len(args) != 2 {
+				return fmt.Errorf("key and value are required")
+			}
+
+			key := args[0]
+
+			var value any
+			if err := json.Unmarshal([]byte(args[1]), &value); err != nil {
+				value = args[1]
+			}
+
+			return run("set "+key, func(ctx context.Context, client *ShellyClient) error {
+				if etagArg != "" {
+					_, err := client.KVS().SetIfMatch(ctx, key, value, etagArg)
+					return err
+				}
+				_, err := client.KVS().Set(ctx, key, value)
+				return err
+			})
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "Returns the keys with their etags and values",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return single(func(ctx context.Context, client *ShellyClient) (any, error) {
+				return client.KVS().GetMany(ctx, matchArg)
+			})
+		},
+	}
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete <key>",
+		Short: "Deletes the key",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			if len(args) != 1 {
+				return fmt.Errorf("one key is required")
+			}
+
+			key := args[0]
+
+			return run("delete "+key, func(ctx context.Context, client *ShellyClient) error {
+				if etagArg != "" {
+					return client.KVS().DeleteIfMatch(ctx, key, etagArg)
+				}
+				return client.KVS().Delete(ctx, key)
+			})
+		},
+	}
+
+	setCmd.PersistentFlags().StringVar(&etagArg, "etag", "", "Only set the value if the etag of the current value matches")
+	deleteCmd.PersistentFlags().StringVar(&etagArg, "etag", "", "Only delete the key if the etag of the current value matches")
+	listCmd.PersistentFlags().StringVar(&matchArg, "match", "", "Only return the keys that match the pattern such as scene_*")
+
+	rootCmd.AddCommand(getCmd, setCmd, listCmd, deleteCmd)
+	return rootCmd
+}
@@ -135,35 +135,16 @@ func New(t callback) *cobra.Command {
 
 				for i, light := range deviceStatus.Light {
 
+					light.Source = &config.Mqtt.LightSource
+
 					m := &MqttStatus{
 						Src:    *deviceInfo.ID,
 						Dst:    topic,
 						Method: "NotifyStatus",
-						Params: &Params{},
-					}
-
-					m.Params.Ts = &ts
-					light.Source = &config.Mqtt.LightSource
-
-					switch i {
-
-					case 0:
-						m.Params.Light0 = light
-					case 1:
-						m.Params.Light1 = light
-					case 2:
-						m.Params.Light2 = light
-					case 3:
-						m.Params.Light3 = light
-					case 4:
-						m.Params.Light4 = light
-					case 5:
-						m.Params.Light5 = light
-					case 6:
-						m.Params.Light6 = light
-					case 7:
-						m.Params.Light7 = light
-
+						Params: Params{
+							"ts":                       ts,
+							fmt.Sprintf("light:%d", i): light,
+						},
 					}
 
 					results = append(results, m)
@@ -7,12 +7,9 @@ import (
 
 	sdk_client "github.com/jodydadescott/shelly-client/sdk/client"
 	sdk_types "github.com/jodydadescott/shelly-client/sdk/client/types"
-	light_types "github.com/jodydadescott/shelly-client/sdk/light/types"
 	shelly_types "github.com/jodydadescott/shelly-client/sdk/shelly/types"
 )
 
-type LightStatus = light_types.Status
-
 type ShellyClient = sdk_client.Client
 
 type ShellyDeviceInfo = shelly_types.DeviceInfo
@@ -42,20 +39,11 @@ func (t *Config) Clone() *Config {
 }
 
 type MqttStatus struct {
-	Src    string  `json:"src,omitempty" yaml:"src,omitempty"`
-	Dst    string  `json:"dst,omitempty" yaml:"dst,omitempty"`
-	Method string  `json:"method,omitempty" yaml:"method,omitempty"`
-	Params *Params `json:"params,omitempty" yaml:"params,omitempty"`
+	Src    string `json:"src,omitempty" yaml:"src,omitempty"`
+	Dst    string `json:"dst,omitempty" yaml:"dst,omitempty"`
+	Method string `json:"method,omitempty" yaml:"method,omitempty"`
+	Params Params `json:"params,omitempty" yaml:"params,omitempty"`
 }
 
-type Params struct {
-	Ts     *float64     `json:"ts,omitempty" yaml:"ts,omitempty"`
-	Light0 *LightStatus `json:"light:0,omitempty" yaml:"light:0,omitempty"`
-	Light1 *LightStatus `json:"light:1,omitempty" yaml:"light:1,omitempty"`
-	Light2 *LightStatus `json:"light:2,omitempty" yaml:"light:2,omitempty"`
-	Light3 *LightStatus `json:"light:3,omitempty" yaml:"light:3,omitempty"`
-	Light4 *LightStatus `json:"light:4,omitempty" yaml:"light:4,omitempty"`
-	Light5 *LightStatus `json:"light:5,omitempty" yaml:"light:5,omitempty"`
-	Light6 *LightStatus `json:"light:6,omitempty" yaml:"light:6,omitempty"`
-	Light7 *LightStatus `json:"light:7,omitempty" yaml:"light:7,omitempty"`
-}
+// Params the params of a NotifyStatus keyed by ts and by component key such as light:0
+type Params map[string]interface{}
@@ -0,0 +1,190 @@
+package script
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+
+	"github.com/jodydadescott/shelly-client/cmd/types"
+	"github.com/jodydadescott/shelly-client/cmd/util"
+	sdk_client "github.com/jodydadescott/shelly-client/sdk/client"
+	shelly_types "github.com/jodydadescott/shelly-client/sdk/shelly/types"
+)
+
+type Config = types.Config
+
+type ShellyClient = sdk_client.Client
+
+type ShellyDeviceInfo = shelly_types.DeviceInfo
+type ShellyDeviceStatus = shelly_types.Status
+
+type callback interface {
+	GetConfig(context.Context) (*Config, error)
+	GetCTX() (context.Context, context.CancelFunc)
+	WriteStdout(input any) error
+}
+
+func New(t callback) *cobra.Command {
+
+	getIds := func(args []string) ([]int, error) {
+
+		if len(args) == 0 {
+			return nil, fmt.Errorf("one or more IDs is required. They can be space of comma delineated")
+		}
+
+		var results []int
+		var errors *multierror.Error
+
+		for _, arg := range args {
+			for _, sub := range strings.Split((strings.TrimSpace(arg)), ",") {
+				id, err := strconv.Atoi(sub)
+				if err != nil {
+					errors = multierror.Append(errors, err)
+				} else {
+					results = append(results, id)
+				}
+			}
+		}
+
+		return results, errors.ErrorOrNil()
+	}
+
+	// run calls fn for each script ID in args on each device
+	run := func(action string, args []string, fn func(ctx context.Context, client *ShellyClient, id int) error) error {
+
+		ids, err := getIds(args)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := t.GetCTX()
+		defer cancel()
+
+		config, err := t.GetConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		do := func(ctx context.Context, hostname string, client *ShellyClient, deviceInfo *ShellyDeviceInfo, deviceStatus *ShellyDeviceStatus) error {
+
+			var errors *multierror.Error
+
+			for _, id := range ids {
+				err := fn(ctx, client, id)
+				if err != nil {
+					t.WriteStdout(fmt.Sprintf("hostname %s, deviceID %s, deviceApp %s, scriptID %d: [%s] failed with error %s", hostname, *deviceInfo.ID, *deviceInfo.App, id, action, err.Error()))
+					errors = multierror.Append(errors, err)
+				} else {
+					t.WriteStdout(fmt.Sprintf("hostname %s, deviceID %s, deviceApp %s, scriptID %d: [%s] completed", hostname, *deviceInfo.ID, *deviceInfo.App, id, action))
+				}
+			}
+
+			return errors.ErrorOrNil()
+		}
+
+		return util.Process(ctx, config, action, false, do)
+	}
+
+	rootCmd := &cobra.Command{
+		Use:   "script",
+		Short: "List, start and stop scripts and show their output",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "Returns the scripts of the device",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			ctx, cancel := t.GetCTX()
+			defer cancel()
+
+			config, err := t.GetConfig(ctx)
+			if err != nil {
+				return err
+			}
+
+			if len(config.Hostnames) != 1 {
+				return fmt.Errorf("one and only one hostname is required for this command")
+			}
+
+			client, err := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			result, err := client.Script().List(ctx)
+			if err != nil {
+				return err
+			}
+
+			return t.WriteStdout(result)
+		},
+	}
+
+	startCmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start script",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run("start", args, func(ctx context.Context, client *ShellyClient, id int) error {
+				return client.Script().Start(ctx, id)
+			})
+		},
+	}
+
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop script",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run("stop", args, func(ctx context.Context, client *ShellyClient, id int) error {
+				return client.Script().Stop(ctx, id)
+			})
+		},
+	}
+
+	logsCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Shows the debug log of the device which includes the output of scripts until interrupted. The debug log over websocket must be enabled (sys.debug.websocket.enable)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			ctx, cancel := t.GetCTX()
+			defer cancel()
+
+			config, err := t.GetConfig(ctx)
+			if err != nil {
+				return err
+			}
+
+			if len(config.Hostnames) != 1 {
+				return fmt.Errorf("one and only one hostname is required for this command")
+			}
+
+			client, err := util.NewShellyClient(config, util.CleanupHostname(config.Hostnames[0]))
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			entries, err := client.DebugLog(ctx)
+			if err != nil {
+				return fmt.Errorf("%w; the debug log over websocket may not be enabled (sys.debug.websocket.enable)", err)
+			}
+
+			for entry := range entries {
+				err := t.WriteStdout(entry.String())
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	rootCmd.AddCommand(listCmd, startCmd, stopCmd, logsCmd)
+	return rootCmd
+}
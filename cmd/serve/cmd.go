@@ -0,0 +1,94 @@
+package serve
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jodydadescott/shelly-client/cmd/types"
+	sdk_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/server"
+)
+
+type Config = types.Config
+type ShellyConfig = sdk_types.Config
+type ServerConfig = server.Config
+
+type callback interface {
+	GetConfig(context.Context) (*Config, error)
+	GetCTX() (context.Context, context.CancelFunc)
+	WriteStdout(input any) error
+	WriteStderr(input any) error
+}
+
+func New(t callback) *cobra.Command {
+
+	var listenArg string
+	var pathArg string
+
+	rootCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Runs a server that devices connect to",
+	}
+
+	wsCmd := &cobra.Command{
+		Use:   "ws",
+		Short: "Accepts outbound websocket connections from devices and writes their notifications to STDOUT",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			ctx, cancel := t.GetCTX()
+			defer cancel()
+
+			config, err := t.GetConfig(ctx)
+			if err != nil {
+				return err
+			}
+
+			clientConfig := &ShellyConfig{}
+			if config.Shelly != nil {
+				clientConfig = config.Shelly.Clone()
+			}
+
+			s := server.New(&ServerConfig{
+				ListenAddress: listenArg,
+				Path:          pathArg,
+				Client:        clientConfig,
+			})
+
+			go func() {
+				for {
+					select {
+
+					case <-ctx.Done():
+						return
+
+					case device := <-s.Connected():
+						// The device info is resolved in its own goroutine so that the
+						// notifications are drained while waiting on a slow device
+						go func() {
+							deviceInfo, err := device.Client.GetDeviceInfo(ctx)
+							if err != nil {
+								t.WriteStderr(fmt.Sprintf("deviceID %s: [connected] device info failed with error %s", device.ID, err.Error()))
+								return
+							}
+							t.WriteStderr(fmt.Sprintf("deviceID %s, deviceApp %s: [connected]", device.ID, *deviceInfo.App))
+						}()
+
+					case notification := <-s.Notifications():
+						t.WriteStdout(notification)
+
+					}
+				}
+			}()
+
+			return s.Run(ctx)
+		},
+	}
+
+	wsCmd.PersistentFlags().StringVar(&listenArg, "listen", "", "Address to listen on; default is :8080")
+	wsCmd.PersistentFlags().StringVar(&pathArg, "path", "", "HTTP path devices connect to; default is /")
+
+	rootCmd.AddCommand(wsCmd)
+	return rootCmd
+}
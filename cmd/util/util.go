@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	logger "github.com/jodydadescott/jody-go-logger"
@@ -31,17 +32,40 @@ type OpenHABConfig = openhab.Config
 
 type Config = types.Config
 
-func NewShellyClient(config *Config, hostname string) *ShellyClient {
+// NewShellyClient returns a client for the hostname using the shelly config. An error is
+// returned if the client can not be created such as when the record or replay file can
+// not be opened.
+func NewShellyClient(config *Config, hostname string) (*ShellyClient, error) {
 
 	if config.Shelly == nil {
-		panic("shelly config is required")
+		return nil, fmt.Errorf("shelly config is required")
 	}
 
 	newShellyConfig := &ShellyConfig{
-		Hostname:      hostname,
-		Username:      config.Shelly.Username,
-		Password:      config.Shelly.Password,
-		ShellyConfigs: config.Shelly.ShellyConfigs,
+		Hostname:       hostname,
+		Username:       config.Shelly.Username,
+		Password:       config.Shelly.Password,
+		ShellyConfigs:  config.Shelly.ShellyConfigs,
+		Transport:      config.Shelly.Transport,
+		MqttTransport:  config.Shelly.MqttTransport,
+		UDPTransport:   config.Shelly.UDPTransport,
+		Scheme:         config.Shelly.Scheme,
+		TLS:            config.Shelly.TLS,
+		RecordFile:     config.Shelly.RecordFile,
+		ReplayFile:     config.Shelly.ReplayFile,
+		Credentials:    config.Shelly.Credentials,
+		SendTimeout:    config.Shelly.SendTimeout,
+		MethodTimeouts: config.Shelly.MethodTimeouts,
+	}
+
+	// The timeout from the arg, envvar or config applies to every method that does not
+	// have its own timeout in the shelly config
+	if config.Timeout != nil {
+		newShellyConfig.SendTimeout = *config.Timeout
+		newShellyConfig.MethodTimeouts = map[string]time.Duration{"*": *config.Timeout}
+		for method, timeout := range config.Shelly.MethodTimeouts {
+			newShellyConfig.MethodTimeouts[method] = timeout
+		}
 	}
 
 	return sdk_client.New(newShellyConfig)
@@ -69,9 +93,18 @@ func Process(ctx context.Context, config *Config, action string, getDevStatus bo
 
 		hostname = CleanupHostname(hostname)
 
-		client := NewShellyClient(config, hostname)
+		client, err := NewShellyClient(config, hostname)
+		if err != nil {
+			return fmt.Errorf("workerID %d, hostname %s, [client] failed with error %w", workerID, hostname, err)
+		}
+
 		defer client.Close()
 
+		err = client.Connect(ctx)
+		if err != nil {
+			return fmt.Errorf("workerID %d, hostname %s, [connect] failed with error %w", workerID, hostname, err)
+		}
+
 		deviceInfo, err := client.GetDeviceInfo(ctx)
 		if err != nil {
 			return fmt.Errorf("workerID %d, hostname %s, [deviceInfo] failed with error %w", workerID, hostname, err)
@@ -161,7 +194,7 @@ func Process(ctx context.Context, config *Config, action string, getDevStatus bo
 
 	if jobCount == 1 {
 		zap.L().Debug("there is only 1 job to execute")
-		return execute(ctx, config.Hostnames[0], 0)
+		return execute(ctx, hostnames[0], 0)
 	}
 
 	zap.L().Debug(fmt.Sprintf("there are %d jobs to execute", jobCount))
@@ -208,7 +241,7 @@ func Process(ctx context.Context, config *Config, action string, getDevStatus bo
 		}()
 	}
 
-	workerCount := 0
+	workerCount := 1
 
 	if jobCount > 5 {
 		workerCount = 4
@@ -218,16 +251,17 @@ func Process(ctx context.Context, config *Config, action string, getDevStatus bo
 		workerCount = 8
 	}
 
+	// The jobs are queued first as a worker returns when there are no more jobs
+	for _, hostname := range hostnames {
+		jobchan <- hostname
+	}
+
 	zap.L().Debug(fmt.Sprintf("starting %d workers", workerCount))
 
 	for i := 0; i < workerCount; i++ {
 		worker(i)
 	}
 
-	for _, hostname := range hostnames {
-		jobchan <- hostname
-	}
-
 	var errors *multierror.Error
 
 	zap.L().Debug("waiting on jobs to complete")
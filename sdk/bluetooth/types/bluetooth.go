@@ -22,6 +22,9 @@ type Result struct {
 }
 
 // GetConfigResponse internal use only
+//
+// Deprecated: the client decodes the result with rpc.Call. This type is no longer used
+// and will be removed.
 type GetConfigResponse struct {
 	Response
 	Result *Config `json:"result,omitempty"`
@@ -29,12 +32,18 @@ type GetConfigResponse struct {
 }
 
 // SetConfigResponse internal use only
+//
+// Deprecated: the client decodes the result with rpc.Call. This type is no longer used
+// and will be removed.
 type SetConfigResponse struct {
 	Response
 	Result *Result `json:"result,omitempty"`
 }
 
 // GetStatusResponse internal use only
+//
+// Deprecated: the client decodes the result with rpc.Call. This type is no longer used
+// and will be removed.
 type GetStatusResponse struct {
 	Response
 	Result *Status `json:"result,omitempty"`
@@ -79,6 +88,10 @@ func (t *Config) Clone() *Config {
 // Sanatize sanatizes config
 func (t *Config) Sanatize() {
 
+	if t == nil {
+		return
+	}
+
 	if t.Enable == nil || !*t.Enable {
 		t.RPC = nil
 		t.Observer = nil
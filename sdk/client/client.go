@@ -2,6 +2,8 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -9,16 +11,34 @@ import (
 	"github.com/jodydadescott/shelly-client/sdk/bluetooth"
 	"github.com/jodydadescott/shelly-client/sdk/client/types"
 	"github.com/jodydadescott/shelly-client/sdk/cloud"
+	"github.com/jodydadescott/shelly-client/sdk/cover"
+	"github.com/jodydadescott/shelly-client/sdk/debuglog"
+	"github.com/jodydadescott/shelly-client/sdk/devicepower"
+	"github.com/jodydadescott/shelly-client/sdk/em"
+	"github.com/jodydadescott/shelly-client/sdk/em1"
+	"github.com/jodydadescott/shelly-client/sdk/em1data"
+	"github.com/jodydadescott/shelly-client/sdk/emdata"
 	"github.com/jodydadescott/shelly-client/sdk/ethernet"
+	"github.com/jodydadescott/shelly-client/sdk/humidity"
+	"github.com/jodydadescott/shelly-client/sdk/illuminance"
 	"github.com/jodydadescott/shelly-client/sdk/input"
+	"github.com/jodydadescott/shelly-client/sdk/interceptor"
+	"github.com/jodydadescott/shelly-client/sdk/kvs"
 	"github.com/jodydadescott/shelly-client/sdk/light"
 	"github.com/jodydadescott/shelly-client/sdk/mqtt"
 	"github.com/jodydadescott/shelly-client/sdk/msghandlers"
 	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/notification"
+	"github.com/jodydadescott/shelly-client/sdk/pm1"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
+	"github.com/jodydadescott/shelly-client/sdk/schedule"
+	"github.com/jodydadescott/shelly-client/sdk/script"
 	"github.com/jodydadescott/shelly-client/sdk/shelly"
 	shelly_types "github.com/jodydadescott/shelly-client/sdk/shelly/types"
 	"github.com/jodydadescott/shelly-client/sdk/switchx"
 	"github.com/jodydadescott/shelly-client/sdk/system"
+	"github.com/jodydadescott/shelly-client/sdk/temperature"
+	"github.com/jodydadescott/shelly-client/sdk/webhook"
 	"github.com/jodydadescott/shelly-client/sdk/websocket"
 	"github.com/jodydadescott/shelly-client/sdk/wifi"
 )
@@ -30,42 +50,146 @@ type Config = types.Config
 type ShellyStatus = shelly_types.Status
 type ConfigReport = shelly_types.ConfigReport
 type ShellyRPCMethods = shelly_types.RPCMethods
+type ShellyComponents = shelly_types.Components
+type ShellyComponentsConfig = shelly_types.ComponentsConfig
 type ShellyConfig = types.ShellyConfig
 type ShelllyDeviceInfo = shelly_types.DeviceInfo
 type ShellyUpdateConfig = shelly_types.UpdateConfig
 type UpdatesReport = shelly_types.UpdatesReport
+type Notification = notification.Notification
+type NotificationFilter = notification.Filter
+type RetryPolicy = msg_types.RetryPolicy
+type TLSConfig = types.TLSConfig
+type Error = msg_types.Error
+type CredentialsConfig = types.CredentialsConfig
+type CredentialProvider = types.CredentialProvider
+type Interceptor = types.Interceptor
+type TemplateVars = shelly_types.TemplateVars
+type DebugLogEntry = debuglog.Entry
+
+// ErrOutcomeUnknown is returned when a request that is not safe to retry was sent but no
+// response was received
+var ErrOutcomeUnknown = msg_types.ErrOutcomeUnknown
+
+// Sentinel errors that can be tested with errors.Is. Use errors.As with *Error to get the
+// code, device ID and method of an error returned by the device.
+var (
+	ErrNotFound           = msg_types.ErrNotFound
+	ErrInvalidArgument    = msg_types.ErrInvalidArgument
+	ErrFailedPrecondition = msg_types.ErrFailedPrecondition
+	ErrUnavailable        = msg_types.ErrUnavailable
+	ErrTimeout            = msg_types.ErrTimeout
+	ErrUnauthorized       = msg_types.ErrUnauthorized
+	ErrDisconnected       = msg_types.ErrDisconnected
+	ErrNotImplemented     = msg_types.ErrNotImplemented
+)
+
+// WithRetryPolicy returns a context that overrides the retry policy for requests sent with
+// it. By default only methods starting with Get, List or Check are retried. Use
+// msg_types.RetryAlways or msg_types.RetryNever for the common overrides.
+func WithRetryPolicy(ctx context.Context, retryPolicy RetryPolicy) context.Context {
+	return msg_types.WithRetryPolicy(ctx, retryPolicy)
+}
+
+// Call sends the method with the params using the handler and returns the result decoded
+// as R. Use NewHandle on the client to get a handler. This can be used to call methods not
+// modeled by this SDK such as Shelly.ListTimezones with your own structs. If params is nil
+// the request is sent without params.
+func Call[P, R any](ctx context.Context, handler MessageHandler, method string, params P) (R, error) {
+	return rpc.Call[P, R](ctx, handler, method, params)
+}
+
+// Exec sends the method with the params using the handler and discards the result
+func Exec[P any](ctx context.Context, handler MessageHandler, method string, params P) error {
+	return rpc.Exec(ctx, handler, method, params)
+}
 
 type Client struct {
-	_system    *system.Client
-	shelly     *shelly.Client
-	_wifi      *wifi.Client
-	_bluetooth *bluetooth.Client
-	_mqtt      *mqtt.Client
-	_cloud     *cloud.Client
-	_switch    *switchx.Client
-	_light     *light.Client
-	_input     *input.Client
-	_websocket *websocket.Client
-	_ethernet  *ethernet.Client
+	componentMutex sync.Mutex
+	_system        *system.Client
+	shelly         *shelly.Client
+	_wifi          *wifi.Client
+	_bluetooth     *bluetooth.Client
+	_mqtt          *mqtt.Client
+	_cloud         *cloud.Client
+	_switch        *switchx.Client
+	_cover         *cover.Client
+	_em            *em.Client
+	_em1           *em1.Client
+	_pm1           *pm1.Client
+	_emData        *emdata.Client
+	_em1Data       *em1data.Client
+	_temperature   *temperature.Client
+	_humidity      *humidity.Client
+	_illuminance   *illuminance.Client
+	_devicePower   *devicepower.Client
+	_schedule      *schedule.Client
+	_webhook       *webhook.Client
+	_script        *script.Client
+	_kvs           *kvs.Client
+	_light         *light.Client
+	_input         *input.Client
+	_websocket     *websocket.Client
+	_ethernet      *ethernet.Client
 	MessageHandlerFactory
 	config *Config
 }
 
-func New(config *Config) *Client {
+// New returns a new client for the config. An error is returned if the message handler
+// can not be created such as when the record or replay file can not be opened.
+func New(config *Config) (*Client, error) {
+
+	messageHandlerFactory, err := msghandlers.New(&msghandlers.Config{
+		Hostname:           config.Hostname,
+		Password:           config.Password,
+		Username:           config.Username,
+		SendTimeout:        config.SendTimeout,
+		RetryWait:          config.RetryWait,
+		RetryMaxWait:       config.RetryMaxWait,
+		SendTrys:           config.SendTrys,
+		Transport:          config.Transport,
+		MqttTransport:      config.MqttTransport,
+		UDPTransport:       config.UDPTransport,
+		Scheme:             config.Scheme,
+		TLS:                config.TLS,
+		RecordFile:         config.RecordFile,
+		ReplayFile:         config.ReplayFile,
+		Credentials:        config.Credentials,
+		CredentialProvider: config.CredentialProvider,
+		MethodTimeouts:     config.MethodTimeouts,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithMessageHandlerFactory(config, messageHandlerFactory), nil
+}
+
+// NewWithMessageHandlerFactory returns a new client that uses the specified MessageHandlerFactory
+// instead of creating one from the config
+func NewWithMessageHandlerFactory(config *Config, messageHandlerFactory MessageHandlerFactory) *Client {
+
 	t := &Client{
-		config: config,
-		MessageHandlerFactory: msghandlers.NewWS(&msghandlers.Config{
-			Hostname:    config.Hostname,
-			Password:    config.Password,
-			Username:    config.Username,
-			SendTimeout: config.SendTimeout,
-		}),
+		config:                config,
+		MessageHandlerFactory: messageHandlerFactory,
 	}
 
 	t.shelly = shelly.New(t)
 	return t
 }
 
+// NewHandle returns a handle from the MessageHandlerFactory wrapped with the interceptors
+// from the config. All component clients get their handle from this.
+func (t *Client) NewHandle(name string) MessageHandler {
+	return interceptor.Wrap(t.MessageHandlerFactory.NewHandle(name), t.config.Interceptors...)
+}
+
+// Hostname returns the hostname used to connect to the device
+func (t *Client) Hostname() string {
+	return t.config.Hostname
+}
+
 func (t *Client) GetShellyConfigByName(name string) *ShellyConfig {
 
 	if t.config.ShellyConfigs == nil {
@@ -81,6 +205,8 @@ func (t *Client) GetShellyConfigByName(name string) *ShellyConfig {
 }
 
 func (t *Client) System() *system.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
 	if t._system == nil {
 		t._system = system.New(t)
 	}
@@ -88,6 +214,8 @@ func (t *Client) System() *system.Client {
 }
 
 func (t *Client) Bluetooth() *bluetooth.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
 	if t._bluetooth == nil {
 		t._bluetooth = bluetooth.New(t)
 	}
@@ -95,6 +223,8 @@ func (t *Client) Bluetooth() *bluetooth.Client {
 }
 
 func (t *Client) Mqtt() *mqtt.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
 	if t._mqtt == nil {
 		t._mqtt = mqtt.New(t)
 	}
@@ -102,6 +232,8 @@ func (t *Client) Mqtt() *mqtt.Client {
 }
 
 func (t *Client) Ethernet() *ethernet.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
 	if t._ethernet == nil {
 		t._ethernet = ethernet.New(t)
 	}
@@ -109,6 +241,8 @@ func (t *Client) Ethernet() *ethernet.Client {
 }
 
 func (t *Client) Wifi() *wifi.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
 	if t._wifi == nil {
 		t._wifi = wifi.New(t)
 	}
@@ -116,6 +250,8 @@ func (t *Client) Wifi() *wifi.Client {
 }
 
 func (t *Client) Cloud() *cloud.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
 	if t._cloud == nil {
 		t._cloud = cloud.New(t)
 	}
@@ -123,13 +259,143 @@ func (t *Client) Cloud() *cloud.Client {
 }
 
 func (t *Client) Switch() *switchx.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
 	if t._switch == nil {
 		t._switch = switchx.New(t)
 	}
 	return t._switch
 }
 
+func (t *Client) Cover() *cover.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
+	if t._cover == nil {
+		t._cover = cover.New(t)
+	}
+	return t._cover
+}
+
+func (t *Client) EM() *em.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
+	if t._em == nil {
+		t._em = em.New(t)
+	}
+	return t._em
+}
+
+func (t *Client) EM1() *em1.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
+	if t._em1 == nil {
+		t._em1 = em1.New(t)
+	}
+	return t._em1
+}
+
+func (t *Client) PM1() *pm1.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
+	if t._pm1 == nil {
+		t._pm1 = pm1.New(t)
+	}
+	return t._pm1
+}
+
+func (t *Client) Temperature() *temperature.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
+	if t._temperature == nil {
+		t._temperature = temperature.New(t)
+	}
+	return t._temperature
+}
+
+func (t *Client) Humidity() *humidity.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
+	if t._humidity == nil {
+		t._humidity = humidity.New(t)
+	}
+	return t._humidity
+}
+
+func (t *Client) Illuminance() *illuminance.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
+	if t._illuminance == nil {
+		t._illuminance = illuminance.New(t)
+	}
+	return t._illuminance
+}
+
+func (t *Client) DevicePower() *devicepower.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
+	if t._devicePower == nil {
+		t._devicePower = devicepower.New(t)
+	}
+	return t._devicePower
+}
+
+func (t *Client) EMData() *emdata.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
+	if t._emData == nil {
+		t._emData = emdata.New(t)
+	}
+	return t._emData
+}
+
+func (t *Client) EM1Data() *em1data.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
+	if t._em1Data == nil {
+		t._em1Data = em1data.New(t)
+	}
+	return t._em1Data
+}
+
+func (t *Client) Schedule() *schedule.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
+	if t._schedule == nil {
+		t._schedule = schedule.New(t)
+	}
+	return t._schedule
+}
+
+func (t *Client) Webhook() *webhook.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
+	if t._webhook == nil {
+		t._webhook = webhook.New(t)
+	}
+	return t._webhook
+}
+
+func (t *Client) Script() *script.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
+	if t._script == nil {
+		t._script = script.New(t)
+	}
+	return t._script
+}
+
+func (t *Client) KVS() *kvs.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
+	if t._kvs == nil {
+		t._kvs = kvs.New(t)
+	}
+	return t._kvs
+}
+
 func (t *Client) Light() *light.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
 	if t._light == nil {
 		t._light = light.New(t)
 	}
@@ -137,6 +403,8 @@ func (t *Client) Light() *light.Client {
 }
 
 func (t *Client) Input() *input.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
 	if t._input == nil {
 		t._input = input.New(t)
 	}
@@ -144,6 +412,8 @@ func (t *Client) Input() *input.Client {
 }
 
 func (t *Client) Websocket() *websocket.Client {
+	t.componentMutex.Lock()
+	defer t.componentMutex.Unlock()
 	if t._websocket == nil {
 		t._websocket = websocket.New(t)
 	}
@@ -160,6 +430,12 @@ func (t *Client) GetStatus(ctx context.Context) (*ShellyStatus, error) {
 	return t.shelly.GetStatus(ctx)
 }
 
+// GetComponents returns the components of the device such as switch:0 or em1:0. The
+// config may be nil in which case only the keys are returned.
+func (t *Client) GetComponents(ctx context.Context, config *ShellyComponentsConfig) (*ShellyComponents, error) {
+	return t.shelly.GetComponents(ctx, config)
+}
+
 // ListMethods lists all available RPC methods. It takes into account both ACL and authentication restrictions
 // and only lists the methods allowed for the particular user/channel that's making the request.
 func (t *Client) ListMethods(ctx context.Context) (*ShellyRPCMethods, error) {
@@ -208,6 +484,48 @@ func (t *Client) Reboot(ctx context.Context) error {
 	return t.shelly.Reboot(ctx)
 }
 
+// Subscribe returns a channel of notifications sent by the device such as NotifyStatus and
+// NotifyEvent that match the filter. A nil filter matches all notifications. The channel is
+// closed when the context is cancelled or the client is closed. The transport must support
+// notifications; the ws and mqtt transports do.
+func (t *Client) Subscribe(ctx context.Context, filter *NotificationFilter) (<-chan *Notification, error) {
+
+	subscriber, ok := t.MessageHandlerFactory.(notification.Subscriber)
+	if !ok {
+		return nil, fmt.Errorf("transport does not support notifications")
+	}
+
+	// The device only sends notifications after it has received a request with a src
+	// on the connection. GetDeviceInfo is cached so Sys.GetStatus is used instead.
+	_, err := t.System().GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriber.Subscribe(ctx, filter), nil
+}
+
+// DebugLog returns a channel of the debug log entries of the device such as the output of
+// scripts. The channel is closed when the context is cancelled or the connection is lost.
+// The log is streamed over websocket directly from the hostname of the config so the
+// debug log over websocket must be enabled on the device (sys.debug.websocket.enable).
+func (t *Client) DebugLog(ctx context.Context) (<-chan *DebugLogEntry, error) {
+	return debuglog.Stream(ctx, t.config)
+}
+
+// Connect establishes the connection to the device for transports that hold one and
+// returns the dial or handshake error if it fails. For stateless transports it does
+// nothing.
+func (t *Client) Connect(ctx context.Context) error {
+
+	connector, ok := t.MessageHandlerFactory.(msg_types.Connector)
+	if !ok {
+		return nil
+	}
+
+	return connector.Connect(ctx)
+}
+
 func ExampleConfig() *Config {
 	return &Config{
 		Password:      "my password",
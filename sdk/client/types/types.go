@@ -5,10 +5,37 @@ import (
 
 	"github.com/jinzhu/copier"
 
+	credentials_types "github.com/jodydadescott/shelly-client/sdk/credentials/types"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
 	shelly_types "github.com/jodydadescott/shelly-client/sdk/shelly/types"
 )
 
 type ShellyConfig = shelly_types.Config
+type CredentialsConfig = credentials_types.Config
+type CredentialProvider = credentials_types.CredentialProvider
+type Interceptor = msg_types.Interceptor
+
+const (
+	// TransportWS uses a persistent websocket connection to the device. This is the default.
+	TransportWS = "ws"
+	// TransportHTTP uses a stateless HTTP POST to the device for each request.
+	TransportHTTP = "http"
+	// TransportMQTT publishes requests to the device through an MQTT broker.
+	TransportMQTT = "mqtt"
+	// TransportUDP sends requests to the device's RPC over UDP listener.
+	TransportUDP = "udp"
+)
+
+const (
+	// SchemeWS plain websocket. This is the default for the ws transport.
+	SchemeWS = "ws"
+	// SchemeWSS websocket over TLS
+	SchemeWSS = "wss"
+	// SchemeHTTP plain HTTP. This is the default for the http transport.
+	SchemeHTTP = "http"
+	// SchemeHTTPS HTTP over TLS
+	SchemeHTTPS = "https"
+)
 
 type Config struct {
 	Hostname      string                   `json:"hostname,omitempty" yaml:"hostname,omitempty"`
@@ -17,7 +44,31 @@ type Config struct {
 	Username      string                   `json:"username,omitempty" yaml:"username,omitempty"`
 	Password      string                   `json:"password,omitempty" yaml:"password,omitempty"`
 	RetryWait     time.Duration            `json:"retryWait,omitempty" yaml:"retryWait,omitempty"`
+	RetryMaxWait  time.Duration            `json:"retryMaxWait,omitempty" yaml:"retryMaxWait,omitempty"`
 	SendTrys      int                      `json:"sendTrys,omitempty" yaml:"sendTrys,omitempty"`
+	Transport     string                   `json:"transport,omitempty" yaml:"transport,omitempty"`
+	MqttTransport *MqttTransportConfig     `json:"mqttTransport,omitempty" yaml:"mqttTransport,omitempty"`
+	UDPTransport  *UDPTransportConfig      `json:"udpTransport,omitempty" yaml:"udpTransport,omitempty"`
+	Scheme        string                   `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	TLS           *TLSConfig               `json:"tls,omitempty" yaml:"tls,omitempty"`
+	// RecordFile if set each request/response pair is appended to this cassette file
+	RecordFile string `json:"recordFile,omitempty" yaml:"recordFile,omitempty"`
+	// ReplayFile if set responses are served from this cassette file and no network is used
+	ReplayFile string `json:"replayFile,omitempty" yaml:"replayFile,omitempty"`
+	// Credentials built-in credential providers consulted on an auth challenge before
+	// Username and Password
+	Credentials *CredentialsConfig `json:"credentials,omitempty" yaml:"credentials,omitempty"`
+	// CredentialProvider if set is consulted on an auth challenge before Credentials
+	CredentialProvider CredentialProvider `json:"-" yaml:"-"`
+	// Interceptors are applied to every handle created by the client. The first
+	// interceptor is the outermost and is called first.
+	Interceptors []Interceptor `json:"-" yaml:"-"`
+	// MethodTimeouts overrides the time to wait for the response by method. The key is a
+	// method name such as Wifi.Scan or a glob pattern such as Switch.* or *. Methods
+	// without an override use a built-in default if there is one and otherwise
+	// SendTimeout. If the context of the request has a deadline it is used instead and
+	// the overrides and SendTimeout are ignored.
+	MethodTimeouts map[string]time.Duration `json:"methodTimeouts,omitempty" yaml:"methodTimeouts,omitempty"`
 }
 
 // Clone return copy
@@ -33,3 +84,71 @@ func (t *Config) AddShellyConfig(name string, config *ShellyConfig) {
 	}
 	t.ShellyConfigs[name] = config
 }
+
+// MqttTransportConfig is the config for the MQTT transport. Requests are published to
+// <TopicPrefix>/rpc and the device replies on <Src>/rpc.
+type MqttTransportConfig struct {
+	// Broker address of the broker such as tcp://mybroker:1883. Required
+	Broker string `json:"broker,omitempty" yaml:"broker,omitempty"`
+	// Username for the broker. Optional
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	// Password for the broker. Optional
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+	// ClientID MQTT client ID. A random ID is generated if not set
+	ClientID string `json:"clientID,omitempty" yaml:"clientID,omitempty"`
+	// TopicPrefix the topic prefix configured on the device. Defaults to the hostname
+	// which is the same as the device ID when the device is using the default prefix
+	TopicPrefix string `json:"topicPrefix,omitempty" yaml:"topicPrefix,omitempty"`
+	// Src the source set on each request. The device replies on <Src>/rpc. Defaults to the ClientID
+	Src string `json:"src,omitempty" yaml:"src,omitempty"`
+}
+
+// Clone return copy
+func (t *MqttTransportConfig) Clone() *MqttTransportConfig {
+	c := &MqttTransportConfig{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// UDPTransportConfig is the config for the UDP transport. The device must have the
+// Sys rpc_udp listen_port configured.
+type UDPTransportConfig struct {
+	// Port the rpc_udp listen_port configured on the device. Required
+	Port int `json:"port,omitempty" yaml:"port,omitempty"`
+}
+
+// Clone return copy
+func (t *UDPTransportConfig) Clone() *UDPTransportConfig {
+	c := &UDPTransportConfig{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// TLSConfig is the TLS config used when the scheme is wss or https. This is useful when
+// the device is behind a TLS terminating reverse proxy.
+type TLSConfig struct {
+	// CAFile path to a PEM bundle of CAs used to verify the server. The system CAs are
+	// used if not set
+	CAFile string `json:"caFile,omitempty" yaml:"caFile,omitempty"`
+	// CertFile path to a PEM client certificate. Requires KeyFile
+	CertFile string `json:"certFile,omitempty" yaml:"certFile,omitempty"`
+	// KeyFile path to the PEM private key of the client certificate. Requires CertFile
+	KeyFile string `json:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+	// ServerName overrides the name sent with SNI and used to verify the server
+	// certificate. Defaults to the host of the hostname
+	ServerName string `json:"serverName,omitempty" yaml:"serverName,omitempty"`
+	// Pins set of base64 encoded SHA-256 hashes of the SubjectPublicKeyInfo of a
+	// certificate in the server chain, optionally prefixed with sha256/. If set at least
+	// one certificate in the chain must match.
+	Pins []string `json:"pins,omitempty" yaml:"pins,omitempty"`
+	// InsecureSkipVerify disables verification of the server certificate chain and name.
+	// Pins are still verified; this allows pinning a self signed certificate.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+}
+
+// Clone return copy
+func (t *TLSConfig) Clone() *TLSConfig {
+	c := &TLSConfig{}
+	copier.Copy(&c, &t)
+	return c
+}
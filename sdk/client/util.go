@@ -26,14 +26,31 @@ func GetConfig(ctx context.Context, client *Client) (*ShellyConfig, error) {
 	config := client.GetShellyConfigByName(*deviceInfo.ID)
 	if config != nil {
 		zap.L().Debug(fmt.Sprintf("retrieved config with deviceID %s", *deviceInfo.ID))
-		return config.Merge(commonConfig), nil
+		return render(client, config.Merge(commonConfig), *deviceInfo.ID)
 	}
 
 	config = client.GetShellyConfigByName(*deviceInfo.App)
 	if config != nil {
 		zap.L().Debug(fmt.Sprintf("retrieved config with deviceApp %s", *deviceInfo.App))
-		return config.Merge(commonConfig), nil
+		return render(client, config.Merge(commonConfig), *deviceInfo.ID)
 	}
 
 	return nil, fmt.Errorf("no config for deviceID %s, deviceApp %s found", *deviceInfo.ID, *deviceInfo.App)
 }
+
+// render returns a copy of the config with the templates such as the webhook URLs executed
+// for the device. The config may be shared by devices with the same app so it is not modified.
+func render(client *Client, config *ShellyConfig, deviceID string) (*ShellyConfig, error) {
+
+	config = config.Clone()
+
+	err := config.Render(&TemplateVars{
+		DeviceID: deviceID,
+		Hostname: client.Hostname(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("config for deviceID %s failed to render; %w", deviceID, err)
+	}
+
+	return config, nil
+}
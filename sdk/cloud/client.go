@@ -2,13 +2,15 @@ package cloud
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 
 	"go.uber.org/zap"
 
 	"github.com/jodydadescott/shelly-client/sdk/cloud/types"
 	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
 )
 
 type MessageHandlerFactory = msg_types.MessageHandlerFactory
@@ -17,9 +19,16 @@ type Request = msg_types.Request
 
 type Config = types.Config
 type Status = types.Status
+type Params = types.Params
+type Result = types.Result
+
+// Deprecated: GetStatusResponse is no longer used by the client.
 type GetStatusResponse = types.GetStatusResponse
+
+// Deprecated: GetConfigResponse is no longer used by the client.
 type GetConfigResponse = types.GetConfigResponse
-type Params = types.Params
+
+// Deprecated: SetConfigResponse is no longer used by the client.
 type SetConfigResponse = types.SetConfigResponse
 
 func New(messageHandlerFactory MessageHandlerFactory) *Client {
@@ -31,10 +40,14 @@ func New(messageHandlerFactory MessageHandlerFactory) *Client {
 // Client the component client
 type Client struct {
 	MessageHandlerFactory
-	_messageHandler MessageHandler
+	messageHandlerMutex sync.Mutex
+	_messageHandler     MessageHandler
 }
 
 func (t *Client) getMessageHandler() MessageHandler {
+	t.messageHandlerMutex.Lock()
+	defer t.messageHandlerMutex.Unlock()
+
 	if t._messageHandler != nil {
 		return t._messageHandler
 	}
@@ -47,6 +60,13 @@ func getErr(method string, err error) error {
 	if err == nil {
 		return nil
 	}
+
+	// An error returned by the device already has the method and device ID
+	rpcErr := &msg_types.Error{}
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
 	return fmt.Errorf("component %s, method %s, error %w", Component, method, err)
 }
 
@@ -55,29 +75,12 @@ func (t *Client) GetStatus(ctx context.Context) (*Status, error) {
 
 	method := Component + ".GetStatus"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-	})
-
+	result, err := rpc.Call[any, *Status](ctx, t.getMessageHandler(), method, nil)
 	if err != nil {
 		return nil, getErr(method, err)
 	}
 
-	response := &GetStatusResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return nil, getErr(method, err)
-	}
-
-	if response.Error != nil {
-		return nil, getErr(method, response.Error)
-	}
-
-	if response.Result == nil {
-		getErr(method, fmt.Errorf("result is missing from response"))
-	}
-
-	return response.Result, nil
+	return result, nil
 }
 
 // GetConfig returns component config or error
@@ -85,28 +88,12 @@ func (t *Client) GetConfig(ctx context.Context) (*Config, error) {
 
 	method := Component + ".GetConfig"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-	})
+	result, err := rpc.Call[any, *Config](ctx, t.getMessageHandler(), method, nil)
 	if err != nil {
 		return nil, getErr(method, err)
 	}
 
-	response := &GetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return nil, getErr(method, err)
-	}
-
-	if response.Error != nil {
-		return nil, getErr(method, response.Error)
-	}
-
-	if response.Result == nil {
-		return nil, getErr(method, fmt.Errorf("result is missing from response"))
-	}
-
-	return response.Result, nil
+	return result, nil
 }
 
 // SetConfig applies config to device component. Returns reboot required or error.
@@ -127,35 +114,18 @@ func (t *Client) SetConfig(ctx context.Context, config *Config) (*bool, error) {
 		config.Enable = &falsex
 	}
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: &Params{
-			Config: config,
-		},
+	result, err := rpc.Call[*Params, *Result](ctx, t.getMessageHandler(), method, &Params{
+		Config: config,
 	})
 
 	if err != nil {
 		return nil, getErr(method, err)
 	}
 
-	response := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return nil, getErr(method, err)
-	}
-
-	if response.Error != nil {
-		return nil, getErr(method, response.Error)
-	}
-
-	if response.Result == nil {
-		getErr(method, fmt.Errorf("result is missing from response"))
-	}
-
 	rebootRequired := false
 
-	if response.Result.RestartRequired != nil {
-		if *response.Result.RestartRequired {
+	if result.RestartRequired != nil {
+		if *result.RestartRequired {
 			rebootRequired = true
 		}
 	}
@@ -0,0 +1,211 @@
+package cover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/jodydadescott/shelly-client/sdk/cover/types"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
+)
+
+type MessageHandlerFactory = msg_types.MessageHandlerFactory
+type MessageHandler = msg_types.MessageHandler
+type Request = msg_types.Request
+
+type Config = types.Config
+type Status = types.Status
+type Params = types.Params
+type Result = types.Result
+
+// New returns new instance of client
+func New(messageHandlerFactory MessageHandlerFactory) *Client {
+	return &Client{
+		MessageHandlerFactory: messageHandlerFactory,
+	}
+}
+
+// Client the component client
+type Client struct {
+	MessageHandlerFactory
+	messageHandlerMutex sync.Mutex
+	_messageHandler     MessageHandler
+}
+
+func (t *Client) getMessageHandler() MessageHandler {
+	t.messageHandlerMutex.Lock()
+	defer t.messageHandlerMutex.Unlock()
+
+	if t._messageHandler != nil {
+		return t._messageHandler
+	}
+
+	t._messageHandler = t.NewHandle(Component)
+	return t._messageHandler
+}
+
+func getErr(method string, id *int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// An error returned by the device already has the method and device ID
+	rpcErr := &msg_types.Error{}
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
+	if id == nil {
+		return fmt.Errorf("component %s, method %s, error %w", Component, method, err)
+	}
+
+	return fmt.Errorf("component %s, method %s, id %d, error %w", Component, method, *id, err)
+}
+
+// GetStatus returns status for component or error
+func (t *Client) GetStatus(ctx context.Context, id int) (*Status, error) {
+
+	method := Component + ".GetStatus"
+
+	result, err := rpc.Call[*Params, *Status](ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
+	})
+
+	if err != nil {
+		return nil, getErr(method, &id, err)
+	}
+
+	return result, nil
+}
+
+// GetConfig returns component config or error
+func (t *Client) GetConfig(ctx context.Context, id int) (*Config, error) {
+
+	method := Component + ".GetConfig"
+
+	result, err := rpc.Call[*Params, *Config](ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
+	})
+
+	if err != nil {
+		return nil, getErr(method, &id, err)
+	}
+
+	return result, nil
+}
+
+// SetConfig applies config to device component
+func (t *Client) SetConfig(ctx context.Context, config *Config) error {
+
+	method := Component + ".SetConfig"
+
+	if config == nil {
+		zap.L().Debug("Cover config is not present and will be disabled")
+		config = &Config{}
+	} else {
+		zap.L().Debug("Cover config is present")
+		config = config.Clone()
+	}
+
+	if config.ID == nil {
+		return fmt.Errorf("config ID is nil")
+	}
+
+	_, err := rpc.Call[*Params, *Result](ctx, t.getMessageHandler(), method, &Params{
+		ID:     *config.ID,
+		Config: config,
+	})
+
+	return getErr(method, config.ID, err)
+}
+
+// Open opens the cover. If duration is set the cover moves in the open direction for the
+// specified number of seconds; otherwise it moves until it is fully open or maxtime_open
+// has elapsed.
+func (t *Client) Open(ctx context.Context, id int, duration *float64) error {
+
+	method := Component + ".Open"
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID:       id,
+		Duration: duration,
+	})
+
+	return getErr(method, &id, err)
+}
+
+// Close closes the cover. If duration is set the cover moves in the close direction for the
+// specified number of seconds; otherwise it moves until it is fully closed or maxtime_close
+// has elapsed.
+func (t *Client) Close(ctx context.Context, id int, duration *float64) error {
+
+	method := Component + ".Close"
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID:       id,
+		Duration: duration,
+	})
+
+	return getErr(method, &id, err)
+}
+
+// Stop stops the cover if it is moving
+func (t *Client) Stop(ctx context.Context, id int) error {
+
+	method := Component + ".Stop"
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
+	})
+
+	return getErr(method, &id, err)
+}
+
+// GoToPosition moves the cover to the position in % from 0 (fully closed) to 100 (fully
+// open). The cover must be calibrated.
+func (t *Client) GoToPosition(ctx context.Context, id int, pos int) error {
+
+	method := Component + ".GoToPosition"
+
+	if pos < 0 || pos > 100 {
+		return getErr(method, &id, fmt.Errorf("%w; pos %d must be between 0 and 100", msg_types.ErrInvalidArgument, pos))
+	}
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID:  id,
+		Pos: &pos,
+	})
+
+	return getErr(method, &id, err)
+}
+
+// Calibrate starts the calibration procedure of the cover. The cover is moved to both end
+// positions to measure the travel time and power.
+func (t *Client) Calibrate(ctx context.Context, id int) error {
+
+	method := Component + ".Calibrate"
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
+	})
+
+	return getErr(method, &id, err)
+}
+
+// ResetCounters resets the energy counters of the cover. If counterTypes is empty all
+// counters are reset; otherwise only the named counters such as aenergy are reset.
+func (t *Client) ResetCounters(ctx context.Context, id int, counterTypes []string) error {
+
+	method := Component + ".ResetCounters"
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID:   id,
+		Type: counterTypes,
+	})
+
+	return getErr(method, &id, err)
+}
@@ -0,0 +1,209 @@
+package cover_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jodydadescott/shelly-client/sdk/client"
+	"github.com/jodydadescott/shelly-client/sdk/cover"
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+// TestMove runs the steps in order against one device. Movement on the fake completes
+// immediately and an Open or Close with a duration moves the cover in proportion to
+// maxtime_open or maxtime_close which default to 60 seconds.
+func TestMove(t *testing.T) {
+
+	ctx := context.Background()
+
+	device := fake.NewDevice(&fake.DeviceConfig{Cover: 1})
+	c := client.NewWithMessageHandlerFactory(&client.Config{}, fake.New(nil, device))
+	defer c.Close()
+
+	duration := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		step    func(x *cover.Client) error
+		wantErr error
+		// sent false if the request must be rejected before it is sent
+		sent          bool
+		state         string
+		pos           int
+		lastDirection string
+	}{
+		{
+			name:    "go to position not calibrated",
+			step:    func(x *cover.Client) error { return x.GoToPosition(ctx, 0, 50) },
+			wantErr: msg_types.ErrFailedPrecondition,
+			sent:    true,
+		},
+		{
+			name:          "open",
+			step:          func(x *cover.Client) error { return x.Open(ctx, 0, nil) },
+			sent:          true,
+			state:         "open",
+			pos:           -1,
+			lastDirection: "open",
+		},
+		{
+			name:          "calibrate",
+			step:          func(x *cover.Client) error { return x.Calibrate(ctx, 0) },
+			sent:          true,
+			state:         "open",
+			pos:           100,
+			lastDirection: "open",
+		},
+		{
+			name:          "close for duration",
+			step:          func(x *cover.Client) error { return x.Close(ctx, 0, duration(15)) },
+			sent:          true,
+			state:         "stopped",
+			pos:           75,
+			lastDirection: "close",
+		},
+		{
+			name:          "open for duration",
+			step:          func(x *cover.Client) error { return x.Open(ctx, 0, duration(6)) },
+			sent:          true,
+			state:         "stopped",
+			pos:           85,
+			lastDirection: "open",
+		},
+		{
+			name:          "go to position",
+			step:          func(x *cover.Client) error { return x.GoToPosition(ctx, 0, 30) },
+			sent:          true,
+			state:         "stopped",
+			pos:           30,
+			lastDirection: "close",
+		},
+		{
+			name:    "zero duration",
+			step:    func(x *cover.Client) error { return x.Open(ctx, 0, duration(0)) },
+			wantErr: msg_types.ErrInvalidArgument,
+			sent:    true,
+		},
+		{
+			name:    "position out of range",
+			step:    func(x *cover.Client) error { return x.GoToPosition(ctx, 0, 101) },
+			wantErr: msg_types.ErrInvalidArgument,
+		},
+		{
+			name:    "missing cover",
+			step:    func(x *cover.Client) error { return x.Close(ctx, 1, nil) },
+			wantErr: msg_types.ErrNotFound,
+			sent:    true,
+		},
+		{
+			name:          "close",
+			step:          func(x *cover.Client) error { return x.Close(ctx, 0, nil) },
+			sent:          true,
+			state:         "closed",
+			pos:           0,
+			lastDirection: "close",
+		},
+		{
+			name:          "stop",
+			step:          func(x *cover.Client) error { return x.Stop(ctx, 0) },
+			sent:          true,
+			state:         "stopped",
+			pos:           0,
+			lastDirection: "close",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			before := len(device.Calls())
+
+			err := tt.step(c.Cover())
+
+			if sent := len(device.Calls()) > before; sent != tt.sent {
+				t.Errorf("request sent %t, want %t", sent, tt.sent)
+			}
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			status, err := c.Cover().GetStatus(ctx, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if status.State == nil || *status.State != tt.state {
+				t.Errorf("state %v, want %s", status.State, tt.state)
+			}
+
+			if status.LastDirection == nil || *status.LastDirection != tt.lastDirection {
+				t.Errorf("last direction %v, want %s", status.LastDirection, tt.lastDirection)
+			}
+
+			// A pos of -1 means the position is not known because the cover is not calibrated
+			if tt.pos < 0 {
+				if status.CurrentPos != nil {
+					t.Errorf("position %d, want none", *status.CurrentPos)
+				}
+				return
+			}
+
+			if status.CurrentPos == nil || *status.CurrentPos != tt.pos {
+				t.Errorf("position %v, want %d", status.CurrentPos, tt.pos)
+			}
+		})
+	}
+}
+
+func TestSetConfig(t *testing.T) {
+
+	ctx := context.Background()
+
+	device := fake.NewDevice(&fake.DeviceConfig{Cover: 1})
+	c := client.NewWithMessageHandlerFactory(&client.Config{}, fake.New(nil, device))
+	defer c.Close()
+
+	config, err := c.Cover().GetConfig(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	maxtime := 30.0
+	config.MaxtimeOpen = &maxtime
+
+	err = c.Cover().SetConfig(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err = c.Cover().GetConfig(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.MaxtimeOpen == nil || *config.MaxtimeOpen != maxtime {
+		t.Errorf("maxtime open %v, want %v", config.MaxtimeOpen, maxtime)
+	}
+
+	// A config without an ID is rejected before it is sent
+	before := len(device.Calls())
+
+	config.ID = nil
+	if err := c.Cover().SetConfig(ctx, config); err == nil {
+		t.Error("error nil for a config without an ID, want error")
+	}
+
+	if len(device.Calls()) != before {
+		t.Errorf("config without an ID sent")
+	}
+}
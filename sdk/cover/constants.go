@@ -0,0 +1,5 @@
+package cover
+
+const (
+	Component = "Cover"
+)
@@ -0,0 +1,581 @@
+package types
+
+import (
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+)
+
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+// Params internal use only
+type Params struct {
+	ID       int      `json:"id" yaml:"id"`
+	Config   *Config  `json:"config,omitempty" yaml:"config,omitempty"`
+	Duration *float64 `json:"duration,omitempty" yaml:"duration,omitempty"`
+	Pos      *int     `json:"pos,omitempty" yaml:"pos,omitempty"`
+	Rel      *int     `json:"rel,omitempty" yaml:"rel,omitempty"`
+	Type     []string `json:"type,omitempty" yaml:"type,omitempty"`
+}
+
+// Result internal use only
+type Result struct {
+	RestartRequired *bool  `json:"restart_required,omitempty"`
+	Error           *Error `json:"error,omitempty"`
+}
+
+// Status status of the Cover component contains information about the state, position, power
+// and energy of the cover instance. To obtain the status of the Cover component its id must be
+// specified. The Cover component is only present on devices running in the cover profile.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Cover#status
+type Status struct {
+	// ID Id of the Cover component instance
+	ID *int `json:"id" yaml:"id"`
+	// Source of the last command, for example: init, WS_in, http, ...
+	Source *string `json:"source,omitempty" yaml:"source,omitempty"`
+	// State range of values: open, closed, opening, closing, stopped, calibrating
+	State *string `json:"state,omitempty" yaml:"state,omitempty"`
+	// Apower active power in Watts
+	Apower *float64 `json:"apower,omitempty" yaml:"apower,omitempty"`
+	// Voltage volts
+	Voltage *float64 `json:"voltage,omitempty" yaml:"voltage,omitempty"`
+	// Current amperes
+	Current *float64 `json:"current,omitempty" yaml:"current,omitempty"`
+	// PowerFactor power factor
+	PowerFactor *float64 `json:"pf,omitempty" yaml:"pf,omitempty"`
+	// Freq network frequency, Hz
+	Freq *float64 `json:"freq,omitempty" yaml:"freq,omitempty"`
+	// Aenergy information about the active energy counter
+	Aenergy *CoverAenergy `json:"aenergy,omitempty" yaml:"aenergy,omitempty"`
+	// Temperature information about the temperature
+	Temperature *CoverTemperature `json:"temperature,omitempty" yaml:"temperature,omitempty"`
+	// PosControl false if cover is not calibrated and only discrete open/close is possible;
+	// true if cover is calibrated and can be commanded to go to arbitrary positions
+	PosControl *bool `json:"pos_control,omitempty" yaml:"pos_control,omitempty"`
+	// LastDirection direction of the last movement: open, close or null when unknown
+	LastDirection *string `json:"last_direction,omitempty" yaml:"last_direction,omitempty"`
+	// CurrentPos current position in % from 0 (fully closed) to 100 (fully open); null if not
+	// calibrated (shown if PosControl is true)
+	CurrentPos *int `json:"current_pos,omitempty" yaml:"current_pos,omitempty"`
+	// TargetPos the requested target position in % (shown if GoToPosition is in progress)
+	TargetPos *int `json:"target_pos,omitempty" yaml:"target_pos,omitempty"`
+	// MoveTimeout seconds, the cover will automatically stop after this (shown if the cover is moving)
+	MoveTimeout *float64 `json:"move_timeout,omitempty" yaml:"move_timeout,omitempty"`
+	// MoveStartedAt Unix timestamp, time when the movement started (shown if the cover is moving)
+	MoveStartedAt *float64 `json:"move_started_at,omitempty" yaml:"move_started_at,omitempty"`
+	// Errors conditions occurred. May contain overtemp, overpower, overvoltage, undervoltage,
+	// obstruction, safety_switch, cal_abort... (shown if at least one error is present)
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Clone return copy
+func (t *Status) Clone() *Status {
+	c := &Status{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// CoverAenergy information about the active energy counter
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Cover#status
+type CoverAenergy struct {
+	// Total energy consumed in Watt-hours
+	Total *float64 `json:"total,omitempty" yaml:"total,omitempty"`
+	// ByMinute energy consumption by minute (in Milliwatt-hours) for the last three minutes
+	// (the lower the index of the element in the array, the closer to the current moment the minute)
+	ByMinute []float64 `json:"by_minute,omitempty" yaml:"by_minute,omitempty"`
+	// MinuteTs Unix timestamp of the first second of the last minute (in UTC)
+	MinuteTs *int `json:"minute_ts,omitempty" yaml:"minute_ts,omitempty"`
+}
+
+// Clone return copy
+func (t *CoverAenergy) Clone() *CoverAenergy {
+	c := &CoverAenergy{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// CoverTemperature information about the temperature
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Cover#status
+type CoverTemperature struct {
+	// TC temperature in Celsius (null if temperature is out of the measurement range)
+	TC *float64 `json:"tC,omitempty" yaml:"tC,omitempty"`
+	// TF temperature in Fahrenheit (null if temperature is out of the measurement range)
+	TF *float64 `json:"tF,omitempty" yaml:"tF,omitempty"`
+}
+
+// Clone return copy
+func (t *CoverTemperature) Clone() *CoverTemperature {
+	c := &CoverTemperature{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Config configuration of the Cover component contains information about the input mode, the
+// motor, the movement timeouts and the protection settings of the chosen cover instance. To
+// Get/Set the configuration of the Cover component its id must be specified.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Cover#configuration
+type Config struct {
+	// ID Id of the Cover component instance
+	ID *int `json:"id" yaml:"id"`
+	// Name of the cover instance
+	Name *string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Motor configuration of the motor
+	Motor *CoverMotor `json:"motor,omitempty" yaml:"motor,omitempty"`
+	// MaxtimeOpen default timeout after which Cover will stop moving in open direction in seconds
+	MaxtimeOpen *float64 `json:"maxtime_open,omitempty" yaml:"maxtime_open,omitempty"`
+	// MaxtimeClose default timeout after which Cover will stop moving in close direction in seconds
+	MaxtimeClose *float64 `json:"maxtime_close,omitempty" yaml:"maxtime_close,omitempty"`
+	// InitialState range of values: open, closed, stopped
+	InitialState *string `json:"initial_state,omitempty" yaml:"initial_state,omitempty"`
+	// InvertDirections true if the directions of the cover are swapped, false otherwise
+	InvertDirections *bool `json:"invert_directions,omitempty" yaml:"invert_directions,omitempty"`
+	// InMode range of values: single, dual, detached
+	InMode *string `json:"in_mode,omitempty" yaml:"in_mode,omitempty"`
+	// SwapInputs true if the functions of the inputs are swapped, false otherwise
+	SwapInputs *bool `json:"swap_inputs,omitempty" yaml:"swap_inputs,omitempty"`
+	// SafetySwitch configuration of the safety switch
+	SafetySwitch *CoverSafetySwitch `json:"safety_switch,omitempty" yaml:"safety_switch,omitempty"`
+	// PowerLimit limit (in Watts) over which overpower condition occurs
+	PowerLimit *float64 `json:"power_limit,omitempty" yaml:"power_limit,omitempty"`
+	// VoltageLimit limit (in Volts) over which overvoltage condition occurs
+	VoltageLimit *float64 `json:"voltage_limit,omitempty" yaml:"voltage_limit,omitempty"`
+	// UndervoltageLimit limit (in Volts) under which undervoltage condition occurs
+	UndervoltageLimit *float64 `json:"undervoltage_limit,omitempty" yaml:"undervoltage_limit,omitempty"`
+	// CurrentLimit limit (in Amperes) over which overcurrent condition occurs
+	CurrentLimit *float64 `json:"current_limit,omitempty" yaml:"current_limit,omitempty"`
+	// ObstructionDetection configuration of the obstruction detection
+	ObstructionDetection *CoverObstructionDetection `json:"obstruction_detection,omitempty" yaml:"obstruction_detection,omitempty"`
+}
+
+// Clone return copy
+func (t *Config) Clone() *Config {
+	c := &Config{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Equals returns true if equal
+func (t *Config) Equals(x *Config) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("Config receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("Config receiver is not nil but input is")
+		return false
+	}
+
+	if !util.CompareInt(t.ID, x.ID) {
+		zap.L().Info("Config ID not equal")
+		return false
+	}
+
+	if !util.CompareString(t.Name, x.Name) {
+		zap.L().Info("Config Name not equal")
+		return false
+	}
+
+	if !t.Motor.Equals(x.Motor) {
+		zap.L().Info("Config Motor not equal")
+		return false
+	}
+
+	if !util.CompareFloat64(t.MaxtimeOpen, x.MaxtimeOpen) {
+		zap.L().Info("Config MaxtimeOpen not equal")
+		return false
+	}
+
+	if !util.CompareFloat64(t.MaxtimeClose, x.MaxtimeClose) {
+		zap.L().Info("Config MaxtimeClose not equal")
+		return false
+	}
+
+	if !util.CompareString(t.InitialState, x.InitialState) {
+		zap.L().Info("Config InitialState not equal")
+		return false
+	}
+
+	if !util.CompareBool(t.InvertDirections, x.InvertDirections) {
+		zap.L().Info("Config InvertDirections not equal")
+		return false
+	}
+
+	if !util.CompareString(t.InMode, x.InMode) {
+		zap.L().Info("Config InMode not equal")
+		return false
+	}
+
+	if !util.CompareBool(t.SwapInputs, x.SwapInputs) {
+		zap.L().Info("Config SwapInputs not equal")
+		return false
+	}
+
+	if !t.SafetySwitch.Equals(x.SafetySwitch) {
+		zap.L().Info("Config SafetySwitch not equal")
+		return false
+	}
+
+	if !util.CompareFloat64(t.PowerLimit, x.PowerLimit) {
+		zap.L().Info("Config PowerLimit not equal")
+		return false
+	}
+
+	if !util.CompareFloat64(t.VoltageLimit, x.VoltageLimit) {
+		zap.L().Info("Config VoltageLimit not equal")
+		return false
+	}
+
+	if !util.CompareFloat64(t.UndervoltageLimit, x.UndervoltageLimit) {
+		zap.L().Info("Config UndervoltageLimit not equal")
+		return false
+	}
+
+	if !util.CompareFloat64(t.CurrentLimit, x.CurrentLimit) {
+		zap.L().Info("Config CurrentLimit not equal")
+		return false
+	}
+
+	if !t.ObstructionDetection.Equals(x.ObstructionDetection) {
+		zap.L().Info("Config ObstructionDetection not equal")
+		return false
+	}
+
+	return true
+}
+
+func (t *Config) Merge(x *Config) {
+
+	if x == nil {
+		return
+	}
+
+	if t.ID == nil {
+		t.ID = x.ID
+	}
+
+	if t.Name == nil {
+		t.Name = x.Name
+	}
+
+	if t.Motor == nil {
+		if x.Motor != nil {
+			t.Motor = x.Motor.Clone()
+		}
+	} else {
+		t.Motor.Merge(x.Motor)
+	}
+
+	if t.MaxtimeOpen == nil {
+		t.MaxtimeOpen = x.MaxtimeOpen
+	}
+
+	if t.MaxtimeClose == nil {
+		t.MaxtimeClose = x.MaxtimeClose
+	}
+
+	if t.InitialState == nil {
+		t.InitialState = x.InitialState
+	}
+
+	if t.InvertDirections == nil {
+		t.InvertDirections = x.InvertDirections
+	}
+
+	if t.InMode == nil {
+		t.InMode = x.InMode
+	}
+
+	if t.SwapInputs == nil {
+		t.SwapInputs = x.SwapInputs
+	}
+
+	if t.SafetySwitch == nil {
+		if x.SafetySwitch != nil {
+			t.SafetySwitch = x.SafetySwitch.Clone()
+		}
+	} else {
+		t.SafetySwitch.Merge(x.SafetySwitch)
+	}
+
+	if t.PowerLimit == nil {
+		t.PowerLimit = x.PowerLimit
+	}
+
+	if t.VoltageLimit == nil {
+		t.VoltageLimit = x.VoltageLimit
+	}
+
+	if t.UndervoltageLimit == nil {
+		t.UndervoltageLimit = x.UndervoltageLimit
+	}
+
+	if t.CurrentLimit == nil {
+		t.CurrentLimit = x.CurrentLimit
+	}
+
+	if t.ObstructionDetection == nil {
+		if x.ObstructionDetection != nil {
+			t.ObstructionDetection = x.ObstructionDetection.Clone()
+		}
+	} else {
+		t.ObstructionDetection.Merge(x.ObstructionDetection)
+	}
+}
+
+// CoverMotor configuration of the motor. The motor is considered stopped when the power
+// stays below the threshold for the confirm period.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Cover#configuration
+type CoverMotor struct {
+	// IdlePowerThr threshold in Watts below which the motor is considered stopped
+	IdlePowerThr *float64 `json:"idle_power_thr,omitempty" yaml:"idle_power_thr,omitempty"`
+	// IdleConfirmPeriod seconds the power must stay below the threshold before the motor is
+	// considered stopped
+	IdleConfirmPeriod *float64 `json:"idle_confirm_period,omitempty" yaml:"idle_confirm_period,omitempty"`
+}
+
+// Clone return copy
+func (t *CoverMotor) Clone() *CoverMotor {
+	c := &CoverMotor{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Equals returns true if equal
+func (t *CoverMotor) Equals(x *CoverMotor) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("CoverMotor receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("CoverMotor receiver is not nil but input is")
+		return false
+	}
+
+	result := true
+
+	if !util.CompareFloat64(t.IdlePowerThr, x.IdlePowerThr) {
+		zap.L().Info("CoverMotor IdlePowerThr not equal")
+		result = false
+	}
+
+	if !util.CompareFloat64(t.IdleConfirmPeriod, x.IdleConfirmPeriod) {
+		zap.L().Info("CoverMotor IdleConfirmPeriod not equal")
+		result = false
+	}
+
+	return result
+}
+
+func (t *CoverMotor) Merge(x *CoverMotor) {
+
+	if x == nil {
+		return
+	}
+
+	if t.IdlePowerThr == nil {
+		t.IdlePowerThr = x.IdlePowerThr
+	}
+
+	if t.IdleConfirmPeriod == nil {
+		t.IdleConfirmPeriod = x.IdleConfirmPeriod
+	}
+}
+
+// CoverSafetySwitch configuration of the safety switch. The safety switch is an input that
+// stops or reverses the cover while it is moving.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Cover#configuration
+type CoverSafetySwitch struct {
+	// Enable true if the safety switch is enabled, false otherwise
+	Enable *bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// Direction the direction of movement in which the safety switch is active: open, close, both
+	Direction *string `json:"direction,omitempty" yaml:"direction,omitempty"`
+	// Action the action taken when the safety switch is triggered: stop, reverse, pause
+	Action *string `json:"action,omitempty" yaml:"action,omitempty"`
+	// AllowedMove the movement allowed while the safety switch is triggered: reverse or null
+	AllowedMove *string `json:"allowed_move,omitempty" yaml:"allowed_move,omitempty"`
+}
+
+// Clone return copy
+func (t *CoverSafetySwitch) Clone() *CoverSafetySwitch {
+	c := &CoverSafetySwitch{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Equals returns true if equal
+func (t *CoverSafetySwitch) Equals(x *CoverSafetySwitch) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("CoverSafetySwitch receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("CoverSafetySwitch receiver is not nil but input is")
+		return false
+	}
+
+	result := true
+
+	if !util.CompareBool(t.Enable, x.Enable) {
+		zap.L().Info("CoverSafetySwitch Enable not equal")
+		result = false
+	}
+
+	if !util.CompareString(t.Direction, x.Direction) {
+		zap.L().Info("CoverSafetySwitch Direction not equal")
+		result = false
+	}
+
+	if !util.CompareString(t.Action, x.Action) {
+		zap.L().Info("CoverSafetySwitch Action not equal")
+		result = false
+	}
+
+	if !util.CompareString(t.AllowedMove, x.AllowedMove) {
+		zap.L().Info("CoverSafetySwitch AllowedMove not equal")
+		result = false
+	}
+
+	return result
+}
+
+func (t *CoverSafetySwitch) Merge(x *CoverSafetySwitch) {
+
+	if x == nil {
+		return
+	}
+
+	if t.Enable == nil {
+		t.Enable = x.Enable
+	}
+
+	if t.Direction == nil {
+		t.Direction = x.Direction
+	}
+
+	if t.Action == nil {
+		t.Action = x.Action
+	}
+
+	if t.AllowedMove == nil {
+		t.AllowedMove = x.AllowedMove
+	}
+}
+
+// CoverObstructionDetection configuration of the obstruction detection. An obstruction is
+// detected when the power exceeds the threshold after the holdoff.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Cover#configuration
+type CoverObstructionDetection struct {
+	// Enable true if obstruction detection is enabled, false otherwise
+	Enable *bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// Direction the direction of movement in which detection is active: open, close, both
+	Direction *string `json:"direction,omitempty" yaml:"direction,omitempty"`
+	// Action the action taken when an obstruction is detected: stop, reverse
+	Action *string `json:"action,omitempty" yaml:"action,omitempty"`
+	// PowerThr power threshold in Watts above which an obstruction is detected
+	PowerThr *float64 `json:"power_thr,omitempty" yaml:"power_thr,omitempty"`
+	// Holdoff seconds to wait after the motor starts before detection is active
+	Holdoff *float64 `json:"holdoff,omitempty" yaml:"holdoff,omitempty"`
+}
+
+// Clone return copy
+func (t *CoverObstructionDetection) Clone() *CoverObstructionDetection {
+	c := &CoverObstructionDetection{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Equals returns true if equal
+func (t *CoverObstructionDetection) Equals(x *CoverObstructionDetection) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("CoverObstructionDetection receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("CoverObstructionDetection receiver is not nil but input is")
+		return false
+	}
+
+	result := true
+
+	if !util.CompareBool(t.Enable, x.Enable) {
+		zap.L().Info("CoverObstructionDetection Enable not equal")
+		result = false
+	}
+
+	if !util.CompareString(t.Direction, x.Direction) {
+		zap.L().Info("CoverObstructionDetection Direction not equal")
+		result = false
+	}
+
+	if !util.CompareString(t.Action, x.Action) {
+		zap.L().Info("CoverObstructionDetection Action not equal")
+		result = false
+	}
+
+	if !util.CompareFloat64(t.PowerThr, x.PowerThr) {
+		zap.L().Info("CoverObstructionDetection PowerThr not equal")
+		result = false
+	}
+
+	if !util.CompareFloat64(t.Holdoff, x.Holdoff) {
+		zap.L().Info("CoverObstructionDetection Holdoff not equal")
+		result = false
+	}
+
+	return result
+}
+
+func (t *CoverObstructionDetection) Merge(x *CoverObstructionDetection) {
+
+	if x == nil {
+		return
+	}
+
+	if t.Enable == nil {
+		t.Enable = x.Enable
+	}
+
+	if t.Direction == nil {
+		t.Direction = x.Direction
+	}
+
+	if t.Action == nil {
+		t.Action = x.Action
+	}
+
+	if t.PowerThr == nil {
+		t.PowerThr = x.PowerThr
+	}
+
+	if t.Holdoff == nil {
+		t.Holdoff = x.Holdoff
+	}
+}
@@ -0,0 +1,13 @@
+package credentials
+
+import (
+	"time"
+)
+
+const (
+	defaultShellyUser  = "admin"
+	defaultEnvPrefix   = "SHELLY"
+	defaultExecTimeout = time.Second * 10
+	execRealmEnvVar    = "SHELLY_REALM"
+	execHostnameEnvVar = "SHELLY_HOSTNAME"
+)
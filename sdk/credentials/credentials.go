@@ -0,0 +1,312 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/credentials/types"
+)
+
+type CredentialProvider = types.CredentialProvider
+type Credentials = types.Credentials
+type Config = types.Config
+type EnvConfig = types.EnvConfig
+type ExecConfig = types.ExecConfig
+type ExecRequest = types.ExecRequest
+type ClientConfig = client_types.Config
+
+// ProviderFunc adapts a function to a CredentialProvider
+type ProviderFunc func(ctx context.Context, realm, hostname string) (*Credentials, error)
+
+func (t ProviderFunc) GetCredentials(ctx context.Context, realm, hostname string) (*Credentials, error) {
+	return t(ctx, realm, hostname)
+}
+
+// Chain returns a provider that consults each provider in order and returns the first
+// credentials found. Nil providers are skipped.
+func Chain(providers ...CredentialProvider) CredentialProvider {
+
+	var chain []CredentialProvider
+	for _, provider := range providers {
+		if provider != nil {
+			chain = append(chain, provider)
+		}
+	}
+
+	return ProviderFunc(func(ctx context.Context, realm, hostname string) (*Credentials, error) {
+		for _, provider := range chain {
+			credentials, err := provider.GetCredentials(ctx, realm, hostname)
+			if err != nil {
+				return nil, err
+			}
+			if credentials != nil {
+				return credentials, nil
+			}
+		}
+		return nil, nil
+	})
+}
+
+// New returns a provider that consults the built-in providers configured in config. Nil is
+// returned if config is nil or has no providers.
+func New(config *Config) CredentialProvider {
+
+	if config == nil {
+		return nil
+	}
+
+	var providers []CredentialProvider
+
+	if len(config.Static) > 0 {
+		zap.L().Debug(fmt.Sprintf("static credentials has %d patterns", len(config.Static)))
+		providers = append(providers, NewStatic(config.Static))
+	}
+
+	if config.Env != nil {
+		providers = append(providers, NewEnv(config.Env))
+	}
+
+	if config.File != "" {
+		zap.L().Debug(fmt.Sprintf("credentials file is %s", config.File))
+		providers = append(providers, NewFile(config.File))
+	}
+
+	if config.Exec != nil {
+		providers = append(providers, NewExec(config.Exec))
+	}
+
+	if len(providers) == 0 {
+		return nil
+	}
+
+	return Chain(providers...)
+}
+
+// NewClientProvider returns the provider used by the transports for the client config. The
+// CredentialProvider from the config is consulted first, then the providers configured in
+// Credentials and last the Username and Password. If the credentials found do not have a
+// username the Username from the config or the default Shelly user is used.
+func NewClientProvider(config *ClientConfig) CredentialProvider {
+
+	var fallback CredentialProvider
+
+	if config.Password != "" {
+		fallback = NewStatic(map[string]*Credentials{
+			"*": {Username: config.Username, Password: config.Password},
+		})
+	}
+
+	chain := Chain(config.CredentialProvider, New(config.Credentials), fallback)
+
+	username := config.Username
+	if username == "" {
+		username = defaultShellyUser
+	}
+
+	return ProviderFunc(func(ctx context.Context, realm, hostname string) (*Credentials, error) {
+
+		credentials, err := chain.GetCredentials(ctx, realm, hostname)
+		if err != nil || credentials == nil {
+			return nil, err
+		}
+
+		if credentials.Password == "" {
+			return nil, fmt.Errorf("credentials for realm %s have an empty password", realm)
+		}
+
+		if credentials.Username == "" {
+			credentials = credentials.Clone()
+			credentials.Username = username
+		}
+
+		return credentials, nil
+	})
+}
+
+// NewStatic returns a provider that looks up credentials in the map. See Config.Static.
+func NewStatic(patterns map[string]*Credentials) CredentialProvider {
+
+	var keys []string
+	for key := range patterns {
+		keys = append(keys, key)
+	}
+
+	// Longer patterns are more specific so they are tried first
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) == len(keys[j]) {
+			return keys[i] < keys[j]
+		}
+		return len(keys[i]) > len(keys[j])
+	})
+
+	return ProviderFunc(func(ctx context.Context, realm, hostname string) (*Credentials, error) {
+		return match(patterns, keys, realm, hostname)
+	})
+}
+
+func match(patterns map[string]*Credentials, keys []string, realm, hostname string) (*Credentials, error) {
+
+	for _, name := range []string{realm, hostname} {
+		if name == "" {
+			continue
+		}
+		if credentials := patterns[name]; credentials != nil {
+			return credentials, nil
+		}
+	}
+
+	for _, name := range []string{realm, hostname} {
+		if name == "" {
+			continue
+		}
+		for _, key := range keys {
+			matched, err := path.Match(key, name)
+			if err != nil {
+				return nil, fmt.Errorf("pattern %s is invalid; %w", key, err)
+			}
+			if matched && patterns[key] != nil {
+				return patterns[key], nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// NewEnv returns a provider that reads credentials from environment variables. See EnvConfig.
+func NewEnv(config *EnvConfig) CredentialProvider {
+
+	prefix := defaultEnvPrefix
+	if config != nil && config.Prefix != "" {
+		prefix = config.Prefix
+	}
+
+	zap.L().Debug(fmt.Sprintf("credentials env prefix is %s", prefix))
+
+	return ProviderFunc(func(ctx context.Context, realm, hostname string) (*Credentials, error) {
+
+		for _, name := range []string{realm, hostname, ""} {
+
+			suffix := ""
+			if name != "" {
+				suffix = "_" + envKey(name)
+			}
+
+			password := os.Getenv(prefix + "_PASS" + suffix)
+			if password == "" {
+				continue
+			}
+
+			return &Credentials{
+				Username: os.Getenv(prefix + "_USER" + suffix),
+				Password: password,
+			}, nil
+		}
+
+		return nil, nil
+	})
+}
+
+func envKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+}
+
+// NewFile returns a provider that reads credentials from a YAML or JSON file. See Config.File.
+func NewFile(filename string) CredentialProvider {
+
+	return ProviderFunc(func(ctx context.Context, realm, hostname string) (*Credentials, error) {
+
+		info, err := os.Stat(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.Mode().Perm()&0077 != 0 {
+			zap.L().Warn(fmt.Sprintf("credentials file %s is accessible by other users", filename))
+		}
+
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		var patterns map[string]*Credentials
+
+		err = yaml.Unmarshal(b, &patterns)
+		if err != nil {
+			return nil, fmt.Errorf("credentials file %s is invalid; %w", filename, err)
+		}
+
+		return NewStatic(patterns).GetCredentials(ctx, realm, hostname)
+	})
+}
+
+// NewExec returns a provider that runs an external helper. See ExecConfig.
+func NewExec(config *ExecConfig) CredentialProvider {
+
+	config = config.Clone()
+
+	if config.Command == "" {
+		panic("exec command is required")
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = defaultExecTimeout
+		zap.L().Debug(fmt.Sprintf("exec timeout is %s (default)", config.Timeout.String()))
+	} else {
+		zap.L().Debug(fmt.Sprintf("exec timeout is %s (config)", config.Timeout.String()))
+	}
+
+	return ProviderFunc(func(ctx context.Context, realm, hostname string) (*Credentials, error) {
+
+		ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+
+		input, err := json.Marshal(&ExecRequest{Realm: realm, Hostname: hostname})
+		if err != nil {
+			return nil, err
+		}
+
+		var stdout, stderr bytes.Buffer
+
+		cmd := exec.CommandContext(ctx, config.Command, config.Args...)
+		cmd.Env = append(os.Environ(), execRealmEnvVar+"="+realm, execHostnameEnvVar+"="+hostname)
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		err = cmd.Run()
+		if err != nil {
+			return nil, fmt.Errorf("credential helper %s failed with error %w; %s", config.Command, err, strings.TrimSpace(stderr.String()))
+		}
+
+		if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+			return nil, nil
+		}
+
+		credentials := &Credentials{}
+		err = json.Unmarshal(stdout.Bytes(), credentials)
+		if err != nil {
+			return nil, fmt.Errorf("credential helper %s output is invalid; %w", config.Command, err)
+		}
+
+		return credentials, nil
+	})
+}
@@ -0,0 +1,94 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/jinzhu/copier"
+)
+
+// CredentialProvider returns the credentials used to answer a digest auth challenge. The
+// realm is the device ID and the hostname is the hostname from the client config. If the
+// provider has no credentials for the device it must return nil and no error.
+type CredentialProvider interface {
+	GetCredentials(ctx context.Context, realm, hostname string) (*Credentials, error)
+}
+
+// Credentials username and password for a device. If the username is empty the default
+// Shelly user (admin) is used.
+type Credentials struct {
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+}
+
+// Clone return copy
+func (t *Credentials) Clone() *Credentials {
+	c := &Credentials{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Config is the config for the built-in providers. Each configured provider is consulted in
+// the order Static, Env, File and Exec until one returns credentials.
+type Config struct {
+	// Static map of patterns to credentials. A pattern is matched against the realm and
+	// then the hostname. Patterns may contain glob wildcards such as shellyplus1pm-* or
+	// *.site1.lan. Exact matches take precedence over wildcard matches.
+	Static map[string]*Credentials `json:"static,omitempty" yaml:"static,omitempty"`
+	// Env reads credentials from environment variables
+	Env *EnvConfig `json:"env,omitempty" yaml:"env,omitempty"`
+	// File path to a YAML or JSON file with the same format as Static. The file is read on
+	// each challenge so changes take effect without a restart.
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+	// Exec runs an external helper to get credentials
+	Exec *ExecConfig `json:"exec,omitempty" yaml:"exec,omitempty"`
+}
+
+// Clone return copy
+func (t *Config) Clone() *Config {
+	c := &Config{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// EnvConfig reads <Prefix>_PASS_<KEY> and <Prefix>_USER_<KEY> where KEY is the realm and
+// then the hostname in upper case with each character that is not a letter or digit
+// replaced by an underscore. If neither is set <Prefix>_PASS and <Prefix>_USER are used.
+type EnvConfig struct {
+	// Prefix of the variable names. Defaults to SHELLY
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+}
+
+// Clone return copy
+func (t *EnvConfig) Clone() *EnvConfig {
+	c := &EnvConfig{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// ExecConfig runs Command with Args. The helper receives the request as JSON on stdin
+// ({"realm":"...","hostname":"..."}) and in the environment variables SHELLY_REALM and
+// SHELLY_HOSTNAME. It must write the credentials as JSON to stdout
+// ({"username":"...","password":"..."}) or nothing if it has no credentials for the device.
+// A non zero exit status is an error.
+type ExecConfig struct {
+	// Command the helper to run. Required
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+	// Args arguments passed to the helper
+	Args []string `json:"args,omitempty" yaml:"args,omitempty"`
+	// Timeout for the helper to complete. Defaults to 10 seconds
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// Clone return copy
+func (t *ExecConfig) Clone() *ExecConfig {
+	c := &ExecConfig{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// ExecRequest is the request written to the stdin of the exec helper
+type ExecRequest struct {
+	Realm    string `json:"realm" yaml:"realm"`
+	Hostname string `json:"hostname" yaml:"hostname"`
+}
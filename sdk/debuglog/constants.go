@@ -0,0 +1,9 @@
+package debuglog
+
+const (
+	wsScheme  = "ws"
+	wssScheme = "wss"
+	wsPath    = "/debug/log"
+	// entryBufferSize the number of entries buffered for the reader
+	entryBufferSize = 100
+)
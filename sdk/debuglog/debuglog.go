@@ -0,0 +1,104 @@
+package debuglog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	gorilla "github.com/gorilla/websocket"
+	logger "github.com/jodydadescott/jody-go-logger"
+	"go.uber.org/zap"
+
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/debuglog/types"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/tlsconfig"
+)
+
+type Config = client_types.Config
+type Entry = types.Entry
+
+// Stream connects to the debug log of the device and returns a channel of the entries.
+// The channel is closed when the context is cancelled or the connection is lost. The
+// debug log over websocket must be enabled on the device with sys.debug.websocket.enable.
+// The hostname, scheme and TLS of the config are used.
+func Stream(ctx context.Context, config *Config) (<-chan *Entry, error) {
+
+	if config.Hostname == "" {
+		return nil, fmt.Errorf("hostname is required")
+	}
+
+	scheme, err := tlsconfig.GetScheme(config, wsScheme, wssScheme)
+	if err != nil {
+		return nil, err
+	}
+
+	theURL := url.URL{Scheme: scheme, Host: config.Hostname, Path: wsPath}
+
+	dialer := &gorilla.Dialer{
+		Proxy:            gorilla.DefaultDialer.Proxy,
+		HandshakeTimeout: gorilla.DefaultDialer.HandshakeTimeout,
+	}
+
+	if tlsconfig.IsSecure(scheme) {
+		dialer.TLSClientConfig, err = tlsconfig.New(config.TLS)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	zap.L().Debug(fmt.Sprintf("Connecting to %s", theURL.String()))
+
+	conn, _, err := dialer.DialContext(ctx, theURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("debug log %s; %w", theURL.String(), err)
+	}
+
+	entries := make(chan *Entry, entryBufferSize)
+
+	// The read is unblocked by closing the connection when the context is cancelled
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	go func() {
+
+		defer close(entries)
+		defer close(done)
+
+		for {
+
+			_, b, err := conn.ReadMessage()
+			if err != nil {
+				zap.L().Debug(fmt.Sprintf("debug log closed; %v", err))
+				return
+			}
+
+			if logger.Wire {
+				zap.L().Debug(fmt.Sprintf("RX->%s", string(b)))
+			}
+
+			entry := &Entry{}
+			err = json.Unmarshal(b, entry)
+			if err != nil {
+				// A frame that is not JSON is passed on as the message
+				data := string(b)
+				entry = &Entry{Data: &data}
+			}
+
+			select {
+			case entries <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return entries, nil
+}
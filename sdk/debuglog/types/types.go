@@ -0,0 +1,35 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Entry a debug log entry of the device. The output of scripts such as print and
+// console.log is included in the debug log.
+// https://shelly-api-docs.shelly.cloud/gen2/General/DebugLogs
+type Entry struct {
+	// Ts time of the entry in seconds since the epoch
+	Ts *float64 `json:"ts,omitempty" yaml:"ts,omitempty"`
+	// Level log level where 0 is error, 1 is warn, 2 is info, 3 is debug and 4 is verbose debug
+	Level *int `json:"level,omitempty" yaml:"level,omitempty"`
+	// Data the message
+	Data *string `json:"data,omitempty" yaml:"data,omitempty"`
+}
+
+// String returns the time and message of the entry
+func (t *Entry) String() string {
+
+	data := ""
+	if t.Data != nil {
+		data = strings.TrimRight(*t.Data, "\r\n")
+	}
+
+	if t.Ts == nil {
+		return data
+	}
+
+	ts := time.UnixMilli(int64(*t.Ts * 1000))
+	return fmt.Sprintf("%s %s", ts.Format(time.RFC3339Nano), data)
+}
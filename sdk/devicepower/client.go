@@ -0,0 +1,81 @@
+package devicepower
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jodydadescott/shelly-client/sdk/devicepower/types"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
+)
+
+type MessageHandlerFactory = msg_types.MessageHandlerFactory
+type MessageHandler = msg_types.MessageHandler
+type Request = msg_types.Request
+
+type Status = types.Status
+type Params = types.Params
+
+// New returns new instance of client
+func New(messageHandlerFactory MessageHandlerFactory) *Client {
+	return &Client{
+		MessageHandlerFactory: messageHandlerFactory,
+	}
+}
+
+// Client the component client
+type Client struct {
+	MessageHandlerFactory
+	messageHandlerMutex sync.Mutex
+	_messageHandler     MessageHandler
+}
+
+func (t *Client) getMessageHandler() MessageHandler {
+	t.messageHandlerMutex.Lock()
+	defer t.messageHandlerMutex.Unlock()
+
+	if t._messageHandler != nil {
+		return t._messageHandler
+	}
+
+	t._messageHandler = t.NewHandle(Component)
+	return t._messageHandler
+}
+
+func getErr(method string, id *int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// An error returned by the device already has the method and device ID
+	rpcErr := &msg_types.Error{}
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
+	if id == nil {
+		return fmt.Errorf("component %s, method %s, error %w", Component, method, err)
+	}
+
+	return fmt.Errorf("component %s, method %s, id %d, error %w", Component, method, *id, err)
+}
+
+// GetStatus returns status for component or error
+func (t *Client) GetStatus(ctx context.Context, id int) (*Status, error) {
+
+	method := Component + ".GetStatus"
+
+	result, err := rpc.Call[*Params, *Status](ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
+	})
+
+	if err != nil {
+		return nil, getErr(method, &id, err)
+	}
+
+	return result, nil
+}
+
+// GetConfig returns component config or error
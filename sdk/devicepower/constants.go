@@ -0,0 +1,5 @@
+package devicepower
+
+const (
+	Component = "DevicePower"
+)
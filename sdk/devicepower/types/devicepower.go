@@ -0,0 +1,68 @@
+package types
+
+import (
+	"github.com/jinzhu/copier"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+// Params internal use only
+type Params struct {
+	ID int `json:"id" yaml:"id"`
+}
+
+// Status status of the DevicePower component contains the state of the battery and the
+// external power supply of battery powered devices such as the H&T Plus. The component has
+// no config.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/DevicePower#status
+type Status struct {
+	// ID Id of the DevicePower component instance
+	ID *int `json:"id" yaml:"id"`
+	// Battery information about the battery
+	Battery *DevicePowerBattery `json:"battery,omitempty" yaml:"battery,omitempty"`
+	// External information about the external power supply
+	External *DevicePowerExternal `json:"external,omitempty" yaml:"external,omitempty"`
+	// Errors error conditions such as read (shown if at least one error is present)
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Clone return copy
+func (t *Status) Clone() *Status {
+	c := &Status{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// DevicePowerBattery information about the battery
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/DevicePower#status
+type DevicePowerBattery struct {
+	// V battery voltage in Volts (null if the voltage could not be obtained)
+	V *float64 `json:"V,omitempty" yaml:"V,omitempty"`
+	// Percent battery charge level in % (null if the charge level could not be obtained)
+	Percent *float64 `json:"percent,omitempty" yaml:"percent,omitempty"`
+}
+
+// Clone return copy
+func (t *DevicePowerBattery) Clone() *DevicePowerBattery {
+	c := &DevicePowerBattery{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// DevicePowerExternal information about the external power supply
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/DevicePower#status
+type DevicePowerExternal struct {
+	// Present true if an external power supply is connected, false otherwise
+	Present *bool `json:"present,omitempty" yaml:"present,omitempty"`
+}
+
+// Clone return copy
+func (t *DevicePowerExternal) Clone() *DevicePowerExternal {
+	c := &DevicePowerExternal{}
+	copier.Copy(&c, &t)
+	return c
+}
@@ -0,0 +1,5 @@
+package em
+
+const (
+	Component = "EM"
+)
@@ -0,0 +1,204 @@
+package types
+
+import (
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+)
+
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+// Params internal use only
+type Params struct {
+	ID     int     `json:"id" yaml:"id"`
+	Config *Config `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// Result internal use only
+type Result struct {
+	RestartRequired *bool  `json:"restart_required,omitempty"`
+	Error           *Error `json:"error,omitempty"`
+}
+
+// Status status of the EM component contains the readings of each phase of a three phase
+// energy meter such as the Pro 3EM. The energy counters are in the status of the EMData
+// component with the same ID.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/EM#status
+type Status struct {
+	// ID Id of the EM component instance
+	ID *int `json:"id" yaml:"id"`
+	// ACurrent phase A current measurement value, [A]
+	ACurrent *float64 `json:"a_current,omitempty" yaml:"a_current,omitempty"`
+	// AVoltage phase A voltage measurement value, [V]
+	AVoltage *float64 `json:"a_voltage,omitempty" yaml:"a_voltage,omitempty"`
+	// AActPower phase A active power measurement value, [W]
+	AActPower *float64 `json:"a_act_power,omitempty" yaml:"a_act_power,omitempty"`
+	// AAprtPower phase A apparent power measurement value, [VA]
+	AAprtPower *float64 `json:"a_aprt_power,omitempty" yaml:"a_aprt_power,omitempty"`
+	// APf phase A power factor measurement value
+	APf *float64 `json:"a_pf,omitempty" yaml:"a_pf,omitempty"`
+	// AFreq phase A network frequency measurement value, [Hz]
+	AFreq *float64 `json:"a_freq,omitempty" yaml:"a_freq,omitempty"`
+	// AErrors phase A error conditions such as out_of_range:active_power (shown if at least
+	// one error is present)
+	AErrors []string `json:"a_errors,omitempty" yaml:"a_errors,omitempty"`
+	// BCurrent phase B current measurement value, [A]
+	BCurrent *float64 `json:"b_current,omitempty" yaml:"b_current,omitempty"`
+	// BVoltage phase B voltage measurement value, [V]
+	BVoltage *float64 `json:"b_voltage,omitempty" yaml:"b_voltage,omitempty"`
+	// BActPower phase B active power measurement value, [W]
+	BActPower *float64 `json:"b_act_power,omitempty" yaml:"b_act_power,omitempty"`
+	// BAprtPower phase B apparent power measurement value, [VA]
+	BAprtPower *float64 `json:"b_aprt_power,omitempty" yaml:"b_aprt_power,omitempty"`
+	// BPf phase B power factor measurement value
+	BPf *float64 `json:"b_pf,omitempty" yaml:"b_pf,omitempty"`
+	// BFreq phase B network frequency measurement value, [Hz]
+	BFreq *float64 `json:"b_freq,omitempty" yaml:"b_freq,omitempty"`
+	// BErrors phase B error conditions (shown if at least one error is present)
+	BErrors []string `json:"b_errors,omitempty" yaml:"b_errors,omitempty"`
+	// CCurrent phase C current measurement value, [A]
+	CCurrent *float64 `json:"c_current,omitempty" yaml:"c_current,omitempty"`
+	// CVoltage phase C voltage measurement value, [V]
+	CVoltage *float64 `json:"c_voltage,omitempty" yaml:"c_voltage,omitempty"`
+	// CActPower phase C active power measurement value, [W]
+	CActPower *float64 `json:"c_act_power,omitempty" yaml:"c_act_power,omitempty"`
+	// CAprtPower phase C apparent power measurement value, [VA]
+	CAprtPower *float64 `json:"c_aprt_power,omitempty" yaml:"c_aprt_power,omitempty"`
+	// CPf phase C power factor measurement value
+	CPf *float64 `json:"c_pf,omitempty" yaml:"c_pf,omitempty"`
+	// CFreq phase C network frequency measurement value, [Hz]
+	CFreq *float64 `json:"c_freq,omitempty" yaml:"c_freq,omitempty"`
+	// CErrors phase C error conditions (shown if at least one error is present)
+	CErrors []string `json:"c_errors,omitempty" yaml:"c_errors,omitempty"`
+	// NCurrent neutral current measurement value, [A] (null if not measured)
+	NCurrent *float64 `json:"n_current,omitempty" yaml:"n_current,omitempty"`
+	// NErrors neutral error conditions (shown if at least one error is present)
+	NErrors []string `json:"n_errors,omitempty" yaml:"n_errors,omitempty"`
+	// TotalCurrent sum of the current of all phases, [A]
+	TotalCurrent *float64 `json:"total_current,omitempty" yaml:"total_current,omitempty"`
+	// TotalActPower sum of the active power of all phases, [W]
+	TotalActPower *float64 `json:"total_act_power,omitempty" yaml:"total_act_power,omitempty"`
+	// TotalAprtPower sum of the apparent power of all phases, [VA]
+	TotalAprtPower *float64 `json:"total_aprt_power,omitempty" yaml:"total_aprt_power,omitempty"`
+	// UserCalibratedPhase the phases with user calibration
+	UserCalibratedPhase []string `json:"user_calibrated_phase,omitempty" yaml:"user_calibrated_phase,omitempty"`
+	// Errors error conditions of the meter such as power_meter_failure (shown if at least
+	// one error is present)
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Clone return copy
+func (t *Status) Clone() *Status {
+	c := &Status{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Config configuration of the EM component
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/EM#configuration
+type Config struct {
+	// ID Id of the EM component instance
+	ID *int `json:"id" yaml:"id"`
+	// Name of the EM instance
+	Name *string `json:"name,omitempty" yaml:"name,omitempty"`
+	// BlinkModeSelector what the LED shows. Range of values: active_energy, apparent_energy
+	BlinkModeSelector *string `json:"blink_mode_selector,omitempty" yaml:"blink_mode_selector,omitempty"`
+	// PhaseSelector the phases shown by the LED. Range of values: all, a, b, c
+	PhaseSelector *string `json:"phase_selector,omitempty" yaml:"phase_selector,omitempty"`
+	// MonitorPhaseSequence true if the phase sequence is monitored, false otherwise
+	MonitorPhaseSequence *bool `json:"monitor_phase_sequence,omitempty" yaml:"monitor_phase_sequence,omitempty"`
+	// CTType the type of the current transformers such as 120A
+	CTType *string `json:"ct_type,omitempty" yaml:"ct_type,omitempty"`
+}
+
+// Clone return copy
+func (t *Config) Clone() *Config {
+	c := &Config{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Equals returns true if equal
+func (t *Config) Equals(x *Config) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("Config receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("Config receiver is not nil but input is")
+		return false
+	}
+
+	if !util.CompareInt(t.ID, x.ID) {
+		zap.L().Info("Config ID not equal")
+		return false
+	}
+
+	if !util.CompareString(t.Name, x.Name) {
+		zap.L().Info("Config Name not equal")
+		return false
+	}
+
+	if !util.CompareString(t.BlinkModeSelector, x.BlinkModeSelector) {
+		zap.L().Info("Config BlinkModeSelector not equal")
+		return false
+	}
+
+	if !util.CompareString(t.PhaseSelector, x.PhaseSelector) {
+		zap.L().Info("Config PhaseSelector not equal")
+		return false
+	}
+
+	if !util.CompareBool(t.MonitorPhaseSequence, x.MonitorPhaseSequence) {
+		zap.L().Info("Config MonitorPhaseSequence not equal")
+		return false
+	}
+
+	if !util.CompareString(t.CTType, x.CTType) {
+		zap.L().Info("Config CTType not equal")
+		return false
+	}
+
+	return true
+}
+
+func (t *Config) Merge(x *Config) {
+
+	if x == nil {
+		return
+	}
+
+	if t.ID == nil {
+		t.ID = x.ID
+	}
+
+	if t.Name == nil {
+		t.Name = x.Name
+	}
+
+	if t.BlinkModeSelector == nil {
+		t.BlinkModeSelector = x.BlinkModeSelector
+	}
+
+	if t.PhaseSelector == nil {
+		t.PhaseSelector = x.PhaseSelector
+	}
+
+	if t.MonitorPhaseSequence == nil {
+		t.MonitorPhaseSequence = x.MonitorPhaseSequence
+	}
+
+	if t.CTType == nil {
+		t.CTType = x.CTType
+	}
+}
@@ -0,0 +1,124 @@
+package em1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/jodydadescott/shelly-client/sdk/em1/types"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
+)
+
+type MessageHandlerFactory = msg_types.MessageHandlerFactory
+type MessageHandler = msg_types.MessageHandler
+type Request = msg_types.Request
+
+type Config = types.Config
+type Status = types.Status
+type Params = types.Params
+type Result = types.Result
+
+// New returns new instance of client
+func New(messageHandlerFactory MessageHandlerFactory) *Client {
+	return &Client{
+		MessageHandlerFactory: messageHandlerFactory,
+	}
+}
+
+// Client the component client
+type Client struct {
+	MessageHandlerFactory
+	messageHandlerMutex sync.Mutex
+	_messageHandler     MessageHandler
+}
+
+func (t *Client) getMessageHandler() MessageHandler {
+	t.messageHandlerMutex.Lock()
+	defer t.messageHandlerMutex.Unlock()
+
+	if t._messageHandler != nil {
+		return t._messageHandler
+	}
+
+	t._messageHandler = t.NewHandle(Component)
+	return t._messageHandler
+}
+
+func getErr(method string, id *int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// An error returned by the device already has the method and device ID
+	rpcErr := &msg_types.Error{}
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
+	if id == nil {
+		return fmt.Errorf("component %s, method %s, error %w", Component, method, err)
+	}
+
+	return fmt.Errorf("component %s, method %s, id %d, error %w", Component, method, *id, err)
+}
+
+// GetStatus returns status for component or error
+func (t *Client) GetStatus(ctx context.Context, id int) (*Status, error) {
+
+	method := Component + ".GetStatus"
+
+	result, err := rpc.Call[*Params, *Status](ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
+	})
+
+	if err != nil {
+		return nil, getErr(method, &id, err)
+	}
+
+	return result, nil
+}
+
+// GetConfig returns component config or error
+func (t *Client) GetConfig(ctx context.Context, id int) (*Config, error) {
+
+	method := Component + ".GetConfig"
+
+	result, err := rpc.Call[*Params, *Config](ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
+	})
+
+	if err != nil {
+		return nil, getErr(method, &id, err)
+	}
+
+	return result, nil
+}
+
+// SetConfig applies config to device component
+func (t *Client) SetConfig(ctx context.Context, config *Config) error {
+
+	method := Component + ".SetConfig"
+
+	if config == nil {
+		zap.L().Debug("EM1 config is not present and will be disabled")
+		config = &Config{}
+	} else {
+		zap.L().Debug("EM1 config is present")
+		config = config.Clone()
+	}
+
+	if config.ID == nil {
+		return fmt.Errorf("config ID is nil")
+	}
+
+	_, err := rpc.Call[*Params, *Result](ctx, t.getMessageHandler(), method, &Params{
+		ID:     *config.ID,
+		Config: config,
+	})
+
+	return getErr(method, config.ID, err)
+}
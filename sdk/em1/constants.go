@@ -0,0 +1,5 @@
+package em1
+
+const (
+	Component = "EM1"
+)
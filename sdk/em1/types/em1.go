@@ -0,0 +1,144 @@
+package types
+
+import (
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+)
+
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+// Params internal use only
+type Params struct {
+	ID     int     `json:"id" yaml:"id"`
+	Config *Config `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// Result internal use only
+type Result struct {
+	RestartRequired *bool  `json:"restart_required,omitempty"`
+	Error           *Error `json:"error,omitempty"`
+}
+
+// Status status of the EM1 component contains the readings of a single phase energy meter
+// channel such as each channel of the Pro EM-50. The energy counters are in the status of
+// the EM1Data component with the same ID.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/EM1#status
+type Status struct {
+	// ID Id of the EM1 component instance
+	ID *int `json:"id" yaml:"id"`
+	// Current current measurement value, [A]
+	Current *float64 `json:"current,omitempty" yaml:"current,omitempty"`
+	// Voltage voltage measurement value, [V]
+	Voltage *float64 `json:"voltage,omitempty" yaml:"voltage,omitempty"`
+	// ActPower active power measurement value, [W]
+	ActPower *float64 `json:"act_power,omitempty" yaml:"act_power,omitempty"`
+	// AprtPower apparent power measurement value, [VA]
+	AprtPower *float64 `json:"aprt_power,omitempty" yaml:"aprt_power,omitempty"`
+	// Pf power factor measurement value
+	Pf *float64 `json:"pf,omitempty" yaml:"pf,omitempty"`
+	// Freq network frequency measurement value, [Hz]
+	Freq *float64 `json:"freq,omitempty" yaml:"freq,omitempty"`
+	// Calibration the calibration in use: factory or user_N
+	Calibration *string `json:"calibration,omitempty" yaml:"calibration,omitempty"`
+	// Errors error conditions such as out_of_range:current (shown if at least one error is
+	// present)
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+	// Flags informational conditions such as count_disabled (shown if at least one flag is
+	// present)
+	Flags []string `json:"flags,omitempty" yaml:"flags,omitempty"`
+}
+
+// Clone return copy
+func (t *Status) Clone() *Status {
+	c := &Status{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Config configuration of the EM1 component
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/EM1#configuration
+type Config struct {
+	// ID Id of the EM1 component instance
+	ID *int `json:"id" yaml:"id"`
+	// Name of the EM1 instance
+	Name *string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Reverse true if the direction of the current is reversed, false otherwise
+	Reverse *bool `json:"reverse,omitempty" yaml:"reverse,omitempty"`
+	// CTType the type of the current transformer such as 50A
+	CTType *string `json:"ct_type,omitempty" yaml:"ct_type,omitempty"`
+}
+
+// Clone return copy
+func (t *Config) Clone() *Config {
+	c := &Config{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Equals returns true if equal
+func (t *Config) Equals(x *Config) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("Config receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("Config receiver is not nil but input is")
+		return false
+	}
+
+	if !util.CompareInt(t.ID, x.ID) {
+		zap.L().Info("Config ID not equal")
+		return false
+	}
+
+	if !util.CompareString(t.Name, x.Name) {
+		zap.L().Info("Config Name not equal")
+		return false
+	}
+
+	if !util.CompareBool(t.Reverse, x.Reverse) {
+		zap.L().Info("Config Reverse not equal")
+		return false
+	}
+
+	if !util.CompareString(t.CTType, x.CTType) {
+		zap.L().Info("Config CTType not equal")
+		return false
+	}
+
+	return true
+}
+
+func (t *Config) Merge(x *Config) {
+
+	if x == nil {
+		return
+	}
+
+	if t.ID == nil {
+		t.ID = x.ID
+	}
+
+	if t.Name == nil {
+		t.Name = x.Name
+	}
+
+	if t.Reverse == nil {
+		t.Reverse = x.Reverse
+	}
+
+	if t.CTType == nil {
+		t.CTType = x.CTType
+	}
+}
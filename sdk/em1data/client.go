@@ -0,0 +1,72 @@
+package em1data
+
+import (
+	"context"
+	"time"
+
+	"github.com/jodydadescott/shelly-client/sdk/em1data/types"
+	"github.com/jodydadescott/shelly-client/sdk/internal/energydata"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+type MessageHandlerFactory = msg_types.MessageHandlerFactory
+type MessageHandler = msg_types.MessageHandler
+type Request = msg_types.Request
+
+type Status = types.Status
+type Records = types.Records
+type DataBlock = types.DataBlock
+type Data = types.Data
+type DataValues = types.DataValues
+type Record = types.Record
+type Params = types.Params
+type Result = types.Result
+
+// New returns new instance of client
+func New(messageHandlerFactory MessageHandlerFactory) *Client {
+	return &Client{
+		MessageHandlerFactory: messageHandlerFactory,
+		data:                  energydata.New[Status](messageHandlerFactory, Component),
+	}
+}
+
+// Client the component client. The EM1Data component stores the energy data of the EM1
+// component with the same ID in intervals.
+type Client struct {
+	MessageHandlerFactory
+	data *energydata.Client[Status]
+}
+
+// GetStatus returns status for component or error
+func (t *Client) GetStatus(ctx context.Context, id int) (*Status, error) {
+	return t.data.GetStatus(ctx, id)
+}
+
+// GetRecords returns the intervals for which the device has stored data starting at from.
+// If from is zero all intervals are returned.
+func (t *Client) GetRecords(ctx context.Context, id int, from time.Time) (*Records, error) {
+	return t.data.GetRecords(ctx, id, from)
+}
+
+// GetData returns the stored data from from until to. The device returns a limited number of
+// records at once; if there is more data NextRecordTs of the result is set. If to is zero
+// the data until the last record is requested. See GetAllData.
+func (t *Client) GetData(ctx context.Context, id int, from time.Time, to time.Time) (*Data, error) {
+	return t.data.GetData(ctx, id, from, to)
+}
+
+// GetAllData returns the stored data from from until to. The data is requested in pages
+// until the device has returned all of it.
+func (t *Client) GetAllData(ctx context.Context, id int, from time.Time, to time.Time) (*Data, error) {
+	return t.data.GetAllData(ctx, id, from, to)
+}
+
+// ResetCounters resets the energy counters of the EM1 component with the same ID
+func (t *Client) ResetCounters(ctx context.Context, id int) error {
+	return t.data.ResetCounters(ctx, id)
+}
+
+// DeleteAllData deletes all stored data
+func (t *Client) DeleteAllData(ctx context.Context, id int) error {
+	return t.data.DeleteAllData(ctx, id)
+}
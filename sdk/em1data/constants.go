@@ -0,0 +1,5 @@
+package em1data
+
+const (
+	Component = "EM1Data"
+)
@@ -0,0 +1,42 @@
+package types
+
+import (
+	"github.com/jinzhu/copier"
+
+	emdata_types "github.com/jodydadescott/shelly-client/sdk/emdata/types"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+// The records and data of EM1Data have the same form as those of EMData
+type Params = emdata_types.Params
+type Result = emdata_types.Result
+type Records = emdata_types.Records
+type DataBlock = emdata_types.DataBlock
+type Data = emdata_types.Data
+type DataValues = emdata_types.DataValues
+type Record = emdata_types.Record
+
+// Status status of the EM1Data component contains the energy counters of the EM1 component
+// with the same ID
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/EM1Data#status
+type Status struct {
+	// ID Id of the EM1Data component instance
+	ID *int `json:"id" yaml:"id"`
+	// TotalActEnergy total active energy, [Wh]
+	TotalActEnergy *float64 `json:"total_act_energy,omitempty" yaml:"total_act_energy,omitempty"`
+	// TotalActRetEnergy total active returned energy, [Wh]
+	TotalActRetEnergy *float64 `json:"total_act_ret_energy,omitempty" yaml:"total_act_ret_energy,omitempty"`
+	// Errors error conditions such as database_error (shown if at least one error is present)
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Clone return copy
+func (t *Status) Clone() *Status {
+	c := &Status{}
+	copier.Copy(&c, &t)
+	return c
+}
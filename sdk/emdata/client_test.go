@@ -0,0 +1,95 @@
+package emdata_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jodydadescott/shelly-client/sdk/emdata"
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+func TestGetAllData(t *testing.T) {
+
+	// The fake stores a record each minute and returns 60 records for each GetData
+	const period = 60
+
+	tests := []struct {
+		name    string
+		records int64
+		pages   int
+		fault   *fake.Fault
+		wantErr error
+	}{
+		{name: "one record", records: 1, pages: 1},
+		{name: "one page", records: 60, pages: 1},
+		{name: "one more", records: 61, pages: 2},
+		{name: "pages", records: 150, pages: 3},
+		{name: "error", records: 150, pages: 1, fault: &fake.Fault{Method: "EMData.GetData", Code: msg_types.ErrorCodeUnAvailable, Message: "busy"}, wantErr: msg_types.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctx := context.Background()
+
+			device := fake.NewDevice(&fake.DeviceConfig{Components: []string{"em:0", "emdata:0"}})
+			factory := fake.New(nil, device)
+			defer factory.Close()
+
+			client := emdata.New(factory)
+
+			if tt.fault != nil {
+				device.InjectFault(tt.fault)
+			}
+
+			// to is in the past so that the records do not change while the test runs
+			now := time.Now().Unix()
+			to := now - now%period - period*10
+			from := to - (tt.records-1)*period
+
+			before := len(device.Calls())
+
+			data, err := client.GetAllData(ctx, 0, time.Unix(from, 0), time.Unix(to, 0))
+
+			pages := 0
+			for _, v := range device.Calls()[before:] {
+				if v == "EMData.GetData" {
+					pages++
+				}
+			}
+
+			if pages != tt.pages {
+				t.Errorf("EMData.GetData sent %d times, want %d", pages, tt.pages)
+			}
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			records := data.Records()
+
+			if int64(len(records)) != tt.records {
+				t.Fatalf("%d records, want %d", len(records), tt.records)
+			}
+
+			for i, v := range records {
+				if want := from + int64(i)*period; v.Ts != want {
+					t.Fatalf("record %d at %d, want %d", i, v.Ts, want)
+				}
+				if len(v.Values) != len(data.Keys) {
+					t.Fatalf("record %d has %d values, want %d", i, len(v.Values), len(data.Keys))
+				}
+			}
+		})
+	}
+}
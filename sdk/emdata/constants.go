@@ -0,0 +1,5 @@
+package emdata
+
+const (
+	Component = "EMData"
+)
@@ -0,0 +1,156 @@
+package types
+
+import (
+	"github.com/jinzhu/copier"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+// Params internal use only
+type Params struct {
+	ID    int    `json:"id" yaml:"id"`
+	Ts    *int64 `json:"ts,omitempty" yaml:"ts,omitempty"`
+	EndTs *int64 `json:"end_ts,omitempty" yaml:"end_ts,omitempty"`
+}
+
+// Result internal use only
+type Result struct {
+	Error *Error `json:"error,omitempty"`
+}
+
+// Status status of the EMData component contains the energy counters of the EM component
+// with the same ID
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/EMData#status
+type Status struct {
+	// ID Id of the EMData component instance
+	ID *int `json:"id" yaml:"id"`
+	// ATotalActEnergy phase A total active energy, [Wh]
+	ATotalActEnergy *float64 `json:"a_total_act_energy,omitempty" yaml:"a_total_act_energy,omitempty"`
+	// ATotalActRetEnergy phase A total active returned energy, [Wh]
+	ATotalActRetEnergy *float64 `json:"a_total_act_ret_energy,omitempty" yaml:"a_total_act_ret_energy,omitempty"`
+	// BTotalActEnergy phase B total active energy, [Wh]
+	BTotalActEnergy *float64 `json:"b_total_act_energy,omitempty" yaml:"b_total_act_energy,omitempty"`
+	// BTotalActRetEnergy phase B total active returned energy, [Wh]
+	BTotalActRetEnergy *float64 `json:"b_total_act_ret_energy,omitempty" yaml:"b_total_act_ret_energy,omitempty"`
+	// CTotalActEnergy phase C total active energy, [Wh]
+	CTotalActEnergy *float64 `json:"c_total_act_energy,omitempty" yaml:"c_total_act_energy,omitempty"`
+	// CTotalActRetEnergy phase C total active returned energy, [Wh]
+	CTotalActRetEnergy *float64 `json:"c_total_act_ret_energy,omitempty" yaml:"c_total_act_ret_energy,omitempty"`
+	// TotalAct total active energy of all phases, [Wh]
+	TotalAct *float64 `json:"total_act,omitempty" yaml:"total_act,omitempty"`
+	// TotalActRet total active returned energy of all phases, [Wh]
+	TotalActRet *float64 `json:"total_act_ret,omitempty" yaml:"total_act_ret,omitempty"`
+	// Errors error conditions such as database_error (shown if at least one error is present)
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Clone return copy
+func (t *Status) Clone() *Status {
+	c := &Status{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Records the intervals for which the device has stored data
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/EMData#emdatagetrecords
+type Records struct {
+	// DataBlocks each continuous interval of stored data
+	DataBlocks []*DataBlock `json:"data_blocks,omitempty" yaml:"data_blocks,omitempty"`
+}
+
+// Clone return copy
+func (t *Records) Clone() *Records {
+	c := &Records{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// DataBlock a continuous interval of stored data
+type DataBlock struct {
+	// Ts Unix timestamp of the first record
+	Ts int64 `json:"ts" yaml:"ts"`
+	// Period the period of each record in seconds
+	Period int64 `json:"period" yaml:"period"`
+	// Records the number of records
+	Records int64 `json:"records" yaml:"records"`
+}
+
+// Data the stored data of an interval. The values of each record are in the order of Keys.
+// If the interval has more data than is returned at once NextRecordTs is the timestamp to
+// request the rest from.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/EMData#emdatagetdata
+type Data struct {
+	// Keys the name of each value such as a_total_act_energy
+	Keys []string `json:"keys,omitempty" yaml:"keys,omitempty"`
+	// Data the values in blocks of consecutive records
+	Data []*DataValues `json:"data,omitempty" yaml:"data,omitempty"`
+	// NextRecordTs Unix timestamp of the next record not returned (shown if there is more
+	// data)
+	NextRecordTs *int64 `json:"next_record_ts,omitempty" yaml:"next_record_ts,omitempty"`
+}
+
+// Clone return copy
+func (t *Data) Clone() *Data {
+	c := &Data{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Records returns each record of the data in order with its values keyed by name
+func (t *Data) Records() []*Record {
+
+	var records []*Record
+
+	for _, block := range t.Data {
+		for i, values := range block.Values {
+
+			record := &Record{
+				Ts:     block.Ts + int64(i)*block.Period,
+				Period: block.Period,
+				Values: make(map[string]float64, len(values)),
+			}
+
+			for j, value := range values {
+				if j < len(t.Keys) {
+					record.Values[t.Keys[j]] = value
+				}
+			}
+
+			records = append(records, record)
+		}
+	}
+
+	return records
+}
+
+// DataValues consecutive records
+type DataValues struct {
+	// Ts Unix timestamp of the first record
+	Ts int64 `json:"ts" yaml:"ts"`
+	// Period the period of each record in seconds
+	Period int64 `json:"period" yaml:"period"`
+	// Values the values of each record
+	Values [][]float64 `json:"values,omitempty" yaml:"values,omitempty"`
+}
+
+// Record the values of a period keyed by name such as a_total_act_energy. The energy
+// values are the energy of the period in Wh.
+type Record struct {
+	// Ts Unix timestamp of the start of the period
+	Ts int64 `json:"ts" yaml:"ts"`
+	// Period the period in seconds
+	Period int64 `json:"period" yaml:"period"`
+	// Values the values keyed by name
+	Values map[string]float64 `json:"values,omitempty" yaml:"values,omitempty"`
+}
+
+// Clone return copy
+func (t *Record) Clone() *Record {
+	c := &Record{}
+	copier.Copy(&c, &t)
+	return c
+}
@@ -0,0 +1,12 @@
+package emulator
+
+const (
+	defaultListenAddress = "127.0.0.1:8080"
+	defaultPath          = "/rpc"
+	shellyPath           = "/shelly"
+	debugLogPath         = "/debug/log"
+	contentType          = "application/json"
+	authHeader           = "WWW-Authenticate"
+	// macPrefix is the first three bytes of the MAC of emulated devices
+	macPrefix = "E0E0E0"
+)
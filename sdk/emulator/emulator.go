@@ -0,0 +1,368 @@
+package emulator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+
+	gorilla "github.com/gorilla/websocket"
+	logger "github.com/jodydadescott/jody-go-logger"
+	"go.uber.org/zap"
+
+	"github.com/jodydadescott/shelly-client/sdk/emulator/types"
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+type Config = types.Config
+type DeviceConfig = types.DeviceConfig
+type Response = msg_types.Response
+type AuthRequest = msg_types.AuthRequest
+
+// Emulator serves a simulated device over the network. RPC is served on the config Path
+// over HTTP POST and websocket and the device info is served on /shelly. Websocket
+// clients that send a src receive NotifyStatus and NotifyEvent frames. The debug log is
+// served over websocket on /debug/log if it is enabled in the sys config.
+type Emulator struct {
+	config   *Config
+	device   *fake.Device
+	upgrader gorilla.Upgrader
+}
+
+// New returns a new emulator. Call Run or Serve to start the emulator.
+func New(config *Config) *Emulator {
+
+	zap.L().Debug("New")
+
+	if config == nil {
+		config = &Config{}
+	} else {
+		config = config.Clone()
+	}
+
+	if config.ListenAddress == "" {
+		config.ListenAddress = defaultListenAddress
+		zap.L().Debug(fmt.Sprintf("listenAddress is %s (default)", config.ListenAddress))
+	} else {
+		zap.L().Debug(fmt.Sprintf("listenAddress is %s (config)", config.ListenAddress))
+	}
+
+	if config.Path == "" {
+		config.Path = defaultPath
+		zap.L().Debug(fmt.Sprintf("path is %s (default)", config.Path))
+	} else {
+		zap.L().Debug(fmt.Sprintf("path is %s (config)", config.Path))
+	}
+
+	return &Emulator{
+		config: config,
+		device: fake.NewDevice(config.Device),
+	}
+}
+
+// Device returns the simulated device. It can be used to inject faults, change status
+// and send events.
+func (t *Emulator) Device() *fake.Device {
+	return t.device
+}
+
+// Run listens on the config ListenAddress and serves until the context is cancelled
+func (t *Emulator) Run(ctx context.Context) error {
+
+	listener, err := net.Listen("tcp", t.config.ListenAddress)
+	if err != nil {
+		return err
+	}
+
+	return t.Serve(ctx, listener)
+}
+
+// Serve serves on the listener until the context is cancelled. This is useful when the
+// listener is created on port 0 so that the port is chosen by the system.
+func (t *Emulator) Serve(ctx context.Context, listener net.Listener) error {
+
+	zap.L().Debug(fmt.Sprintf("Device %s listening on %s", t.device.ID(), listener.Addr().String()))
+
+	httpServer := &http.Server{
+		Handler: t,
+	}
+
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- httpServer.Serve(listener)
+	}()
+
+	select {
+
+	case <-ctx.Done():
+		zap.L().Debug("Emulator cancelled")
+		httpServer.Shutdown(context.Background())
+		return nil
+
+	case err := <-errChan:
+		zap.L().Debug(fmt.Sprintf("Emulator failed with error %v", err))
+		return err
+
+	}
+}
+
+// ServeHTTP implements http.Handler so the emulator can also be used with httptest
+func (t *Emulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	switch r.URL.Path {
+
+	case t.config.Path:
+		if gorilla.IsWebSocketUpgrade(r) {
+			t.handleWS(w, r)
+			return
+		}
+		t.handlePost(w, r)
+
+	case shellyPath:
+		t.handleShelly(w, r)
+
+	case debugLogPath:
+		t.handleDebugLog(w, r)
+
+	default:
+		http.NotFound(w, r)
+
+	}
+}
+
+func (t *Emulator) handleShelly(w http.ResponseWriter, r *http.Request) {
+
+	b, err := t.device.Call(r.Context(), []byte(`{"id":1,"method":"Shelly.GetDeviceInfo"}`))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := &struct {
+		Result json.RawMessage `json:"result"`
+	}{}
+
+	err = json.Unmarshal(b, response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(response.Result)
+}
+
+func (t *Emulator) handlePost(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if logger.Wire {
+		zap.L().Debug(fmt.Sprintf("RX->%s", string(b)))
+	}
+
+	b, err = t.device.Call(r.Context(), b)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if logger.Wire {
+		zap.L().Debug(fmt.Sprintf("TX->%s", string(b)))
+	}
+
+	w.Header().Set("Content-Type", contentType)
+
+	// Over HTTP the device sends the auth challenge as a header
+	response := &Response{}
+	err = json.Unmarshal(b, response)
+	if err == nil && response.Error != nil && response.Error.Code == http.StatusUnauthorized {
+		authRequest := &AuthRequest{}
+		err = json.Unmarshal([]byte(response.Error.Message), authRequest)
+		if err == nil {
+			w.Header().Set(authHeader, fmt.Sprintf("Digest qop=\"auth\", realm=\"%s\", nonce=\"%s\", algorithm=%s", authRequest.Realm, hexNonce(authRequest.Nonce), authRequest.Algorithm))
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}
+
+	w.Write(b)
+}
+
+// hexNonce returns the nonce in hex as the device sends it in the WWW-Authenticate header
+func hexNonce(nonce msg_types.Nonce) string {
+	n, err := strconv.ParseInt(string(nonce), 10, 64)
+	if err != nil {
+		return string(nonce)
+	}
+	return strconv.FormatInt(n, 16)
+}
+
+// wsConn is a websocket connection. Writes are serialized as the connection supports
+// only one concurrent writer.
+type wsConn struct {
+	conn       *gorilla.Conn
+	writeMutex sync.Mutex
+	srcMutex   sync.RWMutex
+	src        string
+}
+
+func (t *wsConn) write(b []byte) error {
+
+	if logger.Wire {
+		zap.L().Debug(fmt.Sprintf("TX->%s", string(b)))
+	}
+
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+	return t.conn.WriteMessage(gorilla.TextMessage, b)
+}
+
+func (t *wsConn) getSrc() string {
+	t.srcMutex.RLock()
+	defer t.srcMutex.RUnlock()
+	return t.src
+}
+
+func (t *wsConn) setSrc(src string) {
+	t.srcMutex.Lock()
+	defer t.srcMutex.Unlock()
+	t.src = src
+}
+
+// notify sends the notification to the connection if the client has sent a src. The dst
+// is set to the src of the client.
+func (t *wsConn) notify(b []byte) {
+
+	src := t.getSrc()
+	if src == "" {
+		return
+	}
+
+	notification := make(map[string]interface{})
+	err := json.Unmarshal(b, &notification)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("notify error %v", err))
+		return
+	}
+
+	notification["dst"] = src
+
+	b, err = json.Marshal(notification)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("notify error %v", err))
+		return
+	}
+
+	err = t.write(b)
+	if err != nil {
+		zap.L().Debug(fmt.Sprintf("notify error %v", err))
+	}
+}
+
+func (t *Emulator) handleWS(w http.ResponseWriter, r *http.Request) {
+
+	zap.L().Debug(fmt.Sprintf("Connection from %s", r.RemoteAddr))
+
+	conn, err := t.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		zap.L().Debug(fmt.Sprintf("Upgrade from %s failed with error %v", r.RemoteAddr, err))
+		return
+	}
+
+	c := &wsConn{conn: conn}
+
+	removeHandler := t.device.AddNotificationHandler(c.notify)
+
+	defer func() {
+		removeHandler()
+		conn.Close()
+		zap.L().Debug(fmt.Sprintf("Connection from %s closed", r.RemoteAddr))
+	}()
+
+	for {
+
+		_, b, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		if logger.Wire {
+			zap.L().Debug(fmt.Sprintf("RX->%s", string(b)))
+		}
+
+		request := &struct {
+			Src string `json:"src"`
+		}{}
+
+		json.Unmarshal(b, request)
+		if request.Src != "" {
+			c.setSrc(request.Src)
+		}
+
+		b, err = t.device.Call(r.Context(), b)
+		if err != nil {
+			zap.L().Debug(fmt.Sprintf("Call failed with error %v; closing", err))
+			return
+		}
+
+		err = c.write(b)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleDebugLog streams the debug log entries of the device until the client closes the
+// connection
+func (t *Emulator) handleDebugLog(w http.ResponseWriter, r *http.Request) {
+
+	sysConfig := t.device.GetConfig("sys")
+	debug, _ := sysConfig["debug"].(map[string]interface{})
+	websocket, _ := debug["websocket"].(map[string]interface{})
+	if enable, _ := websocket["enable"].(bool); !enable {
+		http.Error(w, "debug log over websocket is not enabled", http.StatusNotFound)
+		return
+	}
+
+	conn, err := t.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		zap.L().Debug(fmt.Sprintf("Upgrade from %s failed with error %v", r.RemoteAddr, err))
+		return
+	}
+
+	c := &wsConn{conn: conn}
+
+	removeHandler := t.device.AddLogHandler(func(b []byte) {
+		err := c.write(b)
+		if err != nil {
+			zap.L().Debug(fmt.Sprintf("debug log error %v", err))
+		}
+	})
+
+	defer func() {
+		removeHandler()
+		conn.Close()
+	}()
+
+	// Frames from the client are ignored; the read returns when the connection is closed
+	for {
+		_, _, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+	}
+}
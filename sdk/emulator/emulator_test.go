@@ -0,0 +1,265 @@
+package emulator_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jodydadescott/shelly-client/sdk/client"
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/emulator"
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/switchx"
+)
+
+var transports = []string{client_types.TransportWS, client_types.TransportHTTP}
+
+// newSwitchClient returns a switch client connected to an emulated device with the
+// transport
+func newSwitchClient(t *testing.T, transport string, config *client_types.Config) (*switchx.Client, *fake.Device) {
+
+	e := emulator.New(&emulator.Config{
+		Device: &fake.DeviceConfig{Switch: 4},
+	})
+
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+
+	if config == nil {
+		config = &client_types.Config{}
+	}
+
+	config.Hostname = strings.TrimPrefix(server.URL, "http://")
+	config.Transport = transport
+
+	factory, err := msghandlers.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(factory.Close)
+	return switchx.New(factory), e.Device()
+}
+
+func countCalls(device *fake.Device, method string) int {
+	count := 0
+	for _, v := range device.Calls() {
+		if v == method {
+			count++
+		}
+	}
+	return count
+}
+
+// TestConcurrent uses one client from many goroutines. Each goroutine works on its own
+// switch and must see its own state.
+func TestConcurrent(t *testing.T) {
+
+	for _, transport := range transports {
+		t.Run(transport, func(t *testing.T) {
+
+			client, _ := newSwitchClient(t, transport, nil)
+			ctx := context.Background()
+
+			var wg sync.WaitGroup
+			errs := make(chan error, 4)
+
+			for id := 0; id < 4; id++ {
+				wg.Add(1)
+				go func(id int) {
+					defer wg.Done()
+
+					for i := 0; i < 10; i++ {
+
+						on := (i+id)%2 == 0
+
+						err := client.Set(ctx, id, &on)
+						if err != nil {
+							errs <- err
+							return
+						}
+
+						status, err := client.GetStatus(ctx, id)
+						if err != nil {
+							errs <- err
+							return
+						}
+
+						if *status.ID != id || status.Output != on {
+							errs <- fmt.Errorf("switch %d got status of switch %d with output %t, want %t", id, *status.ID, status.Output, on)
+							return
+						}
+					}
+				}(id)
+			}
+
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestRetry delays the response to the first request past the method timeout. A safe
+// method is sent again and succeeds. A method that changes the state of the device is
+// sent once and fails with ErrOutcomeUnknown.
+func TestRetry(t *testing.T) {
+
+	for _, transport := range transports {
+		t.Run(transport, func(t *testing.T) {
+
+			client, device := newSwitchClient(t, transport, &client_types.Config{
+				MethodTimeouts: map[string]time.Duration{"*": time.Millisecond * 100},
+			})
+
+			ctx := context.Background()
+
+			device.InjectFault(&fake.Fault{Method: "Switch.GetStatus", Delay: time.Millisecond * 300, Count: 1})
+
+			_, err := client.GetStatus(ctx, 0)
+			if err != nil {
+				t.Fatalf("GetStatus error %v, want nil", err)
+			}
+
+			if count := countCalls(device, "Switch.GetStatus"); count < 2 {
+				t.Errorf("GetStatus sent %d times, want at least 2", count)
+			}
+
+			device.InjectFault(&fake.Fault{Method: "Switch.Set", Delay: time.Millisecond * 300, Count: 1})
+
+			on := true
+			err = client.Set(ctx, 0, &on)
+			if !errors.Is(err, msg_types.ErrOutcomeUnknown) {
+				t.Fatalf("Set error %v, want ErrOutcomeUnknown", err)
+			}
+
+			if count := countCalls(device, "Switch.Set"); count != 1 {
+				t.Errorf("Set sent %d times, want 1", count)
+			}
+		})
+	}
+}
+
+// TestMethodTimeout checks that the timeout of the method is used instead of the send
+// timeout and that a deadline of the context is used instead of both
+func TestMethodTimeout(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		deadline time.Duration
+	}{
+		{name: "method", timeout: time.Millisecond * 50},
+		{name: "deadline", timeout: time.Second * 10, deadline: time.Millisecond * 50},
+	}
+
+	for _, tt := range tests {
+		for _, transport := range transports {
+			t.Run(tt.name+"/"+transport, func(t *testing.T) {
+
+				client, device := newSwitchClient(t, transport, &client_types.Config{
+					SendTimeout:    time.Second * 10,
+					SendTrys:       1,
+					MethodTimeouts: map[string]time.Duration{"Switch.GetStatus": tt.timeout},
+				})
+
+				device.InjectFault(&fake.Fault{Method: "Switch.GetStatus", Delay: time.Second})
+
+				ctx := context.Background()
+				if tt.deadline > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+					defer cancel()
+				}
+
+				start := time.Now()
+
+				_, err := client.GetStatus(ctx, 0)
+				if !errors.Is(err, msg_types.ErrTimeout) && !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("error %v, want ErrTimeout", err)
+				}
+
+				if elapsed := time.Since(start); elapsed > time.Millisecond*500 {
+					t.Errorf("timed out after %v, want about 50ms", elapsed)
+				}
+			})
+		}
+	}
+}
+
+// TestSubscribe checks that notifications sent by the device are received by a subscriber
+// with a matching filter and that transports without notifications return an error
+func TestSubscribe(t *testing.T) {
+
+	for _, transport := range transports {
+		t.Run(transport, func(t *testing.T) {
+
+			e := emulator.New(&emulator.Config{
+				Device: &fake.DeviceConfig{Switch: 2},
+			})
+
+			server := httptest.NewServer(e)
+			t.Cleanup(server.Close)
+
+			c, err := client.New(&client.Config{
+				Hostname:  strings.TrimPrefix(server.URL, "http://"),
+				Transport: transport,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(c.Close)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			notifications, err := c.Subscribe(ctx, &client.NotificationFilter{Components: []string{"switch:0"}})
+
+			if transport == client_types.TransportHTTP {
+				if err == nil {
+					t.Fatal("error nil, want error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			on := true
+			for _, id := range []int{1, 0} {
+				err = c.Switch().Set(ctx, id, &on)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			select {
+			case n := <-notifications:
+				if n.Src != e.Device().ID() || n.Switch[0] == nil || !n.Switch[0].Output {
+					t.Errorf("notification %+v, want switch:0 on from %s", n, e.Device().ID())
+				}
+				if n.Switch[1] != nil {
+					t.Errorf("notification for switch:1 received, want filtered")
+				}
+			case <-time.After(time.Second * 5):
+				t.Fatal("notification not received")
+			}
+
+			cancel()
+
+			for range notifications {
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package emulator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// model is a device model that can be emulated
+type model struct {
+	// idPrefix the prefix of the device ID. The ID is the prefix followed by the MAC.
+	idPrefix string
+	config   *DeviceConfig
+	// switchPM if true switches report power metering status
+	switchPM bool
+}
+
+var models = map[string]*model{
+	"PlusWallDimmer": {
+		idPrefix: "shellypluswdus",
+		config: &DeviceConfig{
+			Model:    "SNDM-0013US",
+			App:      "PlusWallDimmer",
+			Ver:      "1.0.8",
+			FwID:     "20231107-164738/1.0.8-g8c7bb8d",
+			Light:    1,
+			Services: []string{"sys", "wifi", "mqtt", "cloud", "ble", "ws"},
+		},
+	},
+	"Plus1PM": {
+		idPrefix: "shellyplus1pm",
+		config: &DeviceConfig{
+			Model:    "SNSW-001P16EU",
+			App:      "Plus1PM",
+			Ver:      "1.0.8",
+			FwID:     "20231107-164738/1.0.8-g8c7bb8d",
+			Switch:   1,
+			Input:    1,
+			Services: []string{"sys", "wifi", "mqtt", "cloud", "ble", "ws"},
+		},
+		switchPM: true,
+	},
+	"Plus2PM": {
+		idPrefix: "shellyplus2pm",
+		config: &DeviceConfig{
+			Model:    "SNSW-102P16EU",
+			App:      "Plus2PM",
+			Ver:      "1.0.8",
+			FwID:     "20231107-164738/1.0.8-g8c7bb8d",
+			Cover:    1,
+			Input:    2,
+			Services: []string{"sys", "wifi", "mqtt", "cloud", "ble", "ws"},
+		},
+	},
+	"PlusPMMini": {
+		idPrefix: "shellypluspmmini",
+		config: &DeviceConfig{
+			Model:      "SNPM-001PCEU16",
+			App:        "PlusPMMini",
+			Ver:        "1.0.8",
+			FwID:       "20231107-164738/1.0.8-g8c7bb8d",
+			Components: []string{"pm1:0"},
+			Services:   []string{"sys", "wifi", "mqtt", "cloud", "ble", "ws"},
+		},
+	},
+	"PlusHT": {
+		idPrefix: "shellyplusht",
+		config: &DeviceConfig{
+			Model:      "SNSN-0013A",
+			App:        "PlusHT",
+			Ver:        "1.0.8",
+			FwID:       "20231107-164738/1.0.8-g8c7bb8d",
+			Components: []string{"temperature:0", "humidity:0", "devicepower:0"},
+			Services:   []string{"sys", "wifi", "mqtt", "cloud", "ble", "ws"},
+		},
+	},
+	"Pro3EM": {
+		idPrefix: "shellypro3em",
+		config: &DeviceConfig{
+			Model:      "SPEM-003CEBEU",
+			App:        "Pro3EM",
+			Ver:        "1.0.8",
+			FwID:       "20231107-164916/1.0.8-g8c7bb8d",
+			Components: []string{"em:0", "emdata:0"},
+			Services:   []string{"sys", "wifi", "eth", "mqtt", "cloud", "ble", "ws"},
+		},
+	},
+	"ProEM": {
+		idPrefix: "shellyproem50",
+		config: &DeviceConfig{
+			Model:      "SPEM-002CEBEU50",
+			App:        "ProEM",
+			Ver:        "1.0.8",
+			FwID:       "20231107-164916/1.0.8-g8c7bb8d",
+			Switch:     1,
+			Components: []string{"em1:0", "em1:1", "em1data:0", "em1data:1"},
+			Services:   []string{"sys", "wifi", "eth", "mqtt", "cloud", "ble", "ws"},
+		},
+	},
+	"Pro4PM": {
+		idPrefix: "shellypro4pm",
+		config: &DeviceConfig{
+			Model:    "SPSW-104PE16EU",
+			App:      "Pro4PM",
+			Ver:      "1.0.8",
+			FwID:     "20231107-164916/1.0.8-g8c7bb8d",
+			Switch:   4,
+			Input:    4,
+			Services: []string{"sys", "wifi", "eth", "mqtt", "cloud", "ble", "ws"},
+		},
+		switchPM: true,
+	},
+}
+
+// Models returns the names of the models that can be emulated. The name is the app
+// reported in the device info.
+func Models() []string {
+	var names []string
+	for name := range models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewDeviceConfig returns the config of an emulated device of the named model. The index
+// is used to give each device a unique MAC and ID.
+func NewDeviceConfig(name string, index int) (*DeviceConfig, error) {
+
+	m, ok := models[name]
+	if !ok {
+		for k, v := range models {
+			if strings.EqualFold(k, name) {
+				m, ok = v, true
+				break
+			}
+		}
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("model %s is not supported; supported models are %s", name, strings.Join(Models(), ", "))
+	}
+
+	config := m.config.Clone()
+	config.MAC = fmt.Sprintf("%s%06X", macPrefix, index)
+	config.ID = m.idPrefix + "-" + strings.ToLower(config.MAC)
+
+	if m.switchPM {
+		config.Status = make(map[string]map[string]interface{})
+		for i := 0; i < config.Switch; i++ {
+			config.Status["switch:"+strconv.Itoa(i)] = map[string]interface{}{
+				"apower":  0.0,
+				"voltage": 230.0,
+				"freq":    50.0,
+				"current": 0.0,
+				"pf":      0.0,
+				"aenergy": map[string]interface{}{
+					"total":     0.0,
+					"by_minute": []float64{0, 0, 0},
+					"minute_ts": 0,
+				},
+			}
+		}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,27 @@
+package types
+
+import (
+	"github.com/jinzhu/copier"
+
+	fake_types "github.com/jodydadescott/shelly-client/sdk/fake/types"
+)
+
+type DeviceConfig = fake_types.DeviceConfig
+
+// Config emulator config. The emulator serves JSON-RPC on Path over HTTP POST and
+// websocket the same way a device does.
+type Config struct {
+	// ListenAddress address to listen on. Default is 127.0.0.1:8080
+	ListenAddress string `json:"listenAddress,omitempty" yaml:"listenAddress,omitempty"`
+	// Path the HTTP path RPC is served on. Default is /rpc
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Device the emulated device. Use NewDeviceConfig to get the config of a known model.
+	Device *DeviceConfig `json:"device,omitempty" yaml:"device,omitempty"`
+}
+
+// Clone return copy
+func (t *Config) Clone() *Config {
+	c := &Config{}
+	copier.Copy(&c, &t)
+	return c
+}
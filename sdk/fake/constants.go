@@ -0,0 +1,53 @@
+package fake
+
+const (
+	defaultID         = "shellyfake-000000000000"
+	defaultMAC        = "000000000000"
+	defaultModel      = "FAKE-001"
+	defaultApp        = "Fake"
+	defaultVer        = "1.0.0"
+	defaultFwID       = "20231107-000000/1.0.0-fake"
+	defaultShellyUser = "admin"
+	gen               = 2
+	dummyHA2          = "6370ec69915103833b5222b368555393393f098bfbfbb59f47e0590af135f062"
+
+	sourceRPC = "WS_in"
+
+	// componentsPageSize the number of components returned by each Shelly.GetComponents
+	componentsPageSize = 8
+	// dynamicComponentMinID the lowest ID of a dynamic component such as boolean:200
+	dynamicComponentMinID = 200
+	// maxSchedules the maximum number of schedule jobs
+	maxSchedules = 20
+	// maxWebhooks the maximum number of webhooks
+	maxWebhooks = 20
+	// maxScripts the maximum number of scripts
+	maxScripts = 10
+	// scriptCodeChunkSize the maximum number of bytes returned by each Script.GetCode
+	scriptCodeChunkSize = 1024
+	// maxKVSKeys the maximum number of keys of the KVS
+	maxKVSKeys = 50
+	// maxKVSKeyLength the maximum length of a key of the KVS in bytes
+	maxKVSKeyLength = 42
+	// maxKVSValueLength the maximum length of a value of the KVS in bytes encoded as JSON
+	maxKVSValueLength = 255
+	// kvsPageSize the number of items returned by each KVS.GetMany
+	kvsPageSize = 10
+	// emDataPeriod the period of each stored EMData and EM1Data record in seconds
+	emDataPeriod = 60
+	// emDataHistory the seconds of stored EMData and EM1Data records after a reset
+	emDataHistory = 24 * 60 * 60
+	// emDataPageSize the maximum number of records returned by each EMData.GetData
+	emDataPageSize = 60
+)
+
+var defaultServices = []string{"sys", "wifi", "mqtt", "cloud", "ble", "ws"}
+
+// restartRequiredServices are the components that require a restart after a config change
+var restartRequiredServices = map[string]bool{
+	"mqtt":  true,
+	"cloud": true,
+	"ble":   true,
+	"ws":    true,
+	"eth":   true,
+}
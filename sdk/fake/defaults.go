@@ -0,0 +1,447 @@
+package fake
+
+// getDefaultConfig returns the default config of a component
+func getDefaultConfig(config *DeviceConfig, componentType string, id int) map[string]interface{} {
+
+	switch componentType {
+
+	case "sys":
+		return map[string]interface{}{
+			"device": map[string]interface{}{
+				"name":         nil,
+				"mac":          config.MAC,
+				"fw_id":        config.FwID,
+				"eco_mode":     false,
+				"discoverable": true,
+			},
+			"location": map[string]interface{}{
+				"tz":  nil,
+				"lat": nil,
+				"lon": nil,
+			},
+			"debug": map[string]interface{}{
+				"mqtt":      map[string]interface{}{"enable": false},
+				"websocket": map[string]interface{}{"enable": false},
+				"udp":       map[string]interface{}{"addr": nil},
+			},
+			"ui_data": map[string]interface{}{},
+			"rpc_udp": map[string]interface{}{
+				"dst_addr":    nil,
+				"listen_port": nil,
+			},
+			"sntp": map[string]interface{}{
+				"server": "time.google.com",
+			},
+			"cfg_rev": 0,
+		}
+
+	case "wifi":
+		return map[string]interface{}{
+			"ap": map[string]interface{}{
+				"ssid":    config.ID,
+				"is_open": true,
+				"enable":  false,
+				"range_extender": map[string]interface{}{
+					"enable": false,
+				},
+			},
+			"sta": map[string]interface{}{
+				"ssid":       nil,
+				"is_open":    true,
+				"enable":     false,
+				"ipv4mode":   "dhcp",
+				"ip":         nil,
+				"netmask":    nil,
+				"gw":         nil,
+				"nameserver": nil,
+			},
+			"sta1": map[string]interface{}{
+				"ssid":       nil,
+				"is_open":    true,
+				"enable":     false,
+				"ipv4mode":   "dhcp",
+				"ip":         nil,
+				"netmask":    nil,
+				"gw":         nil,
+				"nameserver": nil,
+			},
+			"roam": map[string]interface{}{
+				"rssi_thr": -80,
+				"interval": 60,
+			},
+		}
+
+	case "mqtt":
+		return map[string]interface{}{
+			"enable":          false,
+			"server":          nil,
+			"client_id":       config.ID,
+			"user":            nil,
+			"ssl_ca":          nil,
+			"topic_prefix":    config.ID,
+			"rpc_ntf":         true,
+			"status_ntf":      false,
+			"use_client_cert": false,
+			"enable_rpc":      true,
+			"enable_control":  true,
+		}
+
+	case "cloud":
+		return map[string]interface{}{
+			"enable": false,
+			"server": "shelly-fake.shelly.cloud:6022/jrpc",
+		}
+
+	case "ble":
+		return map[string]interface{}{
+			"enable": true,
+			"rpc": map[string]interface{}{
+				"enable": true,
+			},
+			"observer": map[string]interface{}{
+				"enable": false,
+			},
+		}
+
+	case "ws":
+		return map[string]interface{}{
+			"enable": false,
+			"server": nil,
+			"ssl_ca": "ca.pem",
+		}
+
+	case "eth":
+		return map[string]interface{}{
+			"enable":     true,
+			"ipv4mode":   "dhcp",
+			"ip":         nil,
+			"netmask":    nil,
+			"gw":         nil,
+			"nameserver": nil,
+		}
+
+	case "switch":
+		return map[string]interface{}{
+			"id":             id,
+			"name":           nil,
+			"in_mode":        "follow",
+			"initial_state":  "match_input",
+			"auto_on":        false,
+			"auto_on_delay":  60.0,
+			"auto_off":       false,
+			"auto_off_delay": 60.0,
+		}
+
+	case "light":
+		return map[string]interface{}{
+			"id":             id,
+			"name":           nil,
+			"initial_state":  "restore_last",
+			"auto_on":        false,
+			"auto_on_delay":  60.0,
+			"auto_off":       false,
+			"auto_off_delay": 60.0,
+			"default": map[string]interface{}{
+				"brightness": 50.0,
+			},
+		}
+
+	case "input":
+		return map[string]interface{}{
+			"id":     id,
+			"name":   nil,
+			"type":   "button",
+			"invert": false,
+		}
+
+	case "script":
+		return map[string]interface{}{
+			"id":     id,
+			"name":   nil,
+			"enable": false,
+		}
+
+	case "cover":
+		return map[string]interface{}{
+			"id":   id,
+			"name": nil,
+			"motor": map[string]interface{}{
+				"idle_power_thr":      2.0,
+				"idle_confirm_period": 0.25,
+			},
+			"maxtime_open":       60.0,
+			"maxtime_close":      60.0,
+			"initial_state":      "stopped",
+			"invert_directions":  false,
+			"in_mode":            "dual",
+			"swap_inputs":        false,
+			"power_limit":        2800.0,
+			"voltage_limit":      280.0,
+			"undervoltage_limit": 0.0,
+			"current_limit":      10.0,
+			"safety_switch": map[string]interface{}{
+				"enable":       false,
+				"direction":    "both",
+				"action":       "stop",
+				"allowed_move": nil,
+			},
+			"obstruction_detection": map[string]interface{}{
+				"enable":    false,
+				"direction": "both",
+				"action":    "stop",
+				"power_thr": 1000.0,
+				"holdoff":   1.0,
+			},
+		}
+
+	case "em":
+		return map[string]interface{}{
+			"id":                     id,
+			"name":                   nil,
+			"blink_mode_selector":    "active_energy",
+			"phase_selector":         "all",
+			"monitor_phase_sequence": false,
+			"ct_type":                "120A",
+		}
+
+	case "em1":
+		return map[string]interface{}{
+			"id":      id,
+			"name":    nil,
+			"reverse": false,
+			"ct_type": "50A",
+		}
+
+	case "pm1":
+		return map[string]interface{}{
+			"id":   id,
+			"name": nil,
+		}
+
+	case "temperature":
+		return map[string]interface{}{
+			"id":           id,
+			"name":         nil,
+			"report_thr_C": 0.5,
+			"offset_C":     0.0,
+		}
+
+	case "humidity":
+		return map[string]interface{}{
+			"id":         id,
+			"name":       nil,
+			"report_thr": 1.0,
+			"offset":     0.0,
+		}
+
+	case "illuminance":
+		return map[string]interface{}{
+			"id":         id,
+			"name":       nil,
+			"dark_thr":   100,
+			"bright_thr": 500,
+		}
+
+	}
+
+	return map[string]interface{}{}
+}
+
+// getDefaultStatus returns the default status of a component
+func getDefaultStatus(config *DeviceConfig, componentType string, id int) map[string]interface{} {
+
+	switch componentType {
+
+	case "sys":
+		return map[string]interface{}{
+			"mac":               config.MAC,
+			"restart_required":  false,
+			"time":              nil,
+			"unixtime":          nil,
+			"uptime":            0,
+			"ram_size":          246164,
+			"ram_free":          140000,
+			"fs_size":           458752,
+			"fs_free":           200000,
+			"cfg_rev":           0,
+			"kvs_rev":           0,
+			"schedule_rev":      0,
+			"webhook_rev":       0,
+			"available_updates": map[string]interface{}{},
+		}
+
+	case "wifi":
+		return map[string]interface{}{
+			"sta_ip": nil,
+			"status": "disconnected",
+			"ssid":   nil,
+			"rssi":   0,
+		}
+
+	case "mqtt", "cloud", "ws":
+		return map[string]interface{}{
+			"connected": false,
+		}
+
+	case "eth":
+		return map[string]interface{}{
+			"ip": nil,
+		}
+
+	case "switch":
+		return map[string]interface{}{
+			"id":     id,
+			"source": "init",
+			"output": false,
+			"temperature": map[string]interface{}{
+				"tC": 40.0,
+				"tF": 104.0,
+			},
+		}
+
+	case "light":
+		return map[string]interface{}{
+			"id":         id,
+			"source":     "init",
+			"output":     false,
+			"brightness": 50.0,
+		}
+
+	case "input":
+		return map[string]interface{}{
+			"id":    id,
+			"state": nil,
+		}
+
+	case "cover":
+		return map[string]interface{}{
+			"id":             id,
+			"source":         "init",
+			"state":          "stopped",
+			"pos_control":    false,
+			"last_direction": nil,
+			"aenergy": map[string]interface{}{
+				"total":     0.0,
+				"by_minute": []float64{0, 0, 0},
+				"minute_ts": 0,
+			},
+			"temperature": map[string]interface{}{
+				"tC": 40.0,
+				"tF": 104.0,
+			},
+		}
+
+	case "script":
+		return map[string]interface{}{
+			"id":       id,
+			"running":  false,
+			"mem_free": 25000,
+		}
+
+	case "em":
+		status := map[string]interface{}{
+			"id":               id,
+			"n_current":        nil,
+			"total_current":    0.0,
+			"total_act_power":  0.0,
+			"total_aprt_power": 0.0,
+		}
+		for _, phase := range []string{"a", "b", "c"} {
+			status[phase+"_current"] = 0.0
+			status[phase+"_voltage"] = 230.0
+			status[phase+"_act_power"] = 0.0
+			status[phase+"_aprt_power"] = 0.0
+			status[phase+"_pf"] = 0.0
+			status[phase+"_freq"] = 50.0
+		}
+		return status
+
+	case "em1":
+		return map[string]interface{}{
+			"id":          id,
+			"current":     0.0,
+			"voltage":     230.0,
+			"act_power":   0.0,
+			"aprt_power":  0.0,
+			"pf":          0.0,
+			"freq":        50.0,
+			"calibration": "factory",
+		}
+
+	case "pm1":
+		return map[string]interface{}{
+			"id":        id,
+			"voltage":   230.0,
+			"current":   0.0,
+			"apower":    0.0,
+			"aprtpower": 0.0,
+			"pf":        0.0,
+			"freq":      50.0,
+			"aenergy": map[string]interface{}{
+				"total":     0.0,
+				"by_minute": []float64{0, 0, 0},
+				"minute_ts": 0,
+			},
+			"ret_aenergy": map[string]interface{}{
+				"total":     0.0,
+				"by_minute": []float64{0, 0, 0},
+				"minute_ts": 0,
+			},
+		}
+
+	case "emdata":
+		return map[string]interface{}{
+			"id":                     id,
+			"a_total_act_energy":     0.0,
+			"a_total_act_ret_energy": 0.0,
+			"b_total_act_energy":     0.0,
+			"b_total_act_ret_energy": 0.0,
+			"c_total_act_energy":     0.0,
+			"c_total_act_ret_energy": 0.0,
+			"total_act":              0.0,
+			"total_act_ret":          0.0,
+		}
+
+	case "em1data":
+		return map[string]interface{}{
+			"id":                   id,
+			"total_act_energy":     0.0,
+			"total_act_ret_energy": 0.0,
+		}
+
+	case "temperature":
+		return map[string]interface{}{
+			"id": id,
+			"tC": 21.5,
+			"tF": 70.7,
+		}
+
+	case "humidity":
+		return map[string]interface{}{
+			"id": id,
+			"rh": 45.0,
+		}
+
+	case "illuminance":
+		return map[string]interface{}{
+			"id":           id,
+			"lux":          300,
+			"illumination": "twilight",
+		}
+
+	case "devicepower":
+		return map[string]interface{}{
+			"id": id,
+			"battery": map[string]interface{}{
+				"V":       5.5,
+				"percent": 90,
+			},
+			"external": map[string]interface{}{
+				"present": false,
+			},
+		}
+
+	}
+
+	return map[string]interface{}{}
+}
@@ -0,0 +1,1248 @@
+package fake
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+// Device is a stateful simulated device. It processes JSON-RPC frames the same way a
+// device does and is safe for concurrent use.
+type Device struct {
+	mutex      sync.Mutex
+	config     *DeviceConfig
+	components map[string]*component
+	ha1        string
+	nonce      int
+	faults     []*Fault
+	calls      []string
+	handlers   map[int]func([]byte)
+	handlerID  int
+	schedules  []*job
+	scheduleID int
+	webhooks   []map[string]interface{}
+	webhookID  int
+	scriptCode map[int]string
+	kvs        map[string]*kvsItem
+	// emDataStart the timestamp of the first stored record of each EMData and EM1Data
+	// component by key
+	emDataStart map[string]int64
+	// logHandlers receive the debug log entries; pendingLogs are the entries of the call
+	// being processed
+	logHandlers map[int]func([]byte)
+	pendingLogs [][]byte
+}
+
+type component struct {
+	componentType string
+	id            *int
+	config        map[string]interface{}
+	status        map[string]interface{}
+}
+
+// key returns the key of the component such as switch:0 or sys
+func (t *component) key() string {
+	if t.id == nil {
+		return t.componentType
+	}
+	return t.componentType + ":" + strconv.Itoa(*t.id)
+}
+
+type rpcRequest struct {
+	ID     *int            `json:"id,omitempty"`
+	Src    *string         `json:"src,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Auth   *AuthResponse   `json:"auth,omitempty"`
+}
+
+type rpcResponse struct {
+	ID     *int        `json:"id,omitempty"`
+	Src    string      `json:"src"`
+	Dst    *string     `json:"dst,omitempty"`
+	Result interface{} `json:"result"`
+	Error  *Error      `json:"error,omitempty"`
+}
+
+type rpcErrorResponse struct {
+	ID    *int    `json:"id,omitempty"`
+	Src   string  `json:"src"`
+	Dst   *string `json:"dst,omitempty"`
+	Error *Error  `json:"error"`
+}
+
+type params struct {
+	ID     *int                   `json:"id,omitempty"`
+	Config map[string]interface{} `json:"config,omitempty"`
+	On     *bool                  `json:"on,omitempty"`
+	// Brightness is only used by Light.Set
+	Brightness *float64 `json:"brightness,omitempty"`
+	// Duration, Pos and Type are only used by the Cover methods
+	Duration *float64 `json:"duration,omitempty"`
+	Pos      *int     `json:"pos,omitempty"`
+	Type     []string `json:"type,omitempty"`
+	// Ts and EndTs are only used by the EMData and EM1Data methods
+	Ts    *int64 `json:"ts,omitempty"`
+	EndTs *int64 `json:"end_ts,omitempty"`
+	// User, Realm and Ha1 are only used by Shelly.SetAuth
+	User  *string `json:"user,omitempty"`
+	Realm *string `json:"realm,omitempty"`
+	Ha1   *string `json:"ha1,omitempty"`
+	// Enable, Timespec and Calls are only used by the Schedule methods
+	Enable   *bool         `json:"enable,omitempty"`
+	Timespec *string       `json:"timespec,omitempty"`
+	Calls    []interface{} `json:"calls,omitempty"`
+	// Key, Value, Etag and Match are only used by the KVS methods
+	Key   *string         `json:"key,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+	Etag  *string         `json:"etag,omitempty"`
+	Match *string         `json:"match,omitempty"`
+	// Name, Code, Append and Len are only used by the Script methods
+	Name   *string `json:"name,omitempty"`
+	Code   *string `json:"code,omitempty"`
+	Append *bool   `json:"append,omitempty"`
+	Len    *int    `json:"len,omitempty"`
+	// Offset, Include, DynamicOnly and Keys are only used by Shelly.GetComponents and
+	// Offset also by Script.GetCode
+	Offset      *int     `json:"offset,omitempty"`
+	Include     []string `json:"include,omitempty"`
+	DynamicOnly *bool    `json:"dynamic_only,omitempty"`
+	Keys        []string `json:"keys,omitempty"`
+}
+
+// NewDevice returns a new simulated device
+func NewDevice(config *DeviceConfig) *Device {
+
+	if config == nil {
+		config = &DeviceConfig{}
+	} else {
+		config = config.Clone()
+	}
+
+	if config.ID == "" {
+		config.ID = defaultID
+	}
+
+	if config.MAC == "" {
+		config.MAC = defaultMAC
+	}
+
+	if config.Model == "" {
+		config.Model = defaultModel
+	}
+
+	if config.App == "" {
+		config.App = defaultApp
+	}
+
+	if config.Ver == "" {
+		config.Ver = defaultVer
+	}
+
+	if config.FwID == "" {
+		config.FwID = defaultFwID
+	}
+
+	if len(config.Services) == 0 {
+		config.Services = defaultServices
+	}
+
+	t := &Device{
+		config:      config,
+		handlers:    make(map[int]func([]byte)),
+		logHandlers: make(map[int]func([]byte)),
+	}
+
+	t.reset()
+	return t
+}
+
+// reset sets the components and auth to the initial state. mutex must be held.
+func (t *Device) reset() {
+
+	t.components = make(map[string]*component)
+	t.schedules = nil
+	t.scheduleID = 0
+	t.webhooks = nil
+	t.webhookID = 0
+	t.scriptCode = make(map[int]string)
+	t.kvs = make(map[string]*kvsItem)
+
+	add := func(componentType string, id *int) {
+
+		c := &component{
+			componentType: componentType,
+			id:            id,
+		}
+
+		instance := 0
+		if id != nil {
+			instance = *id
+		}
+
+		c.config = copyMap(getDefaultConfig(t.config, componentType, instance))
+		c.status = copyMap(getDefaultStatus(t.config, componentType, instance))
+
+		if id != nil {
+			if _, ok := c.config["id"]; !ok {
+				c.config["id"] = instance
+			}
+			if _, ok := c.status["id"]; !ok {
+				c.status["id"] = instance
+			}
+		}
+
+		if config, ok := t.config.Config[c.key()]; ok {
+			mergeMap(c.config, copyMap(config))
+		}
+
+		if status, ok := t.config.Status[c.key()]; ok {
+			mergeMap(c.status, copyMap(status))
+		}
+
+		t.components[c.key()] = c
+	}
+
+	add("sys", nil)
+
+	for _, service := range t.config.Services {
+		if service != "sys" {
+			add(service, nil)
+		}
+	}
+
+	for i := 0; i < t.config.Switch; i++ {
+		id := i
+		add("switch", &id)
+	}
+
+	for i := 0; i < t.config.Light; i++ {
+		id := i
+		add("light", &id)
+	}
+
+	for i := 0; i < t.config.Input; i++ {
+		id := i
+		add("input", &id)
+	}
+
+	for i := 0; i < t.config.Cover; i++ {
+		id := i
+		add("cover", &id)
+	}
+
+	for _, key := range t.config.Components {
+		componentType, idString, found := strings.Cut(key, ":")
+		if !found {
+			add(componentType, nil)
+			continue
+		}
+		id, err := strconv.Atoi(idString)
+		if err != nil {
+			add(key, nil)
+			continue
+		}
+		add(componentType, &id)
+	}
+
+	t.emDataStart = make(map[string]int64)
+	for key, c := range t.components {
+		if c.componentType == "emdata" || c.componentType == "em1data" {
+			t.emDataStart[key] = emDataNow() - emDataHistory
+		}
+	}
+
+	t.ha1 = ""
+	if t.config.Password != "" {
+		t.ha1 = getSHA256(defaultShellyUser + ":" + t.config.ID + ":" + t.config.Password)
+	}
+
+	t.nonce = newNonce()
+}
+
+// ID returns the device ID
+func (t *Device) ID() string {
+	return t.config.ID
+}
+
+// Config returns the device config
+func (t *Device) Config() *DeviceConfig {
+	return t.config.Clone()
+}
+
+// InjectFault adds a fault. Faults are matched in the order they were added.
+func (t *Device) InjectFault(fault *Fault) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.faults = append(t.faults, fault.Clone())
+}
+
+// ClearFaults removes all faults
+func (t *Device) ClearFaults() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.faults = nil
+}
+
+// Calls returns the methods called on the device in order
+func (t *Device) Calls() []string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return append([]string{}, t.calls...)
+}
+
+// GetConfig returns a copy of the config of the component with the key such as switch:0
+// or nil if the component does not exist
+func (t *Device) GetConfig(key string) map[string]interface{} {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	c := t.components[key]
+	if c == nil {
+		return nil
+	}
+	return copyMap(c.config)
+}
+
+// GetStatus returns a copy of the status of the component with the key such as switch:0
+// or nil if the component does not exist
+func (t *Device) GetStatus(key string) map[string]interface{} {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	c := t.components[key]
+	if c == nil {
+		return nil
+	}
+	return copyMap(c.status)
+}
+
+// SetStatus merges status into the status of the component with the key such as input:0
+// and sends a NotifyStatus with the changes. This simulates a change on the device such
+// as an input changing state.
+func (t *Device) SetStatus(key string, status map[string]interface{}) error {
+
+	t.mutex.Lock()
+
+	c := t.components[key]
+	if c == nil {
+		t.mutex.Unlock()
+		return fmt.Errorf("component %s not found", key)
+	}
+
+	status = copyMap(status)
+	mergeMap(c.status, status)
+	frame := t.newNotification(nil, msg_types.Notification{Method: "NotifyStatus"}, map[string]interface{}{key: status})
+
+	t.mutex.Unlock()
+
+	t.notify(frame)
+	return nil
+}
+
+// Event sends a NotifyEvent for the component with the key such as input:0. This
+// simulates an event such as a button press (single_push, double_push, long_push).
+func (t *Device) Event(key string, event string) error {
+
+	t.mutex.Lock()
+
+	c := t.components[key]
+	if c == nil {
+		t.mutex.Unlock()
+		return fmt.Errorf("component %s not found", key)
+	}
+
+	e := map[string]interface{}{
+		"component": key,
+		"event":     event,
+		"ts":        getTs(),
+	}
+
+	if c.id != nil {
+		e["id"] = *c.id
+	}
+
+	frame := t.newNotification(nil, msg_types.Notification{Method: "NotifyEvent"}, map[string]interface{}{
+		"events": []interface{}{e},
+	})
+
+	t.mutex.Unlock()
+
+	t.notify(frame)
+	return nil
+}
+
+// AddNotificationHandler registers a handler that is called with each notification frame
+// sent by the device. The returned function removes the handler.
+func (t *Device) AddNotificationHandler(handler func([]byte)) func() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.handlerID = t.handlerID + 1
+	id := t.handlerID
+	t.handlers[id] = handler
+
+	return func() {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		delete(t.handlers, id)
+	}
+}
+
+// AddLogHandler registers a handler that is called with each debug log entry of the
+// device such as the start of a script. The returned function removes the handler.
+func (t *Device) AddLogHandler(handler func([]byte)) func() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.handlerID = t.handlerID + 1
+	id := t.handlerID
+	t.logHandlers[id] = handler
+
+	return func() {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		delete(t.logHandlers, id)
+	}
+}
+
+// Log sends a debug log entry with the message. This simulates output of the device such
+// as a script calling print.
+func (t *Device) Log(message string) {
+	t.mutex.Lock()
+	t.log("%s", message)
+	logs := t.pendingLogs
+	t.pendingLogs = nil
+	t.mutex.Unlock()
+
+	t.sendLogs(logs...)
+}
+
+func (t *Device) sendLogs(entries ...[]byte) {
+
+	if len(entries) == 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	var handlers []func([]byte)
+	for _, handler := range t.logHandlers {
+		handlers = append(handlers, handler)
+	}
+	t.mutex.Unlock()
+
+	for _, entry := range entries {
+		for _, handler := range handlers {
+			handler(entry)
+		}
+	}
+}
+
+func (t *Device) newNotification(dst *string, notification msg_types.Notification, params map[string]interface{}) []byte {
+
+	if _, ok := params["ts"]; !ok {
+		params["ts"] = getTs()
+	}
+
+	notification.Src = t.config.ID
+	if dst != nil {
+		notification.Dst = *dst
+	}
+
+	b, err := json.Marshal(params)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("notification error %v", err))
+		return nil
+	}
+
+	notification.Params = b
+
+	b, err = json.Marshal(notification)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("notification error %v", err))
+		return nil
+	}
+
+	return b
+}
+
+func (t *Device) notify(frames ...[]byte) {
+
+	t.mutex.Lock()
+	var handlers []func([]byte)
+	for _, handler := range t.handlers {
+		handlers = append(handlers, handler)
+	}
+	t.mutex.Unlock()
+
+	for _, frame := range frames {
+		if frame == nil {
+			continue
+		}
+		for _, handler := range handlers {
+			handler(frame)
+		}
+	}
+}
+
+// getFault returns the first fault that matches the method and decrements its count.
+// mutex must be held.
+func (t *Device) getFault(method string) *Fault {
+
+	for i, fault := range t.faults {
+
+		if fault.Method != "" && fault.Method != method {
+			continue
+		}
+
+		if fault.Count > 0 {
+			fault.Count--
+			if fault.Count == 0 {
+				t.faults = append(t.faults[:i], t.faults[i+1:]...)
+			}
+		}
+
+		return fault.Clone()
+	}
+
+	return nil
+}
+
+// Call processes a JSON-RPC request frame and returns the response frame. An error is
+// returned only if the frame is not valid JSON or an injected fault has Err set.
+func (t *Device) Call(ctx context.Context, b []byte) ([]byte, error) {
+
+	request := &rpcRequest{}
+	err := json.Unmarshal(b, request)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mutex.Lock()
+	t.calls = append(t.calls, request.Method)
+	fault := t.getFault(request.Method)
+	t.mutex.Unlock()
+
+	if fault != nil {
+
+		if fault.Delay > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(fault.Delay):
+			}
+		}
+
+		if fault.Err != nil {
+			return nil, fault.Err
+		}
+
+		if fault.Code != 0 {
+			return json.Marshal(&rpcErrorResponse{
+				ID:    request.ID,
+				Src:   t.config.ID,
+				Dst:   request.Src,
+				Error: &Error{Code: fault.Code, Message: fault.Message},
+			})
+		}
+	}
+
+	t.mutex.Lock()
+
+	var notifications [][]byte
+	var result interface{}
+	var rpcErr *Error
+
+	if !t.isAuthorized(request) {
+		rpcErr = t.getAuthError()
+	} else {
+		result, notifications, rpcErr = t.dispatch(request)
+	}
+
+	logs := t.pendingLogs
+	t.pendingLogs = nil
+
+	t.mutex.Unlock()
+
+	t.notify(notifications...)
+	t.sendLogs(logs...)
+
+	if rpcErr != nil {
+		return json.Marshal(&rpcErrorResponse{
+			ID:    request.ID,
+			Src:   t.config.ID,
+			Dst:   request.Src,
+			Error: rpcErr,
+		})
+	}
+
+	return json.Marshal(&rpcResponse{
+		ID:     request.ID,
+		Src:    t.config.ID,
+		Dst:    request.Src,
+		Result: result,
+	})
+}
+
+// isAuthorized returns true if auth is disabled, the method does not require auth or the
+// request has a valid auth response. mutex must be held.
+func (t *Device) isAuthorized(request *rpcRequest) bool {
+
+	if t.ha1 == "" {
+		return true
+	}
+
+	if request.Method == "Shelly.GetDeviceInfo" {
+		return true
+	}
+
+	auth := request.Auth
+	if auth == nil {
+		return false
+	}
+
+	if auth.Realm != t.config.ID || auth.Username != defaultShellyUser || !t.isNonce(auth.Nonce) {
+		return false
+	}
+
+	expected := getSHA256(fmt.Sprintf("%s:%s:%d:%s:%s:%s", t.ha1, auth.Nonce, 1, auth.Cnonce, "auth", dummyHA2))
+	return auth.Response == expected
+}
+
+// isNonce returns true if nonce is the current nonce. Like the device the nonce is
+// accepted in decimal as sent in a RPC frame and in hex as sent in the WWW-Authenticate
+// header. mutex must be held.
+func (t *Device) isNonce(nonce string) bool {
+	return nonce == strconv.Itoa(t.nonce) || nonce == strconv.FormatInt(int64(t.nonce), 16)
+}
+
+// getAuthError returns the error sent by the device when auth is required. mutex must be held.
+func (t *Device) getAuthError() *Error {
+
+	b, _ := json.Marshal(&AuthRequest{
+		AuthType:   "digest",
+		Nonce:      msg_types.Nonce(strconv.Itoa(t.nonce)),
+		NonceCount: 1,
+		Realm:      t.config.ID,
+		Algorithm:  "SHA-256",
+	})
+
+	return &Error{
+		Code:    401,
+		Message: string(b),
+	}
+}
+
+// dispatch calls the method. mutex must be held.
+func (t *Device) dispatch(request *rpcRequest) (interface{}, [][]byte, *Error) {
+
+	p := &params{}
+	if len(request.Params) > 0 {
+		err := json.Unmarshal(request.Params, p)
+		if err != nil {
+			return nil, nil, invalidArgument(err.Error())
+		}
+	}
+
+	namespace, name, _ := strings.Cut(request.Method, ".")
+
+	if namespace == "Shelly" {
+		return t.dispatchShelly(request, name, p)
+	}
+
+	if namespace == "Schedule" {
+		return t.dispatchSchedule(request, name, p)
+	}
+
+	if namespace == "Webhook" {
+		return t.dispatchWebhook(request, name, p)
+	}
+
+	if namespace == "Script" {
+		return t.dispatchScript(request, name, p)
+	}
+
+	if namespace == "KVS" {
+		return t.dispatchKVS(request, name, p)
+	}
+
+	componentType := strings.ToLower(namespace)
+
+	var c *component
+
+	for _, v := range t.components {
+		if v.componentType == componentType {
+			c = v
+			break
+		}
+	}
+
+	if c == nil {
+		return nil, nil, noHandler(request.Method)
+	}
+
+	if c.id != nil {
+		if p.ID == nil {
+			return nil, nil, invalidArgument("Missing required argument 'id'!")
+		}
+		c = t.components[componentType+":"+strconv.Itoa(*p.ID)]
+		if c == nil {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeNotFound, Message: fmt.Sprintf("Component not found: %s:%d", componentType, *p.ID)}
+		}
+	}
+
+	switch name {
+
+	case "GetStatus":
+		return copyMap(c.status), nil, nil
+
+	case "GetConfig":
+		return copyMap(c.config), nil, nil
+
+	case "SetConfig":
+		if p.Config == nil {
+			return nil, nil, invalidArgument("Missing required argument 'config'!")
+		}
+		delete(p.Config, "id")
+
+		restartRequired := false
+		if mergeMap(c.config, p.Config) {
+			t.incrementCfgRev()
+			if restartRequiredServices[componentType] {
+				restartRequired = true
+				t.components["sys"].status["restart_required"] = true
+			}
+		}
+
+		return map[string]interface{}{"restart_required": restartRequired}, nil, nil
+
+	}
+
+	if componentType == "switch" || componentType == "light" {
+
+		wasOn, _ := c.status["output"].(bool)
+
+		switch name {
+
+		case "Set":
+			if p.On == nil && p.Brightness == nil {
+				return nil, nil, invalidArgument("Missing required argument 'on'!")
+			}
+
+			changes := map[string]interface{}{"source": sourceRPC}
+
+			if p.On != nil {
+				changes["output"] = *p.On
+			}
+
+			if p.Brightness != nil {
+				if componentType != "light" {
+					return nil, nil, invalidArgument("brightness is not supported")
+				}
+				if *p.Brightness < 0 || *p.Brightness > 100 {
+					return nil, nil, invalidArgument("brightness must be between 0 and 100")
+				}
+				changes["brightness"] = *p.Brightness
+			}
+
+			notification := t.setStatus(request.Src, c, changes)
+
+			if componentType == "switch" {
+				return map[string]interface{}{"was_on": wasOn}, [][]byte{notification}, nil
+			}
+			return nil, [][]byte{notification}, nil
+
+		case "Toggle":
+			notification := t.setStatus(request.Src, c, map[string]interface{}{
+				"source": sourceRPC,
+				"output": !wasOn,
+			})
+
+			if componentType == "switch" {
+				return map[string]interface{}{"was_on": wasOn}, [][]byte{notification}, nil
+			}
+			return nil, [][]byte{notification}, nil
+
+		}
+	}
+
+	if componentType == "cover" {
+		return t.dispatchCover(request, c, name, p)
+	}
+
+	if componentType == "emdata" || componentType == "em1data" {
+		return t.dispatchEMData(request, c, name, p)
+	}
+
+	if componentType == "pm1" {
+		return t.dispatchPM1(request, c, name, p)
+	}
+
+	return nil, nil, noHandler(request.Method)
+}
+
+// dispatchCover calls the Cover method. Movement is simulated as completing immediately;
+// an Open or Close with a duration moves the cover in proportion to maxtime_open or
+// maxtime_close. mutex must be held.
+func (t *Device) dispatchCover(request *rpcRequest, c *component, name string, p *params) (interface{}, [][]byte, *Error) {
+
+	currentPos, _ := c.status["current_pos"].(float64)
+	posControl, _ := c.status["pos_control"].(bool)
+
+	move := func(direction string, maxtimeKey string) (interface{}, [][]byte, *Error) {
+
+		changes := map[string]interface{}{
+			"source":         sourceRPC,
+			"last_direction": direction,
+		}
+
+		pos := 100.0
+		state := "open"
+		if direction == "close" {
+			pos = 0
+			state = "closed"
+		}
+
+		if p.Duration != nil {
+			if *p.Duration <= 0 {
+				return nil, nil, invalidArgument("duration must be greater than 0")
+			}
+			maxtime, _ := c.config[maxtimeKey].(float64)
+			if maxtime > 0 && *p.Duration < maxtime {
+				delta := *p.Duration / maxtime * 100
+				if direction == "close" {
+					delta = -delta
+				}
+				pos = math.Max(0, math.Min(100, currentPos+delta))
+				switch pos {
+				case 100:
+					state = "open"
+				case 0:
+					state = "closed"
+				default:
+					state = "stopped"
+				}
+			}
+		}
+
+		changes["state"] = state
+
+		if posControl {
+			changes["current_pos"] = pos
+		}
+
+		return nil, [][]byte{t.setStatus(request.Src, c, changes)}, nil
+	}
+
+	switch name {
+
+	case "Open":
+		return move("open", "maxtime_open")
+
+	case "Close":
+		return move("close", "maxtime_close")
+
+	case "Stop":
+		return nil, [][]byte{t.setStatus(request.Src, c, map[string]interface{}{
+			"source": sourceRPC,
+			"state":  "stopped",
+		})}, nil
+
+	case "GoToPosition":
+		if p.Pos == nil {
+			return nil, nil, invalidArgument("Missing required argument 'pos'!")
+		}
+		if *p.Pos < 0 || *p.Pos > 100 {
+			return nil, nil, invalidArgument("pos must be between 0 and 100")
+		}
+		if !posControl {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeFailedPrecondition, Message: "Cover is not calibrated!"}
+		}
+
+		pos := float64(*p.Pos)
+
+		changes := map[string]interface{}{
+			"source":      sourceRPC,
+			"state":       "stopped",
+			"current_pos": pos,
+		}
+
+		switch {
+		case pos > currentPos:
+			changes["last_direction"] = "open"
+		case pos < currentPos:
+			changes["last_direction"] = "close"
+		}
+
+		switch pos {
+		case 100:
+			changes["state"] = "open"
+		case 0:
+			changes["state"] = "closed"
+		}
+
+		return nil, [][]byte{t.setStatus(request.Src, c, changes)}, nil
+
+	case "Calibrate":
+		return nil, [][]byte{t.setStatus(request.Src, c, map[string]interface{}{
+			"source":      sourceRPC,
+			"state":       "open",
+			"pos_control": true,
+			"current_pos": 100.0,
+		})}, nil
+
+	case "ResetCounters":
+		aenergy, _ := c.status["aenergy"].(map[string]interface{})
+		total, _ := aenergy["total"].(float64)
+		if aenergy != nil {
+			aenergy["total"] = 0.0
+		}
+		return map[string]interface{}{"aenergy": map[string]interface{}{"total": total}}, nil, nil
+
+	}
+
+	return nil, nil, noHandler(request.Method)
+}
+
+// setStatus merges the changes into the status and returns the NotifyStatus frame. mutex
+// must be held.
+func (t *Device) setStatus(dst *string, c *component, changes map[string]interface{}) []byte {
+	mergeMap(c.status, changes)
+	return t.newNotification(dst, msg_types.Notification{Method: "NotifyStatus"}, map[string]interface{}{c.key(): changes})
+}
+
+// incrementCfgRev increments the config revision. mutex must be held.
+func (t *Device) incrementCfgRev() {
+	sys := t.components["sys"]
+	rev, _ := sys.status["cfg_rev"].(float64)
+	rev++
+	sys.status["cfg_rev"] = rev
+	sys.config["cfg_rev"] = rev
+}
+
+// sortedKeys returns the keys of the components in order. mutex must be held.
+func (t *Device) sortedKeys() []string {
+	var keys []string
+	for key := range t.components {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// dispatchShelly calls the Shelly method. mutex must be held.
+func (t *Device) dispatchShelly(request *rpcRequest, name string, p *params) (interface{}, [][]byte, *Error) {
+
+	switch name {
+
+	case "GetDeviceInfo":
+		var authDomain interface{}
+		if t.ha1 != "" {
+			authDomain = t.config.ID
+		}
+		return map[string]interface{}{
+			"name":        t.components["sys"].config["device"].(map[string]interface{})["name"],
+			"id":          t.config.ID,
+			"mac":         t.config.MAC,
+			"model":       t.config.Model,
+			"gen":         gen,
+			"fw_id":       t.config.FwID,
+			"ver":         t.config.Ver,
+			"app":         t.config.App,
+			"auth_en":     t.ha1 != "",
+			"auth_domain": authDomain,
+		}, nil, nil
+
+	case "GetStatus":
+		result := make(map[string]interface{})
+		for _, key := range t.sortedKeys() {
+			result[key] = copyMap(t.components[key].status)
+		}
+		return result, nil, nil
+
+	case "GetConfig":
+		result := make(map[string]interface{})
+		for _, key := range t.sortedKeys() {
+			result[key] = copyMap(t.components[key].config)
+		}
+		return result, nil, nil
+
+	case "GetComponents":
+		return t.getComponents(p), nil, nil
+
+	case "ListMethods":
+		return map[string]interface{}{"methods": t.listMethods()}, nil, nil
+
+	case "SetAuth":
+		if p.Ha1 == nil {
+			t.ha1 = ""
+			return nil, nil, nil
+		}
+		if p.Realm == nil || *p.Realm != t.config.ID {
+			return nil, nil, invalidArgument("realm must be the device ID")
+		}
+		t.ha1 = *p.Ha1
+		return nil, nil, nil
+
+	case "Reboot":
+		sys := t.components["sys"]
+		sys.status["restart_required"] = false
+		sys.status["uptime"] = 0
+		t.nonce = newNonce()
+		return nil, nil, nil
+
+	case "FactoryReset":
+		t.reset()
+		return nil, nil, nil
+
+	case "ResetWiFiConfig":
+		wifi := t.components["wifi"]
+		if wifi == nil {
+			return nil, nil, noHandler(request.Method)
+		}
+		wifi.config = copyMap(getDefaultConfig(t.config, "wifi", 0))
+		return nil, nil, nil
+
+	case "CheckForUpdate":
+		return map[string]interface{}{}, nil, nil
+
+	case "Update":
+		return nil, nil, nil
+
+	}
+
+	return nil, nil, noHandler(request.Method)
+}
+
+// getComponents returns one page of components starting at the offset. mutex must be held.
+func (t *Device) getComponents(p *params) map[string]interface{} {
+
+	include := func(name string) bool {
+		for _, v := range p.Include {
+			if v == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	var keys []string
+
+	for _, key := range t.sortedKeys() {
+
+		c := t.components[key]
+
+		if p.DynamicOnly != nil && *p.DynamicOnly && (c.id == nil || *c.id < dynamicComponentMinID) {
+			continue
+		}
+
+		if len(p.Keys) > 0 {
+			found := false
+			for _, v := range p.Keys {
+				if v == key {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+
+		keys = append(keys, key)
+	}
+
+	offset := 0
+	if p.Offset != nil && *p.Offset > 0 {
+		offset = *p.Offset
+	}
+
+	components := []interface{}{}
+
+	for i := offset; i < len(keys) && i < offset+componentsPageSize; i++ {
+
+		c := t.components[keys[i]]
+		component := map[string]interface{}{"key": keys[i]}
+
+		if include("status") {
+			component["status"] = copyMap(c.status)
+		}
+
+		if include("config") {
+			component["config"] = copyMap(c.config)
+		}
+
+		components = append(components, component)
+	}
+
+	return map[string]interface{}{
+		"components": components,
+		"cfg_rev":    t.components["sys"].config["cfg_rev"],
+		"offset":     offset,
+		"total":      len(keys),
+	}
+}
+
+// listMethods returns the methods supported by the device. mutex must be held.
+func (t *Device) listMethods() []string {
+
+	methods := []string{
+		"Shelly.GetDeviceInfo",
+		"Shelly.GetStatus",
+		"Shelly.GetConfig",
+		"Shelly.GetComponents",
+		"Shelly.ListMethods",
+		"Shelly.SetAuth",
+		"Shelly.Reboot",
+		"Shelly.FactoryReset",
+		"Shelly.CheckForUpdate",
+		"Shelly.Update",
+	}
+
+	if t.components["wifi"] != nil {
+		methods = append(methods, "Shelly.ResetWiFiConfig")
+	}
+
+	methods = append(methods, "Schedule.List", "Schedule.Create", "Schedule.Update", "Schedule.Delete", "Schedule.DeleteAll")
+	methods = append(methods, "Script.List", "Script.Create", "Script.Delete", "Script.GetStatus", "Script.GetConfig", "Script.SetConfig", "Script.PutCode", "Script.GetCode", "Script.Start", "Script.Stop", "Script.Eval")
+	methods = append(methods, "KVS.Set", "KVS.Get", "KVS.GetMany", "KVS.List", "KVS.Delete")
+	methods = append(methods, "Webhook.ListSupported", "Webhook.List", "Webhook.Create", "Webhook.Update", "Webhook.Delete", "Webhook.DeleteAll")
+
+	namespaces := map[string]string{
+		"sys":         "Sys",
+		"wifi":        "Wifi",
+		"mqtt":        "Mqtt",
+		"cloud":       "Cloud",
+		"ble":         "BLE",
+		"ws":          "Ws",
+		"eth":         "Eth",
+		"switch":      "Switch",
+		"light":       "Light",
+		"input":       "Input",
+		"cover":       "Cover",
+		"em":          "EM",
+		"em1":         "EM1",
+		"pm1":         "PM1",
+		"emdata":      "EMData",
+		"em1data":     "EM1Data",
+		"temperature": "Temperature",
+		"humidity":    "Humidity",
+		"illuminance": "Illuminance",
+		"devicepower": "DevicePower",
+	}
+
+	seen := make(map[string]bool)
+
+	for _, key := range t.sortedKeys() {
+
+		componentType := t.components[key].componentType
+		if seen[componentType] {
+			continue
+		}
+		seen[componentType] = true
+
+		namespace := namespaces[componentType]
+		if namespace == "" {
+			namespace = componentType
+		}
+
+		methods = append(methods, namespace+".GetStatus", namespace+".GetConfig", namespace+".SetConfig")
+
+		if componentType == "switch" || componentType == "light" {
+			methods = append(methods, namespace+".Set", namespace+".Toggle")
+		}
+
+		if componentType == "cover" {
+			methods = append(methods, namespace+".Open", namespace+".Close", namespace+".Stop", namespace+".GoToPosition", namespace+".Calibrate", namespace+".ResetCounters")
+		}
+
+		if componentType == "emdata" || componentType == "em1data" {
+			methods = append(methods, namespace+".GetRecords", namespace+".GetData", namespace+".DeleteAllData", namespace+".ResetCounters")
+		}
+
+		if componentType == "pm1" {
+			methods = append(methods, namespace+".ResetCounters")
+		}
+	}
+
+	return methods
+}
+
+func invalidArgument(message string) *Error {
+	return &Error{Code: msg_types.ErrorCodeInvalidArgument, Message: message}
+}
+
+func noHandler(method string) *Error {
+	return &Error{Code: msg_types.ErrorCodeNotImplemented, Message: "No handler for " + method}
+}
+
+func getTs() float64 {
+	return float64(time.Now().UnixMilli()) / 1000
+}
+
+func newNonce() int {
+	n, err := rand.Int(rand.Reader, big.NewInt(1<<31-1))
+	if err != nil {
+		return int(time.Now().Unix())
+	}
+	return int(n.Int64())
+}
+
+func getSHA256(text string) string {
+	h := sha256.New()
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// copyMap returns a deep copy of m. Numbers are converted to float64 as they would be
+// by a JSON round trip.
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{})
+	b, err := json.Marshal(m)
+	if err != nil {
+		return c
+	}
+	json.Unmarshal(b, &c)
+	return c
+}
+
+// mergeMap merges src into dst. Nested maps are merged. Returns true if dst was changed.
+func mergeMap(dst, src map[string]interface{}) bool {
+
+	changed := false
+
+	for k, v := range src {
+
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+
+		if srcIsMap && dstIsMap {
+			if mergeMap(dstMap, srcMap) {
+				changed = true
+			}
+			continue
+		}
+
+		if existing, ok := dst[k]; ok && reflect.DeepEqual(existing, v) {
+			continue
+		}
+
+		dst[k] = v
+		changed = true
+	}
+
+	return changed
+}
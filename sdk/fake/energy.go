@@ -0,0 +1,169 @@
+package fake
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// emDataKeys returns the keys of the values of each record of the EMData or EM1Data
+// component
+func emDataKeys(componentType string) []string {
+
+	values := []string{"total_act_energy", "total_act_ret_energy", "avg_voltage", "avg_current"}
+
+	if componentType == "em1data" {
+		return values
+	}
+
+	var keys []string
+	for _, phase := range []string{"a", "b", "c"} {
+		for _, v := range values {
+			keys = append(keys, phase+"_"+v)
+		}
+	}
+
+	return keys
+}
+
+// emDataValue returns the value of the key for the record at ts. The values are derived
+// from ts so that the same record always has the same values.
+func emDataValue(key string, ts int64) float64 {
+
+	step := float64(ts / emDataPeriod % 10)
+	voltage := 230 + step/10
+	current := 1 + step/10
+
+	switch {
+
+	case strings.HasSuffix(key, "total_act_energy"):
+		return math.Round(voltage*current*emDataPeriod/3600*1000) / 1000
+
+	case strings.HasSuffix(key, "avg_voltage"):
+		return voltage
+
+	case strings.HasSuffix(key, "avg_current"):
+		return current
+
+	}
+
+	return 0
+}
+
+// emDataNow returns the start of the current period. The record of the current period is
+// not yet stored.
+func emDataNow() int64 {
+	now := time.Now().Unix()
+	return now - now%emDataPeriod
+}
+
+// dispatchEMData calls the EMData or EM1Data method. The device has stored a record for each
+// period since emDataHistory before it was reset or since its data was deleted. mutex must
+// be held.
+func (t *Device) dispatchEMData(request *rpcRequest, c *component, name string, p *params) (interface{}, [][]byte, *Error) {
+
+	now := emDataNow()
+	start := t.emDataStart[c.key()]
+
+	// first returns the timestamp of the first stored record at or after ts
+	first := func(ts *int64) int64 {
+		if ts == nil || *ts <= start {
+			return start
+		}
+		return (*ts + emDataPeriod - 1) / emDataPeriod * emDataPeriod
+	}
+
+	switch name {
+
+	case "GetRecords":
+		blocks := []interface{}{}
+		if from := first(p.Ts); from < now {
+			blocks = append(blocks, map[string]interface{}{
+				"ts":      from,
+				"period":  emDataPeriod,
+				"records": (now - from) / emDataPeriod,
+			})
+		}
+		return map[string]interface{}{"data_blocks": blocks}, nil, nil
+
+	case "GetData":
+		if p.Ts == nil {
+			return nil, nil, invalidArgument("Missing required argument 'ts'!")
+		}
+
+		end := now
+		if p.EndTs != nil && *p.EndTs < end {
+			end = *p.EndTs + 1
+		}
+
+		keys := emDataKeys(c.componentType)
+
+		from := first(p.Ts)
+		ts := from
+		values := []interface{}{}
+
+		for ; ts < end && len(values) < emDataPageSize; ts += emDataPeriod {
+			record := make([]float64, 0, len(keys))
+			for _, key := range keys {
+				record = append(record, emDataValue(key, ts))
+			}
+			values = append(values, record)
+		}
+
+		result := map[string]interface{}{"keys": keys, "data": []interface{}{}}
+
+		if len(values) > 0 {
+			result["data"] = []interface{}{
+				map[string]interface{}{"ts": from, "period": emDataPeriod, "values": values},
+			}
+		}
+
+		if ts < end {
+			result["next_record_ts"] = ts
+		}
+
+		return result, nil, nil
+
+	case "DeleteAllData":
+		t.emDataStart[c.key()] = now
+		return nil, nil, nil
+
+	case "ResetCounters":
+		for key, value := range c.status {
+			if _, ok := value.(float64); ok && key != "id" {
+				c.status[key] = 0.0
+			}
+		}
+		return nil, nil, nil
+
+	}
+
+	return nil, nil, noHandler(request.Method)
+}
+
+// dispatchPM1 calls the PM1 method. mutex must be held.
+func (t *Device) dispatchPM1(request *rpcRequest, c *component, name string, p *params) (interface{}, [][]byte, *Error) {
+
+	switch name {
+
+	case "ResetCounters":
+		counters := p.Type
+		if len(counters) == 0 {
+			counters = []string{"aenergy", "ret_aenergy"}
+		}
+
+		changes := map[string]interface{}{}
+		for _, counter := range counters {
+			if _, ok := c.status[counter]; !ok {
+				return nil, nil, invalidArgument("Invalid counter type " + counter + "!")
+			}
+			changes[counter] = map[string]interface{}{"total": 0.0}
+		}
+
+		notification := t.setStatus(request.Src, c, changes)
+		return nil, [][]byte{notification}, nil
+
+	}
+
+	return nil, nil, noHandler(request.Method)
+}
@@ -0,0 +1,167 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/credentials"
+	"github.com/jodydadescott/shelly-client/sdk/fake/types"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/notification"
+)
+
+type Config = client_types.Config
+type DeviceConfig = types.DeviceConfig
+type Fault = types.Fault
+
+type MessageHandlerFactory = msg_types.MessageHandlerFactory
+type MessageHandler = msg_types.MessageHandler
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+type AuthResponse = msg_types.AuthResponse
+type AuthRequest = msg_types.AuthRequest
+type CredentialProvider = msg_types.CredentialProvider
+type Notification = notification.Notification
+type NotificationFilter = notification.Filter
+
+// Client is a MessageHandlerFactory backed by a simulated Device. No network is used.
+// It can be used with client.NewWithMessageHandlerFactory to test code that uses
+// client.Client without a device.
+type Client struct {
+	config            *Config
+	device            *Device
+	authResponseMutex sync.RWMutex
+	idMutex           sync.Mutex
+	uniqID            int
+	subscribers       *notification.Subscribers
+	removeHandler     func()
+	src               string
+	closeOnce         sync.Once
+	authResponse      *AuthResponse
+	credentials       CredentialProvider
+}
+
+// New returns a new MessageHandlerFactory for the device. The credential providers,
+// Username and Password of the config are used if the device requires authentication.
+// The config may be nil.
+func New(config *Config, device *Device) *Client {
+
+	zap.L().Debug("New")
+
+	if config == nil {
+		config = &Config{}
+	} else {
+		config = config.Clone()
+	}
+
+	if config.Username == "" {
+		config.Username = defaultShellyUser
+	}
+
+	t := &Client{
+		config:      config,
+		device:      device,
+		subscribers: notification.NewSubscribers(),
+		src:         "shelly-client-fake",
+		credentials: credentials.NewClientProvider(config),
+	}
+
+	t.removeHandler = device.AddNotificationHandler(t.subscribers.Publish)
+
+	return t
+}
+
+// Device returns the simulated device
+func (t *Client) Device() *Device {
+	return t.device
+}
+
+// Subscribe returns a channel of notifications sent by the device that match the filter
+func (t *Client) Subscribe(ctx context.Context, filter *NotificationFilter) <-chan *Notification {
+	return t.subscribers.Subscribe(ctx, filter)
+}
+
+func (t *Client) IsAuthEnabled() bool {
+	return t.getAuthResponse() != nil
+}
+
+func (t *Client) getAuthResponse() *AuthResponse {
+	t.authResponseMutex.RLock()
+	defer t.authResponseMutex.RUnlock()
+	return t.authResponse
+}
+
+func (t *Client) setAuthResponse(authResponse *AuthResponse) {
+	t.authResponseMutex.Lock()
+	defer t.authResponseMutex.Unlock()
+	t.authResponse = authResponse
+}
+
+func (t *Client) nextID() int {
+	t.idMutex.Lock()
+	defer t.idMutex.Unlock()
+	t.uniqID = t.uniqID + 1
+	return t.uniqID
+}
+
+func (t *Client) Close() {
+	zap.L().Debug("(*Client) Close()")
+	t.closeOnce.Do(func() {
+		t.removeHandler()
+		t.subscribers.Close()
+	})
+}
+
+func (t *Client) NewHandle(name string) MessageHandler {
+	zap.L().Debug(fmt.Sprintf("(*Client) NewHandle(%s)", name))
+	return &Handle{
+		client: t,
+		name:   name,
+	}
+}
+
+type Handle struct {
+	client *Client
+	name   string
+}
+
+func (t *Handle) send(ctx context.Context, request *Request) (*Response, []byte, error) {
+
+	id := t.client.nextID()
+	request.ID = &id
+
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b, err := t.client.device.Call(ctx, requestBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response := &Response{}
+	err = json.Unmarshal(b, response)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return response, b, nil
+}
+
+func (t *Handle) Send(ctx context.Context, request *Request) ([]byte, error) {
+
+	zap.L().Debug("(*Handle) Send(ctx, *Request)")
+
+	request = request.Clone()
+	request.Src = &t.client.src
+	request.Auth = t.client.getAuthResponse()
+
+	return msg_types.RetryWithAuth(ctx, request, t.client.credentials, t.client.config.Hostname, t.client.setAuthResponse, t.send)
+}
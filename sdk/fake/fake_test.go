@@ -0,0 +1,305 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
+)
+
+type params struct {
+	ID     *int                   `json:"id,omitempty"`
+	On     *bool                  `json:"on,omitempty"`
+	Config map[string]interface{} `json:"config,omitempty"`
+}
+
+type result = map[string]interface{}
+
+func call(ctx context.Context, client *fake.Client, method string, p *params) (result, error) {
+	return rpc.Call[*params, result](ctx, client.NewHandle("Test"), method, p)
+}
+
+func newClient(t *testing.T, deviceConfig *fake.DeviceConfig, config *fake.Config) *fake.Client {
+	client := fake.New(config, fake.NewDevice(deviceConfig))
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestConfig(t *testing.T) {
+
+	id := 0
+
+	tests := []struct {
+		name            string
+		namespace       string
+		id              *int
+		config          map[string]interface{}
+		restartRequired bool
+	}{
+		{
+			name:      "switch",
+			namespace: "Switch",
+			id:        &id,
+			config:    map[string]interface{}{"name": "kitchen", "initial_state": "on"},
+		},
+		{
+			name:      "sys",
+			namespace: "Sys",
+			config:    map[string]interface{}{"device": map[string]interface{}{"name": "porch"}},
+		},
+		{
+			name:            "mqtt",
+			namespace:       "Mqtt",
+			config:          map[string]interface{}{"enable": true, "server": "broker:1883"},
+			restartRequired: true,
+		},
+		{
+			name:            "cloud",
+			namespace:       "Cloud",
+			config:          map[string]interface{}{"enable": true},
+			restartRequired: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctx := context.Background()
+			client := newClient(t, &fake.DeviceConfig{Switch: 1}, nil)
+
+			r, err := call(ctx, client, tt.namespace+".SetConfig", &params{ID: tt.id, Config: tt.config})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if r["restart_required"] != tt.restartRequired {
+				t.Errorf("restart_required %v, want %t", r["restart_required"], tt.restartRequired)
+			}
+
+			config, err := call(ctx, client, tt.namespace+".GetConfig", &params{ID: tt.id})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for key, want := range tt.config {
+				if got := config[key]; !contains(got, want) {
+					t.Errorf("config %s %v, want %v", key, got, want)
+				}
+			}
+
+			status, err := call(ctx, client, "Sys.GetStatus", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if status["restart_required"] != tt.restartRequired {
+				t.Errorf("sys restart_required %v, want %t", status["restart_required"], tt.restartRequired)
+			}
+
+			// The same config again is not a change
+			r, err = call(ctx, client, tt.namespace+".SetConfig", &params{ID: tt.id, Config: tt.config})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if r["restart_required"] != false {
+				t.Errorf("restart_required %v for an unchanged config, want false", r["restart_required"])
+			}
+		})
+	}
+}
+
+// contains returns true if got has the values of want. Maps are compared by the keys of
+// want only as the device merges the config.
+func contains(got, want interface{}) bool {
+
+	wantMap, ok := want.(map[string]interface{})
+	if !ok {
+		return reflect.DeepEqual(got, want)
+	}
+
+	gotMap, ok := got.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	for key, value := range wantMap {
+		if !contains(gotMap[key], value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestAuth(t *testing.T) {
+
+	id := 0
+
+	tests := []struct {
+		name     string
+		password string
+		method   string
+		wantErr  error
+		calls    []string
+	}{
+		{
+			name:     "valid password",
+			password: "secret",
+			method:   "Switch.GetStatus",
+			calls:    []string{"Switch.GetStatus", "Switch.GetStatus"},
+		},
+		{
+			name:     "wrong password",
+			password: "wrong",
+			method:   "Switch.GetStatus",
+			wantErr:  msg_types.ErrUnauthorized,
+			calls:    []string{"Switch.GetStatus", "Switch.GetStatus"},
+		},
+		{
+			name:    "no password",
+			method:  "Switch.GetStatus",
+			wantErr: msg_types.ErrUnauthorized,
+			calls:   []string{"Switch.GetStatus"},
+		},
+		{
+			name:   "device info does not require auth",
+			method: "Shelly.GetDeviceInfo",
+			calls:  []string{"Shelly.GetDeviceInfo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			client := newClient(t, &fake.DeviceConfig{Switch: 1, Password: "secret"}, &fake.Config{Password: tt.password})
+
+			_, err := call(context.Background(), client, tt.method, &params{ID: &id})
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("error %v, want nil", err)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error %v, want %v", err, tt.wantErr)
+			}
+
+			if calls := client.Device().Calls(); !reflect.DeepEqual(calls, tt.calls) {
+				t.Errorf("calls %v, want %v", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestFaults(t *testing.T) {
+
+	errInjected := errors.New("injected")
+
+	tests := []struct {
+		name    string
+		fault   *fake.Fault
+		method  string
+		timeout time.Duration
+		// wantErrs the error expected for each call in order. nil means success.
+		wantErrs []error
+		minTime  time.Duration
+	}{
+		{
+			name:     "code",
+			fault:    &fake.Fault{Method: "Switch.Set", Code: msg_types.ErrorCodeFailedPrecondition, Message: "overpower"},
+			method:   "Switch.Set",
+			wantErrs: []error{msg_types.ErrFailedPrecondition, msg_types.ErrFailedPrecondition},
+		},
+		{
+			name:     "other method",
+			fault:    &fake.Fault{Method: "Switch.Set", Code: msg_types.ErrorCodeFailedPrecondition},
+			method:   "Switch.GetStatus",
+			wantErrs: []error{nil},
+		},
+		{
+			name:     "err",
+			fault:    &fake.Fault{Err: errInjected},
+			method:   "Switch.GetStatus",
+			wantErrs: []error{errInjected},
+		},
+		{
+			name:     "count",
+			fault:    &fake.Fault{Code: msg_types.ErrorCodeUnAvailable, Count: 2},
+			method:   "Switch.GetStatus",
+			wantErrs: []error{msg_types.ErrUnavailable, msg_types.ErrUnavailable, nil},
+		},
+		{
+			name:     "delay",
+			fault:    &fake.Fault{Delay: time.Millisecond * 50},
+			method:   "Switch.GetStatus",
+			wantErrs: []error{nil},
+			minTime:  time.Millisecond * 50,
+		},
+		{
+			name:     "delay past deadline",
+			fault:    &fake.Fault{Delay: time.Second},
+			method:   "Switch.GetStatus",
+			timeout:  time.Millisecond * 20,
+			wantErrs: []error{context.DeadlineExceeded},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			client := newClient(t, &fake.DeviceConfig{Switch: 1}, nil)
+			client.Device().InjectFault(tt.fault)
+
+			id := 0
+			on := true
+
+			for i, wantErr := range tt.wantErrs {
+
+				ctx := context.Background()
+				if tt.timeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+					defer cancel()
+				}
+
+				start := time.Now()
+				_, err := call(ctx, client, tt.method, &params{ID: &id, On: &on})
+
+				if wantErr == nil {
+					if err != nil {
+						t.Fatalf("call %d error %v, want nil", i, err)
+					}
+				} else if !errors.Is(err, wantErr) {
+					t.Fatalf("call %d error %v, want %v", i, err, wantErr)
+				}
+
+				if elapsed := time.Since(start); elapsed < tt.minTime {
+					t.Errorf("call %d took %v, want at least %v", i, elapsed, tt.minTime)
+				}
+			}
+		})
+	}
+}
+
+func TestFaultError(t *testing.T) {
+
+	client := newClient(t, &fake.DeviceConfig{Switch: 1}, nil)
+	client.Device().InjectFault(&fake.Fault{Code: msg_types.ErrorCodeFailedPrecondition, Message: "overpower"})
+
+	id := 0
+	_, err := call(context.Background(), client, "Switch.Set", &params{ID: &id})
+
+	rpcErr := &msg_types.Error{}
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("error %v is not an Error", err)
+	}
+
+	if rpcErr.Method != "Switch.Set" || rpcErr.DeviceID != client.Device().ID() || rpcErr.Message != "overpower" {
+		t.Errorf("error %+v, want method Switch.Set, device ID %s and message overpower", rpcErr, client.Device().ID())
+	}
+}
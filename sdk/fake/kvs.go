@@ -0,0 +1,141 @@
+package fake
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+// kvsItem is a value of the KVS
+type kvsItem struct {
+	etag  string
+	value json.RawMessage
+}
+
+// incrementKvsRev increments the KVS revision and returns it. mutex must be held.
+func (t *Device) incrementKvsRev() float64 {
+	sys := t.components["sys"]
+	rev, _ := sys.status["kvs_rev"].(float64)
+	rev++
+	sys.status["kvs_rev"] = rev
+	return rev
+}
+
+// kvsKeys returns the keys that match the pattern in order. An empty pattern matches all
+// keys. mutex must be held.
+func (t *Device) kvsKeys(match *string) []string {
+	var keys []string
+	for key := range t.kvs {
+		if match != nil && *match != "" {
+			if ok, _ := path.Match(*match, key); !ok {
+				continue
+			}
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// dispatchKVS calls the KVS method. mutex must be held.
+func (t *Device) dispatchKVS(request *rpcRequest, name string, p *params) (interface{}, [][]byte, *Error) {
+
+	switch name {
+
+	case "List":
+		keys := map[string]interface{}{}
+		for _, key := range t.kvsKeys(p.Match) {
+			keys[key] = map[string]interface{}{"etag": t.kvs[key].etag}
+		}
+		rev, _ := t.components["sys"].status["kvs_rev"].(float64)
+		return map[string]interface{}{"keys": keys, "rev": rev}, nil, nil
+
+	case "GetMany":
+		keys := t.kvsKeys(p.Match)
+
+		offset := 0
+		if p.Offset != nil {
+			offset = *p.Offset
+		}
+		if offset < 0 || offset > len(keys) {
+			return nil, nil, invalidArgument("Invalid offset!")
+		}
+
+		end := offset + kvsPageSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		items := []interface{}{}
+		for _, key := range keys[offset:end] {
+			items = append(items, map[string]interface{}{
+				"key":   key,
+				"etag":  t.kvs[key].etag,
+				"value": t.kvs[key].value,
+			})
+		}
+
+		return map[string]interface{}{"items": items, "offset": offset, "total": len(keys)}, nil, nil
+
+	}
+
+	if p.Key == nil {
+		return nil, nil, invalidArgument("Missing required argument 'key'!")
+	}
+
+	key := *p.Key
+	item := t.kvs[key]
+
+	if (name == "Set" || name == "Delete") && p.Etag != nil && (item == nil || item.etag != *p.Etag) {
+		return nil, nil, &Error{Code: msg_types.ErrorCodeFailedPrecondition, Message: "Etag mismatch!"}
+	}
+
+	switch name {
+
+	case "Get":
+		if item == nil {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeNotFound, Message: fmt.Sprintf("Key %s not found!", key)}
+		}
+		return map[string]interface{}{"etag": item.etag, "value": item.value}, nil, nil
+
+	case "Set":
+		if len(p.Value) == 0 {
+			return nil, nil, invalidArgument("Missing required argument 'value'!")
+		}
+		if len(key) > maxKVSKeyLength {
+			return nil, nil, invalidArgument("Key is too long!")
+		}
+		if len(p.Value) > maxKVSValueLength {
+			return nil, nil, invalidArgument("Value is too long!")
+		}
+		if item == nil && len(t.kvs) >= maxKVSKeys {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeResourceExhausted, Message: "Too many keys!"}
+		}
+
+		rev := t.incrementKvsRev()
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%v", key, p.Value, rev)))
+
+		item = &kvsItem{
+			etag:  base64.RawURLEncoding.EncodeToString(sum[:21]),
+			value: append(json.RawMessage{}, p.Value...),
+		}
+
+		t.kvs[key] = item
+		return map[string]interface{}{"etag": item.etag, "rev": rev}, nil, nil
+
+	case "Delete":
+		if item == nil {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeNotFound, Message: fmt.Sprintf("Key %s not found!", key)}
+		}
+		delete(t.kvs, key)
+		return map[string]interface{}{"rev": t.incrementKvsRev()}, nil, nil
+
+	}
+
+	return nil, nil, noHandler(request.Method)
+}
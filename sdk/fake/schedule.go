@@ -0,0 +1,146 @@
+package fake
+
+import (
+	"fmt"
+	"strings"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+// job is a schedule job. The jobs are not run.
+type job struct {
+	id       int
+	enable   bool
+	timespec string
+	calls    []interface{}
+}
+
+func (t *job) toMap() map[string]interface{} {
+	return copyMap(map[string]interface{}{
+		"id":       t.id,
+		"enable":   t.enable,
+		"timespec": t.timespec,
+		"calls":    t.calls,
+	})
+}
+
+// incrementScheduleRev increments the schedule revision and returns it. mutex must be held.
+func (t *Device) incrementScheduleRev() float64 {
+	sys := t.components["sys"]
+	rev, _ := sys.status["schedule_rev"].(float64)
+	rev++
+	sys.status["schedule_rev"] = rev
+	return rev
+}
+
+// getSchedule returns the job with the ID or nil. mutex must be held.
+func (t *Device) getSchedule(id int) (int, *job) {
+	for i, v := range t.schedules {
+		if v.id == id {
+			return i, v
+		}
+	}
+	return -1, nil
+}
+
+// dispatchSchedule calls the Schedule method. mutex must be held.
+func (t *Device) dispatchSchedule(request *rpcRequest, name string, p *params) (interface{}, [][]byte, *Error) {
+
+	switch name {
+
+	case "List":
+		jobs := []interface{}{}
+		for _, v := range t.schedules {
+			jobs = append(jobs, v.toMap())
+		}
+		rev, _ := t.components["sys"].status["schedule_rev"].(float64)
+		return map[string]interface{}{"jobs": jobs, "rev": rev}, nil, nil
+
+	case "Create":
+		if p.Timespec == nil {
+			return nil, nil, invalidArgument("Missing required argument 'timespec'!")
+		}
+		if err := validateTimespec(*p.Timespec); err != nil {
+			return nil, nil, err
+		}
+		if len(t.schedules) >= maxSchedules {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeResourceExhausted, Message: "Too many jobs!"}
+		}
+
+		t.scheduleID++
+
+		j := &job{
+			id:       t.scheduleID,
+			enable:   true,
+			timespec: *p.Timespec,
+			calls:    p.Calls,
+		}
+
+		if p.Enable != nil {
+			j.enable = *p.Enable
+		}
+
+		t.schedules = append(t.schedules, j)
+		return map[string]interface{}{"id": j.id, "rev": t.incrementScheduleRev()}, nil, nil
+
+	case "Update":
+		if p.ID == nil {
+			return nil, nil, invalidArgument("Missing required argument 'id'!")
+		}
+		_, j := t.getSchedule(*p.ID)
+		if j == nil {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeNotFound, Message: fmt.Sprintf("Job %d not found!", *p.ID)}
+		}
+		if p.Timespec != nil {
+			if err := validateTimespec(*p.Timespec); err != nil {
+				return nil, nil, err
+			}
+			j.timespec = *p.Timespec
+		}
+		if p.Enable != nil {
+			j.enable = *p.Enable
+		}
+		if p.Calls != nil {
+			j.calls = p.Calls
+		}
+		return map[string]interface{}{"rev": t.incrementScheduleRev()}, nil, nil
+
+	case "Delete":
+		if p.ID == nil {
+			return nil, nil, invalidArgument("Missing required argument 'id'!")
+		}
+		i, j := t.getSchedule(*p.ID)
+		if j == nil {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeNotFound, Message: fmt.Sprintf("Job %d not found!", *p.ID)}
+		}
+		t.schedules = append(t.schedules[:i], t.schedules[i+1:]...)
+		return map[string]interface{}{"rev": t.incrementScheduleRev()}, nil, nil
+
+	case "DeleteAll":
+		t.schedules = nil
+		return map[string]interface{}{"rev": t.incrementScheduleRev()}, nil, nil
+
+	}
+
+	return nil, nil, noHandler(request.Method)
+}
+
+// validateTimespec returns an error if the timespec does not have the number of fields
+// expected
+func validateTimespec(timespec string) *Error {
+
+	fields := strings.Fields(timespec)
+
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+		if len(fields) != 4 {
+			return invalidArgument("Invalid timespec!")
+		}
+		return nil
+	}
+
+	if len(fields) != 6 {
+		return invalidArgument("Invalid timespec!")
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+// scriptComponents returns the script components in order of ID. mutex must be held.
+func (t *Device) scriptComponents() []*component {
+	var scripts []*component
+	for _, key := range t.sortedKeys() {
+		if c := t.components[key]; c.componentType == "script" && c.id != nil {
+			scripts = append(scripts, c)
+		}
+	}
+	return scripts
+}
+
+// log queues a debug log entry. The entries are sent to the log handlers after the call
+// has been processed. mutex must be held.
+func (t *Device) log(format string, a ...any) {
+
+	b, err := json.Marshal(map[string]interface{}{
+		"ts":    getTs(),
+		"level": 2,
+		"data":  fmt.Sprintf(format, a...) + "\n",
+	})
+
+	if err != nil {
+		return
+	}
+
+	t.pendingLogs = append(t.pendingLogs, b)
+}
+
+// dispatchScript calls the Script method. The code of the scripts is stored but not
+// executed. mutex must be held.
+func (t *Device) dispatchScript(request *rpcRequest, name string, p *params) (interface{}, [][]byte, *Error) {
+
+	switch name {
+
+	case "List":
+		scripts := []interface{}{}
+		for _, c := range t.scriptComponents() {
+			scripts = append(scripts, map[string]interface{}{
+				"id":      *c.id,
+				"name":    c.config["name"],
+				"enable":  c.config["enable"],
+				"running": c.status["running"],
+			})
+		}
+		return map[string]interface{}{"scripts": scripts}, nil, nil
+
+	case "Create":
+		scripts := t.scriptComponents()
+		if len(scripts) >= maxScripts {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeResourceExhausted, Message: "Too many scripts!"}
+		}
+
+		id := 1
+		for _, c := range scripts {
+			if *c.id >= id {
+				id = *c.id + 1
+			}
+		}
+
+		c := &component{
+			componentType: "script",
+			id:            &id,
+			config:        copyMap(getDefaultConfig(t.config, "script", id)),
+			status:        copyMap(getDefaultStatus(t.config, "script", id)),
+		}
+
+		c.config["id"] = id
+		c.status["id"] = id
+
+		if p.Name != nil {
+			c.config["name"] = *p.Name
+		}
+
+		t.components[c.key()] = c
+		t.incrementCfgRev()
+		return map[string]interface{}{"id": id}, nil, nil
+	}
+
+	if p.ID == nil {
+		return nil, nil, invalidArgument("Missing required argument 'id'!")
+	}
+
+	c := t.components["script:"+strconv.Itoa(*p.ID)]
+	if c == nil {
+		return nil, nil, &Error{Code: msg_types.ErrorCodeNotFound, Message: fmt.Sprintf("Script %d not found!", *p.ID)}
+	}
+
+	running, _ := c.status["running"].(bool)
+
+	switch name {
+
+	case "Delete":
+		if running {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeFailedPrecondition, Message: "Script is running!"}
+		}
+		delete(t.components, c.key())
+		delete(t.scriptCode, *c.id)
+		t.incrementCfgRev()
+		return nil, nil, nil
+
+	case "GetStatus":
+		return copyMap(c.status), nil, nil
+
+	case "GetConfig":
+		return copyMap(c.config), nil, nil
+
+	case "SetConfig":
+		if p.Config == nil {
+			return nil, nil, invalidArgument("Missing required argument 'config'!")
+		}
+		delete(p.Config, "id")
+		if mergeMap(c.config, p.Config) {
+			t.incrementCfgRev()
+		}
+		return map[string]interface{}{"restart_required": false}, nil, nil
+
+	case "PutCode":
+		if p.Code == nil {
+			return nil, nil, invalidArgument("Missing required argument 'code'!")
+		}
+		if running {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeFailedPrecondition, Message: "Script is running!"}
+		}
+		code := *p.Code
+		if p.Append != nil && *p.Append {
+			code = t.scriptCode[*c.id] + code
+		}
+		t.scriptCode[*c.id] = code
+		return map[string]interface{}{"len": len(code)}, nil, nil
+
+	case "GetCode":
+		code := t.scriptCode[*c.id]
+
+		offset := 0
+		if p.Offset != nil {
+			offset = *p.Offset
+		}
+		if offset < 0 || offset > len(code) {
+			return nil, nil, invalidArgument("Invalid offset!")
+		}
+
+		size := scriptCodeChunkSize
+		if p.Len != nil && *p.Len < size {
+			size = *p.Len
+		}
+
+		end := offset + size
+		if end > len(code) {
+			end = len(code)
+		}
+
+		return map[string]interface{}{"data": code[offset:end], "left": len(code) - end}, nil, nil
+
+	case "Start":
+		if !running {
+			t.log("script:%d started", *c.id)
+		}
+		frame := t.setStatus(request.Src, c, map[string]interface{}{"running": true})
+		return map[string]interface{}{"was_running": running}, [][]byte{frame}, nil
+
+	case "Stop":
+		if running {
+			t.log("script:%d stopped", *c.id)
+		}
+		frame := t.setStatus(request.Src, c, map[string]interface{}{"running": false})
+		return map[string]interface{}{"was_running": running}, [][]byte{frame}, nil
+
+	case "Eval":
+		if p.Code == nil {
+			return nil, nil, invalidArgument("Missing required argument 'code'!")
+		}
+		if !running {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeFailedPrecondition, Message: "Script is not running!"}
+		}
+		t.log("script:%d eval %s", *c.id, strings.TrimSpace(*p.Code))
+		return map[string]interface{}{"result": "undefined"}, nil, nil
+
+	}
+
+	return nil, nil, noHandler(request.Method)
+}
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"time"
+
+	"github.com/jinzhu/copier"
+)
+
+// DeviceConfig describes the simulated device. Unset attributes have defaults.
+type DeviceConfig struct {
+	// ID the device ID. This is also the auth realm
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+	// MAC the MAC address
+	MAC string `json:"mac,omitempty" yaml:"mac,omitempty"`
+	// Model the model such as SNSW-001P16EU
+	Model string `json:"model,omitempty" yaml:"model,omitempty"`
+	// App the application name such as Plus1PM
+	App string `json:"app,omitempty" yaml:"app,omitempty"`
+	// Ver the firmware version
+	Ver string `json:"ver,omitempty" yaml:"ver,omitempty"`
+	// FwID the firmware ID
+	FwID string `json:"fw_id,omitempty" yaml:"fw_id,omitempty"`
+	// Switch the number of Switch components
+	Switch int `json:"switch,omitempty" yaml:"switch,omitempty"`
+	// Light the number of Light components
+	Light int `json:"light,omitempty" yaml:"light,omitempty"`
+	// Input the number of Input components
+	Input int `json:"input,omitempty" yaml:"input,omitempty"`
+	// Cover the number of Cover components. Devices such as the Plus2PM have Cover
+	// components when running in the cover profile.
+	Cover int `json:"cover,omitempty" yaml:"cover,omitempty"`
+	// Components additional components by key such as em1:0, temperature:100 or
+	// boolean:200. The config and status of these default to the ID and can be set with
+	// Config and Status.
+	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
+	// Services the single instance components such as sys, wifi, mqtt, cloud, ble, ws
+	// and eth. Defaults to all of these except eth. sys is always present.
+	Services []string `json:"services,omitempty" yaml:"services,omitempty"`
+	// Password if set the device requires digest authentication for all methods
+	// except Shelly.GetDeviceInfo
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+	// Config initial config of components by key such as switch:0. Merged with the defaults.
+	Config map[string]map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
+	// Status initial status of components by key such as switch:0. Merged with the defaults.
+	Status map[string]map[string]interface{} `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+// Clone return copy
+func (t *DeviceConfig) Clone() *DeviceConfig {
+	c := &DeviceConfig{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Fault is an error injected into the device. A Fault matches a request by method.
+type Fault struct {
+	// Method the method to match such as Switch.Set. All methods match if empty
+	Method string `json:"method,omitempty" yaml:"method,omitempty"`
+	// Code the RPC error code returned such as -109
+	Code int `json:"code,omitempty" yaml:"code,omitempty"`
+	// Message the RPC error message returned
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// Err if set the request fails with this error instead of an RPC error. This
+	// simulates a transport failure.
+	Err error `json:"-" yaml:"-"`
+	// Delay the time to wait before responding. The request fails if the context is
+	// cancelled first. If Code and Err are not set the request is processed normally
+	// after the delay.
+	Delay time.Duration `json:"delay,omitempty" yaml:"delay,omitempty"`
+	// Count the number of requests the fault applies to. Zero means all requests until
+	// the faults are cleared.
+	Count int `json:"count,omitempty" yaml:"count,omitempty"`
+}
+
+// Clone return copy
+func (t *Fault) Clone() *Fault {
+	c := &Fault{}
+	copier.Copy(&c, &t)
+	return c
+}
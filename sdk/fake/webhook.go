@@ -0,0 +1,145 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+// webhookEvents the events supported by each component type
+var webhookEvents = map[string][]string{
+	"input":  {"input.button_push", "input.button_longpush", "input.button_doublepush", "input.toggle_on", "input.toggle_off"},
+	"switch": {"switch.on", "switch.off"},
+	"light":  {"light.on", "light.off"},
+	"cover":  {"cover.open", "cover.closed", "cover.stopped"},
+}
+
+// incrementWebhookRev increments the webhook revision and returns it. mutex must be held.
+func (t *Device) incrementWebhookRev() float64 {
+	sys := t.components["sys"]
+	rev, _ := sys.status["webhook_rev"].(float64)
+	rev++
+	sys.status["webhook_rev"] = rev
+	return rev
+}
+
+// getWebhook returns the index and the webhook with the ID or nil. mutex must be held.
+func (t *Device) getWebhook(id int) (int, map[string]interface{}) {
+	for i, v := range t.webhooks {
+		if tmp, _ := v["id"].(float64); int(tmp) == id {
+			return i, v
+		}
+	}
+	return -1, nil
+}
+
+// supportedWebhookEvents returns the events supported by the components of the device.
+// mutex must be held.
+func (t *Device) supportedWebhookEvents() map[string]bool {
+	events := make(map[string]bool)
+	for _, c := range t.components {
+		for _, event := range webhookEvents[c.componentType] {
+			events[event] = true
+		}
+	}
+	return events
+}
+
+// dispatchWebhook calls the Webhook method. mutex must be held.
+func (t *Device) dispatchWebhook(request *rpcRequest, name string, p *params) (interface{}, [][]byte, *Error) {
+
+	// The webhook is stored as sent so the fields that are not in params are kept
+	hook := make(map[string]interface{})
+	if len(request.Params) > 0 {
+		if err := json.Unmarshal(request.Params, &hook); err != nil {
+			return nil, nil, invalidArgument(err.Error())
+		}
+	}
+
+	switch name {
+
+	case "ListSupported":
+		types := make(map[string]interface{})
+		for event := range t.supportedWebhookEvents() {
+			types[event] = map[string]interface{}{}
+		}
+		return map[string]interface{}{"types": types}, nil, nil
+
+	case "List":
+		hooks := []interface{}{}
+		for _, v := range t.webhooks {
+			hooks = append(hooks, copyMap(v))
+		}
+		rev, _ := t.components["sys"].status["webhook_rev"].(float64)
+		return map[string]interface{}{"hooks": hooks, "rev": rev}, nil, nil
+
+	case "Create":
+		event, _ := hook["event"].(string)
+		if event == "" {
+			return nil, nil, invalidArgument("Missing required argument 'event'!")
+		}
+		if _, ok := hook["cid"]; !ok {
+			return nil, nil, invalidArgument("Missing required argument 'cid'!")
+		}
+		if !t.supportedWebhookEvents()[event] {
+			return nil, nil, invalidArgument(fmt.Sprintf("Unsupported event %s!", event))
+		}
+		if len(t.webhooks) >= maxWebhooks {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeResourceExhausted, Message: "Too many hooks!"}
+		}
+
+		t.webhookID++
+
+		if _, ok := hook["enable"]; !ok {
+			hook["enable"] = true
+		}
+
+		if _, ok := hook["ssl_ca"]; !ok {
+			hook["ssl_ca"] = "ca.pem"
+		}
+
+		if _, ok := hook["repeat_period"]; !ok {
+			hook["repeat_period"] = float64(0)
+		}
+
+		hook["id"] = float64(t.webhookID)
+		t.webhooks = append(t.webhooks, hook)
+		return map[string]interface{}{"id": t.webhookID, "rev": t.incrementWebhookRev()}, nil, nil
+
+	case "Update":
+		if p.ID == nil {
+			return nil, nil, invalidArgument("Missing required argument 'id'!")
+		}
+		_, v := t.getWebhook(*p.ID)
+		if v == nil {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeNotFound, Message: fmt.Sprintf("Hook %d not found!", *p.ID)}
+		}
+		if event, ok := hook["event"].(string); ok && !t.supportedWebhookEvents()[event] {
+			return nil, nil, invalidArgument(fmt.Sprintf("Unsupported event %s!", event))
+		}
+		delete(hook, "id")
+		for k, x := range hook {
+			v[k] = x
+		}
+		return map[string]interface{}{"rev": t.incrementWebhookRev()}, nil, nil
+
+	case "Delete":
+		if p.ID == nil {
+			return nil, nil, invalidArgument("Missing required argument 'id'!")
+		}
+		i, v := t.getWebhook(*p.ID)
+		if v == nil {
+			return nil, nil, &Error{Code: msg_types.ErrorCodeNotFound, Message: fmt.Sprintf("Hook %d not found!", *p.ID)}
+		}
+		t.webhooks = append(t.webhooks[:i], t.webhooks[i+1:]...)
+		return map[string]interface{}{"rev": t.incrementWebhookRev()}, nil, nil
+
+	case "DeleteAll":
+		t.webhooks = nil
+		return map[string]interface{}{"rev": t.incrementWebhookRev()}, nil, nil
+
+	}
+
+	return nil, nil, noHandler(request.Method)
+}
@@ -1,11 +0,0 @@
-package webhook
-
-// type Request = types.Request
-// type Response = types.Response
-// type Error = types.Error
-// type MessageHandlerFactory = types.MessageHandlerFactory
-// type MessageHandler = types.MessageHandler
-
-// type Webhook = types.Webhook
-// type WebhookConfig = types.WebhookConfig
-// type Config = types.WebhookConfig
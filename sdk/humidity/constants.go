@@ -0,0 +1,5 @@
+package humidity
+
+const (
+	Component = "Humidity"
+)
@@ -0,0 +1,129 @@
+package types
+
+import (
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+)
+
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+// Params internal use only
+type Params struct {
+	ID     int     `json:"id" yaml:"id"`
+	Config *Config `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// Result internal use only
+type Result struct {
+	RestartRequired *bool  `json:"restart_required,omitempty"`
+	Error           *Error `json:"error,omitempty"`
+}
+
+// Status status of the Humidity component contains the relative humidity measured by a
+// sensor such as the sensor of the H&T Plus or a DHT22 on the sensor add-on
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Humidity#status
+type Status struct {
+	// ID Id of the Humidity component instance
+	ID *int `json:"id" yaml:"id"`
+	// Rh relative humidity in % (null if the humidity is out of the measurement range)
+	Rh *float64 `json:"rh,omitempty" yaml:"rh,omitempty"`
+	// Errors error conditions such as read or out_of_range (shown if at least one
+	// error is present)
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Clone return copy
+func (t *Status) Clone() *Status {
+	c := &Status{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Config configuration of the Humidity component. The offset calibrates the sensor and the
+// threshold sets the change that is reported.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Humidity#configuration
+type Config struct {
+	// ID Id of the Humidity component instance
+	ID *int `json:"id" yaml:"id"`
+	// Name of the Humidity instance
+	Name *string `json:"name,omitempty" yaml:"name,omitempty"`
+	// ReportThr relative humidity change in % that triggers a status update
+	ReportThr *float64 `json:"report_thr,omitempty" yaml:"report_thr,omitempty"`
+	// Offset offset in % added to the measured relative humidity
+	Offset *float64 `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+// Clone return copy
+func (t *Config) Clone() *Config {
+	c := &Config{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Equals returns true if equal
+func (t *Config) Equals(x *Config) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("Config receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("Config receiver is not nil but input is")
+		return false
+	}
+
+	if !util.CompareInt(t.ID, x.ID) {
+		zap.L().Info("Config ID not equal")
+		return false
+	}
+
+	if !util.CompareString(t.Name, x.Name) {
+		zap.L().Info("Config Name not equal")
+		return false
+	}
+
+	if !util.CompareFloat64(t.ReportThr, x.ReportThr) {
+		zap.L().Info("Config ReportThr not equal")
+		return false
+	}
+
+	if !util.CompareFloat64(t.Offset, x.Offset) {
+		zap.L().Info("Config Offset not equal")
+		return false
+	}
+
+	return true
+}
+
+func (t *Config) Merge(x *Config) {
+
+	if x == nil {
+		return
+	}
+
+	if t.ID == nil {
+		t.ID = x.ID
+	}
+
+	if t.Name == nil {
+		t.Name = x.Name
+	}
+
+	if t.ReportThr == nil {
+		t.ReportThr = x.ReportThr
+	}
+
+	if t.Offset == nil {
+		t.Offset = x.Offset
+	}
+}
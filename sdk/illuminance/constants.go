@@ -0,0 +1,5 @@
+package illuminance
+
+const (
+	Component = "Illuminance"
+)
@@ -0,0 +1,130 @@
+package types
+
+import (
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+)
+
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+// Params internal use only
+type Params struct {
+	ID     int     `json:"id" yaml:"id"`
+	Config *Config `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// Result internal use only
+type Result struct {
+	RestartRequired *bool  `json:"restart_required,omitempty"`
+	Error           *Error `json:"error,omitempty"`
+}
+
+// Status status of the Illuminance component contains the illuminance measured by a light
+// sensor
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Illuminance#status
+type Status struct {
+	// ID Id of the Illuminance component instance
+	ID *int `json:"id" yaml:"id"`
+	// Lux illuminance in lux (null if the illuminance could not be obtained)
+	Lux *float64 `json:"lux,omitempty" yaml:"lux,omitempty"`
+	// Illumination the illumination level: dark, twilight or bright
+	Illumination *string `json:"illumination,omitempty" yaml:"illumination,omitempty"`
+	// Errors error conditions such as read (shown if at least one error is present)
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Clone return copy
+func (t *Status) Clone() *Status {
+	c := &Status{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Config configuration of the Illuminance component. The thresholds set the illuminance at
+// which the illumination level changes.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Illuminance#configuration
+type Config struct {
+	// ID Id of the Illuminance component instance
+	ID *int `json:"id" yaml:"id"`
+	// Name of the Illuminance instance
+	Name *string `json:"name,omitempty" yaml:"name,omitempty"`
+	// DarkThr illuminance in lux below which the illumination is dark
+	DarkThr *float64 `json:"dark_thr,omitempty" yaml:"dark_thr,omitempty"`
+	// BrightThr illuminance in lux above which the illumination is bright
+	BrightThr *float64 `json:"bright_thr,omitempty" yaml:"bright_thr,omitempty"`
+}
+
+// Clone return copy
+func (t *Config) Clone() *Config {
+	c := &Config{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Equals returns true if equal
+func (t *Config) Equals(x *Config) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("Config receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("Config receiver is not nil but input is")
+		return false
+	}
+
+	if !util.CompareInt(t.ID, x.ID) {
+		zap.L().Info("Config ID not equal")
+		return false
+	}
+
+	if !util.CompareString(t.Name, x.Name) {
+		zap.L().Info("Config Name not equal")
+		return false
+	}
+
+	if !util.CompareFloat64(t.DarkThr, x.DarkThr) {
+		zap.L().Info("Config DarkThr not equal")
+		return false
+	}
+
+	if !util.CompareFloat64(t.BrightThr, x.BrightThr) {
+		zap.L().Info("Config BrightThr not equal")
+		return false
+	}
+
+	return true
+}
+
+func (t *Config) Merge(x *Config) {
+
+	if x == nil {
+		return
+	}
+
+	if t.ID == nil {
+		t.ID = x.ID
+	}
+
+	if t.Name == nil {
+		t.Name = x.Name
+	}
+
+	if t.DarkThr == nil {
+		t.DarkThr = x.DarkThr
+	}
+
+	if t.BrightThr == nil {
+		t.BrightThr = x.BrightThr
+	}
+}
@@ -2,13 +2,15 @@ package input
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 
 	"go.uber.org/zap"
 
 	"github.com/jodydadescott/shelly-client/sdk/input/types"
 	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
 )
 
 type MessageHandlerFactory = msg_types.MessageHandlerFactory
@@ -17,9 +19,16 @@ type Request = msg_types.Request
 
 type Config = types.Config
 type Status = types.Status
+type Params = types.Params
+type Result = types.Result
+
+// Deprecated: GetStatusResponse is no longer used by the client.
 type GetStatusResponse = types.GetStatusResponse
+
+// Deprecated: GetConfigResponse is no longer used by the client.
 type GetConfigResponse = types.GetConfigResponse
-type Params = types.Params
+
+// Deprecated: SetConfigResponse is no longer used by the client.
 type SetConfigResponse = types.SetConfigResponse
 
 func New(messageHandlerFactory MessageHandlerFactory) *Client {
@@ -31,10 +40,14 @@ func New(messageHandlerFactory MessageHandlerFactory) *Client {
 // Client the component client
 type Client struct {
 	MessageHandlerFactory
-	_messageHandler MessageHandler
+	messageHandlerMutex sync.Mutex
+	_messageHandler     MessageHandler
 }
 
 func (t *Client) getMessageHandler() MessageHandler {
+	t.messageHandlerMutex.Lock()
+	defer t.messageHandlerMutex.Unlock()
+
 	if t._messageHandler != nil {
 		return t._messageHandler
 	}
@@ -48,6 +61,12 @@ func getErr(method string, id *int, err error) error {
 		return nil
 	}
 
+	// An error returned by the device already has the method and device ID
+	rpcErr := &msg_types.Error{}
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
 	if id == nil {
 		return fmt.Errorf("component %s, method %s, error %w", Component, method, err)
 	}
@@ -60,32 +79,15 @@ func (t *Client) GetStatus(ctx context.Context, id int) (*Status, error) {
 
 	method := Component + ".GetStatus"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: &Params{
-			ID: id,
-		},
+	result, err := rpc.Call[*Params, *Status](ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
 	})
 
 	if err != nil {
 		return nil, getErr(method, &id, err)
 	}
 
-	response := &GetStatusResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return nil, getErr(method, &id, err)
-	}
-
-	if response.Error != nil {
-		return nil, getErr(method, &id, response.Error)
-	}
-
-	if response.Result == nil {
-		return nil, getErr(method, &id, fmt.Errorf("result is missing from response"))
-	}
-
-	return response.Result, nil
+	return result, nil
 }
 
 // GetConfig returns component config or error
@@ -93,31 +95,15 @@ func (t *Client) GetConfig(ctx context.Context, id int) (*Config, error) {
 
 	method := Component + ".GetConfig"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: &Params{
-			ID: id,
-		},
+	result, err := rpc.Call[*Params, *Config](ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
 	})
-	if err != nil {
-		return nil, err
-	}
 
-	response := &GetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
 	if err != nil {
 		return nil, getErr(method, &id, err)
 	}
 
-	if response.Error != nil {
-		return nil, getErr(method, &id, response.Error)
-	}
-
-	if response.Result == nil {
-		return nil, getErr(method, &id, fmt.Errorf("result is missing from response"))
-	}
-
-	return response.Result, nil
+	return result, nil
 }
 
 // SetConfig applies config to device component
@@ -137,31 +123,10 @@ func (t *Client) SetConfig(ctx context.Context, config *Config) error {
 		return fmt.Errorf("config ID is nil")
 	}
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: &Params{
-			ID:     *config.ID,
-			Config: config,
-		},
+	_, err := rpc.Call[*Params, *Result](ctx, t.getMessageHandler(), method, &Params{
+		ID:     *config.ID,
+		Config: config,
 	})
 
-	if err != nil {
-		return getErr(method, nil, err)
-	}
-
-	response := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return getErr(method, nil, err)
-	}
-
-	if response.Error != nil {
-		return getErr(method, nil, response.Error)
-	}
-
-	if response.Result == nil {
-		return getErr(method, nil, fmt.Errorf("result is missing from response"))
-	}
-
-	return nil
+	return getErr(method, nil, err)
 }
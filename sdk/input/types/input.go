@@ -25,6 +25,9 @@ type Result struct {
 }
 
 // GetConfigResponse internal use only
+//
+// Deprecated: the client decodes the result with rpc.Call. This type is no longer used
+// and will be removed.
 type GetConfigResponse struct {
 	Response
 	Result *Config `json:"result,omitempty"`
@@ -32,12 +35,18 @@ type GetConfigResponse struct {
 }
 
 // SetConfigResponse internal use only
+//
+// Deprecated: the client decodes the result with rpc.Call. This type is no longer used
+// and will be removed.
 type SetConfigResponse struct {
 	Response
 	Result *Result `json:"result,omitempty"`
 }
 
 // GetStatusResponse internal use only
+//
+// Deprecated: the client decodes the result with rpc.Call. This type is no longer used
+// and will be removed.
 type GetStatusResponse struct {
 	Response
 	Result *Status `json:"result,omitempty"`
@@ -0,0 +1,19 @@
+package interceptor
+
+import (
+	"time"
+)
+
+var defaultBuckets = []time.Duration{
+	time.Millisecond * 5,
+	time.Millisecond * 10,
+	time.Millisecond * 25,
+	time.Millisecond * 50,
+	time.Millisecond * 100,
+	time.Millisecond * 250,
+	time.Millisecond * 500,
+	time.Second,
+	time.Millisecond * 2500,
+	time.Second * 5,
+	time.Second * 10,
+}
@@ -0,0 +1,122 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jodydadescott/shelly-client/sdk/interceptor/types"
+)
+
+type HistogramConfig = types.HistogramConfig
+type HistogramSnapshot = types.HistogramSnapshot
+type Bucket = types.Bucket
+
+// Histogram records the latency of requests by method. Add Intercept to the interceptors of
+// the client config and use Snapshot to read the histograms.
+type Histogram struct {
+	buckets []time.Duration
+	mutex   sync.Mutex
+	methods map[string]*HistogramSnapshot
+}
+
+// NewHistogram returns a new Histogram. The config may be nil.
+func NewHistogram(config *HistogramConfig) *Histogram {
+
+	zap.L().Debug("NewHistogram")
+
+	var buckets []time.Duration
+
+	if config == nil || len(config.Buckets) == 0 {
+		buckets = defaultBuckets
+		zap.L().Debug(fmt.Sprintf("buckets is %v (default)", buckets))
+	} else {
+		buckets = append(buckets, config.Buckets...)
+		sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+		zap.L().Debug(fmt.Sprintf("buckets is %v (config)", buckets))
+	}
+
+	return &Histogram{
+		buckets: buckets,
+		methods: make(map[string]*HistogramSnapshot),
+	}
+}
+
+// Intercept is an Interceptor that records the latency of the request
+func (t *Histogram) Intercept(ctx context.Context, request *Request, next SendFunc) ([]byte, error) {
+	start := time.Now()
+	b, err := next(ctx, request)
+	t.Observe(getMethod(request), time.Since(start), err)
+	return b, err
+}
+
+// Observe records a request with the latency. This is called by Intercept.
+func (t *Histogram) Observe(method string, latency time.Duration, err error) {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	snapshot := t.methods[method]
+	if snapshot == nil {
+		snapshot = &HistogramSnapshot{Method: method}
+		for _, upperBound := range t.buckets {
+			snapshot.Buckets = append(snapshot.Buckets, &Bucket{UpperBound: upperBound})
+		}
+		snapshot.Buckets = append(snapshot.Buckets, &Bucket{})
+		t.methods[method] = snapshot
+	}
+
+	if snapshot.Count == 0 || latency < snapshot.Min {
+		snapshot.Min = latency
+	}
+
+	if latency > snapshot.Max {
+		snapshot.Max = latency
+	}
+
+	snapshot.Count++
+	snapshot.Sum += latency
+
+	if err != nil {
+		snapshot.Errors++
+	}
+
+	for _, bucket := range snapshot.Buckets {
+		if bucket.UpperBound == 0 || latency <= bucket.UpperBound {
+			bucket.Count++
+		}
+	}
+}
+
+// Snapshot returns a copy of the histogram of each method sorted by method
+func (t *Histogram) Snapshot() []*HistogramSnapshot {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var snapshots []*HistogramSnapshot
+	for _, snapshot := range t.methods {
+		c := *snapshot
+		c.Buckets = nil
+		for _, bucket := range snapshot.Buckets {
+			b := *bucket
+			c.Buckets = append(c.Buckets, &b)
+		}
+		snapshots = append(snapshots, &c)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Method < snapshots[j].Method })
+
+	return snapshots
+}
+
+// Reset clears all histograms
+func (t *Histogram) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.methods = make(map[string]*HistogramSnapshot)
+}
@@ -0,0 +1,62 @@
+package interceptor
+
+import (
+	"context"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+type MessageHandler = msg_types.MessageHandler
+type Request = msg_types.Request
+type Interceptor = msg_types.Interceptor
+type SendFunc = msg_types.SendFunc
+
+// Wrap returns a handler that sends each request through the interceptors and then the
+// handler. The first interceptor is the outermost. If there are no interceptors the
+// handler is returned.
+func Wrap(handler MessageHandler, interceptors ...Interceptor) MessageHandler {
+
+	if len(interceptors) == 0 {
+		return handler
+	}
+
+	return &Handle{
+		send: chain(handler.Send, interceptors),
+	}
+}
+
+// Chain returns a single interceptor that calls each of the interceptors in order
+func Chain(interceptors ...Interceptor) Interceptor {
+	return func(ctx context.Context, request *Request, next SendFunc) ([]byte, error) {
+		return chain(next, interceptors)(ctx, request)
+	}
+}
+
+func chain(send SendFunc, interceptors []Interceptor) SendFunc {
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+
+		interceptor := interceptors[i]
+
+		if interceptor == nil {
+			continue
+		}
+
+		next := send
+
+		send = func(ctx context.Context, request *Request) ([]byte, error) {
+			return interceptor(ctx, request, next)
+		}
+	}
+
+	return send
+}
+
+// Handle is a MessageHandler that sends requests through an interceptor chain
+type Handle struct {
+	send SendFunc
+}
+
+func (t *Handle) Send(ctx context.Context, request *Request) ([]byte, error) {
+	return t.send(ctx, request)
+}
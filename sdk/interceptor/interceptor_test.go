@@ -0,0 +1,293 @@
+package interceptor_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/jodydadescott/shelly-client/sdk/client"
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	"github.com/jodydadescott/shelly-client/sdk/interceptor"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+// handler records the method of each request and returns response or err
+type handler struct {
+	calls    []string
+	response []byte
+	err      error
+}
+
+func (t *handler) Send(ctx context.Context, request *msg_types.Request) ([]byte, error) {
+	t.calls = append(t.calls, *request.Method)
+	return t.response, t.err
+}
+
+// record returns an interceptor that appends name before and after calling next
+func record(trace *[]string, name string) interceptor.Interceptor {
+	return func(ctx context.Context, request *msg_types.Request, next interceptor.SendFunc) ([]byte, error) {
+		*trace = append(*trace, name+" before")
+		b, err := next(ctx, request)
+		*trace = append(*trace, name+" after")
+		return b, err
+	}
+}
+
+func TestWrap(t *testing.T) {
+
+	errStop := errors.New("stop")
+
+	tests := []struct {
+		name string
+		// interceptors returns the interceptors to wrap the handler with. They append to trace.
+		interceptors func(trace *[]string) []interceptor.Interceptor
+		trace        []string
+		calls        []string
+		wantErr      error
+	}{
+		{
+			name:  "none",
+			calls: []string{"Switch.Set"},
+		},
+		{
+			name: "order",
+			interceptors: func(trace *[]string) []interceptor.Interceptor {
+				return []interceptor.Interceptor{record(trace, "a"), nil, record(trace, "b")}
+			},
+			trace: []string{"a before", "b before", "b after", "a after"},
+			calls: []string{"Switch.Set"},
+		},
+		{
+			name: "chain",
+			interceptors: func(trace *[]string) []interceptor.Interceptor {
+				return []interceptor.Interceptor{interceptor.Chain(record(trace, "a"), record(trace, "b")), record(trace, "c")}
+			},
+			trace: []string{"a before", "b before", "c before", "c after", "b after", "a after"},
+			calls: []string{"Switch.Set"},
+		},
+		{
+			name: "alter request",
+			interceptors: func(trace *[]string) []interceptor.Interceptor {
+				return []interceptor.Interceptor{func(ctx context.Context, request *msg_types.Request, next interceptor.SendFunc) ([]byte, error) {
+					method := "Switch.Toggle"
+					request.Method = &method
+					return next(ctx, request)
+				}}
+			},
+			calls: []string{"Switch.Toggle"},
+		},
+		{
+			name: "short circuit",
+			interceptors: func(trace *[]string) []interceptor.Interceptor {
+				return []interceptor.Interceptor{record(trace, "a"), func(ctx context.Context, request *msg_types.Request, next interceptor.SendFunc) ([]byte, error) {
+					return nil, errStop
+				}, record(trace, "b")}
+			},
+			trace:   []string{"a before", "a after"},
+			wantErr: errStop,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var trace []string
+			var interceptors []interceptor.Interceptor
+			if tt.interceptors != nil {
+				interceptors = tt.interceptors(&trace)
+			}
+
+			h := &handler{response: []byte("ok")}
+			wrapped := interceptor.Wrap(h, interceptors...)
+
+			if len(interceptors) == 0 && wrapped != h {
+				t.Errorf("handler wrapped with no interceptors")
+			}
+
+			method := "Switch.Set"
+			b, err := wrapped.Send(context.Background(), &msg_types.Request{Method: &method})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && string(b) != "ok" {
+				t.Errorf("response %s, want ok", string(b))
+			}
+
+			if strings.Join(trace, ",") != strings.Join(tt.trace, ",") {
+				t.Errorf("trace %v, want %v", trace, tt.trace)
+			}
+
+			if strings.Join(h.calls, ",") != strings.Join(tt.calls, ",") {
+				t.Errorf("handler calls %v, want %v", h.calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestHistogram(t *testing.T) {
+
+	histogram := interceptor.NewHistogram(&interceptor.HistogramConfig{
+		Buckets: []time.Duration{time.Millisecond * 100, time.Millisecond * 10},
+	})
+
+	histogram.Observe("Switch.Set", time.Millisecond*5, nil)
+	histogram.Observe("Switch.Set", time.Millisecond*50, nil)
+	histogram.Observe("Switch.Set", time.Second, errors.New("failed"))
+
+	// Intercept observes the request with the error returned by next
+	h := &handler{err: msg_types.ErrTimeout}
+	method := "Switch.GetStatus"
+	_, err := interceptor.Wrap(h, histogram.Intercept).Send(context.Background(), &msg_types.Request{Method: &method})
+	if !errors.Is(err, msg_types.ErrTimeout) {
+		t.Fatalf("error %v, want ErrTimeout", err)
+	}
+
+	snapshots := histogram.Snapshot()
+
+	if len(snapshots) != 2 || snapshots[0].Method != "Switch.GetStatus" || snapshots[1].Method != "Switch.Set" {
+		t.Fatalf("snapshots %d, want Switch.GetStatus and Switch.Set", len(snapshots))
+	}
+
+	if s := snapshots[0]; s.Count != 1 || s.Errors != 1 {
+		t.Errorf("Switch.GetStatus count %d, errors %d, want 1, 1", s.Count, s.Errors)
+	}
+
+	s := snapshots[1]
+
+	if s.Count != 3 || s.Errors != 1 {
+		t.Errorf("count %d, errors %d, want 3, 1", s.Count, s.Errors)
+	}
+
+	if s.Min != time.Millisecond*5 || s.Max != time.Second {
+		t.Errorf("min %v, max %v, want 5ms, 1s", s.Min, s.Max)
+	}
+
+	if mean := s.Mean(); mean != time.Millisecond*1055/3 {
+		t.Errorf("mean %v, want %v", mean, time.Millisecond*1055/3)
+	}
+
+	// The buckets are sorted and cumulative and the last has no upper bound
+	want := []interceptor.Bucket{
+		{UpperBound: time.Millisecond * 10, Count: 1},
+		{UpperBound: time.Millisecond * 100, Count: 2},
+		{Count: 3},
+	}
+
+	if len(s.Buckets) != len(want) {
+		t.Fatalf("buckets %d, want %d", len(s.Buckets), len(want))
+	}
+
+	for i := range want {
+		if *s.Buckets[i] != want[i] {
+			t.Errorf("bucket %d %+v, want %+v", i, *s.Buckets[i], want[i])
+		}
+	}
+
+	// The snapshot is a copy
+	s.Buckets[0].Count = 100
+	if histogram.Snapshot()[1].Buckets[0].Count != 1 {
+		t.Errorf("snapshot shares buckets with the histogram")
+	}
+
+	histogram.Reset()
+	if len(histogram.Snapshot()) != 0 {
+		t.Errorf("snapshots after Reset, want none")
+	}
+}
+
+func TestLogging(t *testing.T) {
+
+	deviceID := "shellyplus1pm-1"
+
+	tests := []struct {
+		name  string
+		err   error
+		level zapcore.Level
+		// fields the fields that must be present
+		fields []string
+	}{
+		{name: "success", level: zapcore.DebugLevel, fields: []string{"method", "latency", "bytes"}},
+		{name: "device error", err: &msg_types.Error{Code: msg_types.ErrorCodeNotFound, DeviceID: deviceID}, level: zapcore.WarnLevel, fields: []string{"method", "latency", "code", "device", "error"}},
+		{name: "transport error", err: msg_types.ErrTimeout, level: zapcore.WarnLevel, fields: []string{"method", "latency", "error"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			core, logs := observer.New(zapcore.DebugLevel)
+
+			h := &handler{response: []byte("ok"), err: tt.err}
+			method := "Switch.Set"
+
+			_, err := interceptor.Wrap(h, interceptor.NewLogging(zap.New(core))).Send(context.Background(), &msg_types.Request{Method: &method})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error %v, want %v", err, tt.err)
+			}
+
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("%d log entries, want 1", len(entries))
+			}
+
+			entry := entries[0]
+
+			if entry.Level != tt.level {
+				t.Errorf("level %s, want %s", entry.Level, tt.level)
+			}
+
+			fields := entry.ContextMap()
+			for _, v := range tt.fields {
+				if _, ok := fields[v]; !ok {
+					t.Errorf("field %s not logged; fields %v", v, fields)
+				}
+			}
+
+			if fields["method"] != method {
+				t.Errorf("method %v, want %s", fields["method"], method)
+			}
+		})
+	}
+}
+
+// TestClientInterceptors checks that the interceptors of the client config are applied to
+// requests sent by the component clients
+func TestClientInterceptors(t *testing.T) {
+
+	histogram := interceptor.NewHistogram(nil)
+
+	device := fake.NewDevice(&fake.DeviceConfig{Switch: 1})
+	device.InjectFault(&fake.Fault{Method: "Switch.GetStatus", Code: msg_types.ErrorCodeNotFound, Message: "not found"})
+
+	c := client.NewWithMessageHandlerFactory(&client.Config{Interceptors: []client.Interceptor{histogram.Intercept}}, fake.New(nil, device))
+	defer c.Close()
+
+	ctx := context.Background()
+
+	on := true
+	err := c.Switch().Set(ctx, 0, &on)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Switch().GetStatus(ctx, 0)
+	if !errors.Is(err, msg_types.ErrNotFound) {
+		t.Fatalf("error %v, want ErrNotFound", err)
+	}
+
+	counts := make(map[string][2]int)
+	for _, s := range histogram.Snapshot() {
+		counts[s.Method] = [2]int{s.Count, s.Errors}
+	}
+
+	if counts["Switch.Set"] != [2]int{1, 0} || counts["Switch.GetStatus"] != [2]int{1, 1} {
+		t.Errorf("count and errors by method %v, want Switch.Set [1 0] and Switch.GetStatus [1 1]", counts)
+	}
+}
@@ -0,0 +1,54 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+// NewLogging returns an interceptor that logs each request with structured fields. Requests
+// that succeed are logged at debug level and requests that fail at warn level. If logger
+// is nil the global zap logger is used.
+func NewLogging(logger *zap.Logger) Interceptor {
+
+	return func(ctx context.Context, request *Request, next SendFunc) ([]byte, error) {
+
+		start := time.Now()
+		b, err := next(ctx, request)
+		latency := time.Since(start)
+
+		log := logger
+		if log == nil {
+			log = zap.L()
+		}
+
+		fields := []zap.Field{
+			zap.String("method", getMethod(request)),
+			zap.Duration("latency", latency),
+		}
+
+		if err == nil {
+			log.Debug("rpc", append(fields, zap.Int("bytes", len(b)))...)
+			return b, nil
+		}
+
+		rpcError := &msg_types.Error{}
+		if errors.As(err, &rpcError) {
+			fields = append(fields, zap.Int("code", rpcError.Code), zap.String("device", rpcError.DeviceID))
+		}
+
+		log.Warn("rpc", append(fields, zap.Error(err))...)
+		return b, err
+	}
+}
+
+func getMethod(request *Request) string {
+	if request.Method == nil {
+		return ""
+	}
+	return *request.Method
+}
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"time"
+
+	"github.com/jinzhu/copier"
+)
+
+// HistogramConfig is the config for the latency histogram
+type HistogramConfig struct {
+	// Buckets upper bounds of the buckets in ascending order. Defaults to 5ms through 10s
+	Buckets []time.Duration `json:"buckets,omitempty" yaml:"buckets,omitempty"`
+}
+
+// Clone return copy
+func (t *HistogramConfig) Clone() *HistogramConfig {
+	c := &HistogramConfig{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// HistogramSnapshot is the latency histogram of one method
+type HistogramSnapshot struct {
+	Method string `json:"method,omitempty" yaml:"method,omitempty"`
+	// Count number of requests
+	Count int `json:"count" yaml:"count"`
+	// Errors number of requests that returned an error
+	Errors int `json:"errors" yaml:"errors"`
+	// Sum total latency of all requests
+	Sum time.Duration `json:"sum" yaml:"sum"`
+	// Min lowest latency
+	Min time.Duration `json:"min" yaml:"min"`
+	// Max highest latency
+	Max time.Duration `json:"max" yaml:"max"`
+	// Buckets cumulative count of requests with a latency less than or equal to each upper
+	// bound. The last bucket has no upper bound and is equal to Count.
+	Buckets []*Bucket `json:"buckets,omitempty" yaml:"buckets,omitempty"`
+}
+
+// Mean returns the mean latency or zero if there are no requests
+func (t *HistogramSnapshot) Mean() time.Duration {
+	if t.Count == 0 {
+		return 0
+	}
+	return t.Sum / time.Duration(t.Count)
+}
+
+// Bucket is one bucket of a histogram. An UpperBound of zero means there is no upper bound.
+type Bucket struct {
+	UpperBound time.Duration `json:"upperBound,omitempty" yaml:"upperBound,omitempty"`
+	Count      int           `json:"count" yaml:"count"`
+}
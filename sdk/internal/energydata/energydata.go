@@ -0,0 +1,207 @@
+// Package energydata implements the methods shared by the EMData and EM1Data components.
+// The components differ only in name and status and have the same records and data.
+package energydata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	emdata_types "github.com/jodydadescott/shelly-client/sdk/emdata/types"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
+)
+
+type MessageHandlerFactory = msg_types.MessageHandlerFactory
+type MessageHandler = msg_types.MessageHandler
+
+type Params = emdata_types.Params
+type Records = emdata_types.Records
+type Data = emdata_types.Data
+
+// Client the client of an energy data component with the status S
+type Client[S any] struct {
+	component             string
+	messageHandlerFactory MessageHandlerFactory
+	messageHandlerMutex   sync.Mutex
+	_messageHandler       MessageHandler
+}
+
+// New returns a new Client for the component
+func New[S any](messageHandlerFactory MessageHandlerFactory, component string) *Client[S] {
+	return &Client[S]{
+		component:             component,
+		messageHandlerFactory: messageHandlerFactory,
+	}
+}
+
+func (t *Client[S]) getMessageHandler() MessageHandler {
+	t.messageHandlerMutex.Lock()
+	defer t.messageHandlerMutex.Unlock()
+
+	if t._messageHandler != nil {
+		return t._messageHandler
+	}
+
+	t._messageHandler = t.messageHandlerFactory.NewHandle(t.component)
+	return t._messageHandler
+}
+
+func (t *Client[S]) getErr(method string, id int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// An error returned by the device already has the method and device ID
+	rpcErr := &msg_types.Error{}
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
+	return fmt.Errorf("component %s, method %s, id %d, error %w", t.component, method, id, err)
+}
+
+// GetStatus returns status for component or error
+func (t *Client[S]) GetStatus(ctx context.Context, id int) (*S, error) {
+
+	method := t.component + ".GetStatus"
+
+	result, err := rpc.Call[*Params, *S](ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
+	})
+
+	if err != nil {
+		return nil, t.getErr(method, id, err)
+	}
+
+	return result, nil
+}
+
+// GetRecords returns the intervals for which the device has stored data starting at from.
+// If from is zero all intervals are returned.
+func (t *Client[S]) GetRecords(ctx context.Context, id int, from time.Time) (*Records, error) {
+
+	method := t.component + ".GetRecords"
+
+	params := &Params{ID: id}
+	if !from.IsZero() {
+		ts := from.Unix()
+		params.Ts = &ts
+	}
+
+	result, err := rpc.Call[*Params, *Records](ctx, t.getMessageHandler(), method, params)
+	if err != nil {
+		return nil, t.getErr(method, id, err)
+	}
+
+	return result, nil
+}
+
+// GetData returns the stored data from from until to. The device returns a limited number of
+// records at once; if there is more data NextRecordTs of the result is set. If to is zero
+// the data until the last record is requested. See GetAllData.
+func (t *Client[S]) GetData(ctx context.Context, id int, from time.Time, to time.Time) (*Data, error) {
+
+	method := t.component + ".GetData"
+
+	ts := from.Unix()
+	if from.IsZero() {
+		ts = 0
+	}
+
+	params := &Params{ID: id, Ts: &ts}
+	if !to.IsZero() {
+		endTs := to.Unix()
+		params.EndTs = &endTs
+	}
+
+	result, err := rpc.Call[*Params, *Data](ctx, t.getMessageHandler(), method, params)
+	if err != nil {
+		return nil, t.getErr(method, id, err)
+	}
+
+	return result, nil
+}
+
+// GetAllData returns the stored data from from until to. The data is requested in pages
+// until the device has returned all of it.
+func (t *Client[S]) GetAllData(ctx context.Context, id int, from time.Time, to time.Time) (*Data, error) {
+
+	method := t.component + ".GetData"
+
+	data := &Data{}
+
+	for {
+
+		page, err := t.GetData(ctx, id, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(page.Data) > 0 {
+			if data.Keys == nil {
+				data.Keys = page.Keys
+			} else if !equalKeys(data.Keys, page.Keys) {
+				return nil, t.getErr(method, id, fmt.Errorf("keys of the data changed between pages"))
+			}
+			data.Data = append(data.Data, page.Data...)
+		}
+
+		if page.NextRecordTs == nil || len(page.Data) == 0 {
+			return data, nil
+		}
+
+		next := time.Unix(*page.NextRecordTs, 0)
+
+		if !next.After(from) {
+			return nil, t.getErr(method, id, fmt.Errorf("next record %d does not advance", *page.NextRecordTs))
+		}
+
+		if !to.IsZero() && next.After(to) {
+			return data, nil
+		}
+
+		from = next
+	}
+}
+
+// ResetCounters resets the energy counters of the metering component with the same ID
+func (t *Client[S]) ResetCounters(ctx context.Context, id int) error {
+
+	method := t.component + ".ResetCounters"
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
+	})
+
+	return t.getErr(method, id, err)
+}
+
+// DeleteAllData deletes all stored data
+func (t *Client[S]) DeleteAllData(ctx context.Context, id int) error {
+
+	method := t.component + ".DeleteAllData"
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
+	})
+
+	return t.getErr(method, id, err)
+}
+
+func equalKeys(a, b []string) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
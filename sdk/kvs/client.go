@@ -0,0 +1,292 @@
+package kvs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"go.uber.org/zap"
+
+	"github.com/jodydadescott/shelly-client/sdk/kvs/types"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
+)
+
+type MessageHandlerFactory = msg_types.MessageHandlerFactory
+type MessageHandler = msg_types.MessageHandler
+type Request = msg_types.Request
+
+type Item = types.Item
+type Items = types.Items
+type Keys = types.Keys
+type Params = types.Params
+type Result = types.Result
+type GetManyResult = types.GetManyResult
+
+// New returns new instance of client
+func New(messageHandlerFactory MessageHandlerFactory) *Client {
+	return &Client{
+		MessageHandlerFactory: messageHandlerFactory,
+	}
+}
+
+// Client the component client. Writes can be made conditional on the etag of the current
+// value; if the value has been written since the etag was read the write fails with an
+// error that matches ErrFailedPrecondition.
+type Client struct {
+	MessageHandlerFactory
+	messageHandlerMutex sync.Mutex
+	_messageHandler     MessageHandler
+}
+
+func (t *Client) getMessageHandler() MessageHandler {
+	t.messageHandlerMutex.Lock()
+	defer t.messageHandlerMutex.Unlock()
+
+	if t._messageHandler != nil {
+		return t._messageHandler
+	}
+
+	t._messageHandler = t.NewHandle(Component)
+	return t._messageHandler
+}
+
+func getErr(method string, key *string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// An error returned by the device already has the method and device ID
+	rpcErr := &msg_types.Error{}
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
+	if key == nil {
+		return fmt.Errorf("component %s, method %s, error %w", Component, method, err)
+	}
+
+	return fmt.Errorf("component %s, method %s, key %s, error %w", Component, method, *key, err)
+}
+
+// Get returns the value and etag of the key. If the key does not exist the error matches
+// ErrNotFound.
+func (t *Client) Get(ctx context.Context, key string) (*Item, error) {
+
+	method := Component + ".Get"
+
+	result, err := rpc.Call[*Params, *Result](ctx, t.getMessageHandler(), method, &Params{
+		Key: &key,
+	})
+
+	if err != nil {
+		return nil, getErr(method, &key, err)
+	}
+
+	return &Item{
+		Key:   key,
+		Etag:  result.Etag,
+		Value: result.Value,
+	}, nil
+}
+
+// Set writes the value of the key. The value is encoded as JSON. Returns the new etag.
+func (t *Client) Set(ctx context.Context, key string, value any) (string, error) {
+	return t.set(ctx, key, value, nil)
+}
+
+// SetIfMatch writes the value of the key only if the etag of the current value is etag.
+// Returns the new etag.
+func (t *Client) SetIfMatch(ctx context.Context, key string, value any, etag string) (string, error) {
+	return t.set(ctx, key, value, &etag)
+}
+
+func (t *Client) set(ctx context.Context, key string, value any, etag *string) (string, error) {
+
+	method := Component + ".Set"
+
+	b, err := types.Marshal(value)
+	if err != nil {
+		return "", getErr(method, &key, err)
+	}
+
+	result, err := rpc.Call[*Params, *Result](ctx, t.getMessageHandler(), method, &Params{
+		Key:   &key,
+		Value: b,
+		Etag:  etag,
+	})
+
+	if err != nil {
+		return "", getErr(method, &key, err)
+	}
+
+	if result.Etag == nil {
+		return "", nil
+	}
+
+	return *result.Etag, nil
+}
+
+// Delete deletes the key
+func (t *Client) Delete(ctx context.Context, key string) error {
+	return t.delete(ctx, key, nil)
+}
+
+// DeleteIfMatch deletes the key only if the etag of the current value is etag
+func (t *Client) DeleteIfMatch(ctx context.Context, key string, etag string) error {
+	return t.delete(ctx, key, &etag)
+}
+
+func (t *Client) delete(ctx context.Context, key string, etag *string) error {
+
+	method := Component + ".Delete"
+
+	_, err := rpc.Call[*Params, *Result](ctx, t.getMessageHandler(), method, &Params{
+		Key:  &key,
+		Etag: etag,
+	})
+
+	return getErr(method, &key, err)
+}
+
+// List returns the keys and etags that match the pattern such as "scene_*". An empty
+// match returns all keys.
+func (t *Client) List(ctx context.Context, match string) (*Keys, error) {
+
+	method := Component + ".List"
+
+	params := &Params{}
+	if match != "" {
+		params.Match = &match
+	}
+
+	result, err := rpc.Call[*Params, *Keys](ctx, t.getMessageHandler(), method, params)
+	if err != nil {
+		return nil, getErr(method, nil, err)
+	}
+
+	return result, nil
+}
+
+// GetMany returns the keys with their etags and values that match the pattern such as
+// "scene_*". An empty match returns all keys. The items are read in pages if the device
+// pages the result.
+func (t *Client) GetMany(ctx context.Context, match string) (Items, error) {
+
+	method := Component + ".GetMany"
+
+	items := Items{}
+
+	for {
+
+		params := &Params{}
+
+		if match != "" {
+			params.Match = &match
+		}
+
+		if len(items) > 0 {
+			offset := len(items)
+			params.Offset = &offset
+		}
+
+		result, err := rpc.Call[*Params, *GetManyResult](ctx, t.getMessageHandler(), method, params)
+		if err != nil {
+			return nil, getErr(method, nil, err)
+		}
+
+		items = append(items, result.Items...)
+
+		if result.Total == nil || len(result.Items) == 0 || len(items) >= *result.Total {
+			return items, nil
+		}
+	}
+}
+
+// GetAs returns the value of the key decoded as T
+func GetAs[T any](ctx context.Context, client *Client, key string) (T, error) {
+
+	var v T
+
+	item, err := client.Get(ctx, key)
+	if err != nil {
+		return v, err
+	}
+
+	err = item.Decode(&v)
+	return v, err
+}
+
+// GetConfig returns all keys and values
+func (t *Client) GetConfig(ctx context.Context) (map[string]interface{}, error) {
+
+	items, err := t.GetMany(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	config := make(map[string]interface{})
+
+	for _, v := range items {
+
+		var value interface{}
+
+		err := json.Unmarshal(v.Value, &value)
+		if err != nil {
+			return nil, fmt.Errorf("key %s; %w", v.Key, err)
+		}
+
+		config[v.Key] = value
+	}
+
+	return config, nil
+}
+
+// SetConfig writes each key of config that does not exist or has a different value. Keys
+// that are not in config are left alone as the KVS is also written by scripts. Returns
+// true if the device was changed.
+func (t *Client) SetConfig(ctx context.Context, config map[string]interface{}) (bool, error) {
+
+	var keys []string
+	for key := range config {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var errs *multierror.Error
+	changed := false
+
+	for _, key := range keys {
+
+		value, err := types.Marshal(config[key])
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("key %s; %w", key, err))
+			continue
+		}
+
+		item, err := t.Get(ctx, key)
+		if err != nil {
+			if !errors.Is(err, msg_types.ErrNotFound) {
+				errs = multierror.Append(errs, err)
+				continue
+			}
+		} else if types.ValueEquals(item.Value, value) {
+			continue
+		}
+
+		zap.L().Debug(fmt.Sprintf("Setting KVS key %s", key))
+		changed = true
+
+		_, err = t.Set(ctx, key, config[key])
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	return changed, errs.ErrorOrNil()
+}
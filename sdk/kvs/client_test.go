@@ -0,0 +1,208 @@
+package kvs_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	"github.com/jodydadescott/shelly-client/sdk/kvs"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+func newClient(t *testing.T) (*kvs.Client, *fake.Device) {
+	device := fake.NewDevice(&fake.DeviceConfig{Switch: 1})
+	factory := fake.New(nil, device)
+	t.Cleanup(factory.Close)
+	return kvs.New(factory), device
+}
+
+func countCalls(device *fake.Device, method string, from int) int {
+	count := 0
+	for _, v := range device.Calls()[from:] {
+		if v == method {
+			count++
+		}
+	}
+	return count
+}
+
+func TestSetConfig(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		existing map[string]interface{}
+		config   map[string]interface{}
+		want     map[string]interface{}
+		sets     int
+	}{
+		{
+			name:   "create",
+			config: map[string]interface{}{"mode": "away", "level": 3},
+			want:   map[string]interface{}{"mode": "away", "level": float64(3)},
+			sets:   2,
+		},
+		{
+			name:     "keep",
+			existing: map[string]interface{}{"mode": "away", "scene": map[string]interface{}{"on": true}},
+			config:   map[string]interface{}{"mode": "away", "scene": map[string]interface{}{"on": true}},
+			want:     map[string]interface{}{"mode": "away", "scene": map[string]interface{}{"on": true}},
+		},
+		{
+			name:     "update",
+			existing: map[string]interface{}{"mode": "away", "level": 3},
+			config:   map[string]interface{}{"mode": "home", "level": 3},
+			want:     map[string]interface{}{"mode": "home", "level": float64(3)},
+			sets:     1,
+		},
+		{
+			name:     "other keys are kept",
+			existing: map[string]interface{}{"counter": 7},
+			config:   map[string]interface{}{"mode": "home"},
+			want:     map[string]interface{}{"mode": "home", "counter": float64(7)},
+			sets:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctx := context.Background()
+			client, device := newClient(t)
+
+			for key, value := range tt.existing {
+				_, err := client.Set(ctx, key, value)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			before := len(device.Calls())
+
+			changed, err := client.SetConfig(ctx, tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if changed != (tt.sets > 0) {
+				t.Errorf("changed %t, want %t", changed, tt.sets > 0)
+			}
+
+			if sets := countCalls(device, "KVS.Set", before); sets != tt.sets {
+				t.Errorf("KVS.Set sent %d times, want %d", sets, tt.sets)
+			}
+
+			config, err := client.GetConfig(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := fmt.Sprint(config), fmt.Sprint(tt.want); got != want {
+				t.Errorf("config %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+
+	ctx := context.Background()
+	client, _ := newClient(t)
+
+	etag, err := client.Set(ctx, "mode", "away")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := client.SetIfMatch(ctx, "mode", "home", etag)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// etag is stale after the value was set
+	_, err = client.SetIfMatch(ctx, "mode", "night", etag)
+	if !errors.Is(err, msg_types.ErrFailedPrecondition) {
+		t.Errorf("SetIfMatch with a stale etag error %v, want ErrFailedPrecondition", err)
+	}
+
+	err = client.DeleteIfMatch(ctx, "mode", etag)
+	if !errors.Is(err, msg_types.ErrFailedPrecondition) {
+		t.Errorf("DeleteIfMatch with a stale etag error %v, want ErrFailedPrecondition", err)
+	}
+
+	value, err := kvs.GetAs[string](ctx, client, "mode")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value != "home" {
+		t.Errorf("value %s, want home", value)
+	}
+
+	err = client.DeleteIfMatch(ctx, "mode", current)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.Get(ctx, "mode")
+	if !errors.Is(err, msg_types.ErrNotFound) {
+		t.Errorf("Get after delete error %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetMany(t *testing.T) {
+
+	// The fake returns 10 items for each GetMany
+	tests := []struct {
+		name  string
+		keys  int
+		match string
+		want  int
+		pages int
+	}{
+		{name: "empty", pages: 1},
+		{name: "one page", keys: 10, want: 10, pages: 1},
+		{name: "one more", keys: 11, want: 11, pages: 2},
+		{name: "pages", keys: 25, want: 25, pages: 3},
+		{name: "match", keys: 25, match: "key_1*", want: 11, pages: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctx := context.Background()
+			client, device := newClient(t)
+
+			for i := 0; i < tt.keys; i++ {
+				_, err := client.Set(ctx, fmt.Sprintf("key_%d", i), i)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			before := len(device.Calls())
+
+			items, err := client.GetMany(ctx, tt.match)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(items) != tt.want {
+				t.Errorf("%d items, want %d", len(items), tt.want)
+			}
+
+			seen := make(map[string]bool)
+			for _, v := range items {
+				if seen[v.Key] {
+					t.Errorf("key %s returned twice", v.Key)
+				}
+				seen[v.Key] = true
+			}
+
+			if pages := countCalls(device, "KVS.GetMany", before); pages != tt.pages {
+				t.Errorf("KVS.GetMany sent %d times, want %d", pages, tt.pages)
+			}
+		})
+	}
+}
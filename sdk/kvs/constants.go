@@ -0,0 +1,5 @@
+package kvs
+
+const (
+	Component = "KVS"
+)
@@ -0,0 +1,178 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+)
+
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+// Params internal use only
+type Params struct {
+	Key    *string         `json:"key,omitempty" yaml:"key,omitempty"`
+	Value  json.RawMessage `json:"value,omitempty" yaml:"value,omitempty"`
+	Etag   *string         `json:"etag,omitempty" yaml:"etag,omitempty"`
+	Match  *string         `json:"match,omitempty" yaml:"match,omitempty"`
+	Offset *int            `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+// Result internal use only
+type Result struct {
+	Etag  *string         `json:"etag,omitempty"`
+	Rev   *int            `json:"rev,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Item a key of the KVS with its etag and value. The value is any JSON value.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/KVS
+type Item struct {
+	// Key the key
+	Key string `json:"key" yaml:"key"`
+	// Etag changes each time the value is written. It can be used for conditional writes.
+	Etag *string `json:"etag,omitempty" yaml:"etag,omitempty"`
+	// Value the value as JSON. This is not set by List.
+	Value json.RawMessage `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// Decode decodes the value into v
+func (t *Item) Decode(v any) error {
+
+	if len(t.Value) == 0 {
+		return fmt.Errorf("key %s has no value", t.Key)
+	}
+
+	return json.Unmarshal(t.Value, v)
+}
+
+// Items a list of items. The device returns the items either as a list or as an object
+// keyed by key; both are decoded to a list sorted by key.
+type Items []*Item
+
+// UnmarshalJSON decodes the list or object form
+func (t *Items) UnmarshalJSON(b []byte) error {
+
+	var list []*Item
+	if json.Unmarshal(b, &list) == nil {
+		*t = list
+		return nil
+	}
+
+	// List returns only the keys in some versions
+	var keys []string
+	if json.Unmarshal(b, &keys) == nil {
+		items := Items{}
+		for _, key := range keys {
+			items = append(items, &Item{Key: key})
+		}
+		*t = items
+		return nil
+	}
+
+	m := make(map[string]*Item)
+	err := json.Unmarshal(b, &m)
+	if err != nil {
+		return err
+	}
+
+	items := Items{}
+	for key, v := range m {
+		if v == nil {
+			v = &Item{}
+		}
+		v.Key = key
+		items = append(items, v)
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+
+	*t = items
+	return nil
+}
+
+// GetManyResult internal use only
+type GetManyResult struct {
+	Items  Items `json:"items,omitempty"`
+	Offset *int  `json:"offset,omitempty"`
+	Total  *int  `json:"total,omitempty"`
+}
+
+// Keys the keys of the KVS returned by List
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/KVS#kvslist
+type Keys struct {
+	// Keys the keys with their etags
+	Keys Items `json:"keys,omitempty" yaml:"keys,omitempty"`
+	// Rev the current revision of the KVS
+	Rev *int `json:"rev,omitempty" yaml:"rev,omitempty"`
+}
+
+// Marshal returns the value as JSON. Maps decoded from YAML are converted so they can be
+// encoded.
+func Marshal(value any) (json.RawMessage, error) {
+	return json.Marshal(util.StringKeys(value))
+}
+
+// ValueEquals returns true if the JSON values are equal. The values are compared after
+// decoding so 1 and 1.0 and objects with the keys in a different order are equal.
+func ValueEquals(a, b json.RawMessage) bool {
+	return util.CompareString(normalize(a), normalize(b))
+}
+
+func normalize(value json.RawMessage) *string {
+
+	if len(value) == 0 {
+		return nil
+	}
+
+	var v interface{}
+	if json.Unmarshal(value, &v) != nil {
+		s := string(value)
+		return &s
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	s := string(b)
+	return &s
+}
+
+// Contains returns true if each key of x has the same value in t. Keys of t that are not
+// in x are not compared as the KVS is also written by scripts.
+func Contains(t, x map[string]interface{}) bool {
+
+	for key, v := range x {
+
+		k, ok := t[key]
+		if !ok {
+			zap.L().Info(fmt.Sprintf("KVS key %s not found", key))
+			return false
+		}
+
+		a, err := Marshal(k)
+		if err != nil {
+			return false
+		}
+
+		b, err := Marshal(v)
+		if err != nil {
+			return false
+		}
+
+		if !ValueEquals(a, b) {
+			zap.L().Info(fmt.Sprintf("KVS key %s not equal", key))
+			return false
+		}
+	}
+
+	return true
+}
@@ -2,13 +2,15 @@ package light
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 
 	"go.uber.org/zap"
 
 	"github.com/jodydadescott/shelly-client/sdk/light/types"
 	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
 	shelly_types "github.com/jodydadescott/shelly-client/sdk/shelly/types"
 )
 
@@ -19,9 +21,16 @@ type DeviceInfo = shelly_types.DeviceInfo
 
 type Config = types.Config
 type Status = types.Status
+type Params = types.Params
+type Result = types.Result
+
+// Deprecated: GetStatusResponse is no longer used by the client.
 type GetStatusResponse = types.GetStatusResponse
+
+// Deprecated: GetConfigResponse is no longer used by the client.
 type GetConfigResponse = types.GetConfigResponse
-type Params = types.Params
+
+// Deprecated: SetConfigResponse is no longer used by the client.
 type SetConfigResponse = types.SetConfigResponse
 
 type clientContract interface {
@@ -39,10 +48,14 @@ func New(clientContract clientContract) *Client {
 // Client the component client
 type Client struct {
 	clientContract
-	_messageHandler MessageHandler
+	messageHandlerMutex sync.Mutex
+	_messageHandler     MessageHandler
 }
 
 func (t *Client) getMessageHandler() MessageHandler {
+	t.messageHandlerMutex.Lock()
+	defer t.messageHandlerMutex.Unlock()
+
 	if t._messageHandler != nil {
 		return t._messageHandler
 	}
@@ -56,6 +69,12 @@ func getErr(method string, id *int, err error) error {
 		return nil
 	}
 
+	// An error returned by the device already has the method and device ID
+	rpcErr := &msg_types.Error{}
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
 	if id == nil {
 		return fmt.Errorf("component %s, method %s, error %w", Component, method, err)
 	}
@@ -68,32 +87,15 @@ func (t *Client) GetStatus(ctx context.Context, id int) (*Status, error) {
 
 	method := Component + ".GetStatus"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: &Params{
-			ID: id,
-		},
+	result, err := rpc.Call[*Params, *Status](ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
 	})
 
 	if err != nil {
 		return nil, getErr(method, &id, err)
 	}
 
-	response := &GetStatusResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return nil, getErr(method, &id, err)
-	}
-
-	if response.Error != nil {
-		return nil, getErr(method, &id, response.Error)
-	}
-
-	if response.Result == nil {
-		return nil, getErr(method, &id, fmt.Errorf("result is missing from response"))
-	}
-
-	return response.Result, nil
+	return result, nil
 }
 
 // GetConfig returns component config or error
@@ -101,31 +103,15 @@ func (t *Client) GetConfig(ctx context.Context, id int) (*Config, error) {
 
 	method := Component + ".GetConfig"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: &Params{
-			ID: id,
-		},
+	result, err := rpc.Call[*Params, *Config](ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
 	})
-	if err != nil {
-		return nil, getErr(method, &id, err)
-	}
 
-	response := &GetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
 	if err != nil {
 		return nil, getErr(method, &id, err)
 	}
 
-	if response.Error != nil {
-		return nil, getErr(method, &id, response.Error)
-	}
-
-	if response.Result == nil {
-		return nil, getErr(method, &id, fmt.Errorf("result is missing from response"))
-	}
-
-	return response.Result, nil
+	return result, nil
 }
 
 // SetConfig applies config to device component.
@@ -141,103 +127,34 @@ func (t *Client) SetConfig(ctx context.Context, config *Config) error {
 		config = config.Clone()
 	}
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: &Params{
-			ID:     *config.ID,
-			Config: config,
-		},
+	_, err := rpc.Call[*Params, *Result](ctx, t.getMessageHandler(), method, &Params{
+		ID:     *config.ID,
+		Config: config,
 	})
 
-	if err != nil {
-		return getErr(method, nil, err)
-	}
-
-	response := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return getErr(method, nil, err)
-	}
-
-	if response.Error != nil {
-		return getErr(method, nil, response.Error)
-	}
-
-	if response.Result == nil {
-		return getErr(method, nil, fmt.Errorf("result is missing from response"))
-	}
-
-	return nil
+	return getErr(method, nil, err)
 }
 
 func (t *Client) Set(ctx context.Context, id int, on *bool, brightness *float64) error {
 
 	method := Component + ".Set"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: &Params{
-			ID:         id,
-			On:         on,
-			Brightness: brightness,
-		},
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID:         id,
+		On:         on,
+		Brightness: brightness,
 	})
 
-	if err != nil {
-		return getErr(method, &id, err)
-	}
-
-	rawResponse := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, rawResponse)
-
-	if err != nil {
-		return getErr(method, &id, err)
-	}
-
-	response := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return getErr(method, &id, err)
-	}
-
-	if response.Error != nil {
-		return getErr(method, &id, response.Error)
-	}
-
-	return nil
+	return getErr(method, &id, err)
 }
 
 func (t *Client) Toggle(ctx context.Context, id int) error {
 
 	method := Component + ".Toggle"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: &Params{
-			ID: id,
-		},
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
 	})
 
-	if err != nil {
-		return getErr(method, &id, err)
-	}
-
-	rawResponse := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, rawResponse)
-
-	if err != nil {
-		return getErr(method, &id, err)
-	}
-
-	response := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return getErr(method, &id, err)
-	}
-
-	if response.Error != nil {
-		return getErr(method, &id, response.Error)
-	}
-
-	return nil
+	return getErr(method, &id, err)
 }
@@ -0,0 +1,13 @@
+package cassette
+
+import (
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/cassette/types"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+type Entry = types.Entry
+type Error = msg_types.Error
+type Request = msg_types.Request
+type MessageHandlerFactory = msg_types.MessageHandlerFactory
+type MessageHandler = msg_types.MessageHandler
+type Connector = msg_types.Connector
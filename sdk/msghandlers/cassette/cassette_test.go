@@ -0,0 +1,188 @@
+package cassette_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/cassette"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+type params struct {
+	ID *int  `json:"id,omitempty"`
+	On *bool `json:"on,omitempty"`
+}
+
+type exchange struct {
+	method   string
+	params   interface{}
+	response []byte
+	err      error
+}
+
+func newRequest(method string, params interface{}) *msg_types.Request {
+	return &msg_types.Request{Method: &method, Params: params}
+}
+
+// record sends the requests to a fake device through a Recorder that writes to the file
+// and keeps the response or error of each request
+func record(t *testing.T, filename string, requests []*exchange) {
+
+	device := fake.NewDevice(&fake.DeviceConfig{Switch: 1})
+
+	recorder, err := cassette.NewFileRecorder(fake.New(nil, device), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer recorder.Close()
+
+	handle := recorder.NewHandle("Test")
+
+	for _, r := range requests {
+		r.response, r.err = handle.Send(context.Background(), newRequest(r.method, r.params))
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+
+	id0 := 0
+	id5 := 5
+	on := true
+
+	exchanges := []*exchange{
+		{method: "Shelly.GetDeviceInfo"},
+		{method: "Switch.GetStatus", params: &params{ID: &id0}},
+		{method: "Switch.Set", params: &params{ID: &id0, On: &on}},
+		// The same request again must replay the second response and not the first
+		{method: "Switch.GetStatus", params: &params{ID: &id0}},
+		{method: "Switch.GetStatus", params: &params{ID: &id5}},
+	}
+
+	filename := filepath.Join(t.TempDir(), "cassette.jsonl")
+	record(t, filename, exchanges)
+
+	if exchanges[4].err == nil {
+		t.Fatalf("recorded GetStatus of a missing switch did not fail")
+	}
+
+	if bytes.Equal(exchanges[1].response, exchanges[3].response) {
+		t.Fatalf("recorded GetStatus before and after Set are the same")
+	}
+
+	replay, err := cassette.NewFileReplay(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer replay.Close()
+
+	handle := replay.NewHandle("Test")
+
+	for i, want := range exchanges {
+
+		b, err := handle.Send(context.Background(), newRequest(want.method, want.params))
+
+		if want.err == nil {
+			if err != nil {
+				t.Fatalf("replay %d %s error %v, want nil", i, want.method, err)
+			}
+			if !bytes.Equal(b, want.response) {
+				t.Errorf("replay %d %s response %s, want %s", i, want.method, string(b), string(want.response))
+			}
+			continue
+		}
+
+		wantErr := &msg_types.Error{}
+		if !errors.As(want.err, &wantErr) {
+			t.Fatalf("recorded %d %s error %v is not an Error", i, want.method, want.err)
+		}
+
+		rpcErr := &msg_types.Error{}
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("replay %d %s error %v, want %v", i, want.method, err, want.err)
+		}
+
+		if rpcErr.Code != wantErr.Code || rpcErr.Message != wantErr.Message {
+			t.Errorf("replay %d %s error %v, want %v", i, want.method, rpcErr, wantErr)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+
+	id0 := 0
+	on := true
+
+	filename := filepath.Join(t.TempDir(), "cassette.jsonl")
+	record(t, filename, []*exchange{
+		{method: "Switch.Set", params: &params{ID: &id0, On: &on}},
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		params interface{}
+		match  bool
+	}{
+		{
+			name:   "same params",
+			method: "Switch.Set",
+			params: &params{ID: &id0, On: &on},
+			match:  true,
+		},
+		{
+			name:   "params in another order",
+			method: "Switch.Set",
+			params: json.RawMessage(`{ "on": true, "id": 0 }`),
+			match:  true,
+		},
+		{
+			name:   "params as map",
+			method: "Switch.Set",
+			params: map[string]interface{}{"on": true, "id": 0},
+			match:  true,
+		},
+		{
+			name:   "other params",
+			method: "Switch.Set",
+			params: map[string]interface{}{"on": false, "id": 0},
+		},
+		{
+			name:   "no params",
+			method: "Switch.Set",
+		},
+		{
+			name:   "other method",
+			method: "Switch.Toggle",
+			params: &params{ID: &id0, On: &on},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			replay, err := cassette.NewFileReplay(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer replay.Close()
+
+			_, err = replay.NewHandle("Test").Send(context.Background(), newRequest(tt.method, tt.params))
+
+			if tt.match && err != nil {
+				t.Errorf("error %v, want a match", err)
+			}
+
+			if !tt.match && err == nil {
+				t.Errorf("unmatched request did not fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package cassette
+
+const (
+	// maxLineSize is the largest entry that can be read from a cassette. Responses such
+	// as Shelly.GetConfig of a device with many components can be large.
+	maxLineSize = 4 * 1024 * 1024
+)
@@ -0,0 +1,146 @@
+package cassette
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Recorder is a MessageHandlerFactory that wraps another MessageHandlerFactory and writes
+// each request/response pair to a cassette. Credentials are not written as auth is added
+// by the wrapped transport. Params are written as sent so a cassette of SetConfig may
+// contain passwords set in the config. Notifications are not recorded.
+type Recorder struct {
+	factory     MessageHandlerFactory
+	writerMutex sync.Mutex
+	writer      io.Writer
+	closer      io.Closer
+	closeOnce   sync.Once
+}
+
+// NewRecorder returns a new Recorder that writes to writer. The writer is not closed by
+// Close.
+func NewRecorder(factory MessageHandlerFactory, writer io.Writer) *Recorder {
+	zap.L().Debug("NewRecorder")
+	return &Recorder{
+		factory: factory,
+		writer:  writer,
+	}
+}
+
+// NewFileRecorder returns a new Recorder that appends to the file. The file is created if
+// it does not exist and is closed by Close.
+func NewFileRecorder(factory MessageHandlerFactory, filename string) (*Recorder, error) {
+
+	zap.L().Debug(fmt.Sprintf("NewFileRecorder(%s)", filename))
+
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{
+		factory: factory,
+		writer:  f,
+		closer:  f,
+	}, nil
+}
+
+func (t *Recorder) IsAuthEnabled() bool {
+	return t.factory.IsAuthEnabled()
+}
+
+// Connect connects the wrapped MessageHandlerFactory if it supports connecting
+func (t *Recorder) Connect(ctx context.Context) error {
+	if connector, ok := t.factory.(Connector); ok {
+		return connector.Connect(ctx)
+	}
+	return nil
+}
+
+func (t *Recorder) Close() {
+	zap.L().Debug("(*Recorder) Close()")
+	t.closeOnce.Do(func() {
+		t.factory.Close()
+		if t.closer != nil {
+			t.closer.Close()
+		}
+	})
+}
+
+func (t *Recorder) NewHandle(name string) MessageHandler {
+	zap.L().Debug(fmt.Sprintf("(*Recorder) NewHandle(%s)", name))
+	return &recordingHandle{
+		recorder: t,
+		handle:   t.factory.NewHandle(name),
+	}
+}
+
+// write writes the entry as a single line so that concurrent writers to the same file do
+// not interleave
+func (t *Recorder) write(entry *Entry) {
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("cassette error %v", err))
+		return
+	}
+
+	t.writerMutex.Lock()
+	defer t.writerMutex.Unlock()
+
+	_, err = t.writer.Write(append(b, '\n'))
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("cassette error %v", err))
+	}
+}
+
+type recordingHandle struct {
+	recorder *Recorder
+	handle   MessageHandler
+}
+
+func (t *recordingHandle) Send(ctx context.Context, request *Request) ([]byte, error) {
+
+	entry := &Entry{
+		Time: time.Now(),
+	}
+
+	if request.Method != nil {
+		entry.Method = *request.Method
+	}
+
+	if request.Params != nil {
+		b, err := json.Marshal(request.Params)
+		if err != nil {
+			return nil, err
+		}
+		entry.Params = b
+	}
+
+	b, err := t.handle.Send(ctx, request)
+
+	entry.Latency = time.Since(entry.Time)
+
+	if err != nil {
+		rpcErr := &Error{}
+		if errors.As(err, &rpcErr) {
+			entry.Error = rpcErr
+		} else {
+			entry.Err = err.Error()
+		}
+	} else {
+		entry.Response = b
+	}
+
+	t.recorder.write(entry)
+
+	return b, err
+}
@@ -0,0 +1,180 @@
+package cassette
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Replay is a MessageHandlerFactory that serves responses from a cassette. No network is
+// used. A request is matched to the recorded entries by method and params. Entries with
+// the same method and params are served in the order they were recorded and the last one
+// is repeated once the others have been served.
+type Replay struct {
+	mutex   sync.Mutex
+	entries map[string][]*Entry
+}
+
+// NewReplay returns a new Replay with the cassette read from reader
+func NewReplay(reader io.Reader) (*Replay, error) {
+
+	zap.L().Debug("NewReplay")
+
+	t := &Replay{
+		entries: make(map[string][]*Entry),
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, maxLineSize), maxLineSize)
+
+	line := 0
+
+	for scanner.Scan() {
+
+		line++
+
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		entry := &Entry{}
+		err := json.Unmarshal(scanner.Bytes(), entry)
+		if err != nil {
+			return nil, fmt.Errorf("cassette line %d is not valid; %w", line, err)
+		}
+
+		key, err := getKey(entry.Method, entry.Params)
+		if err != nil {
+			return nil, fmt.Errorf("cassette line %d is not valid; %w", line, err)
+		}
+
+		t.entries[key] = append(t.entries[key], entry)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// NewFileReplay returns a new Replay with the cassette read from the file
+func NewFileReplay(filename string) (*Replay, error) {
+
+	zap.L().Debug(fmt.Sprintf("NewFileReplay(%s)", filename))
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return NewReplay(f)
+}
+
+// getKey returns the key used to match a request to entries. The params are normalized
+// so that the order of attributes does not matter.
+func getKey(method string, params json.RawMessage) (string, error) {
+
+	if len(params) == 0 {
+		return method, nil
+	}
+
+	var v interface{}
+	err := json.Unmarshal(params, &v)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return method + " " + string(b), nil
+}
+
+// next returns the next entry for the key or nil if there is none
+func (t *Replay) next(key string) *Entry {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	entries := t.entries[key]
+	if len(entries) == 0 {
+		return nil
+	}
+
+	entry := entries[0]
+	if len(entries) > 1 {
+		t.entries[key] = entries[1:]
+	}
+
+	return entry
+}
+
+func (t *Replay) IsAuthEnabled() bool {
+	return false
+}
+
+func (t *Replay) Close() {
+	zap.L().Debug("(*Replay) Close()")
+}
+
+func (t *Replay) NewHandle(name string) MessageHandler {
+	zap.L().Debug(fmt.Sprintf("(*Replay) NewHandle(%s)", name))
+	return &replayHandle{
+		replay: t,
+	}
+}
+
+type replayHandle struct {
+	replay *Replay
+}
+
+func (t *replayHandle) Send(ctx context.Context, request *Request) ([]byte, error) {
+
+	method := ""
+	if request.Method != nil {
+		method = *request.Method
+	}
+
+	var params json.RawMessage
+
+	if request.Params != nil {
+		b, err := json.Marshal(request.Params)
+		if err != nil {
+			return nil, err
+		}
+		params = b
+	}
+
+	key, err := getKey(method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := t.replay.next(key)
+	if entry == nil {
+		return nil, fmt.Errorf("cassette has no response for method %s with params %s", method, string(params))
+	}
+
+	if entry.Error != nil {
+		return nil, &Error{Code: entry.Error.Code, Message: entry.Error.Message, Method: method}
+	}
+
+	if entry.Err != "" {
+		return nil, errors.New(entry.Err)
+	}
+
+	return entry.Response, nil
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jinzhu/copier"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+type Error = msg_types.Error
+
+// Entry is a single request/response pair in a cassette. A cassette is a file with one
+// JSON encoded Entry per line.
+type Entry struct {
+	// Time the time the request was sent
+	Time time.Time `json:"time" yaml:"time"`
+	// Method the RPC method such as Switch.Set
+	Method string `json:"method" yaml:"method"`
+	// Params the JSON encoded params of the request if any
+	Params json.RawMessage `json:"params,omitempty" yaml:"params,omitempty"`
+	// Response the raw response frame if the request succeeded
+	Response json.RawMessage `json:"response,omitempty" yaml:"response,omitempty"`
+	// Latency the time taken to receive the response or error
+	Latency time.Duration `json:"latency" yaml:"latency"`
+	// Error the RPC error returned by the device if any
+	Error *Error `json:"error,omitempty" yaml:"error,omitempty"`
+	// Err the transport error if any
+	Err string `json:"err,omitempty" yaml:"err,omitempty"`
+}
+
+// Clone return copy
+func (t *Entry) Clone() *Entry {
+	c := &Entry{}
+	copier.Copy(&c, &t)
+	return c
+}
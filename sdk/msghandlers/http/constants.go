@@ -0,0 +1,18 @@
+package http
+
+import (
+	"time"
+)
+
+const (
+	HTTPScheme          = "http"
+	HTTPSScheme         = "https"
+	httpPath            = "/rpc"
+	defaultSendTimeout  = time.Duration(time.Second * 10)
+	defaultSendTrys     = 3
+	defaultRetryWait    = time.Duration(time.Millisecond * 250)
+	defaultRetryMaxWait = time.Duration(time.Second * 2)
+	defaultShellyUser   = "admin"
+	authHeader          = "WWW-Authenticate"
+	contentType         = "application/json"
+)
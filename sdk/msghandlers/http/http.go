@@ -0,0 +1,338 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	net_http "net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	logger "github.com/jodydadescott/jody-go-logger"
+	"go.uber.org/zap"
+
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/credentials"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/tlsconfig"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+type Config = client_types.Config
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+type MessageHandlerFactory = msg_types.MessageHandlerFactory
+type MessageHandler = msg_types.MessageHandler
+type Request = msg_types.Request
+type AuthResponse = msg_types.AuthResponse
+type AuthRequest = msg_types.AuthRequest
+type CredentialProvider = msg_types.CredentialProvider
+
+var (
+	ErrOutcomeUnknown = msg_types.ErrOutcomeUnknown
+	ErrTimeout        = msg_types.ErrTimeout
+	ErrUnauthorized   = msg_types.ErrUnauthorized
+	ErrDisconnected   = msg_types.ErrDisconnected
+)
+
+// Client is a stateless MessageHandlerFactory. Each request is sent to the device as a
+// JSON-RPC frame using an HTTP POST to /rpc. No connection is held open between requests.
+type Client struct {
+	config            *Config
+	authResponseMutex sync.RWMutex
+	idMutex           sync.Mutex
+	uniqID            int
+	url               string
+	httpClient        *net_http.Client
+	authResponse      *AuthResponse
+	credentials       CredentialProvider
+	timeouts          *msg_types.Timeouts
+}
+
+func New(config *Config) (MessageHandlerFactory, error) {
+	zap.L().Debug("New")
+
+	config = config.Clone()
+
+	if config.Hostname == "" {
+		return nil, fmt.Errorf("hostname is required")
+	}
+
+	if config.Username == "" {
+		config.Username = defaultShellyUser
+		zap.L().Debug(fmt.Sprintf("username is %s (default)", config.Username))
+	} else {
+		zap.L().Debug(fmt.Sprintf("username is %s (config)", config.Username))
+	}
+
+	if config.SendTimeout <= 0 {
+		config.SendTimeout = defaultSendTimeout
+		zap.L().Debug(fmt.Sprintf("sendTimeout is %s (default)", config.SendTimeout.String()))
+	} else {
+		zap.L().Debug(fmt.Sprintf("sendTimeout is %s (config)", config.SendTimeout.String()))
+	}
+
+	if config.RetryWait <= 0 {
+		config.RetryWait = defaultRetryWait
+		zap.L().Debug(fmt.Sprintf("retryWait is %s (default)", config.RetryWait.String()))
+	} else {
+		zap.L().Debug(fmt.Sprintf("retryWait is %s (config)", config.RetryWait.String()))
+	}
+
+	if config.RetryMaxWait <= 0 {
+		config.RetryMaxWait = defaultRetryMaxWait
+		zap.L().Debug(fmt.Sprintf("retryMaxWait is %s (default)", config.RetryMaxWait.String()))
+	} else {
+		zap.L().Debug(fmt.Sprintf("retryMaxWait is %s (config)", config.RetryMaxWait.String()))
+	}
+
+	if config.SendTrys <= 0 {
+		config.SendTrys = defaultSendTrys
+		zap.L().Debug(fmt.Sprintf("sendTrys is %d (default)", config.SendTrys))
+	} else {
+		zap.L().Debug(fmt.Sprintf("sendTrys is %d (config)", config.SendTrys))
+	}
+
+	if config.Password == "" {
+		zap.L().Debug("password is NOT set")
+	} else {
+		zap.L().Debug("password is set")
+	}
+
+	scheme, err := tlsconfig.GetScheme(config, HTTPScheme, HTTPSScheme)
+	if err != nil {
+		return nil, err
+	}
+
+	zap.L().Debug(fmt.Sprintf("scheme is %s", scheme))
+
+	theURL := url.URL{Scheme: scheme, Host: config.Hostname, Path: httpPath}
+
+	transport := net_http.DefaultTransport.(*net_http.Transport).Clone()
+
+	if tlsconfig.IsSecure(scheme) {
+		transport.TLSClientConfig, err = tlsconfig.New(config.TLS)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Client{
+		config:      config,
+		url:         theURL.String(),
+		httpClient:  &net_http.Client{Transport: transport},
+		credentials: credentials.NewClientProvider(config),
+		timeouts:    msg_types.NewTimeouts(config.SendTimeout, config.MethodTimeouts),
+	}, nil
+}
+
+func (t *Client) IsAuthEnabled() bool {
+	return t.getAuthResponse() != nil
+}
+
+func (t *Client) getAuthResponse() *AuthResponse {
+	t.authResponseMutex.RLock()
+	defer t.authResponseMutex.RUnlock()
+	return t.authResponse
+}
+
+func (t *Client) setAuthResponse(authResponse *AuthResponse) {
+	t.authResponseMutex.Lock()
+	defer t.authResponseMutex.Unlock()
+	t.authResponse = authResponse
+}
+
+func (t *Client) nextID() int {
+	t.idMutex.Lock()
+	defer t.idMutex.Unlock()
+	t.uniqID = t.uniqID + 1
+	return t.uniqID
+}
+
+func (t *Client) Close() {
+	zap.L().Debug("(*Client) Close()")
+	t.httpClient.CloseIdleConnections()
+}
+
+func (t *Client) NewHandle(name string) MessageHandler {
+	zap.L().Debug(fmt.Sprintf("(*Client) NewHandle(%s)", name))
+	return &Handle{
+		client: t,
+		name:   name,
+	}
+}
+
+type Handle struct {
+	client *Client
+	name   string
+}
+
+// httpResponse is the result of a single POST. If the device requires authentication
+// authRequest will be set and rawBytes will be nil.
+type httpResponse struct {
+	response    *Response
+	rawBytes    []byte
+	authRequest *AuthRequest
+}
+
+// post sends the request and waits at most timeout for each POST. A failed POST is sent
+// again only if retryable is true. The wait between attempts starts at RetryWait and
+// doubles up to RetryMaxWait with jitter. If it is not and the request may have reached
+// the device ErrOutcomeUnknown is returned.
+func (t *Client) post(ctx context.Context, requestBytes []byte, retryable bool, timeout time.Duration) (*httpResponse, error) {
+
+	postOnce := func() (*httpResponse, error) {
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		if logger.Wire {
+			zap.L().Debug(fmt.Sprintf("TX->%s", string(requestBytes)))
+		}
+
+		httpRequest, err := net_http.NewRequestWithContext(ctx, net_http.MethodPost, t.url, bytes.NewReader(requestBytes))
+		if err != nil {
+			return nil, err
+		}
+
+		httpRequest.Header.Set("Content-Type", contentType)
+
+		resp, err := t.httpClient.Do(httpRequest)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil, fmt.Errorf("%w waiting for response; %w", ErrTimeout, err)
+			}
+			return nil, err
+		}
+
+		defer resp.Body.Close()
+
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if logger.Wire {
+			zap.L().Debug(fmt.Sprintf("RX->%s", string(b)))
+		}
+
+		if resp.StatusCode == net_http.StatusUnauthorized {
+			authRequest, err := msg_types.ParseAuthChallenge(resp.Header.Get(authHeader))
+			if err != nil {
+				return nil, err
+			}
+			return &httpResponse{authRequest: authRequest}, nil
+		}
+
+		msg := &Response{}
+		err = json.Unmarshal(b, msg)
+		if err != nil {
+			return nil, fmt.Errorf("http status %d; %w", resp.StatusCode, err)
+		}
+
+		return &httpResponse{
+			response: msg,
+			rawBytes: b,
+		}, nil
+	}
+
+	counter := 0
+	backoff := msg_types.NewBackoff(t.config.RetryWait, t.config.RetryMaxWait)
+
+	for {
+
+		response, err := postOnce()
+		if err == nil {
+			return response, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("channel closed by caller; %w", ctx.Err())
+		}
+
+		if !retryable {
+			if isDialError(err) {
+				return nil, err
+			}
+			zap.L().Debug("request is not safe to retry; giving up")
+			return nil, fmt.Errorf("%w; %w", ErrOutcomeUnknown, err)
+		}
+
+		if counter >= t.config.SendTrys {
+			zap.L().Debug(fmt.Sprintf("try %d of %d; giving up", counter, t.config.SendTrys))
+			return nil, err
+		}
+
+		zap.L().Debug(fmt.Sprintf("try %d of %d failed with error %v; will try again", counter, t.config.SendTrys, err))
+		counter++
+
+		if backoff.Wait(ctx) != nil {
+			return nil, fmt.Errorf("channel closed by caller; %w", ctx.Err())
+		}
+	}
+}
+
+// isDialError returns true if the error occurred before the request was written
+func isDialError(err error) bool {
+	opErr := &net.OpError{}
+	if errors.As(err, &opErr) {
+		return opErr.Op == "dial"
+	}
+	return false
+}
+
+func (t *Handle) Send(ctx context.Context, request *Request) ([]byte, error) {
+
+	zap.L().Debug("(*Handle) Send(ctx, *Request)")
+
+	request = request.Clone()
+	id := t.client.nextID()
+	request.ID = &id
+
+	request.Auth = t.client.getAuthResponse()
+
+	if request.Auth != nil {
+		zap.L().Debug("Using previous auth")
+	} else {
+		zap.L().Debug("Auth is not set")
+	}
+
+	timeout := t.client.timeouts.Get(ctx, request.Method)
+
+	return msg_types.RetryWithAuth(ctx, request, t.client.credentials, t.client.config.Hostname, t.client.setAuthResponse,
+		func(ctx context.Context, request *Request) (*Response, []byte, error) {
+			return t.sendOnce(ctx, request, timeout)
+		})
+}
+
+// sendOnce posts the request once and returns the response. An auth challenge received
+// in the header is returned as a response with the 401 error the other transports receive.
+func (t *Handle) sendOnce(ctx context.Context, request *Request, timeout time.Duration) (*Response, []byte, error) {
+
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response, err := t.client.post(ctx, requestBytes, msg_types.IsRetryable(ctx, request), timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if response.authRequest != nil {
+
+		b, err := json.Marshal(response.authRequest)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &Response{Error: &Error{Code: msg_types.ErrorCodeUnauthorized, Message: string(b)}}, nil, nil
+	}
+
+	return response.response, response.rawBytes, nil
+}
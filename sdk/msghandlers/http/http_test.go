@@ -0,0 +1,194 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	net_http "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/http"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+// TestRetry sends a request to a device that does not respond to the first POST in time.
+// A safe method is sent again and succeeds. A method that changes the state of the device
+// is sent once and fails with ErrOutcomeUnknown.
+func TestRetry(t *testing.T) {
+
+	tests := []struct {
+		method         string
+		posts          int32
+		outcomeUnknown bool
+	}{
+		{method: "Switch.GetStatus", posts: 2},
+		{method: "Switch.Set", posts: 1, outcomeUnknown: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+
+			var posts int32
+
+			server := httptest.NewServer(net_http.HandlerFunc(func(w net_http.ResponseWriter, r *net_http.Request) {
+
+				b, _ := io.ReadAll(r.Body)
+
+				if atomic.AddInt32(&posts, 1) == 1 {
+					// No response in time to the first POST
+					select {
+					case <-r.Context().Done():
+					case <-time.After(time.Second):
+					}
+					return
+				}
+
+				request := &struct {
+					ID int `json:"id"`
+				}{}
+
+				json.Unmarshal(b, request)
+
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"id":     request.ID,
+					"src":    "device",
+					"result": map[string]interface{}{},
+				})
+			}))
+
+			defer server.Close()
+
+			client, err := http.New(&client_types.Config{
+				Hostname:       strings.TrimPrefix(server.URL, "http://"),
+				MethodTimeouts: map[string]time.Duration{"*": time.Millisecond * 100},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer client.Close()
+
+			method := tt.method
+			_, err = client.NewHandle("Switch").Send(context.Background(), &msg_types.Request{
+				Method: &method,
+				Params: map[string]int{"id": 0},
+			})
+
+			if tt.outcomeUnknown {
+				if !errors.Is(err, msg_types.ErrOutcomeUnknown) {
+					t.Errorf("error %v, want ErrOutcomeUnknown", err)
+				}
+			} else if err != nil {
+				t.Errorf("error %v, want nil", err)
+			}
+
+			if got := atomic.LoadInt32(&posts); got != tt.posts {
+				t.Errorf("posts %d, want %d", got, tt.posts)
+			}
+		})
+	}
+}
+
+// TestRetryBackoff checks that a safe method is sent again after a wait that starts at
+// RetryWait and doubles up to RetryMaxWait and that the wait ends when the context is done
+func TestRetryBackoff(t *testing.T) {
+
+	const (
+		retryWait    = time.Millisecond * 40
+		retryMaxWait = time.Millisecond * 80
+	)
+
+	tests := []struct {
+		name      string
+		retryWait time.Duration
+		deadline  time.Duration
+		posts     int
+		wantErr   error
+	}{
+		{name: "backoff", retryWait: retryWait, posts: 4},
+		{name: "cancelled", retryWait: time.Hour, deadline: time.Millisecond * 100, posts: 1, wantErr: context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var mutex sync.Mutex
+			var posts []time.Time
+
+			// The device fails every POST
+			server := httptest.NewServer(net_http.HandlerFunc(func(w net_http.ResponseWriter, r *net_http.Request) {
+				mutex.Lock()
+				posts = append(posts, time.Now())
+				mutex.Unlock()
+				net_http.Error(w, "unavailable", net_http.StatusServiceUnavailable)
+			}))
+
+			defer server.Close()
+
+			client, err := http.New(&client_types.Config{
+				Hostname:     strings.TrimPrefix(server.URL, "http://"),
+				SendTrys:     3,
+				RetryWait:    tt.retryWait,
+				RetryMaxWait: retryMaxWait,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer client.Close()
+
+			ctx := context.Background()
+			if tt.deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+				defer cancel()
+			}
+
+			start := time.Now()
+
+			method := "Switch.GetStatus"
+			_, err = client.NewHandle("Switch").Send(ctx, &msg_types.Request{
+				Method: &method,
+				Params: map[string]int{"id": 0},
+			})
+
+			if err == nil {
+				t.Fatal("error nil, want error")
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.deadline > 0 && time.Since(start) > time.Second {
+				t.Errorf("failed after %v, want when the context is done", time.Since(start))
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if len(posts) != tt.posts {
+				t.Fatalf("posts %d, want %d", len(posts), tt.posts)
+			}
+
+			// The wait before post i+1 is between half and all of min(RetryWait*2^i, RetryMaxWait)
+			wait := tt.retryWait
+			for i := 1; i < len(posts); i++ {
+				if gap := posts[i].Sub(posts[i-1]); gap < wait/2 {
+					t.Errorf("post %d after %v, want at least %v", i+1, gap, wait/2)
+				}
+				wait = wait * 2
+				if wait > retryMaxWait {
+					wait = retryMaxWait
+				}
+			}
+		})
+	}
+}
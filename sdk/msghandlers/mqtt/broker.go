@@ -0,0 +1,129 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"go.uber.org/zap"
+)
+
+// Broker is the subset of an MQTT client used by the transport. It is implemented
+// with paho for real brokers and can be implemented by a local stand-in for testing.
+type Broker interface {
+	// Connect connects to the broker
+	Connect(ctx context.Context) error
+	// Subscribe subscribes to topic. The callback is called for each message received.
+	Subscribe(ctx context.Context, topic string, callback func(topic string, payload []byte)) error
+	// Publish publishes payload to topic
+	Publish(ctx context.Context, topic string, payload []byte) error
+	// Disconnect disconnects from the broker
+	Disconnect()
+	// IsConnected returns true if connected to the broker. A broker that reconnects on
+	// its own and restores the subscriptions returns true while reconnecting.
+	IsConnected() bool
+}
+
+type pahoBroker struct {
+	client             paho.Client
+	subscriptionsMutex sync.Mutex
+	subscriptions      map[string]func(topic string, payload []byte)
+}
+
+// NewPahoBroker returns a Broker backed by the paho MQTT client. The client reconnects
+// on its own when the connection is lost. The session is clean so the subscriptions are
+// made again each time the client connects.
+func NewPahoBroker(config *MqttTransportConfig) Broker {
+
+	t := &pahoBroker{
+		subscriptions: make(map[string]func(topic string, payload []byte)),
+	}
+
+	opts := paho.NewClientOptions().AddBroker(config.Broker).SetClientID(config.ClientID)
+	opts.SetAutoReconnect(true)
+	opts.SetOnConnectHandler(t.onConnect)
+	opts.SetConnectionLostHandler(func(client paho.Client, err error) {
+		zap.L().Debug(fmt.Sprintf("connection to broker lost; %v", err))
+	})
+
+	if config.Username != "" {
+		opts.SetUsername(config.Username)
+	}
+
+	if config.Password != "" {
+		opts.SetPassword(config.Password)
+	}
+
+	t.client = paho.NewClient(opts)
+	return t
+}
+
+// onConnect subscribes again to each topic as the subscriptions of a clean session are
+// lost when the client reconnects. It is called by paho in its own goroutine.
+func (t *pahoBroker) onConnect(client paho.Client) {
+
+	t.subscriptionsMutex.Lock()
+	defer t.subscriptionsMutex.Unlock()
+
+	for topic, callback := range t.subscriptions {
+		zap.L().Debug(fmt.Sprintf("Subscribing to %s again", topic))
+		token := client.Subscribe(topic, qos, pahoCallback(callback))
+		if token.Wait() && token.Error() != nil {
+			zap.L().Error(fmt.Sprintf("subscribe to %s failed with error %v", topic, token.Error()))
+		}
+	}
+}
+
+func pahoCallback(callback func(topic string, payload []byte)) paho.MessageHandler {
+	return func(client paho.Client, msg paho.Message) {
+		callback(msg.Topic(), msg.Payload())
+	}
+}
+
+func waitToken(ctx context.Context, token paho.Token) error {
+
+	select {
+
+	case <-ctx.Done():
+		return fmt.Errorf("channel closed by caller; %w", ctx.Err())
+
+	case <-token.Done():
+		return token.Error()
+
+	}
+}
+
+func (t *pahoBroker) Connect(ctx context.Context) error {
+	zap.L().Debug("(*pahoBroker) Connect()")
+	return waitToken(ctx, t.client.Connect())
+}
+
+func (t *pahoBroker) Subscribe(ctx context.Context, topic string, callback func(topic string, payload []byte)) error {
+	zap.L().Debug(fmt.Sprintf("(*pahoBroker) Subscribe(%s)", topic))
+
+	t.subscriptionsMutex.Lock()
+	t.subscriptions[topic] = callback
+	t.subscriptionsMutex.Unlock()
+
+	return waitToken(ctx, t.client.Subscribe(topic, qos, pahoCallback(callback)))
+}
+
+func (t *pahoBroker) Publish(ctx context.Context, topic string, payload []byte) error {
+	return waitToken(ctx, t.client.Publish(topic, qos, false, payload))
+}
+
+func (t *pahoBroker) Disconnect() {
+	zap.L().Debug("(*pahoBroker) Disconnect()")
+
+	t.subscriptionsMutex.Lock()
+	t.subscriptions = make(map[string]func(topic string, payload []byte))
+	t.subscriptionsMutex.Unlock()
+
+	t.client.Disconnect(uint(time.Second.Milliseconds()))
+}
+
+func (t *pahoBroker) IsConnected() bool {
+	return t.client.IsConnected()
+}
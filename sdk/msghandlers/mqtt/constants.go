@@ -0,0 +1,15 @@
+package mqtt
+
+import (
+	"time"
+)
+
+const (
+	eventsTopicSuffix     = "/events/rpc"
+	rpcTopicSuffix        = "/rpc"
+	defaultSendTimeout    = time.Duration(time.Second * 10)
+	defaultSendTrys       = 3
+	defaultShellyUser     = "admin"
+	defaultClientIDPrefix = "shelly-client-"
+	qos                   = 1
+)
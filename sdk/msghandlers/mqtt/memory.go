@@ -0,0 +1,131 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MemoryBroker is an in-memory stand-in for an MQTT broker for testing. Each party such
+// as the transport and a simulated device gets its own connection from NewConn. Messages
+// are delivered in the goroutine of the publisher. Topic filters may use the + and #
+// wildcards. Like a clean session the subscriptions of a connection are removed when it
+// is disconnected or dropped.
+type MemoryBroker struct {
+	mutex sync.Mutex
+	conns map[*MemoryConn]bool
+}
+
+// NewMemoryBroker returns a new MemoryBroker
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		conns: make(map[*MemoryConn]bool),
+	}
+}
+
+// NewConn returns a new connection to the broker. The connection implements Broker and
+// must be connected before it is used.
+func (t *MemoryBroker) NewConn() *MemoryConn {
+	return &MemoryConn{
+		broker:        t,
+		subscriptions: make(map[string]func(topic string, payload []byte)),
+	}
+}
+
+// MemoryConn is a connection to a MemoryBroker
+type MemoryConn struct {
+	broker        *MemoryBroker
+	subscriptions map[string]func(topic string, payload []byte)
+}
+
+func (t *MemoryConn) Connect(ctx context.Context) error {
+	t.broker.mutex.Lock()
+	defer t.broker.mutex.Unlock()
+	t.broker.conns[t] = true
+	return nil
+}
+
+func (t *MemoryConn) Subscribe(ctx context.Context, topic string, callback func(topic string, payload []byte)) error {
+
+	t.broker.mutex.Lock()
+	defer t.broker.mutex.Unlock()
+
+	if !t.broker.conns[t] {
+		return fmt.Errorf("%w; not connected to broker", ErrDisconnected)
+	}
+
+	t.subscriptions[topic] = callback
+	return nil
+}
+
+func (t *MemoryConn) Publish(ctx context.Context, topic string, payload []byte) error {
+
+	t.broker.mutex.Lock()
+
+	if !t.broker.conns[t] {
+		t.broker.mutex.Unlock()
+		return fmt.Errorf("%w; not connected to broker", ErrDisconnected)
+	}
+
+	var callbacks []func(topic string, payload []byte)
+
+	for conn := range t.broker.conns {
+		for filter, callback := range conn.subscriptions {
+			if matchTopic(filter, topic) {
+				callbacks = append(callbacks, callback)
+			}
+		}
+	}
+
+	t.broker.mutex.Unlock()
+
+	for _, callback := range callbacks {
+		callback(topic, append([]byte{}, payload...))
+	}
+
+	return nil
+}
+
+func (t *MemoryConn) Disconnect() {
+	t.Drop()
+}
+
+func (t *MemoryConn) IsConnected() bool {
+	t.broker.mutex.Lock()
+	defer t.broker.mutex.Unlock()
+	return t.broker.conns[t]
+}
+
+// Drop simulates the loss of the connection to the broker. The subscriptions are removed.
+func (t *MemoryConn) Drop() {
+	t.broker.mutex.Lock()
+	defer t.broker.mutex.Unlock()
+	delete(t.broker.conns, t)
+	t.subscriptions = make(map[string]func(topic string, payload []byte))
+}
+
+// matchTopic returns true if the topic matches the filter. The filter may use the +
+// wildcard for a single level and the # wildcard for the remaining levels.
+func matchTopic(filter string, topic string) bool {
+
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+
+	for i, level := range filterLevels {
+
+		if level == "#" {
+			return true
+		}
+
+		if i >= len(topicLevels) {
+			return false
+		}
+
+		if level != "+" && level != topicLevels[i] {
+			return false
+		}
+	}
+
+	return len(filterLevels) == len(topicLevels)
+}
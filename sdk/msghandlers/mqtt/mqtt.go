@@ -0,0 +1,432 @@
+package mqtt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	logger "github.com/jodydadescott/jody-go-logger"
+	"go.uber.org/zap"
+
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/credentials"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/notification"
+)
+
+type Config = client_types.Config
+type MqttTransportConfig = client_types.MqttTransportConfig
+type Response = msg_types.Response
+
+type MessageHandlerFactory = msg_types.MessageHandlerFactory
+type MessageHandler = msg_types.MessageHandler
+type Request = msg_types.Request
+type AuthResponse = msg_types.AuthResponse
+type AuthRequest = msg_types.AuthRequest
+type CredentialProvider = msg_types.CredentialProvider
+type Notification = notification.Notification
+type NotificationFilter = notification.Filter
+
+var (
+	ErrOutcomeUnknown = msg_types.ErrOutcomeUnknown
+	ErrTimeout        = msg_types.ErrTimeout
+	ErrUnauthorized   = msg_types.ErrUnauthorized
+	ErrDisconnected   = msg_types.ErrDisconnected
+)
+
+// Client is a MessageHandlerFactory that sends RPC requests to the device through an
+// MQTT broker. Requests are published to <TopicPrefix>/rpc and responses are received
+// on <Src>/rpc. Responses are correlated to requests by ID. Notifications are received
+// on <TopicPrefix>/events/rpc.
+type Client struct {
+	config            *Config
+	broker            Broker
+	requestTopic      string
+	responseTopic     string
+	eventsTopic       string
+	subscribers       *notification.Subscribers
+	authResponseMutex sync.RWMutex
+	pendingMutex      sync.Mutex
+	pending           map[int]chan *responseWrapper
+	uniqID            int
+	connectMutex      sync.Mutex
+	connected         bool
+	done              chan struct{}
+	closeOnce         sync.Once
+	authResponse      *AuthResponse
+	credentials       CredentialProvider
+	timeouts          *msg_types.Timeouts
+}
+
+// New returns a new MessageHandlerFactory using the paho MQTT client
+func New(config *Config) (MessageHandlerFactory, error) {
+	config, err := loadConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(config, NewPahoBroker(config.MqttTransport)), nil
+}
+
+// NewWithBroker returns a new MessageHandlerFactory using the specified broker. This
+// can be used with a local broker stand-in.
+func NewWithBroker(config *Config, broker Broker) (MessageHandlerFactory, error) {
+	config, err := loadConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(config, broker), nil
+}
+
+func loadConfig(config *Config) (*Config, error) {
+
+	zap.L().Debug("New")
+
+	config = config.Clone()
+
+	if config.MqttTransport == nil {
+		return nil, fmt.Errorf("mqttTransport config is required")
+	}
+
+	if config.MqttTransport.TopicPrefix == "" {
+		if config.Hostname == "" {
+			return nil, fmt.Errorf("hostname or topicPrefix is required")
+		}
+		config.MqttTransport.TopicPrefix = config.Hostname
+		zap.L().Debug(fmt.Sprintf("topicPrefix is %s (hostname)", config.MqttTransport.TopicPrefix))
+	} else {
+		zap.L().Debug(fmt.Sprintf("topicPrefix is %s (config)", config.MqttTransport.TopicPrefix))
+	}
+
+	if config.MqttTransport.ClientID == "" {
+		config.MqttTransport.ClientID = defaultClientIDPrefix + getRandomID()
+		zap.L().Debug(fmt.Sprintf("clientID is %s (default)", config.MqttTransport.ClientID))
+	} else {
+		zap.L().Debug(fmt.Sprintf("clientID is %s (config)", config.MqttTransport.ClientID))
+	}
+
+	if config.MqttTransport.Src == "" {
+		config.MqttTransport.Src = config.MqttTransport.ClientID
+		zap.L().Debug(fmt.Sprintf("src is %s (default)", config.MqttTransport.Src))
+	} else {
+		zap.L().Debug(fmt.Sprintf("src is %s (config)", config.MqttTransport.Src))
+	}
+
+	if config.Username == "" {
+		config.Username = defaultShellyUser
+		zap.L().Debug(fmt.Sprintf("username is %s (default)", config.Username))
+	} else {
+		zap.L().Debug(fmt.Sprintf("username is %s (config)", config.Username))
+	}
+
+	if config.SendTimeout <= 0 {
+		config.SendTimeout = defaultSendTimeout
+		zap.L().Debug(fmt.Sprintf("sendTimeout is %s (default)", config.SendTimeout.String()))
+	} else {
+		zap.L().Debug(fmt.Sprintf("sendTimeout is %s (config)", config.SendTimeout.String()))
+	}
+
+	if config.SendTrys <= 0 {
+		config.SendTrys = defaultSendTrys
+		zap.L().Debug(fmt.Sprintf("sendTrys is %d (default)", config.SendTrys))
+	} else {
+		zap.L().Debug(fmt.Sprintf("sendTrys is %d (config)", config.SendTrys))
+	}
+
+	if config.Password == "" {
+		zap.L().Debug("password is NOT set")
+	} else {
+		zap.L().Debug("password is set")
+	}
+
+	return config, nil
+}
+
+func newClient(config *Config, broker Broker) *Client {
+	return &Client{
+		config:        config,
+		broker:        broker,
+		requestTopic:  config.MqttTransport.TopicPrefix + rpcTopicSuffix,
+		responseTopic: config.MqttTransport.Src + rpcTopicSuffix,
+		eventsTopic:   config.MqttTransport.TopicPrefix + eventsTopicSuffix,
+		subscribers:   notification.NewSubscribers(),
+		pending:       make(map[int]chan *responseWrapper),
+		done:          make(chan struct{}),
+		credentials:   credentials.NewClientProvider(config),
+		timeouts:      msg_types.NewTimeouts(config.SendTimeout, config.MethodTimeouts),
+	}
+}
+
+func getRandomID() string {
+	b := make([]byte, 4)
+	io.ReadFull(rand.Reader, b)
+	return hex.EncodeToString(b)
+}
+
+func (t *Client) IsAuthEnabled() bool {
+	return t.getAuthResponse() != nil
+}
+
+func (t *Client) getAuthResponse() *AuthResponse {
+	t.authResponseMutex.RLock()
+	defer t.authResponseMutex.RUnlock()
+	return t.authResponse
+}
+
+func (t *Client) setAuthResponse(authResponse *AuthResponse) {
+	t.authResponseMutex.Lock()
+	defer t.authResponseMutex.Unlock()
+	t.authResponse = authResponse
+}
+
+// Subscribe returns a channel of notifications published by the device that match the
+// filter. The device must have RPC notifications over MQTT enabled. The channel is closed
+// when the context is cancelled or the client is closed.
+func (t *Client) Subscribe(ctx context.Context, filter *NotificationFilter) <-chan *Notification {
+	return t.subscribers.Subscribe(ctx, filter)
+}
+
+func (t *Client) Close() {
+	zap.L().Debug("(*Client) Close()")
+
+	t.closeOnce.Do(func() {
+		close(t.done)
+		t.subscribers.Close()
+
+		t.connectMutex.Lock()
+		defer t.connectMutex.Unlock()
+
+		if t.connected {
+			t.broker.Disconnect()
+			t.connected = false
+		}
+	})
+}
+
+// Connect connects to the broker and subscribes to the response topic if not already
+// connected. The connection is also established on the first request.
+func (t *Client) Connect(ctx context.Context) error {
+	return t.connect(ctx)
+}
+
+// connect connects to the broker and subscribes to the response and events topics if not
+// already connected. If the connection was lost and the broker did not restore it the
+// connection and subscriptions are made again.
+func (t *Client) connect(ctx context.Context) error {
+
+	t.connectMutex.Lock()
+	defer t.connectMutex.Unlock()
+
+	if t.connected {
+
+		if t.broker.IsConnected() {
+			return nil
+		}
+
+		zap.L().Debug("connection to broker lost; connecting again")
+		t.broker.Disconnect()
+		t.connected = false
+	}
+
+	select {
+	case <-t.done:
+		return fmt.Errorf("%w; channel closed shutdown", ErrDisconnected)
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.config.SendTimeout)
+	defer cancel()
+
+	zap.L().Debug(fmt.Sprintf("Connecting to %s", t.config.MqttTransport.Broker))
+
+	err := t.broker.Connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = t.broker.Subscribe(ctx, t.responseTopic, t.routeMessage)
+	if err != nil {
+		t.broker.Disconnect()
+		return err
+	}
+
+	err = t.broker.Subscribe(ctx, t.eventsTopic, t.routeNotification)
+	if err != nil {
+		t.broker.Disconnect()
+		return err
+	}
+
+	zap.L().Debug("Connected")
+
+	t.connected = true
+	return nil
+}
+
+func (t *Client) routeMessage(topic string, b []byte) {
+
+	if logger.Wire {
+		zap.L().Debug(fmt.Sprintf("RX->%s", string(b)))
+	}
+
+	msg := &Response{}
+	err := json.Unmarshal(b, msg)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("routeMessage error %v", err))
+		return
+	}
+
+	if msg.ID == nil {
+		zap.L().Debug(fmt.Sprintf("ignoring message without ID on topic %s", topic))
+		return
+	}
+
+	t.pendingMutex.Lock()
+	defer t.pendingMutex.Unlock()
+
+	receive := t.pending[*msg.ID]
+	if receive == nil {
+		zap.L().Debug(fmt.Sprintf("pending lookup ID %d failure", *msg.ID))
+		return
+	}
+
+	select {
+	case receive <- &responseWrapper{response: msg, rawBytes: b}:
+	default:
+		zap.L().Debug(fmt.Sprintf("pending ID %d already has a response", *msg.ID))
+	}
+}
+
+func (t *Client) routeNotification(topic string, b []byte) {
+
+	if logger.Wire {
+		zap.L().Debug(fmt.Sprintf("RX->%s", string(b)))
+	}
+
+	t.subscribers.Publish(b)
+}
+
+func (t *Client) addPending() (int, chan *responseWrapper) {
+	t.pendingMutex.Lock()
+	defer t.pendingMutex.Unlock()
+	t.uniqID = t.uniqID + 1
+	receive := make(chan *responseWrapper, 1)
+	t.pending[t.uniqID] = receive
+	return t.uniqID, receive
+}
+
+func (t *Client) removePending(id int) {
+	t.pendingMutex.Lock()
+	defer t.pendingMutex.Unlock()
+	delete(t.pending, id)
+}
+
+func (t *Client) NewHandle(name string) MessageHandler {
+	zap.L().Debug(fmt.Sprintf("(*Client) NewHandle(%s)", name))
+	return &Handle{
+		client: t,
+		name:   name,
+	}
+}
+
+type responseWrapper struct {
+	response *Response
+	rawBytes []byte
+}
+
+type Handle struct {
+	client *Client
+	name   string
+}
+
+// send publishes the request with a new ID and waits for the matching response
+// and is sent again on timeout only if the retry policy of the context allows it
+func (t *Handle) send(ctx context.Context, request *Request) (*responseWrapper, error) {
+
+	id, receive := t.client.addPending()
+	defer t.client.removePending(id)
+
+	request.ID = &id
+
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	retryable := msg_types.IsRetryable(ctx, request)
+	timeout := t.client.timeouts.Get(ctx, request.Method)
+	counter := 0
+
+	for {
+
+		if logger.Wire {
+			zap.L().Debug(fmt.Sprintf("TX->%s", string(requestBytes)))
+		}
+
+		err := t.client.broker.Publish(ctx, t.client.requestTopic, requestBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		select {
+
+		case response := <-receive:
+			return response, nil
+
+		case <-t.client.done:
+			return nil, fmt.Errorf("%w; channel closed shutdown", ErrDisconnected)
+
+		case <-ctx.Done():
+			return nil, fmt.Errorf("channel closed by caller; %w", ctx.Err())
+
+		case <-time.After(timeout):
+			if !retryable {
+				zap.L().Debug("request is not safe to retry; giving up")
+				return nil, fmt.Errorf("%w; %w waiting for response", ErrOutcomeUnknown, ErrTimeout)
+			}
+			if counter >= t.client.config.SendTrys {
+				zap.L().Debug(fmt.Sprintf("try %d of %d; giving up", counter, t.client.config.SendTrys))
+				return nil, fmt.Errorf("%w waiting for response", ErrTimeout)
+			}
+			zap.L().Debug(fmt.Sprintf("try %d of %d; will try again", counter, t.client.config.SendTrys))
+			counter++
+
+		}
+	}
+}
+
+func (t *Handle) Send(ctx context.Context, request *Request) ([]byte, error) {
+
+	zap.L().Debug("(*Handle) Send(ctx, *Request)")
+
+	err := t.client.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request = request.Clone()
+	request.Src = &t.client.config.MqttTransport.Src
+	request.Auth = t.client.getAuthResponse()
+
+	if request.Auth != nil {
+		zap.L().Debug("Using previous auth")
+	} else {
+		zap.L().Debug("Auth is not set")
+	}
+
+	return msg_types.RetryWithAuth(ctx, request, t.client.credentials, t.client.config.Hostname, t.client.setAuthResponse, t.sendOnce)
+}
+
+// sendOnce sends the request once and returns the response
+func (t *Handle) sendOnce(ctx context.Context, request *Request) (*Response, []byte, error) {
+
+	response, err := t.send(ctx, request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return response.response, response.rawBytes, nil
+}
@@ -0,0 +1,216 @@
+package mqtt_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/mqtt"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+// connectDevice connects the device to the broker the same way a device with RPC over
+// MQTT enabled is connected. Requests are received on <id>/rpc and the responses are
+// published to <dst>/rpc.
+func connectDevice(t *testing.T, broker *mqtt.MemoryBroker, device *fake.Device) *mqtt.MemoryConn {
+
+	ctx := context.Background()
+	conn := broker.NewConn()
+
+	err := conn.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = conn.Subscribe(ctx, device.ID()+"/rpc", func(topic string, payload []byte) {
+
+		b, err := device.Call(ctx, payload)
+		if err != nil {
+			return
+		}
+
+		response := &struct {
+			Dst string `json:"dst"`
+		}{}
+
+		err = json.Unmarshal(b, response)
+		if err != nil || response.Dst == "" {
+			return
+		}
+
+		conn.Publish(ctx, response.Dst+"/rpc", b)
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remove := device.AddNotificationHandler(func(b []byte) {
+		conn.Publish(ctx, device.ID()+"/events/rpc", b)
+	})
+
+	t.Cleanup(remove)
+	return conn
+}
+
+func newClient(t *testing.T, device *fake.Device, conn mqtt.Broker, password string) msg_types.MessageHandlerFactory {
+
+	client, err := mqtt.NewWithBroker(&client_types.Config{
+		Password:    password,
+		SendTimeout: time.Millisecond * 200,
+		MqttTransport: &client_types.MqttTransportConfig{
+			TopicPrefix: device.ID(),
+		},
+	}, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(client.Close)
+	return client
+}
+
+func getDeviceInfo(ctx context.Context, client msg_types.MessageHandlerFactory) (string, error) {
+
+	method := "Shelly.GetDeviceInfo"
+
+	b, err := client.NewHandle("Shelly").Send(ctx, &msg_types.Request{Method: &method})
+	if err != nil {
+		return "", err
+	}
+
+	response := &struct {
+		Result struct {
+			ID string `json:"id"`
+		} `json:"result"`
+	}{}
+
+	err = json.Unmarshal(b, response)
+	return response.Result.ID, err
+}
+
+func TestSend(t *testing.T) {
+
+	ctx := context.Background()
+	broker := mqtt.NewMemoryBroker()
+	device := fake.NewDevice(&fake.DeviceConfig{Switch: 1})
+	connectDevice(t, broker, device)
+
+	client := newClient(t, device, broker.NewConn(), "")
+
+	id, err := getDeviceInfo(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != device.ID() {
+		t.Errorf("id %s, want %s", id, device.ID())
+	}
+}
+
+func TestAuth(t *testing.T) {
+
+	ctx := context.Background()
+	broker := mqtt.NewMemoryBroker()
+	device := fake.NewDevice(&fake.DeviceConfig{Switch: 1, Password: "secret"})
+	connectDevice(t, broker, device)
+
+	method := "Switch.GetStatus"
+	request := &msg_types.Request{Method: &method, Params: map[string]int{"id": 0}}
+
+	client := newClient(t, device, broker.NewConn(), "secret")
+
+	_, err := client.NewHandle("Switch").Send(ctx, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !client.IsAuthEnabled() {
+		t.Errorf("auth is not enabled")
+	}
+
+	client = newClient(t, device, broker.NewConn(), "wrong")
+
+	_, err = client.NewHandle("Switch").Send(ctx, request)
+	if !errors.Is(err, msg_types.ErrUnauthorized) {
+		t.Errorf("error %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+
+	ctx := context.Background()
+	broker := mqtt.NewMemoryBroker()
+	device := fake.NewDevice(&fake.DeviceConfig{Switch: 1})
+	connectDevice(t, broker, device)
+
+	conn := broker.NewConn()
+	client := newClient(t, device, conn, "")
+
+	_, err := getDeviceInfo(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The connection is lost and with it the subscriptions
+	conn.Drop()
+
+	id, err := getDeviceInfo(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != device.ID() {
+		t.Errorf("id %s, want %s", id, device.ID())
+	}
+}
+
+func TestNotifications(t *testing.T) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	broker := mqtt.NewMemoryBroker()
+	device := fake.NewDevice(&fake.DeviceConfig{Input: 1})
+	connectDevice(t, broker, device)
+
+	factory, err := mqtt.NewWithBroker(&client_types.Config{
+		MqttTransport: &client_types.MqttTransportConfig{
+			TopicPrefix: device.ID(),
+		},
+	}, broker.NewConn())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := factory.(*mqtt.Client)
+	defer client.Close()
+
+	err = client.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notifications := client.Subscribe(ctx, nil)
+
+	err = device.Event("input:0", "single_push")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+
+	case n := <-notifications:
+		if n == nil || n.Method != "NotifyEvent" {
+			t.Errorf("notification %v, want NotifyEvent", n)
+		}
+
+	case <-ctx.Done():
+		t.Fatal("notification not received")
+
+	}
+}
@@ -1,8 +1,14 @@
 package msghandlers
 
 import (
+	"fmt"
+
 	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/cassette"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/http"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/mqtt"
 	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/udp"
 	"github.com/jodydadescott/shelly-client/sdk/msghandlers/ws"
 )
 
@@ -11,6 +17,66 @@ type Request = msg_types.Request
 type MessageHandlerFactory = msg_types.MessageHandlerFactory
 type MessageHandler = msg_types.MessageHandler
 
-func NewWS(config *Config) MessageHandlerFactory {
+// New returns a MessageHandlerFactory for the transport specified in the config. If the
+// transport is not set the websocket transport is used. If ReplayFile is set responses are
+// served from the cassette instead. If RecordFile is set the transport is wrapped with a
+// recorder.
+func New(config *Config) (MessageHandlerFactory, error) {
+
+	if config.ReplayFile != "" {
+		return cassette.NewFileReplay(config.ReplayFile)
+	}
+
+	factory, err := newTransport(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.RecordFile != "" {
+		recorder, err := cassette.NewFileRecorder(factory, config.RecordFile)
+		if err != nil {
+			factory.Close()
+			return nil, err
+		}
+		return recorder, nil
+	}
+
+	return factory, nil
+}
+
+func newTransport(config *Config) (MessageHandlerFactory, error) {
+
+	switch config.Transport {
+
+	case "", client_types.TransportWS:
+		return NewWS(config)
+
+	case client_types.TransportHTTP:
+		return NewHTTP(config)
+
+	case client_types.TransportMQTT:
+		return NewMQTT(config)
+
+	case client_types.TransportUDP:
+		return NewUDP(config)
+
+	}
+
+	return nil, fmt.Errorf("transport %s is not supported", config.Transport)
+}
+
+func NewWS(config *Config) (MessageHandlerFactory, error) {
 	return ws.New(config)
 }
+
+func NewHTTP(config *Config) (MessageHandlerFactory, error) {
+	return http.New(config)
+}
+
+func NewMQTT(config *Config) (MessageHandlerFactory, error) {
+	return mqtt.New(config)
+}
+
+func NewUDP(config *Config) (MessageHandlerFactory, error) {
+	return udp.New(config)
+}
@@ -0,0 +1,44 @@
+package msghandlers_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers"
+)
+
+// TestNewInvalidConfig checks that an invalid config is returned as an error by every
+// transport rather than a panic
+func TestNewInvalidConfig(t *testing.T) {
+
+	missing := &client_types.TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}
+
+	tests := []struct {
+		name   string
+		config *msghandlers.Config
+	}{
+		{name: "unknown transport", config: &msghandlers.Config{Hostname: "device.lan", Transport: "smoke"}},
+		{name: "ws no hostname", config: &msghandlers.Config{Transport: client_types.TransportWS}},
+		{name: "ws unknown scheme", config: &msghandlers.Config{Hostname: "device.lan", Scheme: "http"}},
+		{name: "ws invalid ca file", config: &msghandlers.Config{Hostname: "device.lan", TLS: missing}},
+		{name: "http no hostname", config: &msghandlers.Config{Transport: client_types.TransportHTTP}},
+		{name: "http unknown scheme", config: &msghandlers.Config{Hostname: "device.lan", Transport: client_types.TransportHTTP, Scheme: "wss"}},
+		{name: "http invalid ca file", config: &msghandlers.Config{Hostname: "device.lan", Transport: client_types.TransportHTTP, TLS: missing}},
+		{name: "udp no hostname", config: &msghandlers.Config{Transport: client_types.TransportUDP, UDPTransport: &client_types.UDPTransportConfig{Port: 1010}}},
+		{name: "udp no port", config: &msghandlers.Config{Hostname: "device.lan", Transport: client_types.TransportUDP}},
+		{name: "mqtt no config", config: &msghandlers.Config{Hostname: "device.lan", Transport: client_types.TransportMQTT}},
+		{name: "mqtt no topic prefix", config: &msghandlers.Config{Transport: client_types.TransportMQTT, MqttTransport: &client_types.MqttTransportConfig{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			factory, err := msghandlers.New(tt.config)
+			if err == nil {
+				factory.Close()
+				t.Errorf("error nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package tlsconfig
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+)
+
+type Config = client_types.Config
+type TLSConfig = client_types.TLSConfig
+
+const pinPrefix = "sha256/"
+
+// IsSecure returns true if the scheme is wss or https
+func IsSecure(scheme string) bool {
+	return scheme == client_types.SchemeWSS || scheme == client_types.SchemeHTTPS
+}
+
+// GetScheme returns the scheme from the config for a transport that supports the plain
+// and the secure scheme. If the scheme is not set the secure scheme is used if the TLS
+// config is set.
+func GetScheme(config *Config, plain, secure string) (string, error) {
+
+	switch strings.ToLower(config.Scheme) {
+
+	case "":
+		if config.TLS != nil {
+			return secure, nil
+		}
+		return plain, nil
+
+	case plain:
+		return plain, nil
+
+	case secure:
+		return secure, nil
+
+	}
+
+	return "", fmt.Errorf("scheme %s is not valid, expecting %s or %s", config.Scheme, plain, secure)
+}
+
+// New returns a tls.Config built from config. If config is nil the default tls.Config is
+// returned.
+func New(config *TLSConfig) (*tls.Config, error) {
+
+	tlsConfig := &tls.Config{}
+
+	if config == nil {
+		return tlsConfig, nil
+	}
+
+	if config.CAFile != "" {
+
+		b, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("caFile %s does not contain a PEM certificate", config.CAFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+
+		if config.CertFile == "" {
+			return nil, fmt.Errorf("certFile is required when keyFile is set")
+		}
+
+		if config.KeyFile == "" {
+			return nil, fmt.Errorf("keyFile is required when certFile is set")
+		}
+
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	tlsConfig.ServerName = config.ServerName
+	tlsConfig.InsecureSkipVerify = config.InsecureSkipVerify
+
+	if len(config.Pins) > 0 {
+
+		pins := make(map[string]bool)
+
+		for _, pin := range config.Pins {
+			pin = strings.TrimPrefix(pin, pinPrefix)
+			b, err := base64.StdEncoding.DecodeString(pin)
+			if err != nil || len(b) != sha256.Size {
+				return nil, fmt.Errorf("pin %s is not a base64 encoded SHA-256 hash", pin)
+			}
+			pins[pin] = true
+		}
+
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			for _, cert := range state.PeerCertificates {
+				if pins[GetPin(cert)] {
+					return nil
+				}
+			}
+			return fmt.Errorf("server certificate chain does not match any pin")
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// GetPin returns the base64 encoded SHA-256 hash of the SubjectPublicKeyInfo of the certificate
+func GetPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,132 @@
+package tlsconfig_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/tlsconfig"
+)
+
+func TestGetScheme(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		config  *client_types.Config
+		want    string
+		wantErr bool
+	}{
+		{name: "default", config: &client_types.Config{}, want: "ws"},
+		{name: "default with tls", config: &client_types.Config{TLS: &client_types.TLSConfig{}}, want: "wss"},
+		{name: "plain", config: &client_types.Config{Scheme: "WS"}, want: "ws"},
+		{name: "secure", config: &client_types.Config{Scheme: "wss"}, want: "wss"},
+		{name: "unknown", config: &client_types.Config{Scheme: "https"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			got, err := tlsconfig.GetScheme(tt.config, "ws", "wss")
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("scheme %s, want error", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("scheme %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+
+	dir := t.TempDir()
+
+	notPEM := filepath.Join(dir, "ca.txt")
+	err := os.WriteFile(notPEM, []byte("not a certificate"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		config  *client_types.TLSConfig
+		wantErr bool
+	}{
+		{name: "nil"},
+		{name: "pin", config: &client_types.TLSConfig{Pins: []string{"sha256/" + base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))}}},
+		{name: "missing ca file", config: &client_types.TLSConfig{CAFile: filepath.Join(dir, "missing.pem")}, wantErr: true},
+		{name: "ca file not PEM", config: &client_types.TLSConfig{CAFile: notPEM}, wantErr: true},
+		{name: "cert without key", config: &client_types.TLSConfig{CertFile: notPEM}, wantErr: true},
+		{name: "key without cert", config: &client_types.TLSConfig{KeyFile: notPEM}, wantErr: true},
+		{name: "malformed cert", config: &client_types.TLSConfig{CertFile: notPEM, KeyFile: notPEM}, wantErr: true},
+		{name: "pin not base64", config: &client_types.TLSConfig{Pins: []string{"sha256/not base64"}}, wantErr: true},
+		{name: "pin not SHA-256", config: &client_types.TLSConfig{Pins: []string{"sha256/AAAA"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			_, err := tlsconfig.New(tt.config)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error %v, error want %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPins(t *testing.T) {
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	pin := tlsconfig.GetPin(server.Certificate())
+	other := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	tests := []struct {
+		name    string
+		pins    []string
+		wantErr bool
+	}{
+		{name: "match", pins: []string{"sha256/" + pin}},
+		{name: "one of", pins: []string{other, pin}},
+		{name: "mismatch", pins: []string{other}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// The certificate of the test server is self signed so only the pin is verified
+			tlsConfig, err := tlsconfig.New(&client_types.TLSConfig{InsecureSkipVerify: true, Pins: tt.pins})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			defer client.CloseIdleConnections()
+
+			response, err := client.Get(server.URL)
+			if err == nil {
+				response.Body.Close()
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error %v, error want %t", err, tt.wantErr)
+			}
+		})
+	}
+}
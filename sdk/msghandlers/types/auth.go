@@ -0,0 +1,67 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// AuthSendFunc sends the request once and returns the response and the raw response bytes.
+// An auth challenge is returned as a response with an error of code 401 and the
+// AuthRequest as the message.
+type AuthSendFunc func(ctx context.Context, request *Request) (*Response, []byte, error)
+
+// RetryWithAuth sends the request using send. If the device responds with an auth
+// challenge the credentials for the hostname are used to answer it, the AuthResponse is
+// passed to setAuth so that later requests can use it and the request is sent again with
+// the auth set. The request is modified so the caller should pass a clone. An error
+// returned by the device has the device ID and method set.
+func RetryWithAuth(ctx context.Context, request *Request, credentials CredentialProvider, hostname string, setAuth func(*AuthResponse), send AuthSendFunc) ([]byte, error) {
+
+	response, b, err := send(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Error == nil {
+		return b, nil
+	}
+
+	if response.Error.Code != ErrorCodeUnauthorized {
+		return nil, response.Error.WithRequest(response.Src, request.Method)
+	}
+
+	authRequest := &AuthRequest{}
+	err = json.Unmarshal([]byte(response.Error.Message), authRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	err = authRequest.SetCredentials(ctx, credentials, hostname)
+	if err != nil {
+		return nil, err
+	}
+
+	authResponse, err := authRequest.ToAuthResponse()
+	if err != nil {
+		return nil, err
+	}
+
+	setAuth(authResponse)
+
+	request.Auth = authResponse
+
+	response, b, err = send(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Error != nil {
+		if response.Error.Code == ErrorCodeUnauthorized {
+			return nil, fmt.Errorf("%w; authentication failed", ErrUnauthorized)
+		}
+		return nil, response.Error.WithRequest(response.Src, request.Method)
+	}
+
+	return b, nil
+}
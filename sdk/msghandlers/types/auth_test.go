@@ -0,0 +1,109 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	credentials_types "github.com/jodydadescott/shelly-client/sdk/credentials/types"
+)
+
+type staticProvider struct {
+	credentials *credentials_types.Credentials
+}
+
+func (t *staticProvider) GetCredentials(ctx context.Context, realm, hostname string) (*credentials_types.Credentials, error) {
+	return t.credentials, nil
+}
+
+func TestRetryWithAuth(t *testing.T) {
+
+	deviceID := "shellyplus1pm-a8032ab12345"
+	challenge := `{"auth_type":"digest","nonce":1625038134,"nc":1,"realm":"shellyplus1pm-a8032ab12345","algorithm":"SHA-256"}`
+
+	tests := []struct {
+		name        string
+		credentials *credentials_types.Credentials
+		// accept the device accepts a request with auth
+		accept bool
+		// requireAuth the device responds with a challenge to a request without auth
+		requireAuth bool
+		// code the device responds with an error with the code if not 0
+		code    int
+		calls   int
+		wantErr error
+	}{
+		{name: "auth not required", calls: 1},
+		{name: "challenge", credentials: &credentials_types.Credentials{Username: "admin", Password: "secret"}, requireAuth: true, accept: true, calls: 2},
+		{name: "rejected", credentials: &credentials_types.Credentials{Username: "admin", Password: "wrong"}, requireAuth: true, calls: 2, wantErr: ErrUnauthorized},
+		{name: "no credentials", requireAuth: true, calls: 1, wantErr: ErrUnauthorized},
+		{name: "device error", code: ErrorCodeNotFound, calls: 1, wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			calls := 0
+			var auth *AuthResponse
+
+			send := func(ctx context.Context, request *Request) (*Response, []byte, error) {
+
+				calls++
+
+				response := &Response{Src: &deviceID}
+
+				switch {
+				case tt.code != 0:
+					response.Error = &Error{Code: tt.code, Message: "error"}
+				case tt.requireAuth && (request.Auth == nil || !tt.accept):
+					response.Error = &Error{Code: ErrorCodeUnauthorized, Message: challenge}
+				case request.Auth != nil && (request.Auth.Realm != deviceID || request.Auth.Nonce != "1625038134"):
+					t.Errorf("auth %+v does not answer the challenge", request.Auth)
+				}
+
+				return response, []byte("ok"), nil
+			}
+
+			method := "Switch.GetStatus"
+
+			b, err := RetryWithAuth(context.Background(), &Request{Method: &method}, &staticProvider{credentials: tt.credentials}, "device.lan",
+				func(a *AuthResponse) { auth = a }, send)
+
+			if calls != tt.calls {
+				t.Errorf("sent %d times, want %d", calls, tt.calls)
+			}
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != "ok" {
+				t.Errorf("response %s, want ok", string(b))
+			}
+
+			if (auth != nil) != tt.requireAuth {
+				t.Errorf("auth %+v, auth set want %t", auth, tt.requireAuth)
+			}
+		})
+	}
+
+	// An error from the device has the device ID and method
+	send := func(ctx context.Context, request *Request) (*Response, []byte, error) {
+		return &Response{Src: &deviceID, Error: &Error{Code: ErrorCodeNotFound}}, nil, nil
+	}
+
+	method := "Switch.GetStatus"
+	_, err := RetryWithAuth(context.Background(), &Request{Method: &method}, &staticProvider{}, "device.lan", func(*AuthResponse) {}, send)
+
+	rpcErr := &Error{}
+	if !errors.As(err, &rpcErr) || rpcErr.DeviceID != deviceID || rpcErr.Method != method {
+		t.Errorf("error %v, want an Error with device ID %s and method %s", err, deviceID, method)
+	}
+}
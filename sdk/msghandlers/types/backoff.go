@@ -0,0 +1,60 @@
+package types
+
+import (
+	"context"
+	mrand "math/rand"
+	"time"
+)
+
+// Backoff is the wait between attempts. The wait starts at the initial wait and doubles
+// up to the max wait. Each wait is jittered by up to half so that many clients do not
+// try again at the same time.
+type Backoff struct {
+	wait    time.Duration
+	maxWait time.Duration
+}
+
+// NewBackoff returns a new Backoff. If maxWait is less than wait it is set to wait.
+func NewBackoff(wait, maxWait time.Duration) *Backoff {
+
+	if maxWait < wait {
+		maxWait = wait
+	}
+
+	return &Backoff{
+		wait:    wait,
+		maxWait: maxWait,
+	}
+}
+
+// Next returns the wait before the next attempt. It is between half and all of the
+// current wait which is then doubled up to the max wait.
+func (t *Backoff) Next() time.Duration {
+
+	jitter := time.Duration(mrand.Int63n(int64(t.wait)/2 + 1))
+	next := t.wait/2 + jitter
+
+	t.wait = t.wait * 2
+	if t.wait > t.maxWait {
+		t.wait = t.maxWait
+	}
+
+	return next
+}
+
+// Wait waits for Next. If the context is done first its error is returned.
+func (t *Backoff) Wait(ctx context.Context) error {
+
+	timer := time.NewTimer(t.Next())
+	defer timer.Stop()
+
+	select {
+
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case <-timer.C:
+		return nil
+
+	}
+}
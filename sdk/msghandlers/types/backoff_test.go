@@ -0,0 +1,62 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBackoffNext(t *testing.T) {
+
+	tests := []struct {
+		wait    time.Duration
+		maxWait time.Duration
+		// want the wait before each attempt before jitter
+		want []time.Duration
+	}{
+		{wait: time.Second, maxWait: time.Second * 5, want: []time.Duration{time.Second, time.Second * 2, time.Second * 4, time.Second * 5, time.Second * 5}},
+		{wait: time.Second, maxWait: time.Millisecond, want: []time.Duration{time.Second, time.Second, time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v-%v", tt.wait, tt.maxWait), func(t *testing.T) {
+
+			backoff := NewBackoff(tt.wait, tt.maxWait)
+
+			for i, want := range tt.want {
+				if next := backoff.Next(); next < want/2 || next > want {
+					t.Errorf("wait %d is %v, want between %v and %v", i, next, want/2, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoffWait(t *testing.T) {
+
+	backoff := NewBackoff(time.Millisecond*20, time.Millisecond*20)
+
+	start := time.Now()
+
+	err := backoff.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Millisecond*10 {
+		t.Errorf("waited %v, want at least 10ms", elapsed)
+	}
+
+	// The wait ends when the context is done
+	backoff = NewBackoff(time.Hour, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+
+	err = backoff.Wait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error %v, want DeadlineExceeded", err)
+	}
+}
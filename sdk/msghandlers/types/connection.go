@@ -0,0 +1,51 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// ConnectionStatus the status of a connection to the device
+type ConnectionStatus string
+
+const (
+	// ConnectionStatusConnecting the connection is being established
+	ConnectionStatusConnecting ConnectionStatus = "connecting"
+	// ConnectionStatusConnected the connection is established
+	ConnectionStatusConnected ConnectionStatus = "connected"
+	// ConnectionStatusDisconnected the connection is not established. Err is set if the
+	// connection was lost or could not be established.
+	ConnectionStatusDisconnected ConnectionStatus = "disconnected"
+)
+
+// ConnectionState a change in the state of a connection to the device
+type ConnectionState struct {
+	Status ConnectionStatus
+	// Err the last error. Set when Status is disconnected because of a failure.
+	Err error
+	// Time the time the state changed
+	Time time.Time
+}
+
+// Clone return copy
+func (t *ConnectionState) Clone() *ConnectionState {
+	c := *t
+	return &c
+}
+
+// Connector is implemented by a MessageHandlerFactory that holds a connection to the device
+type Connector interface {
+	// Connect establishes the connection if it is not already established and returns
+	// the dial or handshake error if it fails
+	Connect(ctx context.Context) error
+}
+
+// ConnectionStateNotifier is implemented by a MessageHandlerFactory that reports changes
+// in the state of its connection
+type ConnectionStateNotifier interface {
+	// ConnectionState returns the current state
+	ConnectionState() *ConnectionState
+	// ConnectionStates returns a stream of state changes. If the stream is not read
+	// state changes are dropped.
+	ConnectionStates() <-chan *ConnectionState
+}
@@ -1,5 +1,9 @@
 package types
 
+import (
+	"time"
+)
+
 const (
 	dummyHA2 = "6370ec69915103833b5222b368555393393f098bfbfbb59f47e0590af135f062"
 
@@ -10,4 +14,19 @@ const (
 	ErrorCodeFailedPrecondition = -109
 	ErrorCodeUnAvailable        = -114
 	ErrorCodeNotImplemented     = 404
+	ErrorCodeUnauthorized       = 401
 )
+
+var safeMethodPrefixes = []string{"Get", "List", "Check"}
+
+// defaultMethodTimeouts are the built-in timeouts of methods that are expected to take
+// much longer or much shorter than the send timeout
+var defaultMethodTimeouts = map[string]time.Duration{
+	"Switch.Set":            time.Second * 2,
+	"Switch.Toggle":         time.Second * 2,
+	"Light.Set":             time.Second * 2,
+	"Light.Toggle":          time.Second * 2,
+	"Wifi.Scan":             time.Second * 30,
+	"Shelly.CheckForUpdate": time.Second * 30,
+	"Shelly.Update":         time.Second * 30,
+}
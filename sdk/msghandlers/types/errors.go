@@ -0,0 +1,65 @@
+package types
+
+import (
+	"errors"
+)
+
+// Sentinel errors. These can be tested with errors.Is. An Error returned by the device
+// matches the sentinel for its code. Transport failures wrap ErrTimeout or ErrDisconnected.
+var (
+	// ErrNotFound the component instance with the specified ID was not found
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidArgument the params do not match the ones specified by the method
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrFailedPrecondition a precondition for the action is not satisfied such as overpower
+	ErrFailedPrecondition = errors.New("failed precondition")
+	// ErrUnavailable a service used by the device is unavailable
+	ErrUnavailable = errors.New("unavailable")
+	// ErrTimeout no response was received in time or the device timed out the request
+	ErrTimeout = errors.New("timeout")
+	// ErrUnauthorized authentication is required or failed
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrDisconnected the connection to the device was lost or closed
+	ErrDisconnected = errors.New("disconnected")
+	// ErrNotImplemented the device does not have a handler for the method
+	ErrNotImplemented = errors.New("not implemented")
+)
+
+var errorCodeSentinels = map[int]error{
+	ErrorCodeInvalidArgument:    ErrInvalidArgument,
+	ErrorCodeDeadLineExceeded:   ErrTimeout,
+	ErrorCodeNotFound:           ErrNotFound,
+	ErrorCodeFailedPrecondition: ErrFailedPrecondition,
+	ErrorCodeUnAvailable:        ErrUnavailable,
+	ErrorCodeUnauthorized:       ErrUnauthorized,
+	ErrorCodeNotImplemented:     ErrNotImplemented,
+}
+
+// Is returns true if target is the sentinel error for the code such as ErrNotFound
+// for -105
+func (t *Error) Is(target error) bool {
+	sentinel, ok := errorCodeSentinels[t.Code]
+	return ok && sentinel == target
+}
+
+// WithRequest returns a copy of the error with the device ID and method set. The device
+// ID is the src of the response.
+func (t *Error) WithRequest(deviceID *string, method *string) *Error {
+
+	c := &Error{
+		Code:     t.Code,
+		Message:  t.Message,
+		DeviceID: t.DeviceID,
+		Method:   t.Method,
+	}
+
+	if deviceID != nil {
+		c.DeviceID = *deviceID
+	}
+
+	if method != nil {
+		c.Method = *method
+	}
+
+	return c
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorIs(t *testing.T) {
+
+	sentinels := []error{
+		ErrNotFound,
+		ErrInvalidArgument,
+		ErrFailedPrecondition,
+		ErrUnavailable,
+		ErrTimeout,
+		ErrUnauthorized,
+		ErrDisconnected,
+		ErrNotImplemented,
+	}
+
+	tests := []struct {
+		code int
+		// want the sentinel for the code or nil if the code has none
+		want error
+	}{
+		{code: ErrorCodeInvalidArgument, want: ErrInvalidArgument},
+		{code: ErrorCodeDeadLineExceeded, want: ErrTimeout},
+		{code: ErrorCodeNotFound, want: ErrNotFound},
+		{code: ErrorCodeResourceExhausted},
+		{code: ErrorCodeFailedPrecondition, want: ErrFailedPrecondition},
+		{code: ErrorCodeUnAvailable, want: ErrUnavailable},
+		{code: ErrorCodeNotImplemented, want: ErrNotImplemented},
+		{code: ErrorCodeUnauthorized, want: ErrUnauthorized},
+		{code: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.code), func(t *testing.T) {
+
+			err := &Error{Code: tt.code, Message: "test"}
+
+			for _, sentinel := range sentinels {
+				if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+					t.Errorf("errors.Is(%d, %v) %t, want %t", tt.code, sentinel, got, !got)
+				}
+			}
+
+			// The code is matched through wrapping and WithRequest
+			method := "Switch.Set"
+			wrapped := fmt.Errorf("wrapped: %w", err.WithRequest(nil, &method))
+
+			if tt.want != nil && !errors.Is(wrapped, tt.want) {
+				t.Errorf("wrapped error %v is not %v", wrapped, tt.want)
+			}
+		})
+	}
+}
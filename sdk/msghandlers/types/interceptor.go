@@ -0,0 +1,14 @@
+package types
+
+import (
+	"context"
+)
+
+// SendFunc sends the request and returns the raw response. It has the same signature as
+// MessageHandler.Send.
+type SendFunc func(ctx context.Context, request *Request) ([]byte, error)
+
+// Interceptor is called for each request sent with a handle. It may inspect or alter the
+// request, call next to continue the chain and inspect or alter the response or error. An
+// interceptor may also return without calling next.
+type Interceptor func(ctx context.Context, request *Request, next SendFunc) ([]byte, error)
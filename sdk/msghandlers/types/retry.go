@@ -0,0 +1,69 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrOutcomeUnknown is returned when a request that is not safe to retry was sent but no
+// response was received. The request may or may not have been executed by the device.
+var ErrOutcomeUnknown = errors.New("outcome unknown; request was sent but no response was received and it is not safe to retry")
+
+// RetryPolicy returns true if a request for the method may be sent again after no response
+// was received
+type RetryPolicy func(method string) bool
+
+// DefaultRetryPolicy allows retry of methods that do not change the state of the device.
+// These are methods with a name starting with Get, List or Check such as Switch.GetStatus
+// or Shelly.ListMethods.
+func DefaultRetryPolicy(method string) bool {
+
+	_, name, found := strings.Cut(method, ".")
+	if !found {
+		name = method
+	}
+
+	for _, prefix := range safeMethodPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RetryAlways allows retry of all methods
+func RetryAlways(method string) bool {
+	return true
+}
+
+// RetryNever does not allow retry of any method
+func RetryNever(method string) bool {
+	return false
+}
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy returns a context that overrides the retry policy for requests sent with it
+func WithRetryPolicy(ctx context.Context, retryPolicy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, retryPolicy)
+}
+
+// GetRetryPolicy returns the retry policy set on the context or DefaultRetryPolicy
+func GetRetryPolicy(ctx context.Context) RetryPolicy {
+	retryPolicy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy)
+	if ok && retryPolicy != nil {
+		return retryPolicy
+	}
+	return DefaultRetryPolicy
+}
+
+// IsRetryable returns true if the request may be sent again according to the retry
+// policy of the context
+func IsRetryable(ctx context.Context, request *Request) bool {
+	if request.Method == nil {
+		return false
+	}
+	return GetRetryPolicy(ctx)(*request.Method)
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDefaultRetryPolicy(t *testing.T) {
+
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{method: "Switch.GetStatus", want: true},
+		{method: "Shelly.GetConfig", want: true},
+		{method: "Shelly.ListMethods", want: true},
+		{method: "Shelly.CheckForUpdate", want: true},
+		{method: "Switch.Set", want: false},
+		{method: "Switch.Toggle", want: false},
+		{method: "Switch.SetConfig", want: false},
+		{method: "Shelly.Reboot", want: false},
+		{method: "KVS.Delete", want: false},
+		{method: "GetStatus", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			if got := DefaultRetryPolicy(tt.method); got != tt.want {
+				t.Errorf("DefaultRetryPolicy(%s) %t, want %t", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+
+	set := "Switch.Set"
+	getStatus := "Switch.GetStatus"
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		request *Request
+		want    bool
+	}{
+		{name: "default unsafe", ctx: context.Background(), request: &Request{Method: &set}, want: false},
+		{name: "default safe", ctx: context.Background(), request: &Request{Method: &getStatus}, want: true},
+		{name: "always", ctx: WithRetryPolicy(context.Background(), RetryAlways), request: &Request{Method: &set}, want: true},
+		{name: "never", ctx: WithRetryPolicy(context.Background(), RetryNever), request: &Request{Method: &getStatus}, want: false},
+		{name: "no method", ctx: context.Background(), request: &Request{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.ctx, tt.request); got != tt.want {
+				t.Errorf("IsRetryable %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
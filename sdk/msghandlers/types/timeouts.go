@@ -0,0 +1,80 @@
+package types
+
+import (
+	"context"
+	"path"
+	"sort"
+	"time"
+)
+
+// Timeouts returns the time to wait for the response to a request. The timeout of a method
+// is taken from the config overrides, then the built-in defaults and then the send timeout.
+// Overrides may use glob patterns such as Switch.* or * and an exact method name takes
+// precedence over a pattern.
+type Timeouts struct {
+	sendTimeout time.Duration
+	overrides   map[string]time.Duration
+	patterns    []string
+}
+
+// NewTimeouts returns Timeouts with the send timeout and the method overrides. The
+// overrides may be nil.
+func NewTimeouts(sendTimeout time.Duration, overrides map[string]time.Duration) *Timeouts {
+
+	t := &Timeouts{
+		sendTimeout: sendTimeout,
+		overrides:   make(map[string]time.Duration),
+	}
+
+	for pattern, timeout := range overrides {
+		if timeout > 0 {
+			t.overrides[pattern] = timeout
+			t.patterns = append(t.patterns, pattern)
+		}
+	}
+
+	// Longer patterns are more specific so they are tried first
+	sort.Slice(t.patterns, func(i, j int) bool {
+		if len(t.patterns[i]) == len(t.patterns[j]) {
+			return t.patterns[i] < t.patterns[j]
+		}
+		return len(t.patterns[i]) > len(t.patterns[j])
+	})
+
+	return t
+}
+
+// Get returns the time remaining until the deadline of ctx if it has one and otherwise the
+// timeout of the method. A caller that sets a deadline chooses the timeout of the request.
+func (t *Timeouts) Get(ctx context.Context, method *string) time.Duration {
+
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Until(deadline)
+	}
+
+	if method == nil {
+		return t.sendTimeout
+	}
+
+	return t.GetMethodTimeout(*method)
+}
+
+// GetMethodTimeout returns the timeout of the method ignoring any context deadline
+func (t *Timeouts) GetMethodTimeout(method string) time.Duration {
+
+	if timeout, ok := t.overrides[method]; ok {
+		return timeout
+	}
+
+	for _, pattern := range t.patterns {
+		if matched, _ := path.Match(pattern, method); matched {
+			return t.overrides[pattern]
+		}
+	}
+
+	if timeout, ok := defaultMethodTimeouts[method]; ok {
+		return timeout
+	}
+
+	return t.sendTimeout
+}
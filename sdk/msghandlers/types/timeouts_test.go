@@ -0,0 +1,58 @@
+package types
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeoutsGet(t *testing.T) {
+
+	timeouts := NewTimeouts(time.Second*10, map[string]time.Duration{
+		"Switch.GetStatus": time.Second,
+		"Switch.*":         time.Second * 3,
+		"Wifi.Scan":        time.Second * 60,
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		deadline time.Duration
+		want     time.Duration
+	}{
+		{name: "override", method: "Switch.GetStatus", want: time.Second},
+		{name: "pattern", method: "Switch.GetConfig", want: time.Second * 3},
+		{name: "exact before pattern", method: "Switch.GetStatus", want: time.Second},
+		{name: "override before default", method: "Wifi.Scan", want: time.Second * 60},
+		{name: "default", method: "Shelly.CheckForUpdate", want: time.Second * 30},
+		{name: "send timeout", method: "Shelly.GetConfig", want: time.Second * 10},
+		{name: "no method", want: time.Second * 10},
+		{name: "deadline sooner", method: "Shelly.GetConfig", deadline: time.Second * 2, want: time.Second * 2},
+		{name: "deadline later", method: "Switch.GetStatus", deadline: time.Minute, want: time.Minute},
+		{name: "deadline no method", deadline: time.Second * 20, want: time.Second * 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctx := context.Background()
+			if tt.deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+				defer cancel()
+			}
+
+			var method *string
+			if tt.method != "" {
+				method = &tt.method
+			}
+
+			got := timeouts.Get(ctx, method)
+
+			// The time remaining until a deadline is slightly less than the deadline
+			if got > tt.want || got < tt.want-time.Millisecond*100 {
+				t.Errorf("timeout %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,11 +2,18 @@ package types
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/jinzhu/copier"
+
+	credentials_types "github.com/jodydadescott/shelly-client/sdk/credentials/types"
 )
 
+type CredentialProvider = credentials_types.CredentialProvider
+
 // Auth RFC7616 HTTP Digest Access Authentication
 // https://www.rfc-editor.org/rfc/rfc7616
 type AuthResponse struct {
@@ -26,9 +33,41 @@ type AuthResponse struct {
 	Algorithm string `json:"algorithm" yaml:"algorithm"`
 }
 
+// Nonce the nonce of an auth challenge. The device sends the nonce as a number in the
+// error of a RPC frame and as a hex string such as 60dc59c6 in the WWW-Authenticate
+// header. The nonce is used in the digest exactly as it was received.
+type Nonce string
+
+// UnmarshalJSON accepts the nonce as a number or a string
+func (t *Nonce) UnmarshalJSON(b []byte) error {
+
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = Nonce(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("nonce %s is not valid", string(b))
+	}
+
+	*t = Nonce(n.String())
+	return nil
+}
+
+// MarshalJSON writes a decimal nonce as a number as the device does and any other nonce
+// as a string
+func (t Nonce) MarshalJSON() ([]byte, error) {
+	if _, err := strconv.ParseUint(string(t), 10, 64); err == nil {
+		return []byte(t), nil
+	}
+	return json.Marshal(string(t))
+}
+
 type AuthRequest struct {
 	AuthType   string `json:"auth_type" yaml:"auth_type"`
-	Nonce      int    `json:"nonce" yaml:"nonce"`
+	Nonce      Nonce  `json:"nonce" yaml:"nonce"`
 	NonceCount int    `json:"nc" yaml:"nc"`
 	Realm      string `json:"realm" yaml:"realm"`
 	Algorithm  string `json:"algorithm" yaml:"algorithm"`
@@ -43,8 +82,30 @@ func (t *AuthRequest) Clone() *AuthRequest {
 	return c
 }
 
+// SetCredentials sets the Username and Password from the provider using the realm of the
+// challenge and the hostname. ErrUnauthorized is returned if the provider has no credentials.
+func (t *AuthRequest) SetCredentials(ctx context.Context, provider CredentialProvider, hostname string) error {
+
+	credentials, err := provider.GetCredentials(ctx, t.Realm, hostname)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrUnauthorized, err)
+	}
+
+	if credentials == nil {
+		return fmt.Errorf("%w; no credentials for realm %s, hostname %s", ErrUnauthorized, t.Realm, hostname)
+	}
+
+	t.Username = credentials.Username
+	t.Password = credentials.Password
+	return nil
+}
+
 func (t *AuthRequest) ToAuthResponse() (*AuthResponse, error) {
 
+	if t.Nonce == "" {
+		return nil, fmt.Errorf("attribute 'Nonce' empty")
+	}
+
 	if t.NonceCount <= 0 {
 		return nil, fmt.Errorf("attribute 'NonceCount' not valid")
 	}
@@ -68,7 +129,7 @@ func (t *AuthRequest) ToAuthResponse() (*AuthResponse, error) {
 	cnonce := getCnonce()
 
 	auth := &AuthResponse{
-		Nonce:     fmt.Sprintf("%d", t.Nonce),
+		Nonce:     string(t.Nonce),
 		Realm:     t.Realm,
 		Username:  t.Username,
 		Cnonce:    cnonce,
@@ -80,7 +141,7 @@ func (t *AuthRequest) ToAuthResponse() (*AuthResponse, error) {
 	case "SHA-256":
 		ha1 := getSHA256(t.Username + ":" + t.Realm + ":" + t.Password)
 		// ha2 := getSHA256("dummy_method:dummy_uri")
-		auth.Response = getSHA256(fmt.Sprintf("%s:%d:%v:%s:%s:%s", ha1, t.Nonce, t.NonceCount, cnonce, "auth", dummyHA2))
+		auth.Response = getSHA256(fmt.Sprintf("%s:%s:%v:%s:%s:%s", ha1, t.Nonce, t.NonceCount, cnonce, "auth", dummyHA2))
 
 	default:
 		return nil, fmt.Errorf("algorithm %s not supported", auth.Algorithm)
@@ -104,6 +165,7 @@ type MessageHandler interface {
 type Request struct {
 	Auth   *AuthResponse
 	ID     *int        `json:"id,omitempty" yaml:"id,omitempty"`
+	Src    *string     `json:"src,omitempty" yaml:"src,omitempty"`
 	Method *string     `json:"method,omitempty" yaml:"method,omitempty"`
 	Params interface{} `json:"params,omitempty" yaml:"params,omitempty"`
 }
@@ -129,10 +191,33 @@ func (t *Response) Clone() *Response {
 	return c
 }
 
-// Error Shelly Error
+// Notification is a frame sent by the device that is not a response to a request such
+// as NotifyStatus, NotifyFullStatus and NotifyEvent
+// https://shelly-api-docs.shelly.cloud/gen2/General/Notifications
+type Notification struct {
+	Src    string          `json:"src,omitempty" yaml:"src,omitempty"`
+	Dst    string          `json:"dst,omitempty" yaml:"dst,omitempty"`
+	Method string          `json:"method,omitempty" yaml:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+// Clone return copy
+func (t *Notification) Clone() *Notification {
+	c := &Notification{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Error Shelly Error. Use errors.As to get the Error and errors.Is to test the code
+// against the sentinel errors such as ErrNotFound. The device ID and method are included
+// in the message when known.
 type Error struct {
 	Code    int    `json:"code,omitempty" yaml:"code,omitempty"`
 	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// DeviceID the ID of the device that returned the error if known
+	DeviceID string `json:"-" yaml:"-"`
+	// Method the method of the request if known
+	Method string `json:"-" yaml:"-"`
 }
 
 func (t *Error) Error() string {
@@ -141,13 +226,21 @@ func (t *Error) Error() string {
 		panic("error is nil")
 	}
 
-	m := errorCodeMap[t.Code]
+	s := fmt.Sprintf("status %d: err %s", t.Code, t.Message)
+
+	if m := errorCodeMap[t.Code]; m != "" {
+		s = s + "; " + m
+	}
 
-	if m != "" {
-		return fmt.Sprintf("status %d: err %s; %s", t.Code, t.Message, m)
+	if t.Method != "" {
+		s = fmt.Sprintf("method %s, %s", t.Method, s)
 	}
 
-	return fmt.Sprintf("status %d: err %s", t.Code, t.Message)
+	if t.DeviceID != "" {
+		s = fmt.Sprintf("device %s, %s", t.DeviceID, s)
+	}
+
+	return s
 }
 
 type ErrorCode int
@@ -165,3 +258,53 @@ var getErrorCodeMap = func() map[int]string {
 }
 
 var errorCodeMap = getErrorCodeMap()
+
+// ParseAuthChallenge parses the WWW-Authenticate header returned by the device when
+// authentication is required and returns an AuthRequest. The Username and Password are
+// not set. The header is expected to be in the form
+// Digest qop="auth", realm="shellypro4pm-f008d1d8b8b8", nonce="60dc59c6", algorithm=SHA-256
+func ParseAuthChallenge(header string) (*AuthRequest, error) {
+
+	authType, attributes, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found {
+		return nil, fmt.Errorf("auth challenge %s is not valid", header)
+	}
+
+	authRequest := &AuthRequest{
+		AuthType:   strings.ToLower(authType),
+		NonceCount: 1,
+	}
+
+	for _, attribute := range strings.Split(attributes, ",") {
+
+		key, value, found := strings.Cut(strings.TrimSpace(attribute), "=")
+		if !found {
+			continue
+		}
+
+		value = strings.Trim(value, "\"")
+
+		switch strings.ToLower(key) {
+
+		case "realm":
+			authRequest.Realm = value
+
+		case "algorithm":
+			authRequest.Algorithm = value
+
+		case "nonce":
+			authRequest.Nonce = Nonce(value)
+
+		}
+	}
+
+	if authRequest.Nonce == "" {
+		return nil, fmt.Errorf("auth challenge %s has no nonce", header)
+	}
+
+	if authRequest.Algorithm == "" {
+		authRequest.Algorithm = "SHA-256"
+	}
+
+	return authRequest, nil
+}
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestParseAuthChallenge(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		header    string
+		realm     string
+		nonce     Nonce
+		algorithm string
+		wantErr   bool
+	}{
+		{
+			name:      "hex nonce",
+			header:    `Digest qop="auth", realm="shellypro4pm-f008d1d8b8b8", nonce="60dc59c6", algorithm=SHA-256`,
+			realm:     "shellypro4pm-f008d1d8b8b8",
+			nonce:     "60dc59c6",
+			algorithm: "SHA-256",
+		},
+		{
+			name:      "decimal nonce",
+			header:    `Digest qop="auth", realm="shellyplus1pm-a8032ab12345", nonce="1625038134"`,
+			realm:     "shellyplus1pm-a8032ab12345",
+			nonce:     "1625038134",
+			algorithm: "SHA-256",
+		},
+		{
+			name:    "missing nonce",
+			header:  `Digest qop="auth", realm="shellypro4pm-f008d1d8b8b8", algorithm=SHA-256`,
+			wantErr: true,
+		},
+		{
+			name:    "not valid",
+			header:  `Digest`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			authRequest, err := ParseAuthChallenge(tt.header)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if authRequest.AuthType != "digest" {
+				t.Errorf("AuthType %s, want digest", authRequest.AuthType)
+			}
+
+			if authRequest.Realm != tt.realm {
+				t.Errorf("Realm %s, want %s", authRequest.Realm, tt.realm)
+			}
+
+			if authRequest.Nonce != tt.nonce {
+				t.Errorf("Nonce %s, want %s", authRequest.Nonce, tt.nonce)
+			}
+
+			if authRequest.Algorithm != tt.algorithm {
+				t.Errorf("Algorithm %s, want %s", authRequest.Algorithm, tt.algorithm)
+			}
+
+			authRequest.Username = "admin"
+			authRequest.Password = "secret"
+
+			authResponse, err := authRequest.ToAuthResponse()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if authResponse.Nonce != string(tt.nonce) {
+				t.Errorf("response Nonce %s, want %s", authResponse.Nonce, tt.nonce)
+			}
+
+			ha1 := getSHA256("admin:" + tt.realm + ":secret")
+			expected := getSHA256(fmt.Sprintf("%s:%s:1:%s:auth:%s", ha1, tt.nonce, authResponse.Cnonce, dummyHA2))
+
+			if authResponse.Response != expected {
+				t.Errorf("Response %s, want %s", authResponse.Response, expected)
+			}
+		})
+	}
+}
+
+func TestNonceJSON(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		input string
+		nonce Nonce
+		json  string
+	}{
+		{name: "number", input: `{"nonce":1625038134}`, nonce: "1625038134", json: `{"nonce":1625038134}`},
+		{name: "string", input: `{"nonce":"60dc59c6"}`, nonce: "60dc59c6", json: `{"nonce":"60dc59c6"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			v := &struct {
+				Nonce Nonce `json:"nonce"`
+			}{}
+
+			err := json.Unmarshal([]byte(tt.input), v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v.Nonce != tt.nonce {
+				t.Errorf("Nonce %s, want %s", v.Nonce, tt.nonce)
+			}
+
+			b, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != tt.json {
+				t.Errorf("json %s, want %s", string(b), tt.json)
+			}
+		})
+	}
+}
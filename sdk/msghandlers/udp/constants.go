@@ -0,0 +1,12 @@
+package udp
+
+import (
+	"time"
+)
+
+const (
+	defaultSendTimeout = time.Duration(time.Second * 2)
+	defaultSendTrys    = 4
+	defaultShellyUser  = "admin"
+	maxDatagramSize    = 65535
+)
@@ -0,0 +1,412 @@
+package udp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	logger "github.com/jodydadescott/jody-go-logger"
+	"go.uber.org/zap"
+
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/credentials"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+type Config = client_types.Config
+type Response = msg_types.Response
+
+type MessageHandlerFactory = msg_types.MessageHandlerFactory
+type MessageHandler = msg_types.MessageHandler
+type Request = msg_types.Request
+type AuthResponse = msg_types.AuthResponse
+type AuthRequest = msg_types.AuthRequest
+type CredentialProvider = msg_types.CredentialProvider
+
+var (
+	ErrOutcomeUnknown = msg_types.ErrOutcomeUnknown
+	ErrTimeout        = msg_types.ErrTimeout
+	ErrUnauthorized   = msg_types.ErrUnauthorized
+	ErrDisconnected   = msg_types.ErrDisconnected
+)
+
+// Client is a MessageHandlerFactory that sends each request as a JSON-RPC datagram to
+// the device's RPC over UDP listener. Responses are matched to requests by ID. Requests
+// are retransmitted SendTrys times within SendTimeout.
+type Client struct {
+	config            *Config
+	address           string
+	authResponseMutex sync.RWMutex
+	pendingMutex      sync.Mutex
+	pending           map[int]chan *responseWrapper
+	uniqID            int
+	connMutex         sync.Mutex
+	conn              net.Conn
+	wg                sync.WaitGroup
+	done              chan struct{}
+	closeOnce         sync.Once
+	authResponse      *AuthResponse
+	credentials       CredentialProvider
+	timeouts          *msg_types.Timeouts
+}
+
+func New(config *Config) (MessageHandlerFactory, error) {
+	zap.L().Debug("New")
+
+	config = config.Clone()
+
+	if config.Hostname == "" {
+		return nil, fmt.Errorf("hostname is required")
+	}
+
+	if config.UDPTransport == nil || config.UDPTransport.Port <= 0 {
+		return nil, fmt.Errorf("udpTransport port is required")
+	}
+
+	if config.Username == "" {
+		config.Username = defaultShellyUser
+		zap.L().Debug(fmt.Sprintf("username is %s (default)", config.Username))
+	} else {
+		zap.L().Debug(fmt.Sprintf("username is %s (config)", config.Username))
+	}
+
+	if config.SendTimeout <= 0 {
+		config.SendTimeout = defaultSendTimeout
+		zap.L().Debug(fmt.Sprintf("sendTimeout is %s (default)", config.SendTimeout.String()))
+	} else {
+		zap.L().Debug(fmt.Sprintf("sendTimeout is %s (config)", config.SendTimeout.String()))
+	}
+
+	if config.SendTrys <= 0 {
+		config.SendTrys = defaultSendTrys
+		zap.L().Debug(fmt.Sprintf("sendTrys is %d (default)", config.SendTrys))
+	} else {
+		zap.L().Debug(fmt.Sprintf("sendTrys is %d (config)", config.SendTrys))
+	}
+
+	if config.Password == "" {
+		zap.L().Debug("password is NOT set")
+	} else {
+		zap.L().Debug("password is set")
+	}
+
+	return &Client{
+		config:      config,
+		address:     net.JoinHostPort(config.Hostname, strconv.Itoa(config.UDPTransport.Port)),
+		pending:     make(map[int]chan *responseWrapper),
+		done:        make(chan struct{}),
+		credentials: credentials.NewClientProvider(config),
+		timeouts:    msg_types.NewTimeouts(config.SendTimeout, config.MethodTimeouts),
+	}, nil
+}
+
+func (t *Client) IsAuthEnabled() bool {
+	return t.getAuthResponse() != nil
+}
+
+func (t *Client) getAuthResponse() *AuthResponse {
+	t.authResponseMutex.RLock()
+	defer t.authResponseMutex.RUnlock()
+	return t.authResponse
+}
+
+func (t *Client) setAuthResponse(authResponse *AuthResponse) {
+	t.authResponseMutex.Lock()
+	defer t.authResponseMutex.Unlock()
+	t.authResponse = authResponse
+}
+
+func (t *Client) Close() {
+	zap.L().Debug("(*Client) Close()")
+
+	t.closeOnce.Do(func() {
+		close(t.done)
+
+		t.connMutex.Lock()
+		if t.conn != nil {
+			t.conn.Close()
+		}
+		t.connMutex.Unlock()
+
+		t.wg.Wait()
+	})
+}
+
+// Connect creates the socket to the device if it does not exist. As UDP is connectionless
+// this only fails if the address can not be resolved.
+func (t *Client) Connect(ctx context.Context) error {
+	_, err := t.getConn(ctx)
+	return err
+}
+
+// getConn returns the connection to the device. The connection is created on first use.
+func (t *Client) getConn(ctx context.Context) (net.Conn, error) {
+
+	t.connMutex.Lock()
+	defer t.connMutex.Unlock()
+
+	if t.conn != nil {
+		return t.conn, nil
+	}
+
+	select {
+	case <-t.done:
+		return nil, fmt.Errorf("%w; channel closed shutdown", ErrDisconnected)
+	default:
+	}
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "udp", t.address)
+	if err != nil {
+		return nil, err
+	}
+
+	zap.L().Debug(fmt.Sprintf("Connected to %s", t.address))
+
+	t.conn = conn
+
+	t.wg.Add(1)
+
+	go func() {
+		defer t.wg.Done()
+
+		b := make([]byte, maxDatagramSize)
+
+		for {
+			n, err := conn.Read(b)
+			if err != nil {
+				select {
+				case <-t.done:
+					zap.L().Debug("ingress closed by shutdown")
+					return
+				default:
+				}
+
+				// A read error on a connected UDP socket is usually an ICMP port
+				// unreachable from an earlier send. The socket is still usable.
+				zap.L().Debug(fmt.Sprintf("ingress error %v", err))
+				continue
+			}
+
+			msg := make([]byte, n)
+			copy(msg, b[:n])
+			t.routeMessage(msg)
+		}
+	}()
+
+	return conn, nil
+}
+
+func (t *Client) routeMessage(b []byte) {
+
+	if logger.Wire {
+		zap.L().Debug(fmt.Sprintf("RX->%s", string(b)))
+	}
+
+	msg := &Response{}
+	err := json.Unmarshal(b, msg)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("routeMessage error %v", err))
+		return
+	}
+
+	if msg.ID == nil {
+		zap.L().Debug("ignoring message without ID")
+		return
+	}
+
+	t.pendingMutex.Lock()
+	defer t.pendingMutex.Unlock()
+
+	receive := t.pending[*msg.ID]
+	if receive == nil {
+		zap.L().Debug(fmt.Sprintf("pending lookup ID %d failure", *msg.ID))
+		return
+	}
+
+	select {
+	case receive <- &responseWrapper{response: msg, rawBytes: b}:
+	default:
+		zap.L().Debug(fmt.Sprintf("pending ID %d already has a response", *msg.ID))
+	}
+}
+
+func (t *Client) addPending() (int, chan *responseWrapper) {
+	t.pendingMutex.Lock()
+	defer t.pendingMutex.Unlock()
+	t.uniqID = t.uniqID + 1
+	receive := make(chan *responseWrapper, 1)
+	t.pending[t.uniqID] = receive
+	return t.uniqID, receive
+}
+
+func (t *Client) removePending(id int) {
+	t.pendingMutex.Lock()
+	defer t.pendingMutex.Unlock()
+	delete(t.pending, id)
+}
+
+func (t *Client) NewHandle(name string) MessageHandler {
+	zap.L().Debug(fmt.Sprintf("(*Client) NewHandle(%s)", name))
+	return &Handle{
+		client: t,
+		name:   name,
+	}
+}
+
+type responseWrapper struct {
+	response *Response
+	rawBytes []byte
+}
+
+type Handle struct {
+	client *Client
+	name   string
+}
+
+// send writes the request with a new ID and waits for the matching response. If the
+// retry policy of the context allows it the request is retransmitted every
+// timeout/SendTrys until a response is received or the timeout of the method has elapsed.
+func (t *Handle) send(ctx context.Context, request *Request) (*responseWrapper, error) {
+
+	conn, err := t.client.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, receive := t.client.addPending()
+	defer t.client.removePending(id)
+
+	request.ID = &id
+
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	retryable := msg_types.IsRetryable(ctx, request)
+	sendTimeout := t.client.timeouts.Get(ctx, request.Method)
+	interval := sendTimeout / time.Duration(t.client.config.SendTrys)
+	timeout := time.After(sendTimeout)
+
+	counter := 0
+
+	for {
+
+		if logger.Wire {
+			zap.L().Debug(fmt.Sprintf("TX->%s", string(requestBytes)))
+		}
+
+		_, err := conn.Write(requestBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		counter++
+
+		// A nil channel is never ready so a request that is not safe to retry is
+		// written once
+		var retransmit <-chan time.Time
+		if retryable {
+			retransmit = time.After(interval)
+		}
+
+		select {
+
+		case response := <-receive:
+			return response, nil
+
+		case <-t.client.done:
+			return nil, fmt.Errorf("%w; channel closed shutdown", ErrDisconnected)
+
+		case <-ctx.Done():
+			return nil, fmt.Errorf("channel closed by caller; %w", ctx.Err())
+
+		case <-timeout:
+			if !retryable {
+				zap.L().Debug("request is not safe to retry; giving up")
+				return nil, fmt.Errorf("%w; %w waiting for response", ErrOutcomeUnknown, ErrTimeout)
+			}
+			zap.L().Debug(fmt.Sprintf("try %d of %d; giving up", counter, t.client.config.SendTrys))
+			return nil, fmt.Errorf("%w waiting for response", ErrTimeout)
+
+		case <-retransmit:
+			zap.L().Debug(fmt.Sprintf("try %d of %d; will try again", counter, t.client.config.SendTrys))
+
+		}
+	}
+}
+
+func (t *Handle) Send(ctx context.Context, request *Request) ([]byte, error) {
+
+	zap.L().Debug("(*Handle) Send(ctx, *Request)")
+
+	request = request.Clone()
+	request.Auth = t.client.getAuthResponse()
+
+	if request.Auth != nil {
+		zap.L().Debug("Using previous auth")
+	} else {
+		zap.L().Debug("Auth is not set")
+	}
+
+	return msg_types.RetryWithAuth(ctx, request, t.client.credentials, t.client.config.Hostname, t.client.setAuthResponse, t.sendOnce)
+}
+
+// sendOnce sends the request once and returns the response
+func (t *Handle) sendOnce(ctx context.Context, request *Request) (*Response, []byte, error) {
+
+	response, err := t.send(ctx, request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return response.response, response.rawBytes, nil
+}
+
+// Broadcast sends each request as a fire-and-forget datagram to address. The address may
+// be a unicast, broadcast (such as 192.168.1.255:1010) or multicast (such as 239.0.0.1:1010)
+// address. Responses are not read. Devices with authentication enabled will reject the
+// requests.
+func Broadcast(ctx context.Context, address string, requests ...*Request) error {
+
+	zap.L().Debug(fmt.Sprintf("Broadcast(%s)", address))
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	for i, request := range requests {
+
+		request = request.Clone()
+
+		if request.ID == nil {
+			id := i + 1
+			request.ID = &id
+		}
+
+		requestBytes, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+
+		if logger.Wire {
+			zap.L().Debug(fmt.Sprintf("TX->%s", string(requestBytes)))
+		}
+
+		_, err = conn.Write(requestBytes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
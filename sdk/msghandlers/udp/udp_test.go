@@ -0,0 +1,141 @@
+package udp_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/udp"
+)
+
+// listen serves the device on a local UDP socket as the rpc_udp listener of a device does.
+// The first drop datagrams are not answered; a negative drop answers none.
+func listen(t *testing.T, device *fake.Device, drop int32) (*net.UDPAddr, *int32) {
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+
+	var received int32
+
+	go func() {
+		b := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFrom(b)
+			if err != nil {
+				return
+			}
+
+			count := atomic.AddInt32(&received, 1)
+			if drop < 0 || count <= drop {
+				continue
+			}
+
+			response, err := device.Call(context.Background(), b[:n])
+			if err != nil {
+				continue
+			}
+
+			conn.WriteTo(response, addr)
+		}
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr), &received
+}
+
+func TestSend(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		method string
+		drop   int32
+		fault  *fake.Fault
+		// sent the number of datagrams sent
+		sent    int32
+		wantErr error
+	}{
+		{name: "response", method: "Switch.GetStatus", sent: 1},
+		{name: "retransmit", method: "Switch.GetStatus", drop: 1, sent: 2},
+		{name: "not safe to retry", method: "Switch.Set", drop: 1, sent: 1, wantErr: msg_types.ErrOutcomeUnknown},
+		{name: "timeout", method: "Switch.GetStatus", drop: -1, sent: 4, wantErr: msg_types.ErrTimeout},
+		{name: "device error", method: "Switch.GetStatus", fault: &fake.Fault{Method: "Switch.GetStatus", Code: msg_types.ErrorCodeNotFound}, sent: 1, wantErr: msg_types.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			device := fake.NewDevice(&fake.DeviceConfig{Switch: 1})
+			if tt.fault != nil {
+				device.InjectFault(tt.fault)
+			}
+
+			addr, received := listen(t, device, tt.drop)
+
+			factory, err := udp.New(&client_types.Config{
+				Hostname:     addr.IP.String(),
+				SendTrys:     4,
+				UDPTransport: &client_types.UDPTransportConfig{Port: addr.Port},
+				// Switch.Set has a longer built-in timeout
+				MethodTimeouts: map[string]time.Duration{"*": time.Millisecond * 200},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer factory.Close()
+
+			method := tt.method
+			_, err = factory.NewHandle("Switch").Send(context.Background(), &msg_types.Request{
+				Method: &method,
+				Params: map[string]interface{}{"id": 0, "on": true},
+			})
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if sent := atomic.LoadInt32(received); sent != tt.sent {
+				t.Errorf("sent %d datagrams, want %d", sent, tt.sent)
+			}
+		})
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+
+	device := fake.NewDevice(&fake.DeviceConfig{Switch: 1})
+	addr, received := listen(t, device, 0)
+
+	method := "Switch.Set"
+	err := udp.Broadcast(context.Background(), addr.String(), &msg_types.Request{
+		Method: &method,
+		Params: map[string]interface{}{"id": 0, "on": true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The datagram is not answered so wait for the device to process it
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if output, _ := device.GetStatus("switch:0")["output"].(bool); output {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	t.Errorf("switch is off after a broadcast of Switch.Set on; %d datagrams received", atomic.LoadInt32(received))
+}
@@ -5,10 +5,15 @@ import (
 )
 
 const (
-	WsScheme           = "ws"
-	wsPath             = "/rpc"
-	defaultRetryWait   = time.Duration(3) * time.Second
-	defaultSendTimeout = time.Duration(time.Second * 10)
-	defaultSendTrys    = 3
-	defaultShellyUser  = "admin"
+	WsScheme            = "ws"
+	WssScheme           = "wss"
+	wsPath              = "/rpc"
+	defaultRetryWait    = time.Duration(3) * time.Second
+	defaultRetryMaxWait = time.Duration(time.Minute)
+	defaultSendTimeout  = time.Duration(time.Second * 10)
+	defaultSendTrys     = 3
+	defaultShellyUser   = "admin"
+	defaultSrcPrefix    = "shelly-client-"
+	egressBufferSize    = 50
+	stateBufferSize     = 10
 )
@@ -2,8 +2,11 @@ package ws
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/url"
 	"sync"
 	"time"
@@ -13,7 +16,10 @@ import (
 	"go.uber.org/zap"
 
 	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	"github.com/jodydadescott/shelly-client/sdk/credentials"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/tlsconfig"
 	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/notification"
 )
 
 type Config = client_types.Config
@@ -24,28 +30,127 @@ type MessageHandler = msg_types.MessageHandler
 type Request = msg_types.Request
 type AuthResponse = msg_types.AuthResponse
 type AuthRequest = msg_types.AuthRequest
+type CredentialProvider = msg_types.CredentialProvider
+type Notification = notification.Notification
+type NotificationFilter = notification.Filter
+type ConnectionState = msg_types.ConnectionState
+type ConnectionStatus = msg_types.ConnectionStatus
+
+var (
+	ErrOutcomeUnknown = msg_types.ErrOutcomeUnknown
+	ErrTimeout        = msg_types.ErrTimeout
+	ErrUnauthorized   = msg_types.ErrUnauthorized
+	ErrDisconnected   = msg_types.ErrDisconnected
+)
+
+const (
+	ConnectionStatusConnecting   = msg_types.ConnectionStatusConnecting
+	ConnectionStatusConnected    = msg_types.ConnectionStatusConnected
+	ConnectionStatusDisconnected = msg_types.ConnectionStatusDisconnected
+)
 
+// Client is a MessageHandlerFactory that multiplexes requests from any number of
+// goroutines over one websocket connection. Each request is assigned a unique ID and
+// the response is routed back to the caller by ID. If the connection is lost in-flight
+// requests fail and the connection is re-established with exponential backoff.
 type Client struct {
-	config            *Config
-	authResponseMutex sync.RWMutex
-	handleMutex       sync.RWMutex
-	handleMap         map[int]*Handle
-	egressMessages    chan []byte
-	uniqID            int
-	wg                sync.WaitGroup
-	cancel            context.CancelFunc
-	authResponse      *AuthResponse
-}
-
-func New(config *Config) MessageHandlerFactory {
+	config              *Config
+	url                 string
+	dialer              *gorilla.Dialer
+	authResponseMutex   sync.RWMutex
+	pendingMutex        sync.Mutex
+	pending             map[int]chan *responseWrapper
+	egressMessages      chan []byte
+	uniqID              int
+	wg                  sync.WaitGroup
+	ctx                 context.Context
+	cancel              context.CancelFunc
+	connectMutex        sync.Mutex
+	connected           bool
+	stateMutex          sync.RWMutex
+	state               *ConnectionState
+	states              chan *ConnectionState
+	conn                *gorilla.Conn
+	notificationHandler func([]byte)
+	subscribers         *notification.Subscribers
+	src                 string
+	done                chan struct{}
+	shutdownOnce        sync.Once
+	authResponse        *AuthResponse
+	credentials         CredentialProvider
+	timeouts            *msg_types.Timeouts
+}
+
+// New returns a new Client. The connection is established in the background; use
+// Connect to establish it and get the error if it fails.
+func New(config *Config) (MessageHandlerFactory, error) {
 	zap.L().Debug("New")
 
 	config = config.Clone()
 
 	if config.Hostname == "" {
-		panic("hostname is required")
+		return nil, fmt.Errorf("hostname is required")
+	}
+
+	scheme, err := tlsconfig.GetScheme(config, WsScheme, WssScheme)
+	if err != nil {
+		return nil, err
+	}
+
+	zap.L().Debug(fmt.Sprintf("scheme is %s", scheme))
+
+	t := newClient(config)
+
+	theURL := url.URL{Scheme: scheme, Host: config.Hostname, Path: wsPath}
+	t.url = theURL.String()
+
+	t.dialer = &gorilla.Dialer{
+		Proxy:            gorilla.DefaultDialer.Proxy,
+		HandshakeTimeout: gorilla.DefaultDialer.HandshakeTimeout,
+	}
+
+	if tlsconfig.IsSecure(scheme) {
+		t.dialer.TLSClientConfig, err = tlsconfig.New(config.TLS)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.reconnect(nil)
+	}()
+
+	return t, nil
+}
+
+// NewFromConn returns a new Client bound to an existing connection such as one
+// initiated by the device. The connection is not re-established if it is lost; use
+// Done to be notified when the connection has ended. Frames received from the device
+// that are not a response to a request are passed to notificationHandler if it is not nil.
+func NewFromConn(config *Config, conn *gorilla.Conn, notificationHandler func([]byte)) *Client {
+	zap.L().Debug("NewFromConn")
+
+	config = config.Clone()
+
+	if config.Hostname == "" {
+		config.Hostname = conn.RemoteAddr().String()
 	}
 
+	t := newClient(config)
+	t.conn = conn
+	t.notificationHandler = notificationHandler
+
+	t.connectMutex.Lock()
+	t.startSession(conn)
+	t.connectMutex.Unlock()
+
+	return t
+}
+
+func newClient(config *Config) *Client {
+
 	if config.Username == "" {
 		config.Username = defaultShellyUser
 		zap.L().Debug(fmt.Sprintf("username is %s (default)", config.Username))
@@ -67,6 +172,17 @@ func New(config *Config) MessageHandlerFactory {
 		zap.L().Debug(fmt.Sprintf("retryWait is %s (config)", config.RetryWait.String()))
 	}
 
+	if config.RetryMaxWait <= 0 {
+		config.RetryMaxWait = defaultRetryMaxWait
+		zap.L().Debug(fmt.Sprintf("retryMaxWait is %s (default)", config.RetryMaxWait.String()))
+	} else {
+		zap.L().Debug(fmt.Sprintf("retryMaxWait is %s (config)", config.RetryMaxWait.String()))
+	}
+
+	if config.RetryMaxWait < config.RetryWait {
+		config.RetryMaxWait = config.RetryWait
+	}
+
 	if config.SendTrys <= 0 {
 		config.SendTrys = defaultSendTrys
 		zap.L().Debug(fmt.Sprintf("sendTrys is %d (default)", config.SendTrys))
@@ -80,14 +196,79 @@ func New(config *Config) MessageHandlerFactory {
 		zap.L().Debug("password is set")
 	}
 
-	t := &Client{
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Client{
 		config:         config,
-		handleMap:      make(map[int]*Handle),
-		egressMessages: make(chan []byte, 50),
+		pending:        make(map[int]chan *responseWrapper),
+		egressMessages: make(chan []byte, egressBufferSize),
+		ctx:            ctx,
+		cancel:         cancel,
+		state:          &ConnectionState{Status: ConnectionStatusDisconnected, Time: time.Now()},
+		states:         make(chan *ConnectionState, stateBufferSize),
+		subscribers:    notification.NewSubscribers(),
+		src:            defaultSrcPrefix + getRandomID(),
+		done:           make(chan struct{}),
+		credentials:    credentials.NewClientProvider(config),
+		timeouts:       msg_types.NewTimeouts(config.SendTimeout, config.MethodTimeouts),
 	}
+}
 
-	t.run()
-	return t
+func getRandomID() string {
+	b := make([]byte, 4)
+	io.ReadFull(rand.Reader, b)
+	return hex.EncodeToString(b)
+}
+
+// Subscribe returns a channel of notifications sent by the device that match the filter.
+// The device only sends notifications on a connection after it has received a request
+// with a src. The channel is closed when the context is cancelled or the client is closed.
+func (t *Client) Subscribe(ctx context.Context, filter *NotificationFilter) <-chan *Notification {
+	return t.subscribers.Subscribe(ctx, filter)
+}
+
+// Done returns a channel that is closed when the client is closed or, for a client
+// created with NewFromConn, when the connection has ended.
+func (t *Client) Done() <-chan struct{} {
+	return t.done
+}
+
+// ConnectionState returns the current state of the connection
+func (t *Client) ConnectionState() *ConnectionState {
+	t.stateMutex.RLock()
+	defer t.stateMutex.RUnlock()
+	return t.state.Clone()
+}
+
+// ConnectionStates returns a stream of connection state changes. If the stream is not
+// read state changes are dropped.
+func (t *Client) ConnectionStates() <-chan *ConnectionState {
+	return t.states
+}
+
+func (t *Client) setState(status ConnectionStatus, err error) {
+
+	state := &ConnectionState{
+		Status: status,
+		Err:    err,
+		Time:   time.Now(),
+	}
+
+	t.stateMutex.Lock()
+	t.state = state
+	t.stateMutex.Unlock()
+
+	if err != nil {
+		zap.L().Debug(fmt.Sprintf("Connection to %s is %s with error %v", t.config.Hostname, status, err))
+	} else {
+		zap.L().Debug(fmt.Sprintf("Connection to %s is %s", t.config.Hostname, status))
+	}
+
+	select {
+	case t.states <- state.Clone():
+	default:
+		zap.L().Debug(fmt.Sprintf("Connection state stream is full; state %s dropped", status))
+	}
 }
 
 func (t *Client) IsAuthEnabled() bool {
@@ -108,315 +289,408 @@ func (t *Client) setAuthResponse(authResponse *AuthResponse) {
 
 func (t *Client) Close() {
 	zap.L().Debug("(*Client) Close()")
+	t.shutdown()
+	t.cancel()
+	t.wg.Wait()
+}
 
-	for _, handle := range t.handleMap {
-		handle.close()
+// shutdown fails all in-flight requests. It is safe to call more than once.
+func (t *Client) shutdown() {
+	t.shutdownOnce.Do(func() {
+		t.subscribers.Close()
+		close(t.done)
+	})
+}
+
+// Connect establishes the connection to the device if it is not already established.
+// The dial or handshake error is returned if it fails. A client created with
+// NewFromConn can not be reconnected.
+func (t *Client) Connect(ctx context.Context) error {
+
+	t.connectMutex.Lock()
+	defer t.connectMutex.Unlock()
+
+	if t.connected {
+		return nil
 	}
 
-	t.cancel()
-	t.wg.Wait()
+	select {
+	case <-t.done:
+		return fmt.Errorf("%w; channel closed shutdown", ErrDisconnected)
+	default:
+	}
+
+	if t.conn != nil {
+		return fmt.Errorf("%w; connection from %s has ended", ErrDisconnected, t.config.Hostname)
+	}
+
+	t.setState(ConnectionStatusConnecting, nil)
+
+	ctx, cancel := context.WithTimeout(ctx, t.config.SendTimeout)
+	defer cancel()
+
+	conn, _, err := t.dialer.DialContext(ctx, t.url, nil)
+	if err != nil {
+		t.setState(ConnectionStatusDisconnected, err)
+		return err
+	}
+
+	t.startSession(conn)
+	return nil
 }
 
-func (t *Client) run() {
+// reconnect calls Connect until it succeeds or the client is closed. The wait between
+// attempts starts at RetryWait and doubles up to RetryMaxWait with jitter. If err is
+// not nil it is the error that ended the previous connection and the first attempt is
+// made after a wait.
+func (t *Client) reconnect(err error) {
 
-	ctx, cancel := context.WithCancel(context.Background())
-	t.cancel = cancel
+	backoff := msg_types.NewBackoff(t.config.RetryWait, t.config.RetryMaxWait)
 
-	routeMessage := func(b []byte) {
+	for {
 
-		msg := &Response{}
-		err := json.Unmarshal(b, msg)
 		if err != nil {
-			zap.L().Error(fmt.Sprintf("routeMessage error %v", err))
-			return
+
+			zap.L().Debug(fmt.Sprintf("Connect error %v; will try again", err))
+
+			if backoff.Wait(t.ctx) != nil {
+				zap.L().Debug("Connect cancelled")
+				return
+			}
 		}
 
-		zap.L().Debug("getting handle mutex")
-		t.handleMutex.RLock()
-		defer t.handleMutex.RUnlock()
-		defer zap.L().Debug("releasing handle mutex")
+		zap.L().Debug(fmt.Sprintf("Connecting to %s", t.config.Hostname))
 
-		handle := t.handleMap[*msg.ID]
-		if handle == nil {
-			zap.L().Error(fmt.Sprintf("handle lookup ID %d failure", msg.ID))
+		err = t.Connect(t.ctx)
+		if err == nil {
 			return
 		}
 
-		handle.receive <- &responseWrapper{
-			response: msg,
-			rawBytes: b,
+		select {
+		case <-t.ctx.Done():
+			return
+		default:
 		}
 	}
+}
 
-	handleEgress := func(conn *gorilla.Conn, errChan *errChan) {
-		go func() {
-			for {
-				select {
-				case <-ctx.Done():
-					zap.L().Debug("handleEgress closed by context")
-					conn.WriteMessage(gorilla.CloseMessage, gorilla.FormatCloseMessage(gorilla.CloseNormalClosure, ""))
-					return
+// startSession handles the connection until it ends. When the connection ends in-flight
+// requests are failed and, unless the client is bound to a connection with NewFromConn,
+// the connection is re-established. connectMutex must be held.
+func (t *Client) startSession(conn *gorilla.Conn) {
 
-				case b := <-t.egressMessages:
+	t.connected = true
+	t.setState(ConnectionStatusConnected, nil)
 
-					if logger.Wire {
-						zap.L().Debug(fmt.Sprintf("TX->%s", string(b)))
-					}
+	t.wg.Add(1)
 
-					err := conn.WriteMessage(gorilla.BinaryMessage, b)
-					if err != nil {
-						zap.L().Debug("handleEgress closed by error")
-						errChan.putError(err)
-						return
-					}
+	go func() {
+		defer t.wg.Done()
 
-				}
-			}
+		err := t.handle(conn)
+		conn.Close()
 
-		}()
-	}
+		t.connectMutex.Lock()
+		t.connected = false
+		t.connectMutex.Unlock()
 
-	handleIngress := func(conn *gorilla.Conn, errChan *errChan) {
-		go func() {
-			for {
-				_, b, err := conn.ReadMessage()
+		t.setState(ConnectionStatusDisconnected, err)
 
-				if logger.Wire {
-					zap.L().Debug(fmt.Sprintf("RX->%s", string(b)))
-				}
+		if err == nil {
+			err = fmt.Errorf("connection closed")
+		}
 
-				if err != nil {
-					zap.L().Debug("handleIngress closed by error")
-					errChan.putError(err)
-					return
-				}
+		t.failPending(fmt.Errorf("%w; connection lost; %w", ErrDisconnected, err))
 
-				routeMessage(b)
-			}
-		}()
-	}
+		if t.conn != nil {
+			zap.L().Debug(fmt.Sprintf("Connection from %s ended with error %v", t.config.Hostname, err))
+			t.shutdown()
+			return
+		}
 
-	handle := func(conn *gorilla.Conn) error {
-		ctx, cancel := context.WithCancel(ctx)
-		defer cancel()
+		select {
+		case <-t.ctx.Done():
+			return
+		default:
+		}
 
-		errChan := newErrChan()
+		t.reconnect(err)
+	}()
+}
 
-		handleIngress(conn, errChan)
-		handleEgress(conn, errChan)
+// failPending fails all in-flight requests with err and discards requests that have not
+// yet been sent so that they are not sent on the next connection
+func (t *Client) failPending(err error) {
 
-		err := errChan.getError(ctx)
-		errChan.close()
-		return err
+	t.pendingMutex.Lock()
+	defer t.pendingMutex.Unlock()
+
+	for {
+		select {
+		case <-t.egressMessages:
+			continue
+		default:
+		}
+		break
+	}
+
+	for id, receive := range t.pending {
+		select {
+		case receive <- &responseWrapper{err: err}:
+		default:
+			zap.L().Debug(fmt.Sprintf("pending ID %d already has a response", id))
+		}
 	}
+}
 
-	connect := func() error {
-		theURL := url.URL{Scheme: WsScheme, Host: t.config.Hostname, Path: wsPath}
-		conn, _, err := gorilla.DefaultDialer.Dial(theURL.String(), nil)
+func (t *Client) routeMessage(b []byte) {
 
-		if err != nil {
-			return err
+	msg := &Response{}
+	err := json.Unmarshal(b, msg)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("routeMessage error %v", err))
+		return
+	}
+
+	if msg.ID == nil {
+		t.subscribers.Publish(b)
+		if t.notificationHandler != nil {
+			t.notificationHandler(b)
 		}
+		return
+	}
 
-		zap.L().Debug("Connected")
+	t.pendingMutex.Lock()
+	defer t.pendingMutex.Unlock()
 
-		return handle(conn)
+	receive := t.pending[*msg.ID]
+	if receive == nil {
+		zap.L().Debug(fmt.Sprintf("pending lookup ID %d failure", *msg.ID))
+		return
 	}
 
-	t.wg.Add(1)
+	select {
+	case receive <- &responseWrapper{response: msg, rawBytes: b}:
+	default:
+		zap.L().Debug(fmt.Sprintf("pending ID %d already has a response", *msg.ID))
+	}
+}
 
-	go func() {
+// handle reads and writes the connection until it fails or the client is closed. A nil
+// error is returned if the client was closed.
+func (t *Client) handle(conn *gorilla.Conn) error {
 
-		defer t.wg.Done()
-		defer close(t.egressMessages)
+	ctx, cancel := context.WithCancel(t.ctx)
+	defer cancel()
 
-		for {
+	errChan := newErrChan()
 
-			zap.L().Debug(fmt.Sprintf("Connecting to %s", t.config.Hostname))
+	go func() {
+		for {
+			_, b, err := conn.ReadMessage()
 
-			err := connect()
+			if logger.Wire {
+				zap.L().Debug(fmt.Sprintf("RX->%s", string(b)))
+			}
 
-			if err == nil {
+			if err != nil {
+				zap.L().Debug("handleIngress closed by error")
+				errChan.putError(err)
 				return
 			}
 
-			zap.L().Debug(fmt.Sprintf("Connect error %v; will try again in %v", err, defaultRetryWait.String()))
+			t.routeMessage(b)
+		}
+	}()
 
-			select {
+	var egressWG sync.WaitGroup
+	egressWG.Add(1)
 
+	go func() {
+		defer egressWG.Done()
+		for {
+			select {
 			case <-ctx.Done():
-				zap.L().Debug("Connect cancelled")
+				zap.L().Debug("handleEgress closed by context")
+				conn.WriteMessage(gorilla.CloseMessage, gorilla.FormatCloseMessage(gorilla.CloseNormalClosure, ""))
 				return
 
-			case <-time.After(defaultRetryWait):
-				continue
-			}
+			case b := <-t.egressMessages:
 
-		}
+				if ctx.Err() != nil {
+					// The connection is ending; leave the message for the next connection
+					select {
+					case t.egressMessages <- b:
+					default:
+					}
+					continue
+				}
+
+				if logger.Wire {
+					zap.L().Debug(fmt.Sprintf("TX->%s", string(b)))
+				}
 
+				err := conn.WriteMessage(gorilla.BinaryMessage, b)
+				if err != nil {
+					zap.L().Debug("handleEgress closed by error")
+					errChan.putError(err)
+					return
+				}
+
+			}
+		}
 	}()
 
+	err := errChan.getError(ctx)
+	cancel()
+	egressWG.Wait()
+	errChan.close()
+	return err
 }
 
-func (t *Client) NewHandle(name string) MessageHandler {
+func (t *Client) addPending() (int, chan *responseWrapper) {
+	t.pendingMutex.Lock()
+	defer t.pendingMutex.Unlock()
+	t.uniqID = t.uniqID + 1
+	receive := make(chan *responseWrapper, 1)
+	t.pending[t.uniqID] = receive
+	return t.uniqID, receive
+}
 
-	zap.L().Debug(fmt.Sprintf("(*Client) NewHandle(%s)", name))
+func (t *Client) removePending(id int) {
+	t.pendingMutex.Lock()
+	defer t.pendingMutex.Unlock()
+	delete(t.pending, id)
+}
 
-	zap.L().Debug("getting handle mutex")
-	t.handleMutex.Lock()
-	defer t.handleMutex.Unlock()
-	defer zap.L().Debug("releasing handle mutex")
+// push queues the message for the connection. It does not block longer than timeout.
+func (t *Client) push(ctx context.Context, b []byte, timeout time.Duration) error {
 
-	t.uniqID = t.uniqID + 1
+	select {
 
-	handle := &Handle{
-		client:  t,
-		id:      t.uniqID,
-		receive: make(chan *responseWrapper, 30),
-		done:    make(chan struct{}),
-	}
+	case t.egressMessages <- b:
+		return nil
+
+	case <-t.done:
+		return fmt.Errorf("%w; channel closed shutdown", ErrDisconnected)
 
-	t.handleMap[handle.id] = handle
+	case <-ctx.Done():
+		return fmt.Errorf("channel closed by caller; %w", ctx.Err())
 
-	zap.L().Debug(fmt.Sprintf("(*Client) NewHandle(%s) returned", name))
+	case <-time.After(timeout):
+		return fmt.Errorf("%w waiting to send", ErrTimeout)
 
-	return handle
+	}
 }
 
-func (t *Handle) close() {
-	zap.L().Debug("(*Handle) close()")
-	close(t.done)
-	close(t.receive)
+func (t *Client) NewHandle(name string) MessageHandler {
+	zap.L().Debug(fmt.Sprintf("(*Client) NewHandle(%s)", name))
+	return &Handle{
+		client: t,
+		name:   name,
+	}
 }
 
+// responseWrapper holds the response or, if the connection was lost, the error
 type responseWrapper struct {
 	response *Response
 	rawBytes []byte
+	err      error
 }
 
+// Handle is safe for concurrent use. Each call to Send is assigned its own ID.
 type Handle struct {
-	client  *Client
-	id      int
-	receive chan *responseWrapper
-	done    chan struct{}
+	client *Client
+	name   string
 }
 
-func (t *Handle) Send(ctx context.Context, request *Request) ([]byte, error) {
-
-	zap.L().Debug("(*Handle) Send(ctx, *Request)")
+// send writes the request with a new ID and waits for the matching response
+// and is sent again on timeout only if the retry policy of the context allows it
+func (t *Handle) send(ctx context.Context, request *Request) (*responseWrapper, error) {
 
-	request = request.Clone()
-	request.ID = &t.id
+	id, receive := t.client.addPending()
+	defer t.client.removePending(id)
 
-	request.Auth = t.client.getAuthResponse()
-
-	if request.Auth != nil {
-		zap.L().Debug("Using previous auth")
-	} else {
-		zap.L().Debug("Auth is not set")
-	}
+	request.ID = &id
 
 	requestBytes, err := json.Marshal(request)
-
 	if err != nil {
 		return nil, err
 	}
 
-	waitOnResponse := func() (*responseWrapper, error) {
-
-		ctx, cancel := context.WithCancel(ctx)
-		defer cancel()
-
-		counter := 0
-
-		for {
-
-			t.client.egressMessages <- requestBytes
-
-			select {
+	retryable := msg_types.IsRetryable(ctx, request)
+	timeout := t.client.timeouts.Get(ctx, request.Method)
+	counter := 0
 
-			case response := <-t.receive:
-				return response, nil
+	for {
 
-			case <-t.done:
-				return nil, fmt.Errorf("channel closed shutdown")
+		err := t.client.push(ctx, requestBytes, timeout)
+		if err != nil {
+			return nil, err
+		}
 
-			case <-ctx.Done():
-				return nil, fmt.Errorf("channel closed by caller")
+		select {
 
-			case <-time.After(t.client.config.SendTimeout):
-				if counter >= t.client.config.SendTrys {
-					zap.L().Debug(fmt.Sprintf("try %d of %d; giving up", counter, t.client.config.SendTrys))
-					return nil, fmt.Errorf("timeout waiting for response")
+		case response := <-receive:
+			if response.err != nil {
+				if !retryable {
+					return nil, fmt.Errorf("%w; %w", ErrOutcomeUnknown, response.err)
 				}
-				zap.L().Debug(fmt.Sprintf("try %d of %d; will try again", counter, t.client.config.SendTrys))
-				counter++
-
+				return nil, response.err
 			}
+			return response, nil
 
-		}
-
-	}
-
-	response, err := waitOnResponse()
-
-	if err != nil {
-		return nil, err
-	}
-
-	if response.response.Error != nil {
-
-		if response.response.Error.Code == 401 {
-
-			zap.L().Debug("server responded with auth required")
-
-			if t.client.config.Username == "" {
-				return nil, fmt.Errorf("username is required")
+		case <-t.client.done:
+			if !retryable {
+				return nil, fmt.Errorf("%w; %w; channel closed shutdown", ErrOutcomeUnknown, ErrDisconnected)
 			}
+			return nil, fmt.Errorf("%w; channel closed shutdown", ErrDisconnected)
 
-			if t.client.config.Password == "" {
-				return nil, fmt.Errorf("password is required")
-			}
+		case <-ctx.Done():
+			return nil, fmt.Errorf("channel closed by caller; %w", ctx.Err())
 
-			authRequest := &AuthRequest{}
-			err = json.Unmarshal([]byte(response.response.Error.Message), authRequest)
-			if err != nil {
-				return nil, err
+		case <-time.After(timeout):
+			if !retryable {
+				zap.L().Debug("request is not safe to retry; giving up")
+				return nil, fmt.Errorf("%w; %w waiting for response", ErrOutcomeUnknown, ErrTimeout)
 			}
-
-			authRequest.Username = t.client.config.Username
-			authRequest.Password = t.client.config.Password
-			authResponse, err := authRequest.ToAuthResponse()
-
-			if err != nil {
-				return nil, err
+			if counter >= t.client.config.SendTrys {
+				zap.L().Debug(fmt.Sprintf("try %d of %d; giving up", counter, t.client.config.SendTrys))
+				return nil, fmt.Errorf("%w waiting for response", ErrTimeout)
 			}
+			zap.L().Debug(fmt.Sprintf("try %d of %d; will try again", counter, t.client.config.SendTrys))
+			counter++
 
-			t.client.setAuthResponse(authResponse)
-
-			request.Auth = authResponse
+		}
+	}
+}
 
-			requestBytes, err := json.Marshal(request)
-			if err != nil {
-				return nil, err
-			}
+func (t *Handle) Send(ctx context.Context, request *Request) ([]byte, error) {
 
-			t.client.egressMessages <- requestBytes
+	zap.L().Debug("(*Handle) Send(ctx, *Request)")
 
-			response, err := waitOnResponse()
+	request = request.Clone()
+	request.Src = &t.client.src
+	request.Auth = t.client.getAuthResponse()
 
-			if err != nil {
-				return nil, err
-			}
+	if request.Auth != nil {
+		zap.L().Debug("Using previous auth")
+	} else {
+		zap.L().Debug("Auth is not set")
+	}
 
-			return response.rawBytes, nil
+	return msg_types.RetryWithAuth(ctx, request, t.client.credentials, t.client.config.Hostname, t.client.setAuthResponse, t.sendOnce)
+}
 
-		}
+// sendOnce sends the request once and returns the response
+func (t *Handle) sendOnce(ctx context.Context, request *Request) (*Response, []byte, error) {
 
-		return nil, response.response.Error
+	response, err := t.send(ctx, request)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return response.rawBytes, nil
+	return response.response, response.rawBytes, nil
 }
 
 type errChan struct {
@@ -0,0 +1,409 @@
+package ws_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/ws"
+)
+
+type frame struct {
+	ID     int             `json:"id"`
+	Src    string          `json:"src"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// newConn returns the client side of a websocket connection. The device side is handled
+// by device in its own goroutine.
+func newConn(t *testing.T, device func(conn *gorilla.Conn)) *gorilla.Conn {
+
+	upgrader := gorilla.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		device(conn)
+	}))
+
+	t.Cleanup(server.Close)
+
+	conn, _, err := gorilla.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
+
+// readFrames reads count request frames
+func readFrames(conn *gorilla.Conn, count int) ([]*frame, error) {
+
+	var frames []*frame
+
+	for len(frames) < count {
+
+		_, b, err := conn.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+
+		f := &frame{}
+		err = json.Unmarshal(b, f)
+		if err != nil {
+			return nil, err
+		}
+
+		frames = append(frames, f)
+	}
+
+	return frames, nil
+}
+
+func send(ctx context.Context, client *ws.Client, method string, params interface{}) ([]byte, error) {
+	return client.NewHandle("Test").Send(ctx, &msg_types.Request{Method: &method, Params: params})
+}
+
+// TestConcurrentSend sends requests from many goroutines. The device responds in the
+// reverse order and each response must be routed to the caller of its request.
+func TestConcurrentSend(t *testing.T) {
+
+	const count = 20
+
+	conn := newConn(t, func(conn *gorilla.Conn) {
+
+		frames, err := readFrames(conn, count)
+		if err != nil {
+			return
+		}
+
+		for i := len(frames) - 1; i >= 0; i-- {
+			b, _ := json.Marshal(map[string]interface{}{
+				"id":     frames[i].ID,
+				"src":    "device",
+				"dst":    frames[i].Src,
+				"result": frames[i].Params,
+			})
+			err := conn.WriteMessage(gorilla.TextMessage, b)
+			if err != nil {
+				return
+			}
+		}
+
+		conn.ReadMessage()
+	})
+
+	client := ws.NewFromConn(&client_types.Config{SendTimeout: time.Second * 5}, conn, nil)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, count)
+
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			b, err := send(ctx, client, "Shelly.GetStatus", map[string]int{"n": i})
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			response := &struct {
+				Result struct {
+					N int `json:"n"`
+				} `json:"result"`
+			}{}
+
+			err = json.Unmarshal(b, response)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			if response.Result.N != i {
+				errs <- fmt.Errorf("request %d got the response to request %d", i, response.Result.N)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// TestConnectionLost drops the connection while requests are in flight. The requests
+// must fail with ErrDisconnected without waiting for the timeout and requests that are
+// not safe to retry must also report ErrOutcomeUnknown.
+func TestConnectionLost(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		method         string
+		outcomeUnknown bool
+	}{
+		{name: "safe", method: "Switch.GetStatus"},
+		{name: "unsafe", method: "Switch.Set", outcomeUnknown: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			conn := newConn(t, func(conn *gorilla.Conn) {
+				// The request is read and the connection is closed without a response
+				readFrames(conn, 1)
+			})
+
+			client := ws.NewFromConn(&client_types.Config{SendTimeout: time.Second * 30}, conn, nil)
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+			defer cancel()
+
+			start := time.Now()
+
+			_, err := send(ctx, client, tt.method, map[string]int{"id": 0})
+
+			if !errors.Is(err, msg_types.ErrDisconnected) {
+				t.Fatalf("error %v, want ErrDisconnected", err)
+			}
+
+			if errors.Is(err, msg_types.ErrOutcomeUnknown) != tt.outcomeUnknown {
+				t.Errorf("error %v, outcome unknown want %t", err, tt.outcomeUnknown)
+			}
+
+			if time.Since(start) > time.Second*5 {
+				t.Errorf("request failed after %v; want before the timeout", time.Since(start))
+			}
+
+			select {
+			case <-client.Done():
+			case <-ctx.Done():
+				t.Fatal("client is not done after the connection ended")
+			}
+
+			_, err = send(ctx, client, tt.method, map[string]int{"id": 0})
+			if !errors.Is(err, msg_types.ErrDisconnected) {
+				t.Errorf("error %v after the connection ended, want ErrDisconnected", err)
+			}
+		})
+	}
+}
+
+// TestConnect checks that Connect returns the dial error when the device can not be
+// reached
+func TestConnect(t *testing.T) {
+
+	// A listener that is closed gives a port with nothing listening
+	server := httptest.NewServer(http.NotFoundHandler())
+	hostname := strings.TrimPrefix(server.URL, "http://")
+	server.Close()
+
+	factory, err := ws.New(&client_types.Config{Hostname: hostname, SendTimeout: time.Second, RetryWait: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer factory.Close()
+
+	client := factory.(*ws.Client)
+
+	err = client.Connect(context.Background())
+	if err == nil {
+		t.Fatal("error nil, want dial error")
+	}
+
+	if state := client.ConnectionState(); state.Status == msg_types.ConnectionStatusConnected {
+		t.Errorf("state %s, want not connected", state.Status)
+	}
+
+	client.Close()
+
+	err = client.Connect(context.Background())
+	if !errors.Is(err, msg_types.ErrDisconnected) {
+		t.Errorf("error %v after Close, want ErrDisconnected", err)
+	}
+}
+
+// TestReconnect checks that failed attempts back off with jitter, that the state changes
+// are reported and that a lost connection is re-established
+func TestReconnect(t *testing.T) {
+
+	const (
+		retryWait    = time.Millisecond * 20
+		retryMaxWait = time.Millisecond * 80
+	)
+
+	var (
+		mutex    sync.Mutex
+		accept   bool
+		attempts []time.Time
+		conns    = make(chan *gorilla.Conn, 10)
+	)
+
+	upgrader := gorilla.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		mutex.Lock()
+		attempts = append(attempts, time.Now())
+		ok := accept
+		mutex.Unlock()
+
+		if !ok {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conns <- conn
+	}))
+	defer server.Close()
+
+	factory, err := ws.New(&client_types.Config{
+		Hostname:     strings.TrimPrefix(server.URL, "http://"),
+		SendTimeout:  time.Second,
+		RetryWait:    retryWait,
+		RetryMaxWait: retryMaxWait,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer factory.Close()
+
+	client := factory.(*ws.Client)
+
+	var states []*msg_types.ConnectionState
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case state := <-client.ConnectionStates():
+				mutex.Lock()
+				states = append(states, state)
+				mutex.Unlock()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	waitFor := func(what string, fn func() bool) {
+		deadline := time.Now().Add(time.Second * 5)
+		for {
+			mutex.Lock()
+			ok := fn()
+			mutex.Unlock()
+			if ok {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+			time.Sleep(time.Millisecond * 5)
+		}
+	}
+
+	waitFor("failed attempts", func() bool { return len(attempts) >= 5 })
+
+	mutex.Lock()
+
+	// The wait before attempt i+1 is between half and all of min(RetryWait*2^i, RetryMaxWait)
+	wait := retryWait
+	for i := 1; i < 5; i++ {
+		if gap := attempts[i].Sub(attempts[i-1]); gap < wait/2 {
+			t.Errorf("attempt %d after %v, want at least %v", i+1, gap, wait/2)
+		}
+		wait = wait * 2
+		if wait > retryMaxWait {
+			wait = retryMaxWait
+		}
+	}
+
+	for _, state := range states {
+		if state.Status == msg_types.ConnectionStatusDisconnected && state.Err == nil {
+			t.Errorf("disconnected state without the dial error")
+		}
+	}
+
+	accept = true
+	mutex.Unlock()
+
+	var conn *gorilla.Conn
+	select {
+	case conn = <-conns:
+	case <-time.After(time.Second * 5):
+		t.Fatal("not connected")
+	}
+
+	waitFor("connected", func() bool {
+		return len(states) > 0 && states[len(states)-1].Status == msg_types.ConnectionStatusConnected
+	})
+
+	if state := client.ConnectionState(); state.Status != msg_types.ConnectionStatusConnected {
+		t.Errorf("state %s, want connected", state.Status)
+	}
+
+	// The connection is re-established after it is lost
+	mutex.Lock()
+	lost := len(states)
+	mutex.Unlock()
+
+	conn.Close()
+
+	select {
+	case conn = <-conns:
+		defer conn.Close()
+	case <-time.After(time.Second * 5):
+		t.Fatal("not reconnected")
+	}
+
+	waitFor("reconnected", func() bool {
+		return len(states) > lost && states[len(states)-1].Status == msg_types.ConnectionStatusConnected
+	})
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	want := []msg_types.ConnectionStatus{msg_types.ConnectionStatusDisconnected, msg_types.ConnectionStatusConnecting, msg_types.ConnectionStatusConnected}
+	got := states[lost:]
+
+	if len(got) != len(want) {
+		t.Fatalf("%d state changes after the connection was lost, want %d", len(got), len(want))
+	}
+
+	for i := range want {
+		if got[i].Status != want[i] {
+			t.Errorf("state %d %s, want %s", i, got[i].Status, want[i])
+		}
+	}
+
+	if got[0].Err == nil {
+		t.Errorf("disconnected state without the error that ended the connection")
+	}
+}
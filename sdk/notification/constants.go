@@ -0,0 +1,12 @@
+package notification
+
+const (
+	// MethodNotifyStatus is sent when the status of a component changes. Only the changed attributes are present.
+	MethodNotifyStatus = "NotifyStatus"
+	// MethodNotifyFullStatus is sent with the full status of all components.
+	MethodNotifyFullStatus = "NotifyFullStatus"
+	// MethodNotifyEvent is sent when an event occurs such as a button press.
+	MethodNotifyEvent = "NotifyEvent"
+
+	subscriberBufferSize = 100
+)
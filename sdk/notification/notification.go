@@ -0,0 +1,267 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/jodydadescott/shelly-client/sdk/notification/types"
+)
+
+type Notification = types.Notification
+type RawNotification = types.RawNotification
+type Filter = types.Filter
+type Event = types.Event
+type SwitchStatus = types.SwitchStatus
+type LightStatus = types.LightStatus
+type InputStatus = types.InputStatus
+type SystemStatus = types.SystemStatus
+
+// Subscriber is implemented by a MessageHandlerFactory that can receive notifications
+// from the device
+type Subscriber interface {
+	// Subscribe returns a channel of notifications that match the filter. The channel
+	// is closed when the context is cancelled or the factory is closed.
+	Subscribe(ctx context.Context, filter *Filter) <-chan *Notification
+}
+
+type eventParams struct {
+	Ts     *float64 `json:"ts,omitempty"`
+	Events []*Event `json:"events,omitempty"`
+}
+
+// Decode decodes a raw notification
+func Decode(raw *RawNotification) (*Notification, error) {
+
+	notification := &Notification{
+		Src:    raw.Src,
+		Dst:    raw.Dst,
+		Method: raw.Method,
+		Raw:    raw,
+	}
+
+	if len(raw.Params) == 0 {
+		return notification, nil
+	}
+
+	if raw.Method == MethodNotifyEvent {
+		params := &eventParams{}
+		err := json.Unmarshal(raw.Params, params)
+		if err != nil {
+			return nil, err
+		}
+		notification.Ts = params.Ts
+		notification.Events = params.Events
+		return notification, nil
+	}
+
+	params := make(map[string]json.RawMessage)
+	err := json.Unmarshal(raw.Params, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range params {
+
+		if key == "ts" {
+			err := json.Unmarshal(value, &notification.Ts)
+			if err != nil {
+				return nil, fmt.Errorf("key %s; %w", key, err)
+			}
+			continue
+		}
+
+		if key == "sys" {
+			notification.System = &SystemStatus{}
+			err := json.Unmarshal(value, notification.System)
+			if err != nil {
+				return nil, fmt.Errorf("key %s; %w", key, err)
+			}
+			continue
+		}
+
+		component, idString, found := strings.Cut(key, ":")
+		id, idErr := strconv.Atoi(idString)
+
+		if !found || idErr != nil {
+			addOther(notification, key, value)
+			continue
+		}
+
+		switch component {
+
+		case "switch":
+			status := &SwitchStatus{}
+			err := json.Unmarshal(value, status)
+			if err != nil {
+				return nil, fmt.Errorf("key %s; %w", key, err)
+			}
+			if notification.Switch == nil {
+				notification.Switch = make(map[int]*SwitchStatus)
+			}
+			notification.Switch[id] = status
+
+		case "light":
+			status := &LightStatus{}
+			err := json.Unmarshal(value, status)
+			if err != nil {
+				return nil, fmt.Errorf("key %s; %w", key, err)
+			}
+			if notification.Light == nil {
+				notification.Light = make(map[int]*LightStatus)
+			}
+			notification.Light[id] = status
+
+		case "input":
+			status := &InputStatus{}
+			err := json.Unmarshal(value, status)
+			if err != nil {
+				return nil, fmt.Errorf("key %s; %w", key, err)
+			}
+			if notification.Input == nil {
+				notification.Input = make(map[int]*InputStatus)
+			}
+			notification.Input[id] = status
+
+		default:
+			addOther(notification, key, value)
+
+		}
+	}
+
+	return notification, nil
+}
+
+func addOther(notification *Notification, key string, value json.RawMessage) {
+	if notification.Other == nil {
+		notification.Other = make(map[string]json.RawMessage)
+	}
+	notification.Other[key] = value
+}
+
+type subscriber struct {
+	filter        *Filter
+	notifications chan *Notification
+}
+
+// Subscribers fans out notifications to subscribers. It is intended to be used by
+// MessageHandlerFactory implementations to implement Subscriber.
+type Subscribers struct {
+	mutex       sync.Mutex
+	subscribers map[int]*subscriber
+	uniqID      int
+	closed      bool
+}
+
+// NewSubscribers returns a new instance of Subscribers
+func NewSubscribers() *Subscribers {
+	return &Subscribers{
+		subscribers: make(map[int]*subscriber),
+	}
+}
+
+// Subscribe returns a channel of notifications that match the filter. The channel is
+// closed when the context is cancelled or Close is called.
+func (t *Subscribers) Subscribe(ctx context.Context, filter *Filter) <-chan *Notification {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	notifications := make(chan *Notification, subscriberBufferSize)
+
+	if t.closed {
+		close(notifications)
+		return notifications
+	}
+
+	t.uniqID = t.uniqID + 1
+	id := t.uniqID
+
+	t.subscribers[id] = &subscriber{
+		filter:        filter.Clone(),
+		notifications: notifications,
+	}
+
+	go func() {
+		<-ctx.Done()
+		t.unsubscribe(id)
+	}()
+
+	return notifications
+}
+
+func (t *Subscribers) unsubscribe(id int) {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	subscriber := t.subscribers[id]
+	if subscriber == nil {
+		return
+	}
+
+	delete(t.subscribers, id)
+	close(subscriber.notifications)
+}
+
+// Publish decodes the frame and sends it to each subscriber with a matching filter. If a
+// subscriber is not keeping up the notification is dropped for that subscriber.
+func (t *Subscribers) Publish(b []byte) {
+
+	raw := &RawNotification{}
+	err := json.Unmarshal(b, raw)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("notification error %v", err))
+		return
+	}
+
+	if raw.Method == "" {
+		zap.L().Debug("ignoring frame without method")
+		return
+	}
+
+	notification, err := Decode(raw)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("notification %s from %s decode error %v", raw.Method, raw.Src, err))
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, subscriber := range t.subscribers {
+
+		if !subscriber.filter.Match(notification) {
+			continue
+		}
+
+		select {
+		case subscriber.notifications <- notification:
+		default:
+			zap.L().Warn(fmt.Sprintf("subscriber is full; notification %s from %s dropped", notification.Method, notification.Src))
+		}
+	}
+}
+
+// Close closes the channel of each subscriber. Subscribe after Close returns a closed channel.
+func (t *Subscribers) Close() {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.closed {
+		return
+	}
+
+	t.closed = true
+
+	for id, subscriber := range t.subscribers {
+		delete(t.subscribers, id)
+		close(subscriber.notifications)
+	}
+}
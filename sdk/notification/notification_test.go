@@ -0,0 +1,194 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jodydadescott/shelly-client/sdk/notification"
+)
+
+func TestDecode(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		frame      string
+		components []string
+		events     []string
+		wantErr    bool
+	}{
+		{
+			name:       "status",
+			frame:      `{"src":"shellyplus1pm-1","dst":"client","method":"NotifyStatus","params":{"ts":1.5,"switch:0":{"id":0,"output":true},"input:1":{"id":1,"state":false},"sys":{"uptime":10},"wifi":{"rssi":-60}}}`,
+			components: []string{"input:1", "switch:0", "sys", "wifi"},
+		},
+		{
+			name:       "event",
+			frame:      `{"src":"shellyplus1pm-1","method":"NotifyEvent","params":{"ts":1.5,"events":[{"component":"input:0","id":0,"event":"single_push","ts":1.5}]}}`,
+			components: []string{"input:0"},
+			events:     []string{"single_push"},
+		},
+		{
+			name:  "no params",
+			frame: `{"src":"shellyplus1pm-1","method":"NotifyFullStatus"}`,
+		},
+		{
+			name:    "bad switch status",
+			frame:   `{"src":"shellyplus1pm-1","method":"NotifyStatus","params":{"switch:0":{"output":"on"}}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			raw := &notification.RawNotification{}
+			err := json.Unmarshal([]byte(tt.frame), raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			n, err := notification.Decode(raw)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("error nil, want error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if n.Src != "shellyplus1pm-1" || n.Raw != raw {
+				t.Errorf("src %s, want shellyplus1pm-1 with the raw notification", n.Src)
+			}
+
+			components := n.Components()
+			sort.Strings(components)
+
+			if strings.Join(components, ",") != strings.Join(tt.components, ",") {
+				t.Errorf("components %v, want %v", components, tt.components)
+			}
+
+			var events []string
+			for _, v := range n.Events {
+				events = append(events, *v.Event)
+			}
+
+			if strings.Join(events, ",") != strings.Join(tt.events, ",") {
+				t.Errorf("events %v, want %v", events, tt.events)
+			}
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+
+	push := "single_push"
+	input := "input:0"
+
+	status := &notification.Notification{
+		Src:    "shellyplus1pm-1",
+		Method: notification.MethodNotifyStatus,
+		Switch: map[int]*notification.SwitchStatus{0: {}},
+	}
+
+	event := &notification.Notification{
+		Src:    "shellyplus1pm-1",
+		Method: notification.MethodNotifyEvent,
+		Events: []*notification.Event{{Component: &input, Event: &push}},
+	}
+
+	tests := []struct {
+		name   string
+		filter *notification.Filter
+		status bool
+		event  bool
+	}{
+		{name: "nil", status: true, event: true},
+		{name: "empty", filter: &notification.Filter{}, status: true, event: true},
+		{name: "src", filter: &notification.Filter{Src: []string{"shellyplus1pm-1"}}, status: true, event: true},
+		{name: "other src", filter: &notification.Filter{Src: []string{"shellyplus1pm-2"}}},
+		{name: "method", filter: &notification.Filter{Methods: []string{notification.MethodNotifyEvent}}, event: true},
+		{name: "component key", filter: &notification.Filter{Components: []string{"switch:0"}}, status: true},
+		{name: "component type", filter: &notification.Filter{Components: []string{"input"}}, event: true},
+		{name: "other component", filter: &notification.Filter{Components: []string{"switch:1"}}},
+		{name: "event", filter: &notification.Filter{Events: []string{"single_push"}}, event: true},
+		{name: "other event", filter: &notification.Filter{Events: []string{"long_push"}}},
+		{name: "all must match", filter: &notification.Filter{Methods: []string{notification.MethodNotifyStatus}, Events: []string{"single_push"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			if got := tt.filter.Match(status); got != tt.status {
+				t.Errorf("status match %t, want %t", got, tt.status)
+			}
+
+			if got := tt.filter.Match(event); got != tt.event {
+				t.Errorf("event match %t, want %t", got, tt.event)
+			}
+		})
+	}
+}
+
+func TestSubscribers(t *testing.T) {
+
+	subscribers := notification.NewSubscribers()
+
+	all := subscribers.Subscribe(context.Background(), nil)
+	events := subscribers.Subscribe(context.Background(), &notification.Filter{Methods: []string{notification.MethodNotifyEvent}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := subscribers.Subscribe(ctx, nil)
+
+	subscribers.Publish([]byte(`{"src":"shellyplus1pm-1","method":"NotifyStatus","params":{"switch:0":{"output":true}}}`))
+	subscribers.Publish([]byte(`{"src":"shellyplus1pm-1","method":"NotifyEvent","params":{"events":[{"component":"input:0","event":"single_push"}]}}`))
+
+	// Frames that are not notifications are dropped
+	subscribers.Publish([]byte(`{"id":1,"src":"shellyplus1pm-1","result":{}}`))
+	subscribers.Publish([]byte(`not json`))
+
+	receive := func(name string, c <-chan *notification.Notification, want ...string) {
+		for _, method := range want {
+			select {
+			case n := <-c:
+				if n.Method != method {
+					t.Errorf("%s received %s, want %s", name, n.Method, method)
+				}
+			case <-time.After(time.Second * 5):
+				t.Fatalf("%s did not receive %s", name, method)
+			}
+		}
+	}
+
+	receive("all", all, notification.MethodNotifyStatus, notification.MethodNotifyEvent)
+	receive("events", events, notification.MethodNotifyEvent)
+	receive("cancelled", cancelled, notification.MethodNotifyStatus, notification.MethodNotifyEvent)
+
+	closed := func(name string, c <-chan *notification.Notification) {
+		select {
+		case n, ok := <-c:
+			if ok {
+				t.Errorf("%s received %s, want closed", name, n.Method)
+			}
+		case <-time.After(time.Second * 5):
+			t.Errorf("%s not closed", name)
+		}
+	}
+
+	// The channel is closed when the context is cancelled
+	cancel()
+	closed("cancelled", cancelled)
+
+	// Close closes the remaining channels and Subscribe after Close returns a closed channel
+	subscribers.Close()
+	closed("all", all)
+	closed("events", events)
+	closed("after close", subscribers.Subscribe(context.Background(), nil))
+}
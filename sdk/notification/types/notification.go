@@ -0,0 +1,185 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/jinzhu/copier"
+
+	input_types "github.com/jodydadescott/shelly-client/sdk/input/types"
+	light_types "github.com/jodydadescott/shelly-client/sdk/light/types"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	switch_types "github.com/jodydadescott/shelly-client/sdk/switchx/types"
+	system_types "github.com/jodydadescott/shelly-client/sdk/system/types"
+)
+
+type RawNotification = msg_types.Notification
+
+type SwitchStatus = switch_types.Status
+type LightStatus = light_types.Status
+type InputStatus = input_types.Status
+type SystemStatus = system_types.Status
+
+// Notification a decoded notification sent by the device. For NotifyStatus only the
+// attributes that have changed are set. For NotifyEvent the Events are set.
+// https://shelly-api-docs.shelly.cloud/gen2/General/Notifications
+type Notification struct {
+	// Src the device ID
+	Src string `json:"src,omitempty" yaml:"src,omitempty"`
+	// Dst the destination of the notification
+	Dst string `json:"dst,omitempty" yaml:"dst,omitempty"`
+	// Method one of NotifyStatus, NotifyFullStatus or NotifyEvent
+	Method string `json:"method,omitempty" yaml:"method,omitempty"`
+	// Ts Unix timestamp of the notification
+	Ts *float64 `json:"ts,omitempty" yaml:"ts,omitempty"`
+	// System status of the Sys component
+	System *SystemStatus `json:"sys,omitempty" yaml:"sys,omitempty"`
+	// Switch status of the Switch components by ID
+	Switch map[int]*SwitchStatus `json:"switch,omitempty" yaml:"switch,omitempty"`
+	// Light status of the Light components by ID
+	Light map[int]*LightStatus `json:"light,omitempty" yaml:"light,omitempty"`
+	// Input status of the Input components by ID
+	Input map[int]*InputStatus `json:"input,omitempty" yaml:"input,omitempty"`
+	// Events the events of a NotifyEvent
+	Events []*Event `json:"events,omitempty" yaml:"events,omitempty"`
+	// Other the status of components that are not decoded by key such as wifi or cloud
+	Other map[string]json.RawMessage `json:"other,omitempty" yaml:"other,omitempty"`
+	// Raw the notification as received
+	Raw *RawNotification `json:"-" yaml:"-"`
+}
+
+// Clone return copy
+func (t *Notification) Clone() *Notification {
+	c := &Notification{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Components returns the keys of the components present in the notification such
+// as switch:0 or sys
+func (t *Notification) Components() []string {
+
+	var components []string
+
+	if t.System != nil {
+		components = append(components, "sys")
+	}
+
+	for id := range t.Switch {
+		components = append(components, componentKey("switch", id))
+	}
+
+	for id := range t.Light {
+		components = append(components, componentKey("light", id))
+	}
+
+	for id := range t.Input {
+		components = append(components, componentKey("input", id))
+	}
+
+	for _, event := range t.Events {
+		if event.Component != nil {
+			components = append(components, *event.Component)
+		}
+	}
+
+	for key := range t.Other {
+		components = append(components, key)
+	}
+
+	return components
+}
+
+// Event an event from a NotifyEvent such as single_push from an Input
+// https://shelly-api-docs.shelly.cloud/gen2/General/Notifications#notifyevent
+type Event struct {
+	// Component the component key such as input:0
+	Component *string `json:"component,omitempty" yaml:"component,omitempty"`
+	// ID Id of the component instance
+	ID *int `json:"id,omitempty" yaml:"id,omitempty"`
+	// Event the name of the event such as single_push, double_push or long_push
+	Event *string `json:"event,omitempty" yaml:"event,omitempty"`
+	// Ts Unix timestamp of the event
+	Ts *float64 `json:"ts,omitempty" yaml:"ts,omitempty"`
+}
+
+// Clone return copy
+func (t *Event) Clone() *Event {
+	c := &Event{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Filter selects notifications. Each non empty attribute must match. A nil Filter
+// matches all notifications.
+type Filter struct {
+	// Src device IDs to match
+	Src []string `json:"src,omitempty" yaml:"src,omitempty"`
+	// Methods to match such as NotifyStatus or NotifyEvent
+	Methods []string `json:"methods,omitempty" yaml:"methods,omitempty"`
+	// Components to match. A component may be a key such as switch:0 or a
+	// type such as switch which matches any ID
+	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
+	// Events names to match such as single_push. Only NotifyEvent notifications match.
+	Events []string `json:"events,omitempty" yaml:"events,omitempty"`
+}
+
+// Clone return copy
+func (t *Filter) Clone() *Filter {
+	c := &Filter{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Match returns true if the notification matches the filter
+func (t *Filter) Match(notification *Notification) bool {
+
+	if t == nil {
+		return true
+	}
+
+	has := func(x string, s []string) bool {
+		for _, v := range s {
+			if v == x {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(t.Src) > 0 && !has(notification.Src, t.Src) {
+		return false
+	}
+
+	if len(t.Methods) > 0 && !has(notification.Method, t.Methods) {
+		return false
+	}
+
+	if len(t.Components) > 0 {
+		match := false
+		for _, component := range notification.Components() {
+			if has(component, t.Components) || has(strings.Split(component, ":")[0], t.Components) {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+
+	if len(t.Events) > 0 {
+		match := false
+		for _, event := range notification.Events {
+			if event.Event != nil && has(*event.Event, t.Events) {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+
+	return true
+}
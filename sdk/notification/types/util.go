@@ -0,0 +1,9 @@
+package types
+
+import (
+	"fmt"
+)
+
+func componentKey(component string, id int) string {
+	return fmt.Sprintf("%s:%d", component, id)
+}
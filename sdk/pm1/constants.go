@@ -0,0 +1,5 @@
+package pm1
+
+const (
+	Component = "PM1"
+)
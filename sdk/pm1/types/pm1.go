@@ -0,0 +1,140 @@
+package types
+
+import (
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+)
+
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+// Params internal use only
+type Params struct {
+	ID     int      `json:"id" yaml:"id"`
+	Config *Config  `json:"config,omitempty" yaml:"config,omitempty"`
+	Type   []string `json:"type,omitempty" yaml:"type,omitempty"`
+}
+
+// Result internal use only
+type Result struct {
+	RestartRequired *bool  `json:"restart_required,omitempty"`
+	Error           *Error `json:"error,omitempty"`
+}
+
+// Status status of the PM1 component contains the readings of a power meter without a
+// switch such as the Plus PM Mini
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/PM1#status
+type Status struct {
+	// ID Id of the PM1 component instance
+	ID *int `json:"id" yaml:"id"`
+	// Voltage volts
+	Voltage *float64 `json:"voltage,omitempty" yaml:"voltage,omitempty"`
+	// Current amperes
+	Current *float64 `json:"current,omitempty" yaml:"current,omitempty"`
+	// Apower active power in Watts
+	Apower *float64 `json:"apower,omitempty" yaml:"apower,omitempty"`
+	// Aprtpower apparent power in Volt-Amperes
+	Aprtpower *float64 `json:"aprtpower,omitempty" yaml:"aprtpower,omitempty"`
+	// Pf power factor
+	Pf *float64 `json:"pf,omitempty" yaml:"pf,omitempty"`
+	// Freq network frequency, Hz
+	Freq *float64 `json:"freq,omitempty" yaml:"freq,omitempty"`
+	// Aenergy information about the active energy counter
+	Aenergy *PM1Energy `json:"aenergy,omitempty" yaml:"aenergy,omitempty"`
+	// RetAenergy information about the returned active energy counter
+	RetAenergy *PM1Energy `json:"ret_aenergy,omitempty" yaml:"ret_aenergy,omitempty"`
+	// Errors error conditions such as out_of_range:active_power (shown if at least one error
+	// is present)
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Clone return copy
+func (t *Status) Clone() *Status {
+	c := &Status{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// PM1Energy information about an energy counter
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/PM1#status
+type PM1Energy struct {
+	// Total energy in Watt-hours
+	Total *float64 `json:"total,omitempty" yaml:"total,omitempty"`
+	// ByMinute energy by minute (in Milliwatt-hours) for the last three minutes (the lower
+	// the index of the element in the array, the closer to the current moment the minute)
+	ByMinute []float64 `json:"by_minute,omitempty" yaml:"by_minute,omitempty"`
+	// MinuteTs Unix timestamp of the first second of the last minute (in UTC)
+	MinuteTs *int `json:"minute_ts,omitempty" yaml:"minute_ts,omitempty"`
+}
+
+// Clone return copy
+func (t *PM1Energy) Clone() *PM1Energy {
+	c := &PM1Energy{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Config configuration of the PM1 component
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/PM1#configuration
+type Config struct {
+	// ID Id of the PM1 component instance
+	ID *int `json:"id" yaml:"id"`
+	// Name of the PM1 instance
+	Name *string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// Clone return copy
+func (t *Config) Clone() *Config {
+	c := &Config{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Equals returns true if equal
+func (t *Config) Equals(x *Config) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("Config receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("Config receiver is not nil but input is")
+		return false
+	}
+
+	if !util.CompareInt(t.ID, x.ID) {
+		zap.L().Info("Config ID not equal")
+		return false
+	}
+
+	if !util.CompareString(t.Name, x.Name) {
+		zap.L().Info("Config Name not equal")
+		return false
+	}
+
+	return true
+}
+
+func (t *Config) Merge(x *Config) {
+
+	if x == nil {
+		return
+	}
+
+	if t.ID == nil {
+		t.ID = x.ID
+	}
+
+	if t.Name == nil {
+		t.Name = x.Name
+	}
+}
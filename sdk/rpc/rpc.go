@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+)
+
+type MessageHandler = msg_types.MessageHandler
+type Request = msg_types.Request
+type Response = msg_types.Response
+
+// rawResponse is the response frame with the raw result
+type rawResponse struct {
+	Response
+	Result *json.RawMessage `json:"result,omitempty"`
+}
+
+// Call sends the method with the params using the handler and returns the result decoded
+// as R. This can be used to call any method including methods not modeled by this SDK
+// such as Shelly.ListTimezones. If params is nil the request is sent without params. An
+// error is returned if the device responds with an error or the result is missing or null.
+func Call[P, R any](ctx context.Context, handler MessageHandler, method string, params P) (R, error) {
+
+	var result R
+
+	b, err := send(ctx, handler, method, params)
+	if err != nil {
+		return result, err
+	}
+
+	if b == nil || string(*b) == "null" {
+		return result, fmt.Errorf("result is missing from response")
+	}
+
+	err = json.Unmarshal(*b, &result)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Exec sends the method with the params using the handler. The result is not decoded.
+// This is used for methods such as Shelly.Reboot that return a null result or when the
+// result is not needed. An error is returned if the device responds with an error.
+func Exec[P any](ctx context.Context, handler MessageHandler, method string, params P) error {
+	_, err := send(ctx, handler, method, params)
+	return err
+}
+
+// send sends the request and returns the raw result which may be nil
+func send[P any](ctx context.Context, handler MessageHandler, method string, params P) (*json.RawMessage, error) {
+
+	request := &Request{
+		Method: &method,
+	}
+
+	if !isNil(params) {
+		request.Params = params
+	}
+
+	b, err := handler.Send(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &rawResponse{}
+	err = json.Unmarshal(b, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Error != nil {
+		return nil, r.Error.WithRequest(r.Src, &method)
+	}
+
+	return r.Result, nil
+}
+
+// isNil returns true if v is nil or a nil pointer, map, slice or interface
+func isNil(v any) bool {
+
+	if v == nil {
+		return true
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+
+	return false
+}
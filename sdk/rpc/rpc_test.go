@@ -0,0 +1,152 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
+)
+
+// handler responds to every request with the response frame
+type handler struct {
+	response string
+	request  *msg_types.Request
+}
+
+func (t *handler) Send(ctx context.Context, request *msg_types.Request) ([]byte, error) {
+	t.request = request
+	return []byte(t.response), nil
+}
+
+type params struct {
+	ID int `json:"id"`
+}
+
+type result struct {
+	Output bool `json:"output"`
+}
+
+func TestCall(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		response string
+		want     *result
+		wantErr  error
+		rpcErr   bool
+	}{
+		{
+			name:     "result",
+			response: `{"id":1,"src":"shellyplus1pm-1","result":{"output":true}}`,
+			want:     &result{Output: true},
+		},
+		{
+			name:     "error object",
+			response: `{"id":1,"src":"shellyplus1pm-1","error":{"code":-105,"message":"Argument 'id', value 5 not found!"}}`,
+			wantErr:  msg_types.ErrNotFound,
+			rpcErr:   true,
+		},
+		{
+			name:     "missing result",
+			response: `{"id":1,"src":"shellyplus1pm-1"}`,
+		},
+		{
+			name:     "null result",
+			response: `{"id":1,"src":"shellyplus1pm-1","result":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			h := &handler{response: tt.response}
+
+			r, err := rpc.Call[*params, *result](context.Background(), h, "Switch.GetStatus", &params{ID: 0})
+
+			if *h.request.Method != "Switch.GetStatus" || h.request.Params == nil {
+				t.Errorf("request %+v, want method Switch.GetStatus with params", h.request)
+			}
+
+			if tt.want != nil {
+				if err != nil {
+					t.Fatalf("error %v, want nil", err)
+				}
+				if *r != *tt.want {
+					t.Errorf("result %+v, want %+v", r, tt.want)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("error nil, want error")
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error %v, want %v", err, tt.wantErr)
+			}
+
+			rpcErr := &msg_types.Error{}
+			if errors.As(err, &rpcErr) != tt.rpcErr {
+				t.Fatalf("error %v, Error want %t", err, tt.rpcErr)
+			}
+
+			if tt.rpcErr && (rpcErr.Method != "Switch.GetStatus" || rpcErr.DeviceID != "shellyplus1pm-1") {
+				t.Errorf("error method %s, device ID %s, want Switch.GetStatus, shellyplus1pm-1", rpcErr.Method, rpcErr.DeviceID)
+			}
+		})
+	}
+}
+
+func TestExec(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		response string
+		wantErr  error
+	}{
+		{
+			name:     "result",
+			response: `{"id":1,"src":"shellyplus1pm-1","result":{"was_on":false}}`,
+		},
+		{
+			name:     "error object",
+			response: `{"id":1,"src":"shellyplus1pm-1","error":{"code":-109,"message":"overpower"}}`,
+			wantErr:  msg_types.ErrFailedPrecondition,
+		},
+		{
+			name:     "missing result",
+			response: `{"id":1,"src":"shellyplus1pm-1"}`,
+		},
+		{
+			name:     "null result",
+			response: `{"id":1,"src":"shellyplus1pm-1","result":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			h := &handler{response: tt.response}
+
+			// Nil params are not sent
+			err := rpc.Exec[*params](context.Background(), h, "Shelly.Reboot", nil)
+
+			if h.request.Params != nil {
+				t.Errorf("params %v, want nil", h.request.Params)
+			}
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("error %v, want nil", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
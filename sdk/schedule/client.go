@@ -0,0 +1,243 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
+	"github.com/jodydadescott/shelly-client/sdk/schedule/types"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+)
+
+type MessageHandlerFactory = msg_types.MessageHandlerFactory
+type MessageHandler = msg_types.MessageHandler
+type Request = msg_types.Request
+
+type Jobs = types.Jobs
+type Job = types.Job
+type Call = types.Call
+type Timespec = types.Timespec
+type Params = types.Params
+type Result = types.Result
+
+// New returns new instance of client
+func New(messageHandlerFactory MessageHandlerFactory) *Client {
+	return &Client{
+		MessageHandlerFactory: messageHandlerFactory,
+	}
+}
+
+// Client the component client
+type Client struct {
+	MessageHandlerFactory
+	messageHandlerMutex sync.Mutex
+	_messageHandler     MessageHandler
+}
+
+func (t *Client) getMessageHandler() MessageHandler {
+	t.messageHandlerMutex.Lock()
+	defer t.messageHandlerMutex.Unlock()
+
+	if t._messageHandler != nil {
+		return t._messageHandler
+	}
+
+	t._messageHandler = t.NewHandle(Component)
+	return t._messageHandler
+}
+
+func getErr(method string, id *int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// An error returned by the device already has the method and device ID
+	rpcErr := &msg_types.Error{}
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
+	if id == nil {
+		return fmt.Errorf("component %s, method %s, error %w", Component, method, err)
+	}
+
+	return fmt.Errorf("component %s, method %s, id %d, error %w", Component, method, *id, err)
+}
+
+// List returns all jobs of the device
+func (t *Client) List(ctx context.Context) (*Jobs, error) {
+
+	method := Component + ".List"
+
+	result, err := rpc.Call[any, *Jobs](ctx, t.getMessageHandler(), method, nil)
+	if err != nil {
+		return nil, getErr(method, nil, err)
+	}
+
+	return result, nil
+}
+
+// Create creates the job and returns the ID assigned by the device. The ID of the job is
+// ignored.
+func (t *Client) Create(ctx context.Context, job *Job) (int, error) {
+
+	method := Component + ".Create"
+
+	if job == nil || job.Timespec == nil {
+		return 0, fmt.Errorf("job timespec is required")
+	}
+
+	enable := job.IsEnabled()
+
+	result, err := rpc.Call[*Params, *Result](ctx, t.getMessageHandler(), method, &Params{
+		Enable:   &enable,
+		Timespec: job.Timespec,
+		Calls:    job.Calls,
+	})
+
+	if err != nil {
+		return 0, getErr(method, nil, err)
+	}
+
+	if result.ID == nil {
+		return 0, getErr(method, nil, fmt.Errorf("result is missing id"))
+	}
+
+	return *result.ID, nil
+}
+
+// Update replaces the enable, timespec and calls of the job with the ID of the job
+func (t *Client) Update(ctx context.Context, job *Job) error {
+
+	method := Component + ".Update"
+
+	if job == nil || job.ID == nil {
+		return fmt.Errorf("job ID is required")
+	}
+
+	enable := job.IsEnabled()
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID:       job.ID,
+		Enable:   &enable,
+		Timespec: job.Timespec,
+		Calls:    job.Calls,
+	})
+
+	return getErr(method, job.ID, err)
+}
+
+// Delete deletes the job with the ID
+func (t *Client) Delete(ctx context.Context, id int) error {
+
+	method := Component + ".Delete"
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID: &id,
+	})
+
+	return getErr(method, &id, err)
+}
+
+// DeleteAll deletes all jobs of the device
+func (t *Client) DeleteAll(ctx context.Context) error {
+
+	method := Component + ".DeleteAll"
+
+	err := rpc.Exec[any](ctx, t.getMessageHandler(), method, nil)
+
+	return getErr(method, nil, err)
+}
+
+// SetConfig converges the jobs of the device to jobs. Jobs that are equal to an existing
+// job are left alone. A job is updated in place if it has the ID of an existing job or
+// else the same timespec as an existing job. The remaining jobs are created and the
+// remaining existing jobs are deleted. The jobs are validated before the device is
+// changed. Returns true if the device was changed.
+func (t *Client) SetConfig(ctx context.Context, jobs []*Job) (bool, error) {
+
+	for i, job := range jobs {
+		if job == nil {
+			return false, fmt.Errorf("job %d is nil", i)
+		}
+		if job.Timespec == nil {
+			return false, fmt.Errorf("job %d timespec is required", i)
+		}
+	}
+
+	existing, err := t.List(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	matcher := util.NewMatcher(existing.Jobs)
+	var pending []*Job
+
+	for _, job := range jobs {
+		if _, ok := matcher.Match(job.Equals); !ok {
+			pending = append(pending, job)
+		}
+	}
+
+	var updates []*Job
+	var creates []*Job
+
+	for _, job := range pending {
+
+		match, ok := matcher.Match(func(v *Job) bool { return job.ID != nil && v.ID != nil && *job.ID == *v.ID })
+
+		if !ok {
+			match, ok = matcher.Match(func(v *Job) bool { return job.Timespec.Equals(v.Timespec) })
+		}
+
+		if !ok {
+			creates = append(creates, job)
+			continue
+		}
+
+		update := job.Clone()
+		update.ID = match.ID
+		updates = append(updates, update)
+	}
+
+	var errors *multierror.Error
+	changed := false
+
+	for _, v := range matcher.Unmatched() {
+		if v.ID == nil {
+			continue
+		}
+		zap.L().Debug(fmt.Sprintf("Deleting schedule %d", *v.ID))
+		changed = true
+		err := t.Delete(ctx, *v.ID)
+		if err != nil {
+			errors = multierror.Append(errors, err)
+		}
+	}
+
+	for _, v := range updates {
+		zap.L().Debug(fmt.Sprintf("Updating schedule %d", *v.ID))
+		changed = true
+		err := t.Update(ctx, v)
+		if err != nil {
+			errors = multierror.Append(errors, err)
+		}
+	}
+
+	for _, v := range creates {
+		zap.L().Debug(fmt.Sprintf("Creating schedule with timespec %s", v.Timespec.String()))
+		changed = true
+		_, err := t.Create(ctx, v)
+		if err != nil {
+			errors = multierror.Append(errors, err)
+		}
+	}
+
+	return changed, errors.ErrorOrNil()
+}
@@ -0,0 +1,147 @@
+package schedule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	"github.com/jodydadescott/shelly-client/sdk/schedule"
+	"github.com/jodydadescott/shelly-client/sdk/schedule/types"
+)
+
+func newClient(t *testing.T) (*schedule.Client, *fake.Device) {
+	device := fake.NewDevice(&fake.DeviceConfig{Switch: 1})
+	factory := fake.New(nil, device)
+	t.Cleanup(factory.Close)
+	return schedule.New(factory), device
+}
+
+func TestSetConfig(t *testing.T) {
+
+	timespec := func(s string) *types.Timespec {
+		v, err := types.ParseTimespec(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	on := []*types.Call{{Method: "Switch.Set", Params: map[string]interface{}{"id": float64(0), "on": true}}}
+	off := []*types.Call{{Method: "Switch.Set", Params: map[string]interface{}{"id": float64(0), "on": false}}}
+
+	morning := timespec("0 0 7 * * MON-FRI")
+	evening := timespec("0 0 22 * * *")
+
+	id1 := 1
+
+	tests := []struct {
+		name     string
+		existing []*types.Job
+		jobs     []*types.Job
+		want     []*types.Job
+		// calls the methods sent to converge the jobs
+		calls   []string
+		wantErr bool
+	}{
+		{
+			name:     "keep",
+			existing: []*types.Job{{Timespec: morning, Calls: on}},
+			jobs:     []*types.Job{{Timespec: morning, Calls: on}},
+			want:     []*types.Job{{ID: &id1, Timespec: morning, Calls: on}},
+		},
+		{
+			name:     "update by ID",
+			existing: []*types.Job{{Timespec: morning, Calls: on}},
+			jobs:     []*types.Job{{ID: &id1, Timespec: evening, Calls: off}},
+			want:     []*types.Job{{ID: &id1, Timespec: evening, Calls: off}},
+			calls:    []string{"Schedule.Update"},
+		},
+		{
+			name:     "update by timespec",
+			existing: []*types.Job{{Timespec: morning, Calls: on}},
+			jobs:     []*types.Job{{Timespec: morning, Calls: off}},
+			want:     []*types.Job{{ID: &id1, Timespec: morning, Calls: off}},
+			calls:    []string{"Schedule.Update"},
+		},
+		{
+			name:  "create",
+			jobs:  []*types.Job{{Timespec: morning, Calls: on}},
+			want:  []*types.Job{{Timespec: morning, Calls: on}},
+			calls: []string{"Schedule.Create"},
+		},
+		{
+			name:     "delete",
+			existing: []*types.Job{{Timespec: morning, Calls: on}, {Timespec: evening, Calls: off}},
+			jobs:     []*types.Job{{Timespec: evening, Calls: off}},
+			want:     []*types.Job{{Timespec: evening, Calls: off}},
+			calls:    []string{"Schedule.Delete"},
+		},
+		{
+			name:     "nil job",
+			existing: []*types.Job{{Timespec: morning, Calls: on}},
+			jobs:     []*types.Job{nil},
+			want:     []*types.Job{{Timespec: morning, Calls: on}},
+			wantErr:  true,
+		},
+		{
+			name:     "no timespec",
+			existing: []*types.Job{{Timespec: morning, Calls: on}},
+			jobs:     []*types.Job{{Calls: off}},
+			want:     []*types.Job{{Timespec: morning, Calls: on}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctx := context.Background()
+			client, device := newClient(t)
+
+			for _, v := range tt.existing {
+				_, err := client.Create(ctx, v)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			before := len(device.Calls())
+
+			changed, err := client.SetConfig(ctx, tt.jobs)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, error want %t", err, tt.wantErr)
+			}
+
+			if want := len(tt.calls) > 0; changed != want {
+				t.Errorf("changed %t, want %t", changed, want)
+			}
+
+			var calls []string
+			for _, v := range device.Calls()[before:] {
+				if v != "Schedule.List" {
+					calls = append(calls, v)
+				}
+			}
+
+			if len(calls) != len(tt.calls) || (len(calls) > 0 && calls[0] != tt.calls[0]) {
+				t.Errorf("calls %v, want %v", calls, tt.calls)
+			}
+
+			jobs, err := client.List(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !types.JobsEqual(jobs.Jobs, tt.want) {
+				t.Errorf("jobs not equal")
+			}
+
+			for i, v := range tt.want {
+				if v.ID != nil && (i >= len(jobs.Jobs) || jobs.Jobs[i].ID == nil || *jobs.Jobs[i].ID != *v.ID) {
+					t.Errorf("job %d does not have ID %d", i, *v.ID)
+				}
+			}
+		})
+	}
+}
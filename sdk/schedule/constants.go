@@ -0,0 +1,5 @@
+package schedule
+
+const (
+	Component = "Schedule"
+)
@@ -0,0 +1,8 @@
+package types
+
+const (
+	// SunRise the sunrise event of a Timespec
+	SunRise = "sunrise"
+	// SunSet the sunset event of a Timespec
+	SunSet = "sunset"
+)
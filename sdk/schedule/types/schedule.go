@@ -0,0 +1,242 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+)
+
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+// Params internal use only
+type Params struct {
+	ID       *int      `json:"id,omitempty" yaml:"id,omitempty"`
+	Enable   *bool     `json:"enable,omitempty" yaml:"enable,omitempty"`
+	Timespec *Timespec `json:"timespec,omitempty" yaml:"timespec,omitempty"`
+	Calls    []*Call   `json:"calls,omitempty" yaml:"calls,omitempty"`
+}
+
+// Result internal use only
+type Result struct {
+	ID  *int `json:"id,omitempty"`
+	Rev *int `json:"rev,omitempty"`
+}
+
+// Jobs the schedule jobs of the device
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Schedule#schedulelist
+type Jobs struct {
+	// Jobs the jobs
+	Jobs []*Job `json:"jobs,omitempty" yaml:"jobs,omitempty"`
+	// Rev the current revision of the schedules
+	Rev *int `json:"rev,omitempty" yaml:"rev,omitempty"`
+}
+
+// Clone return copy
+func (t *Jobs) Clone() *Jobs {
+	c := &Jobs{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Job a schedule job. At the time of the timespec the calls are invoked in order.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Schedule#schedulecreate
+type Job struct {
+	// ID Id assigned to the job by the device. This is ignored by Equals.
+	ID *int `json:"id,omitempty" yaml:"id,omitempty"`
+	// Enable true to enable the execution of this job, false otherwise. Defaults to true.
+	Enable *bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// Timespec when the job is invoked
+	Timespec *Timespec `json:"timespec,omitempty" yaml:"timespec,omitempty"`
+	// Calls the RPC methods invoked by the job
+	Calls []*Call `json:"calls,omitempty" yaml:"calls,omitempty"`
+}
+
+// Clone return copy
+func (t *Job) Clone() *Job {
+	c := &Job{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// IsEnabled returns true if Enable is nil or true
+func (t *Job) IsEnabled() bool {
+	return t.Enable == nil || *t.Enable
+}
+
+// Equals returns true if equal. The ID is not compared.
+func (t *Job) Equals(x *Job) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("Job receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("Job receiver is not nil but input is")
+		return false
+	}
+
+	if t.IsEnabled() != x.IsEnabled() {
+		zap.L().Info("Job Enable not equal")
+		return false
+	}
+
+	if !t.Timespec.Equals(x.Timespec) {
+		zap.L().Info("Job Timespec not equal")
+		return false
+	}
+
+	if len(t.Calls) != len(x.Calls) {
+		zap.L().Info("Job Calls not equal")
+		return false
+	}
+
+	for i := range t.Calls {
+		if !t.Calls[i].Equals(x.Calls[i]) {
+			zap.L().Info(fmt.Sprintf("Job Call %d not equal", i))
+			return false
+		}
+	}
+
+	return true
+}
+
+// JobsEqual returns true if a and b contain the same jobs in any order. The IDs are not
+// compared.
+func JobsEqual(a, b []*Job) bool {
+
+	if len(a) != len(b) {
+		zap.L().Info(fmt.Sprintf("Jobs count %d not equal to %d", len(a), len(b)))
+		return false
+	}
+
+	used := make([]bool, len(b))
+
+	for _, j := range a {
+
+		found := false
+
+		for i, k := range b {
+			if !used[i] && j.Equals(k) {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			zap.L().Info(fmt.Sprintf("Job with timespec %s not found", j.Timespec.String()))
+			return false
+		}
+	}
+
+	return true
+}
+
+// Call an RPC method invoked by a job
+type Call struct {
+	// Method name of the method such as Switch.Set
+	Method string `json:"method" yaml:"method"`
+	// Params of the method such as {"id": 0, "on": true}
+	Params map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+// UnmarshalYAML decodes the call. Nested params are converted so they can be sent as JSON.
+func (t *Call) UnmarshalYAML(unmarshal func(interface{}) error) error {
+
+	raw := struct {
+		Method string                 `yaml:"method"`
+		Params map[string]interface{} `yaml:"params"`
+	}{}
+
+	err := unmarshal(&raw)
+	if err != nil {
+		return err
+	}
+
+	t.Method = raw.Method
+	t.Params = nil
+
+	if raw.Params != nil {
+		t.Params = util.StringKeys(raw.Params).(map[string]interface{})
+	}
+
+	return nil
+}
+
+// Clone return copy
+func (t *Call) Clone() *Call {
+	c := &Call{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Equals returns true if equal. The method name is not case sensitive. The params are
+// compared by their JSON encoding so 1 and 1.0 are equal.
+func (t *Call) Equals(x *Call) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("Call receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("Call receiver is not nil but input is")
+		return false
+	}
+
+	if !strings.EqualFold(t.Method, x.Method) {
+		zap.L().Info("Call Method not equal")
+		return false
+	}
+
+	if !util.CompareString(normalizeParams(t.Params), normalizeParams(x.Params)) {
+		zap.L().Info("Call Params not equal")
+		return false
+	}
+
+	return true
+}
+
+// normalizeParams returns the params as JSON with the numbers decoded as float64 and the
+// keys sorted. Nil and empty params are both returned as nil.
+func normalizeParams(params map[string]interface{}) *string {
+
+	if len(params) == 0 {
+		return nil
+	}
+
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil
+	}
+
+	var m map[string]interface{}
+	if json.Unmarshal(b, &m) != nil {
+		return nil
+	}
+
+	b, err = json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+
+	s := string(b)
+	return &s
+}
@@ -0,0 +1,176 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Timespec when a job is invoked. The format is that of cron with seconds; six fields
+// separated by spaces: second, minute, hour, day of month, month and day of week such as
+// "0 30 7 * * MON-FRI". The first three fields can be replaced by a sun event with an
+// optional offset such as "@sunset-30m * * SAT,SUN". The Timespec is encoded as this string
+// in JSON and YAML.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Schedule#timespec
+type Timespec struct {
+	// Second 0-59
+	Second string
+	// Minute 0-59
+	Minute string
+	// Hour 0-23
+	Hour string
+	// Sun sunrise or sunset. If set Second, Minute and Hour are not used.
+	Sun string
+	// SunOffset offset from the sun event with a sign such as +1h or -30m
+	SunOffset string
+	// DayOfMonth 1-31
+	DayOfMonth string
+	// Month 1-12 or JAN-DEC
+	Month string
+	// DayOfWeek 0-6 or SUN-SAT
+	DayOfWeek string
+}
+
+// ParseTimespec parses a timespec such as "0 30 7 * * MON-FRI" or "@sunrise+15m * * *"
+func ParseTimespec(s string) (*Timespec, error) {
+
+	fields := strings.Fields(s)
+
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("timespec %q with sun event must have 4 fields", s)
+		}
+
+		t := &Timespec{
+			DayOfMonth: fields[1],
+			Month:      fields[2],
+			DayOfWeek:  fields[3],
+		}
+
+		event := strings.ToLower(strings.TrimPrefix(fields[0], "@"))
+
+		if i := strings.IndexAny(event, "+-"); i >= 0 {
+			t.SunOffset = event[i:]
+			event = event[:i]
+
+			_, err := time.ParseDuration(t.SunOffset[1:])
+			if err != nil {
+				return nil, fmt.Errorf("timespec %q sun offset %s is not valid; %w", s, t.SunOffset, err)
+			}
+		}
+
+		if event != SunRise && event != SunSet {
+			return nil, fmt.Errorf("timespec %q sun event must be %s or %s", s, SunRise, SunSet)
+		}
+
+		t.Sun = event
+		return t, nil
+	}
+
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("timespec %q must have 6 fields", s)
+	}
+
+	return &Timespec{
+		Second:     fields[0],
+		Minute:     fields[1],
+		Hour:       fields[2],
+		DayOfMonth: fields[3],
+		Month:      fields[4],
+		DayOfWeek:  fields[5],
+	}, nil
+}
+
+// String returns the timespec in the format used by the device
+func (t *Timespec) String() string {
+
+	if t == nil {
+		return ""
+	}
+
+	field := func(s string) string {
+		if s == "" {
+			return "*"
+		}
+		return s
+	}
+
+	if t.Sun != "" {
+		return strings.Join([]string{"@" + t.Sun + t.SunOffset, field(t.DayOfMonth), field(t.Month), field(t.DayOfWeek)}, " ")
+	}
+
+	return strings.Join([]string{field(t.Second), field(t.Minute), field(t.Hour), field(t.DayOfMonth), field(t.Month), field(t.DayOfWeek)}, " ")
+}
+
+// Clone return copy
+func (t *Timespec) Clone() *Timespec {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+// Equals returns true if equal. Names such as MON are not case sensitive.
+func (t *Timespec) Equals(x *Timespec) bool {
+
+	if t == nil {
+		return x == nil
+	}
+
+	if x == nil {
+		return false
+	}
+
+	return strings.EqualFold(t.String(), x.String())
+}
+
+// MarshalJSON encodes the timespec as a string
+func (t Timespec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON decodes the timespec from a string
+func (t *Timespec) UnmarshalJSON(b []byte) error {
+
+	var s string
+
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+
+	x, err := ParseTimespec(s)
+	if err != nil {
+		return err
+	}
+
+	*t = *x
+	return nil
+}
+
+// MarshalYAML encodes the timespec as a string
+func (t Timespec) MarshalYAML() (interface{}, error) {
+	return t.String(), nil
+}
+
+// UnmarshalYAML decodes the timespec from a string
+func (t *Timespec) UnmarshalYAML(unmarshal func(interface{}) error) error {
+
+	var s string
+
+	err := unmarshal(&s)
+	if err != nil {
+		return err
+	}
+
+	x, err := ParseTimespec(s)
+	if err != nil {
+		return err
+	}
+
+	*t = *x
+	return nil
+}
@@ -0,0 +1,465 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
+	"github.com/jodydadescott/shelly-client/sdk/script/types"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+)
+
+type MessageHandlerFactory = msg_types.MessageHandlerFactory
+type MessageHandler = msg_types.MessageHandler
+type Request = msg_types.Request
+
+type Scripts = types.Scripts
+type Script = types.Script
+type Info = types.Info
+type Config = types.Config
+type Status = types.Status
+type Params = types.Params
+type Result = types.Result
+
+// New returns new instance of client
+func New(messageHandlerFactory MessageHandlerFactory) *Client {
+	return &Client{
+		MessageHandlerFactory: messageHandlerFactory,
+	}
+}
+
+// Client the component client
+type Client struct {
+	MessageHandlerFactory
+	messageHandlerMutex sync.Mutex
+	_messageHandler     MessageHandler
+}
+
+func (t *Client) getMessageHandler() MessageHandler {
+	t.messageHandlerMutex.Lock()
+	defer t.messageHandlerMutex.Unlock()
+
+	if t._messageHandler != nil {
+		return t._messageHandler
+	}
+
+	t._messageHandler = t.NewHandle(Component)
+	return t._messageHandler
+}
+
+func getErr(method string, id *int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// An error returned by the device already has the method and device ID
+	rpcErr := &msg_types.Error{}
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
+	if id == nil {
+		return fmt.Errorf("component %s, method %s, error %w", Component, method, err)
+	}
+
+	return fmt.Errorf("component %s, method %s, id %d, error %w", Component, method, *id, err)
+}
+
+// List returns all scripts of the device. The code is not returned; see GetCode.
+func (t *Client) List(ctx context.Context) (*Scripts, error) {
+
+	method := Component + ".List"
+
+	result, err := rpc.Call[any, *Scripts](ctx, t.getMessageHandler(), method, nil)
+	if err != nil {
+		return nil, getErr(method, nil, err)
+	}
+
+	return result, nil
+}
+
+// Create creates an empty script with the name and returns the ID assigned by the device
+func (t *Client) Create(ctx context.Context, name string) (int, error) {
+
+	method := Component + ".Create"
+
+	result, err := rpc.Call[*Params, *Result](ctx, t.getMessageHandler(), method, &Params{
+		Name: &name,
+	})
+
+	if err != nil {
+		return 0, getErr(method, nil, err)
+	}
+
+	if result.ID == nil {
+		return 0, getErr(method, nil, fmt.Errorf("result is missing id"))
+	}
+
+	return *result.ID, nil
+}
+
+// Delete deletes the script with the ID. A running script must be stopped first.
+func (t *Client) Delete(ctx context.Context, id int) error {
+
+	method := Component + ".Delete"
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID: &id,
+	})
+
+	return getErr(method, &id, err)
+}
+
+// GetStatus returns status for script or error
+func (t *Client) GetStatus(ctx context.Context, id int) (*Status, error) {
+
+	method := Component + ".GetStatus"
+
+	result, err := rpc.Call[*Params, *Status](ctx, t.getMessageHandler(), method, &Params{
+		ID: &id,
+	})
+
+	if err != nil {
+		return nil, getErr(method, &id, err)
+	}
+
+	return result, nil
+}
+
+// GetConfig returns script config or error
+func (t *Client) GetConfig(ctx context.Context, id int) (*Config, error) {
+
+	method := Component + ".GetConfig"
+
+	result, err := rpc.Call[*Params, *Config](ctx, t.getMessageHandler(), method, &Params{
+		ID: &id,
+	})
+
+	if err != nil {
+		return nil, getErr(method, &id, err)
+	}
+
+	return result, nil
+}
+
+// SetConfig applies config to the script with the ID of the config
+func (t *Client) SetConfig(ctx context.Context, config *Config) error {
+
+	method := Component + ".SetConfig"
+
+	if config == nil || config.ID == nil {
+		return fmt.Errorf("config ID is nil")
+	}
+
+	config = config.Clone()
+
+	_, err := rpc.Call[*Params, *Result](ctx, t.getMessageHandler(), method, &Params{
+		ID:     config.ID,
+		Config: config,
+	})
+
+	return getErr(method, config.ID, err)
+}
+
+// PutCode replaces the code of the script. The code is sent in chunks as the size of a
+// request is limited.
+func (t *Client) PutCode(ctx context.Context, id int, code string) error {
+
+	method := Component + ".PutCode"
+
+	chunks := util.SplitByWidth(code, util.MaxRPCChunkSize)
+	if len(chunks) == 0 {
+		chunks = []string{""}
+	}
+
+	for i, chunk := range chunks {
+
+		chunk := chunk
+		append := i > 0
+
+		err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+			ID:     &id,
+			Code:   &chunk,
+			Append: &append,
+		})
+
+		if err != nil {
+			return getErr(method, &id, err)
+		}
+	}
+
+	return nil
+}
+
+// GetCode returns the code of the script. The code is read in chunks as the size of a
+// response is limited.
+func (t *Client) GetCode(ctx context.Context, id int) (string, error) {
+
+	method := Component + ".GetCode"
+
+	var b strings.Builder
+
+	for {
+
+		offset := b.Len()
+
+		result, err := rpc.Call[*Params, *Result](ctx, t.getMessageHandler(), method, &Params{
+			ID:     &id,
+			Offset: &offset,
+		})
+
+		if err != nil {
+			return "", getErr(method, &id, err)
+		}
+
+		if result.Data != nil {
+			b.WriteString(*result.Data)
+		}
+
+		if result.Left == nil || *result.Left <= 0 || result.Data == nil || *result.Data == "" {
+			return b.String(), nil
+		}
+	}
+}
+
+// Start starts the script
+func (t *Client) Start(ctx context.Context, id int) error {
+
+	method := Component + ".Start"
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID: &id,
+	})
+
+	return getErr(method, &id, err)
+}
+
+// Stop stops the script
+func (t *Client) Stop(ctx context.Context, id int) error {
+
+	method := Component + ".Stop"
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID: &id,
+	})
+
+	return getErr(method, &id, err)
+}
+
+// Eval evaluates the code in the context of the running script and returns the result
+func (t *Client) Eval(ctx context.Context, id int, code string) (string, error) {
+
+	method := Component + ".Eval"
+
+	result, err := rpc.Call[*Params, *Result](ctx, t.getMessageHandler(), method, &Params{
+		ID:   &id,
+		Code: &code,
+	})
+
+	if err != nil {
+		return "", getErr(method, &id, err)
+	}
+
+	if result.Result == nil {
+		return "", nil
+	}
+
+	return *result.Result, nil
+}
+
+// GetScripts returns the scripts of the device with their code. Enable and Running are
+// always set.
+func (t *Client) GetScripts(ctx context.Context) ([]*Script, error) {
+
+	list, err := t.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scripts := []*Script{}
+
+	for _, v := range list.Scripts {
+
+		if v.ID == nil {
+			continue
+		}
+
+		code, err := t.GetCode(ctx, *v.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		enable := v.Enable != nil && *v.Enable
+		running := v.Running != nil && *v.Running
+
+		scripts = append(scripts, &Script{
+			ID:      v.ID,
+			Name:    v.Name,
+			Enable:  &enable,
+			Running: &running,
+			Code:    &code,
+		})
+	}
+
+	return scripts, nil
+}
+
+// SetScripts converges the scripts of the device to scripts. Scripts are matched to the
+// scripts of the device by name. The code and config of a matched script are replaced if
+// they differ and it is started or stopped as required. The remaining scripts are created
+// and the remaining scripts of the device are stopped and deleted. Each script must have
+// its code set; the files are not read so use Script.Load first. Returns true if the device
+// was changed.
+func (t *Client) SetScripts(ctx context.Context, scripts []*Script) (bool, error) {
+
+	existing, err := t.GetScripts(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	matcher := util.NewMatcher(existing)
+
+	type pair struct {
+		desired  *Script
+		existing *Script
+	}
+
+	var pairs []*pair
+
+	for _, v := range scripts {
+
+		if v.GetName() == "" {
+			return false, fmt.Errorf("script name is required")
+		}
+
+		if v.Code == nil {
+			return false, fmt.Errorf("script %s has no code", v.GetName())
+		}
+
+		desired := v.Clone()
+		desired.Sanatize()
+
+		match, _ := matcher.Match(func(x *Script) bool { return x.GetName() == desired.GetName() })
+		pairs = append(pairs, &pair{desired: desired, existing: match})
+	}
+
+	var errors *multierror.Error
+	changed := false
+
+	for _, v := range matcher.Unmatched() {
+
+		if v.ID == nil {
+			continue
+		}
+
+		zap.L().Debug(fmt.Sprintf("Deleting script %d (%s)", *v.ID, v.GetName()))
+		changed = true
+
+		if *v.Running {
+			err := t.Stop(ctx, *v.ID)
+			if err != nil {
+				errors = multierror.Append(errors, err)
+				continue
+			}
+		}
+
+		err := t.Delete(ctx, *v.ID)
+		if err != nil {
+			errors = multierror.Append(errors, err)
+		}
+	}
+
+	for _, v := range pairs {
+		c, err := t.setScript(ctx, v.desired, v.existing)
+		if c {
+			changed = true
+		}
+		if err != nil {
+			errors = multierror.Append(errors, err)
+		}
+	}
+
+	return changed, errors.ErrorOrNil()
+}
+
+// setScript converges the existing script to the desired script. The script is created if
+// existing is nil. Returns true if the device was changed.
+func (t *Client) setScript(ctx context.Context, desired *Script, existing *Script) (bool, error) {
+
+	if existing != nil && desired.Equals(existing) {
+		return false, nil
+	}
+
+	running := false
+
+	if existing == nil {
+
+		zap.L().Debug(fmt.Sprintf("Creating script %s", desired.GetName()))
+
+		id, err := t.Create(ctx, desired.GetName())
+		if err != nil {
+			return false, err
+		}
+
+		disabled := false
+		existing = &Script{ID: &id, Name: desired.Name, Enable: &disabled}
+
+	} else {
+		running = *existing.Running
+	}
+
+	id := *existing.ID
+
+	if !util.CompareString(desired.Code, existing.Code) {
+
+		zap.L().Debug(fmt.Sprintf("Updating code of script %d (%s)", id, desired.GetName()))
+
+		// The code of a running script can not be replaced
+		if running {
+			err := t.Stop(ctx, id)
+			if err != nil {
+				return true, err
+			}
+			running = false
+		}
+
+		err := t.PutCode(ctx, id, *desired.Code)
+		if err != nil {
+			return true, err
+		}
+	}
+
+	if !util.CompareBool(desired.Enable, existing.Enable) {
+
+		zap.L().Debug(fmt.Sprintf("Updating config of script %d (%s)", id, desired.GetName()))
+
+		err := t.SetConfig(ctx, &Config{
+			ID:     &id,
+			Name:   desired.Name,
+			Enable: desired.Enable,
+		})
+
+		if err != nil {
+			return true, err
+		}
+	}
+
+	if *desired.Running && !running {
+		zap.L().Debug(fmt.Sprintf("Starting script %d (%s)", id, desired.GetName()))
+		return true, t.Start(ctx, id)
+	}
+
+	if !*desired.Running && running {
+		zap.L().Debug(fmt.Sprintf("Stopping script %d (%s)", id, desired.GetName()))
+		return true, t.Stop(ctx, id)
+	}
+
+	return true, nil
+}
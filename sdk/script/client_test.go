@@ -0,0 +1,175 @@
+package script_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	"github.com/jodydadescott/shelly-client/sdk/script"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+)
+
+func newClient(t *testing.T) (*script.Client, *fake.Device) {
+	device := fake.NewDevice(&fake.DeviceConfig{Switch: 1})
+	factory := fake.New(nil, device)
+	t.Cleanup(factory.Close)
+	return script.New(factory), device
+}
+
+func countCalls(device *fake.Device, method string) int {
+	count := 0
+	for _, v := range device.Calls() {
+		if v == method {
+			count++
+		}
+	}
+	return count
+}
+
+func TestCode(t *testing.T) {
+
+	tests := []struct {
+		name string
+		code string
+		puts int
+	}{
+		{name: "empty", code: "", puts: 1},
+		{name: "one chunk", code: strings.Repeat("a", util.MaxRPCChunkSize), puts: 1},
+		{name: "one more", code: strings.Repeat("a", util.MaxRPCChunkSize+1), puts: 2},
+		{name: "chunks", code: strings.Repeat("let x = 1;\n", 250), puts: 3},
+		// A rune is not split between chunks
+		{name: "multibyte", code: strings.Repeat("a", util.MaxRPCChunkSize-1) + "é" + "b", puts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctx := context.Background()
+			client, device := newClient(t)
+
+			id, err := client.Create(ctx, "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = client.PutCode(ctx, id, tt.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if puts := countCalls(device, "Script.PutCode"); puts != tt.puts {
+				t.Errorf("PutCode sent %d times, want %d", puts, tt.puts)
+			}
+
+			code, err := client.GetCode(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if code != tt.code {
+				t.Errorf("code of length %d, want length %d", len(code), len(tt.code))
+			}
+		})
+	}
+}
+
+func TestSetScripts(t *testing.T) {
+
+	str := func(s string) *string { return &s }
+	yes, no := true, false
+
+	tests := []struct {
+		name     string
+		existing []*script.Script
+		scripts  []*script.Script
+		want     []*script.Script
+		changed  bool
+		wantErr  bool
+	}{
+		{
+			name:    "create",
+			scripts: []*script.Script{{Name: str("a"), Enable: &yes, Code: str("print(1)")}},
+			want:    []*script.Script{{Name: str("a"), Enable: &yes, Running: &yes, Code: str("print(1)")}},
+			changed: true,
+		},
+		{
+			name:     "keep",
+			existing: []*script.Script{{Name: str("a"), Code: str("print(1)")}},
+			scripts:  []*script.Script{{Name: str("a"), Code: str("print(1)")}},
+			want:     []*script.Script{{Name: str("a"), Enable: &no, Running: &no, Code: str("print(1)")}},
+		},
+		{
+			name:     "update",
+			existing: []*script.Script{{Name: str("a"), Enable: &yes, Code: str("print(1)")}},
+			scripts:  []*script.Script{{Name: str("a"), Running: &no, Code: str("print(2)")}},
+			want:     []*script.Script{{Name: str("a"), Enable: &no, Running: &no, Code: str("print(2)")}},
+			changed:  true,
+		},
+		{
+			name:     "delete",
+			existing: []*script.Script{{Name: str("a"), Enable: &yes, Code: str("print(1)")}, {Name: str("b"), Code: str("print(2)")}},
+			scripts:  []*script.Script{{Name: str("b"), Code: str("print(2)")}},
+			want:     []*script.Script{{Name: str("b"), Enable: &no, Running: &no, Code: str("print(2)")}},
+			changed:  true,
+		},
+		{
+			name:     "no code",
+			existing: []*script.Script{{Name: str("a"), Code: str("print(1)")}},
+			scripts:  []*script.Script{{Name: str("b"), File: str("b.js")}},
+			want:     []*script.Script{{Name: str("a"), Enable: &no, Running: &no, Code: str("print(1)")}},
+			wantErr:  true,
+		},
+		{
+			name:     "no name",
+			existing: []*script.Script{{Name: str("a"), Code: str("print(1)")}},
+			scripts:  []*script.Script{{Code: str("print(2)")}},
+			want:     []*script.Script{{Name: str("a"), Enable: &no, Running: &no, Code: str("print(1)")}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctx := context.Background()
+			client, _ := newClient(t)
+
+			if tt.existing != nil {
+				_, err := client.SetScripts(ctx, tt.existing)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			changed, err := client.SetScripts(ctx, tt.scripts)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, error want %t", err, tt.wantErr)
+			}
+
+			if changed != tt.changed {
+				t.Errorf("changed %t, want %t", changed, tt.changed)
+			}
+
+			scripts, err := client.GetScripts(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(scripts) != len(tt.want) {
+				t.Fatalf("%d scripts, want %d", len(scripts), len(tt.want))
+			}
+
+			for i, v := range tt.want {
+				if !scripts[i].Equals(v) {
+					t.Errorf("script %s, want %s", describe(scripts[i]), describe(v))
+				}
+			}
+		})
+	}
+}
+
+func describe(v *script.Script) string {
+	return v.GetName() + " " + strings.TrimSpace(*v.Code)
+}
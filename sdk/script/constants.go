@@ -0,0 +1,5 @@
+package script
+
+const (
+	Component = "Script"
+)
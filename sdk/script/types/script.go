@@ -0,0 +1,285 @@
+package types
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+)
+
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+// Params internal use only
+type Params struct {
+	ID     *int    `json:"id,omitempty" yaml:"id,omitempty"`
+	Name   *string `json:"name,omitempty" yaml:"name,omitempty"`
+	Config *Config `json:"config,omitempty" yaml:"config,omitempty"`
+	Code   *string `json:"code,omitempty" yaml:"code,omitempty"`
+	Append *bool   `json:"append,omitempty" yaml:"append,omitempty"`
+	Offset *int    `json:"offset,omitempty" yaml:"offset,omitempty"`
+	Len    *int    `json:"len,omitempty" yaml:"len,omitempty"`
+}
+
+// Result internal use only
+type Result struct {
+	ID              *int    `json:"id,omitempty"`
+	Len             *int    `json:"len,omitempty"`
+	Data            *string `json:"data,omitempty"`
+	Left            *int    `json:"left,omitempty"`
+	WasRunning      *bool   `json:"was_running,omitempty"`
+	Result          *string `json:"result,omitempty"`
+	RestartRequired *bool   `json:"restart_required,omitempty"`
+	Error           *Error  `json:"error,omitempty"`
+}
+
+// Scripts the scripts of the device
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#scriptlist
+type Scripts struct {
+	Scripts []*Info `json:"scripts,omitempty" yaml:"scripts,omitempty"`
+}
+
+// Info a script as returned by List
+type Info struct {
+	// ID Id of the script
+	ID *int `json:"id,omitempty" yaml:"id,omitempty"`
+	// Name of the script
+	Name *string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Enable true if the script runs on boot, false otherwise
+	Enable *bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// Running true if the script is currently running, false otherwise
+	Running *bool `json:"running,omitempty" yaml:"running,omitempty"`
+}
+
+// Status status of the script
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#status
+type Status struct {
+	// ID Id of the script
+	ID *int `json:"id" yaml:"id"`
+	// Running true if the script is currently running, false otherwise
+	Running *bool `json:"running,omitempty" yaml:"running,omitempty"`
+	// MemUsed memory used by the script in bytes
+	MemUsed *int `json:"mem_used,omitempty" yaml:"mem_used,omitempty"`
+	// MemPeak peak memory used by the script in bytes
+	MemPeak *int `json:"mem_peak,omitempty" yaml:"mem_peak,omitempty"`
+	// MemFree memory available to the script in bytes
+	MemFree *int `json:"mem_free,omitempty" yaml:"mem_free,omitempty"`
+	// Errors present only when the script has stopped with an error such as crashed,
+	// syntax_error or reference_error
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Clone return copy
+func (t *Status) Clone() *Status {
+	c := &Status{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Config configuration of the script
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Script#configuration
+type Config struct {
+	// ID Id of the script
+	ID *int `json:"id,omitempty" yaml:"id,omitempty"`
+	// Name of the script
+	Name *string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Enable true if the script runs on boot, false otherwise
+	Enable *bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+}
+
+// Clone return copy
+func (t *Config) Clone() *Config {
+	c := &Config{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Equals returns true if equal
+func (t *Config) Equals(x *Config) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("Config receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("Config receiver is not nil but input is")
+		return false
+	}
+
+	if !util.CompareInt(t.ID, x.ID) {
+		zap.L().Info("Config ID not equal")
+		return false
+	}
+
+	if !util.CompareString(t.Name, x.Name) {
+		zap.L().Info("Config Name not equal")
+		return false
+	}
+
+	if !util.CompareBool(t.Enable, x.Enable) {
+		zap.L().Info("Config Enable not equal")
+		return false
+	}
+
+	return true
+}
+
+// Script a script and its code. Scripts are matched to the scripts of the device by
+// name. The code is either set in Code or read from File by Load.
+type Script struct {
+	// ID Id assigned to the script by the device. This is ignored by Equals.
+	ID *int `json:"id,omitempty" yaml:"id,omitempty"`
+	// Name of the script. Required.
+	Name *string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Enable true if the script runs on boot, false otherwise. Defaults to false.
+	Enable *bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// Running true if the script should be running, false otherwise. Defaults to Enable.
+	Running *bool `json:"running,omitempty" yaml:"running,omitempty"`
+	// File path of a local file with the code such as scripts/auto-off.js. A relative
+	// path is relative to the directory given to Load. This is ignored by Equals.
+	File *string `json:"file,omitempty" yaml:"file,omitempty"`
+	// Code the code of the script
+	Code *string `json:"code,omitempty" yaml:"code,omitempty"`
+}
+
+// Clone return copy
+func (t *Script) Clone() *Script {
+	c := &Script{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Sanatize sets the values the device uses when they are not set
+func (t *Script) Sanatize() {
+
+	if t == nil {
+		return
+	}
+
+	if t.Enable == nil {
+		tmp := false
+		t.Enable = &tmp
+	}
+
+	if t.Running == nil {
+		tmp := *t.Enable
+		t.Running = &tmp
+	}
+}
+
+// Load reads the code from File if Code is not set. A relative File is joined to dir.
+func (t *Script) Load(dir string) error {
+
+	if t.Code != nil {
+		return nil
+	}
+
+	if t.File == nil {
+		return fmt.Errorf("script %s has neither file nor code", t.GetName())
+	}
+
+	filename := *t.File
+	if dir != "" && !filepath.IsAbs(filename) {
+		filename = filepath.Join(dir, filename)
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("script %s; %w", t.GetName(), err)
+	}
+
+	code := string(b)
+	t.Code = &code
+	return nil
+}
+
+// GetName returns the name or an empty string if it is not set
+func (t *Script) GetName() string {
+	if t.Name == nil {
+		return ""
+	}
+	return *t.Name
+}
+
+// Equals returns true if equal. The ID and File are not compared.
+func (t *Script) Equals(x *Script) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("Script receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("Script receiver is not nil but input is")
+		return false
+	}
+
+	if !util.CompareString(t.Name, x.Name) {
+		zap.L().Info("Script Name not equal")
+		return false
+	}
+
+	if !util.CompareBool(t.Enable, x.Enable) {
+		zap.L().Info(fmt.Sprintf("Script %s Enable not equal", t.GetName()))
+		return false
+	}
+
+	if !util.CompareBool(t.Running, x.Running) {
+		zap.L().Info(fmt.Sprintf("Script %s Running not equal", t.GetName()))
+		return false
+	}
+
+	if !util.CompareString(t.Code, x.Code) {
+		zap.L().Info(fmt.Sprintf("Script %s Code not equal", t.GetName()))
+		return false
+	}
+
+	return true
+}
+
+// ScriptsEqual returns true if a and b contain the same scripts in any order. The IDs and
+// files are not compared.
+func ScriptsEqual(a, b []*Script) bool {
+
+	if len(a) != len(b) {
+		zap.L().Info(fmt.Sprintf("Scripts count %d not equal to %d", len(a), len(b)))
+		return false
+	}
+
+	used := make([]bool, len(b))
+
+	for _, j := range a {
+
+		found := false
+
+		for i, k := range b {
+			if !used[i] && j.Equals(k) {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			zap.L().Info(fmt.Sprintf("Script %s not found", j.GetName()))
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,13 @@
+package server
+
+import (
+	"time"
+)
+
+const (
+	defaultListenAddress     = ":8080"
+	defaultPath              = "/"
+	defaultFirstFrameTimeout = time.Second * 30
+	connectedBufferSize      = 100
+	notificationBufferSize   = 1000
+)
@@ -0,0 +1,262 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+	logger "github.com/jodydadescott/jody-go-logger"
+	"go.uber.org/zap"
+
+	"github.com/jodydadescott/shelly-client/sdk/client"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/msghandlers/ws"
+	"github.com/jodydadescott/shelly-client/sdk/server/types"
+)
+
+type Config = types.Config
+type ClientConfig = types.ClientConfig
+type Notification = msg_types.Notification
+
+// Device is a device that has connected to the server
+type Device struct {
+	// ID the device ID taken from the src of the first frame sent by the device
+	ID string
+	// Client the client bound to the device connection
+	Client  *client.Client
+	factory *ws.Client
+}
+
+// Done returns a channel that is closed when the device connection has ended
+func (t *Device) Done() <-chan struct{} {
+	return t.factory.Done()
+}
+
+// Server accepts outbound websocket connections initiated by devices. This is configured
+// on the device with the Ws component. Each device is identified by the src of the first
+// frame it sends.
+type Server struct {
+	config        *Config
+	upgrader      gorilla.Upgrader
+	devicesMutex  sync.RWMutex
+	devices       map[string]*Device
+	connected     chan *Device
+	notifications chan *Notification
+}
+
+// New returns a new server. Call Run to start the server.
+func New(config *Config) *Server {
+
+	zap.L().Debug("New")
+
+	if config == nil {
+		config = &Config{}
+	} else {
+		config = config.Clone()
+	}
+
+	if config.ListenAddress == "" {
+		config.ListenAddress = defaultListenAddress
+		zap.L().Debug(fmt.Sprintf("listenAddress is %s (default)", config.ListenAddress))
+	} else {
+		zap.L().Debug(fmt.Sprintf("listenAddress is %s (config)", config.ListenAddress))
+	}
+
+	if config.Path == "" {
+		config.Path = defaultPath
+		zap.L().Debug(fmt.Sprintf("path is %s (default)", config.Path))
+	} else {
+		zap.L().Debug(fmt.Sprintf("path is %s (config)", config.Path))
+	}
+
+	if config.FirstFrameTimeout <= 0 {
+		config.FirstFrameTimeout = defaultFirstFrameTimeout
+		zap.L().Debug(fmt.Sprintf("firstFrameTimeout is %s (default)", config.FirstFrameTimeout.String()))
+	} else {
+		zap.L().Debug(fmt.Sprintf("firstFrameTimeout is %s (config)", config.FirstFrameTimeout.String()))
+	}
+
+	if config.Client == nil {
+		config.Client = &ClientConfig{}
+	}
+
+	return &Server{
+		config:        config,
+		devices:       make(map[string]*Device),
+		connected:     make(chan *Device, connectedBufferSize),
+		notifications: make(chan *Notification, notificationBufferSize),
+	}
+}
+
+// Connected returns a stream of devices as they connect. If the stream is not read
+// devices are still available with GetDevice and Devices.
+func (t *Server) Connected() <-chan *Device {
+	return t.connected
+}
+
+// Notifications returns a stream of notifications sent by all connected devices. The
+// first frame sent by each device is included.
+func (t *Server) Notifications() <-chan *Notification {
+	return t.notifications
+}
+
+// GetDevice returns the connected device with the specified ID or nil
+func (t *Server) GetDevice(id string) *Device {
+	t.devicesMutex.RLock()
+	defer t.devicesMutex.RUnlock()
+	return t.devices[id]
+}
+
+// Devices returns all connected devices
+func (t *Server) Devices() []*Device {
+	t.devicesMutex.RLock()
+	defer t.devicesMutex.RUnlock()
+
+	var devices []*Device
+	for _, device := range t.devices {
+		devices = append(devices, device)
+	}
+	return devices
+}
+
+// Run runs the server until the context is cancelled or the server fails
+func (t *Server) Run(ctx context.Context) error {
+
+	listener, err := net.Listen("tcp", t.config.ListenAddress)
+	if err != nil {
+		return err
+	}
+
+	zap.L().Debug(fmt.Sprintf("Listening on %s", listener.Addr().String()))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(t.config.Path, t.handle)
+
+	httpServer := &http.Server{
+		Handler: mux,
+	}
+
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- httpServer.Serve(listener)
+	}()
+
+	select {
+
+	case <-ctx.Done():
+		zap.L().Debug("Server cancelled")
+		httpServer.Shutdown(context.Background())
+		err = nil
+
+	case err = <-errChan:
+		zap.L().Debug(fmt.Sprintf("Server failed with error %v", err))
+
+	}
+
+	for _, device := range t.Devices() {
+		device.Client.Close()
+	}
+
+	return err
+}
+
+func (t *Server) handle(w http.ResponseWriter, r *http.Request) {
+
+	zap.L().Debug(fmt.Sprintf("Connection from %s", r.RemoteAddr))
+
+	conn, err := t.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		zap.L().Debug(fmt.Sprintf("Upgrade from %s failed with error %v", r.RemoteAddr, err))
+		return
+	}
+
+	conn.SetReadDeadline(time.Now().Add(t.config.FirstFrameTimeout))
+	_, b, err := conn.ReadMessage()
+	if err != nil {
+		zap.L().Debug(fmt.Sprintf("First frame from %s failed with error %v", r.RemoteAddr, err))
+		conn.Close()
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	if logger.Wire {
+		zap.L().Debug(fmt.Sprintf("RX->%s", string(b)))
+	}
+
+	notification := &Notification{}
+	err = json.Unmarshal(b, notification)
+	if err != nil || notification.Src == "" {
+		zap.L().Debug(fmt.Sprintf("First frame from %s is missing src; closing", r.RemoteAddr))
+		conn.Close()
+		return
+	}
+
+	if notification.Method != "" {
+		t.publish(notification)
+	}
+
+	config := t.config.Client.Clone()
+	config.Hostname = r.RemoteAddr
+
+	factory := ws.NewFromConn(config, conn, t.handleNotification)
+
+	device := &Device{
+		ID:      notification.Src,
+		Client:  client.NewWithMessageHandlerFactory(config, factory),
+		factory: factory,
+	}
+
+	t.devicesMutex.Lock()
+	existing := t.devices[device.ID]
+	t.devices[device.ID] = device
+	t.devicesMutex.Unlock()
+
+	if existing != nil {
+		zap.L().Debug(fmt.Sprintf("Device %s reconnected; closing previous connection", device.ID))
+		existing.Client.Close()
+	}
+
+	zap.L().Debug(fmt.Sprintf("Device %s connected from %s", device.ID, r.RemoteAddr))
+
+	go func() {
+		<-device.Done()
+		zap.L().Debug(fmt.Sprintf("Device %s disconnected", device.ID))
+		t.devicesMutex.Lock()
+		defer t.devicesMutex.Unlock()
+		if t.devices[device.ID] == device {
+			delete(t.devices, device.ID)
+		}
+	}()
+
+	select {
+	case t.connected <- device:
+	default:
+		zap.L().Debug(fmt.Sprintf("Connected stream is full; device %s not sent", device.ID))
+	}
+}
+
+func (t *Server) handleNotification(b []byte) {
+
+	notification := &Notification{}
+	err := json.Unmarshal(b, notification)
+	if err != nil {
+		zap.L().Error(fmt.Sprintf("handleNotification error %v", err))
+		return
+	}
+
+	t.publish(notification)
+}
+
+func (t *Server) publish(notification *Notification) {
+	select {
+	case t.notifications <- notification:
+	default:
+		zap.L().Warn(fmt.Sprintf("Notification stream is full; notification %s from %s dropped", notification.Method, notification.Src))
+	}
+}
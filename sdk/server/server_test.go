@@ -0,0 +1,225 @@
+package server_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	"github.com/jodydadescott/shelly-client/sdk/server"
+)
+
+// run starts a server with the config on a free local port and returns the server and
+// its websocket URL
+func run(t *testing.T, config *server.Config) (*server.Server, string) {
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.ListenAddress = listener.Addr().String()
+	listener.Close()
+
+	s := server.New(config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run error %v", err)
+		}
+	})
+
+	return s, "ws://" + config.ListenAddress + "/"
+}
+
+// dial connects to the server as the device would and returns the connection. It
+// retries until the server is listening.
+func dial(t *testing.T, url string) *gorilla.Conn {
+
+	deadline := time.Now().Add(time.Second * 5)
+
+	for {
+		conn, _, err := gorilla.DefaultDialer.Dial(url, nil)
+		if err == nil {
+			t.Cleanup(func() { conn.Close() })
+			return conn
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+// serve answers requests from the server with the device and forwards the device
+// notifications
+func serve(conn *gorilla.Conn, device *fake.Device) {
+
+	var mutex sync.Mutex
+	write := func(b []byte) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		conn.WriteMessage(gorilla.TextMessage, b)
+	}
+
+	remove := device.AddNotificationHandler(write)
+
+	go func() {
+		defer remove()
+		for {
+			_, b, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			response, err := device.Call(context.Background(), b)
+			if err != nil {
+				continue
+			}
+			write(response)
+		}
+	}()
+}
+
+func TestServer(t *testing.T) {
+
+	s, url := run(t, &server.Config{})
+
+	device := fake.NewDevice(&fake.DeviceConfig{Switch: 1})
+
+	conn := dial(t, url)
+	err := conn.WriteMessage(gorilla.TextMessage, []byte(`{"src":"`+device.ID()+`","dst":"server","method":"NotifyFullStatus","params":{"ts":1}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serve(conn, device)
+
+	var d *server.Device
+	select {
+	case d = <-s.Connected():
+	case <-time.After(time.Second * 5):
+		t.Fatal("device not connected")
+	}
+
+	if d.ID != device.ID() {
+		t.Errorf("device ID %s, want %s", d.ID, device.ID())
+	}
+
+	if s.GetDevice(device.ID()) != d {
+		t.Errorf("GetDevice(%s) did not return the connected device", device.ID())
+	}
+
+	// The first frame is published as a notification
+	select {
+	case n := <-s.Notifications():
+		if n.Src != device.ID() || n.Method != "NotifyFullStatus" {
+			t.Errorf("notification %s from %s, want NotifyFullStatus from %s", n.Method, n.Src, device.ID())
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("first frame not published")
+	}
+
+	// Requests are sent to the device over its connection
+	ctx := context.Background()
+
+	on := true
+	err = d.Client.Switch().Set(ctx, 0, &on)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := d.Client.Switch().GetStatus(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !status.Output {
+		t.Errorf("output %t, want true", status.Output)
+	}
+
+	// Notifications sent by the device after the first frame are published
+	select {
+	case n := <-s.Notifications():
+		if n.Src != device.ID() || n.Method != "NotifyStatus" {
+			t.Errorf("notification %s from %s, want NotifyStatus from %s", n.Method, n.Src, device.ID())
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("status notification not published")
+	}
+
+	// The device is removed when its connection ends
+	conn.Close()
+
+	select {
+	case <-d.Done():
+	case <-time.After(time.Second * 5):
+		t.Fatal("device not done after disconnect")
+	}
+
+	deadline := time.Now().Add(time.Second * 5)
+	for s.GetDevice(device.ID()) != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("device not removed after disconnect")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestServerRejectsFirstFrame(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		frame string
+	}{
+		{name: "missing src", frame: `{"method":"NotifyFullStatus","params":{}}`},
+		{name: "not json", frame: `hello`},
+		{name: "timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			s, url := run(t, &server.Config{FirstFrameTimeout: time.Millisecond * 100})
+
+			conn := dial(t, url)
+
+			if tt.frame != "" {
+				err := conn.WriteMessage(gorilla.TextMessage, []byte(tt.frame))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// The server closes the connection
+			conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+			_, _, err := conn.ReadMessage()
+			if err == nil {
+				t.Fatal("connection not closed")
+			}
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				t.Fatal("connection not closed")
+			}
+
+			if len(s.Devices()) != 0 {
+				t.Errorf("devices %d, want 0", len(s.Devices()))
+			}
+
+			select {
+			case d := <-s.Connected():
+				t.Errorf("device %s connected, want none", d.ID)
+			default:
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"time"
+
+	"github.com/jinzhu/copier"
+
+	client_types "github.com/jodydadescott/shelly-client/sdk/client/types"
+)
+
+type ClientConfig = client_types.Config
+
+// Config server config. Devices must be configured with the outbound websocket
+// (Ws component) server set to ws://<host><ListenAddress><Path>
+type Config struct {
+	// ListenAddress address to listen on. Default is :8080
+	ListenAddress string `json:"listenAddress,omitempty" yaml:"listenAddress,omitempty"`
+	// Path the HTTP path devices connect to. Default is /
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// FirstFrameTimeout how long to wait for the device to send its first frame after connecting
+	FirstFrameTimeout time.Duration `json:"firstFrameTimeout,omitempty" yaml:"firstFrameTimeout,omitempty"`
+	// Client is used as the config for each client created for a connected device. The hostname is ignored.
+	Client *ClientConfig `json:"client,omitempty" yaml:"client,omitempty"`
+}
+
+// Clone return copy
+func (t *Config) Clone() *Config {
+	c := &Config{}
+	copier.Copy(&c, &t)
+	return c
+}
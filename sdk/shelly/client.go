@@ -5,7 +5,9 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/hashicorp/go-multierror"
 	"go.uber.org/zap"
@@ -14,20 +16,42 @@ import (
 	bluetooth_types "github.com/jodydadescott/shelly-client/sdk/bluetooth/types"
 	cloud_client "github.com/jodydadescott/shelly-client/sdk/cloud"
 	cloud_types "github.com/jodydadescott/shelly-client/sdk/cloud/types"
+	cover_client "github.com/jodydadescott/shelly-client/sdk/cover"
+	cover_types "github.com/jodydadescott/shelly-client/sdk/cover/types"
+	em_client "github.com/jodydadescott/shelly-client/sdk/em"
+	em_types "github.com/jodydadescott/shelly-client/sdk/em/types"
+	em1_client "github.com/jodydadescott/shelly-client/sdk/em1"
+	em1_types "github.com/jodydadescott/shelly-client/sdk/em1/types"
 	ethernet_client "github.com/jodydadescott/shelly-client/sdk/ethernet"
 	ethernet_types "github.com/jodydadescott/shelly-client/sdk/ethernet/types"
+	humidity_client "github.com/jodydadescott/shelly-client/sdk/humidity"
+	humidity_types "github.com/jodydadescott/shelly-client/sdk/humidity/types"
+	illuminance_client "github.com/jodydadescott/shelly-client/sdk/illuminance"
+	illuminance_types "github.com/jodydadescott/shelly-client/sdk/illuminance/types"
 	input_client "github.com/jodydadescott/shelly-client/sdk/input"
 	input_types "github.com/jodydadescott/shelly-client/sdk/input/types"
+	kvs_client "github.com/jodydadescott/shelly-client/sdk/kvs"
 	light_client "github.com/jodydadescott/shelly-client/sdk/light"
 	light_types "github.com/jodydadescott/shelly-client/sdk/light/types"
 	mqtt_client "github.com/jodydadescott/shelly-client/sdk/mqtt"
 	mqtt_types "github.com/jodydadescott/shelly-client/sdk/mqtt/types"
 	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	pm1_client "github.com/jodydadescott/shelly-client/sdk/pm1"
+	pm1_types "github.com/jodydadescott/shelly-client/sdk/pm1/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
+	schedule_client "github.com/jodydadescott/shelly-client/sdk/schedule"
+	schedule_types "github.com/jodydadescott/shelly-client/sdk/schedule/types"
+	script_client "github.com/jodydadescott/shelly-client/sdk/script"
 	shelly_types "github.com/jodydadescott/shelly-client/sdk/shelly/types"
 	switch_client "github.com/jodydadescott/shelly-client/sdk/switchx"
 	switch_types "github.com/jodydadescott/shelly-client/sdk/switchx/types"
 	system_client "github.com/jodydadescott/shelly-client/sdk/system"
 	system_types "github.com/jodydadescott/shelly-client/sdk/system/types"
+	temperature_client "github.com/jodydadescott/shelly-client/sdk/temperature"
+	temperature_types "github.com/jodydadescott/shelly-client/sdk/temperature/types"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+	webhook_client "github.com/jodydadescott/shelly-client/sdk/webhook"
+	webhook_types "github.com/jodydadescott/shelly-client/sdk/webhook/types"
 	websocket_client "github.com/jodydadescott/shelly-client/sdk/websocket"
 	websocket_types "github.com/jodydadescott/shelly-client/sdk/websocket/types"
 	wifi_client "github.com/jodydadescott/shelly-client/sdk/wifi"
@@ -41,17 +65,18 @@ type Request = msg_types.Request
 type Config = shelly_types.Config
 type ConfigReport = shelly_types.ConfigReport
 type DeviceInfo = shelly_types.DeviceInfo
-type ListMethodsResponse = shelly_types.ListMethodsResponse
 type TLSConfig = shelly_types.TLSConfig
 type Status = shelly_types.Status
-type SetConfigResponse = shelly_types.SetConfigResponse
 type RawTLSConfig = shelly_types.RawTLSConfig
+type RawConfig = shelly_types.RawConfig
 type AuthConfig = shelly_types.AuthConfig
 type RawAuthConfig = shelly_types.RawAuthConfig
-type GetConfigResponse = shelly_types.GetConfigResponse
-type GetStatusResponse = shelly_types.GetStatusResponse
+type RawShellyStatus = shelly_types.RawShellyStatus
 type RPCMethods = shelly_types.RPCMethods
-type DeviceInfoResponse = shelly_types.DeviceInfoResponse
+type ComponentsConfig = shelly_types.ComponentsConfig
+type Components = shelly_types.Components
+type RawComponentsParams = shelly_types.RawComponentsParams
+type RawComponentsResult = shelly_types.RawComponentsResult
 type UpdatesReport = shelly_types.UpdatesReport
 type UpdateConfig = shelly_types.UpdateConfig
 type CheckForUpdateResponse = shelly_types.CheckForUpdateResponse
@@ -65,6 +90,32 @@ type WifiConfig = wifi_types.Config
 type LightConfig = light_types.Config
 type InputConfig = input_types.Config
 type SwitchConfig = switch_types.Config
+type CoverConfig = cover_types.Config
+type EMConfig = em_types.Config
+type EM1Config = em1_types.Config
+type PM1Config = pm1_types.Config
+type TemperatureConfig = temperature_types.Config
+type HumidityConfig = humidity_types.Config
+type IlluminanceConfig = illuminance_types.Config
+type ScheduleJob = schedule_types.Job
+type Webhook = webhook_types.Webhook
+type Script = shelly_types.Script
+type TemplateVars = webhook_types.TemplateVars
+
+// Deprecated: ListMethodsResponse is no longer used by the client.
+type ListMethodsResponse = shelly_types.ListMethodsResponse
+
+// Deprecated: SetConfigResponse is no longer used by the client.
+type SetConfigResponse = shelly_types.SetConfigResponse
+
+// Deprecated: GetConfigResponse is no longer used by the client.
+type GetConfigResponse = shelly_types.GetConfigResponse
+
+// Deprecated: GetStatusResponse is no longer used by the client.
+type GetStatusResponse = shelly_types.GetStatusResponse
+
+// Deprecated: DeviceInfoResponse is no longer used by the client.
+type DeviceInfoResponse = shelly_types.DeviceInfoResponse
 
 type clientContract interface {
 	MessageHandlerFactory
@@ -74,6 +125,18 @@ type clientContract interface {
 	Wifi() *wifi_client.Client
 	Cloud() *cloud_client.Client
 	Switch() *switch_client.Client
+	Cover() *cover_client.Client
+	EM() *em_client.Client
+	EM1() *em1_client.Client
+	PM1() *pm1_client.Client
+	Temperature() *temperature_client.Client
+	Humidity() *humidity_client.Client
+	Illuminance() *illuminance_client.Client
+	Schedule() *schedule_client.Client
+	Webhook() *webhook_client.Client
+	Script() *script_client.Client
+	KVS() *kvs_client.Client
+	Hostname() string
 	Input() *input_client.Client
 	Light() *light_client.Client
 	Websocket() *websocket_client.Client
@@ -89,12 +152,17 @@ func New(clientContract clientContract) *Client {
 
 type Client struct {
 	clientContract
-	deviceInfo      *DeviceInfo
-	shellyConfig    *Config
-	_messageHandler MessageHandler
+	cacheMutex          sync.Mutex
+	deviceInfo          *DeviceInfo
+	shellyConfig        *Config
+	messageHandlerMutex sync.Mutex
+	_messageHandler     MessageHandler
 }
 
 func (t *Client) getMessageHandler() MessageHandler {
+	t.messageHandlerMutex.Lock()
+	defer t.messageHandlerMutex.Unlock()
+
 	if t._messageHandler != nil {
 		return t._messageHandler
 	}
@@ -107,6 +175,13 @@ func getErr(method string, err error) error {
 	if err == nil {
 		return nil
 	}
+
+	// An error returned by the device already has the method and device ID
+	rpcErr := &msg_types.Error{}
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
 	return fmt.Errorf("component %s, method %s, error %w", Component, method, err)
 }
 
@@ -115,28 +190,68 @@ func (t *Client) GetStatus(ctx context.Context) (*Status, error) {
 
 	method := Component + ".GetStatus"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-	})
+	result, err := rpc.Call[any, RawShellyStatus](ctx, t.getMessageHandler(), method, nil)
 	if err != nil {
 		return nil, getErr(method, err)
 	}
 
-	response := &GetStatusResponse{}
-	err = json.Unmarshal(respBytes, response)
+	status, err := result.Convert()
 	if err != nil {
 		return nil, getErr(method, err)
 	}
 
-	if response.Error != nil {
-		return nil, getErr(method, response.Error)
+	return status, nil
+}
+
+// GetComponents returns the components of the device. The device returns the components in
+// pages; requests are sent with an increasing offset until all components are received.
+// The config may be nil in which case only the keys are returned.
+func (t *Client) GetComponents(ctx context.Context, config *ComponentsConfig) (*Components, error) {
+
+	method := Component + ".GetComponents"
+
+	params := &RawComponentsParams{}
+
+	if config != nil {
+
+		if config.IncludeStatus {
+			params.Include = append(params.Include, "status")
+		}
+
+		if config.IncludeConfig {
+			params.Include = append(params.Include, "config")
+		}
+
+		if config.DynamicOnly {
+			params.DynamicOnly = &config.DynamicOnly
+		}
+
+		params.Keys = config.Keys
 	}
 
-	if response.Result == nil {
-		return nil, getErr(method, fmt.Errorf("result is missing from response"))
+	components := &Components{}
+
+	for {
+
+		offset := len(components.Components)
+		params.Offset = &offset
+
+		result, err := rpc.Call[*RawComponentsParams, *RawComponentsResult](ctx, t.getMessageHandler(), method, params)
+		if err != nil {
+			return nil, getErr(method, err)
+		}
+
+		components.CfgRev = result.CfgRev
+		components.Components = append(components.Components, result.Components...)
+
+		if len(result.Components) == 0 || len(components.Components) >= result.Total {
+			break
+		}
+
+		zap.L().Debug(fmt.Sprintf("received %d of %d components", len(components.Components), result.Total))
 	}
 
-	return response.Result.Convert(), nil
+	return components, nil
 }
 
 // ListMethods lists all available RPC methods. It takes into account both ACL and authentication restrictions
@@ -147,28 +262,12 @@ func (t *Client) ListMethods(ctx context.Context) (*RPCMethods, error) {
 
 	method := Component + ".ListMethods"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-	})
-	if err != nil {
-		return nil, getErr(method, err)
-	}
-
-	response := &ListMethodsResponse{}
-	err = json.Unmarshal(respBytes, response)
+	result, err := rpc.Call[any, *RPCMethods](ctx, t.getMessageHandler(), method, nil)
 	if err != nil {
 		return nil, getErr(method, err)
 	}
 
-	if response.Error != nil {
-		return nil, getErr(method, response.Error)
-	}
-
-	if response.Result == nil {
-		return nil, getErr(method, fmt.Errorf("result is missing from response"))
-	}
-
-	return response.Result, nil
+	return result, nil
 }
 
 // GetConfig returns the configuration of all the components of the device.
@@ -179,36 +278,66 @@ func (t *Client) GetConfig(ctx context.Context, forceRefresh bool) (*Config, err
 	if forceRefresh {
 		zap.L().Debug("forceRefresh is true")
 	} else {
-		if t.shellyConfig == nil {
+		shellyConfig := t.getCachedConfig()
+		if shellyConfig == nil {
 			zap.L().Debug("forceRefresh is false but there is no existing ShellyConfig")
 		} else {
 			zap.L().Debug("forceRefresh is false and there is an existing ShellyConfig")
-			return t.shellyConfig.Clone(), nil
+			return shellyConfig, nil
 		}
 	}
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-	})
+	result, err := rpc.Call[any, RawConfig](ctx, t.getMessageHandler(), method, nil)
 	if err != nil {
 		return nil, getErr(method, err)
 	}
 
-	response := &GetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
+	config, err := result.Convert()
 	if err != nil {
 		return nil, getErr(method, err)
 	}
 
-	if response.Error != nil {
-		return nil, getErr(method, response.Error)
+	jobs, err := t.Schedule().List(ctx)
+	if err != nil {
+		if !errors.Is(err, msg_types.ErrNotImplemented) {
+			return nil, err
+		}
+		zap.L().Debug("device does not support schedules")
+	} else {
+		config.Schedules = []*ScheduleJob{}
+		config.Schedules = append(config.Schedules, jobs.Jobs...)
 	}
 
-	if response.Result == nil {
-		return nil, fmt.Errorf("result is missing from response")
+	hooks, err := t.Webhook().List(ctx)
+	if err != nil {
+		if !errors.Is(err, msg_types.ErrNotImplemented) {
+			return nil, err
+		}
+		zap.L().Debug("device does not support webhooks")
+	} else {
+		config.Webhooks = []*Webhook{}
+		config.Webhooks = append(config.Webhooks, hooks.Hooks...)
+	}
+
+	scripts, err := t.Script().GetScripts(ctx)
+	if err != nil {
+		if !errors.Is(err, msg_types.ErrNotImplemented) {
+			return nil, err
+		}
+		zap.L().Debug("device does not support scripts")
+	} else {
+		config.Scripts = scripts
 	}
 
-	config := response.Result.Convert()
+	kvs, err := t.KVS().GetConfig(ctx)
+	if err != nil {
+		if !errors.Is(err, msg_types.ErrNotImplemented) {
+			return nil, err
+		}
+		zap.L().Debug("device does not support KVS")
+	} else {
+		config.KVS = kvs
+	}
 
 	config.Auth = &AuthConfig{}
 
@@ -220,14 +349,30 @@ func (t *Client) GetConfig(ctx context.Context, forceRefresh bool) (*Config, err
 
 	config.Auth.Enable = &authEnabled
 
+	t.cacheMutex.Lock()
 	t.shellyConfig = config
+	t.cacheMutex.Unlock()
+
 	return config.Clone(), nil
 }
 
+func (t *Client) getCachedConfig() *Config {
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+	if t.shellyConfig == nil {
+		return nil
+	}
+	return t.shellyConfig.Clone()
+}
+
 // SetConfig sets the configuration for each component with non nil config. Note that this function
 // calls into each componenet as necessary.
 func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*ConfigReport, error) {
 
+	// The config is changed by sanitizing and rendering so a clone is used and the config
+	// of the caller is left as is
+	config = config.Clone()
+
 	config.Sanatize()
 
 	deviceInfo, err := t.GetDeviceInfo(ctx)
@@ -239,6 +384,14 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 		return nil, fmt.Errorf("deviceInfo.ID is nil")
 	}
 
+	err = config.Render(&TemplateVars{
+		DeviceID: *deviceInfo.ID,
+		Hostname: t.Hostname(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	if deviceInfo.App == nil {
 		return nil, fmt.Errorf("deviceInfo.App is nil")
 	}
@@ -264,26 +417,6 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 
 	rebootRequired := false
 
-	send := func(request *Request) error {
-
-		respBytes, err := t.getMessageHandler().Send(ctx, request)
-		if err != nil {
-			return err
-		}
-
-		response := &SetConfigResponse{}
-		err = json.Unmarshal(respBytes, response)
-		if err != nil {
-			return err
-		}
-
-		if response.Error != nil {
-			return response.Error
-		}
-
-		return nil
-	}
-
 	setTLSClientKey := func(config *TLSConfig) error {
 
 		if existingConfig.TLSClientKey == nil {
@@ -308,7 +441,7 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 				return fmt.Errorf("missing required data")
 			}
 
-			data := splitByWidth(*config.Data, maxRPCChunkSize)
+			data := util.SplitByWidth(*config.Data, util.MaxRPCChunkSize)
 			counter := 0
 			append := true
 
@@ -320,12 +453,9 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 					append = false
 				}
 
-				err := send(&Request{
-					Method: &method,
-					Params: &RawTLSConfig{
-						Data:   &chunk,
-						Append: &append,
-					},
+				err := rpc.Exec(ctx, t.getMessageHandler(), method, &RawTLSConfig{
+					Data:   &chunk,
+					Append: &append,
 				})
 
 				if err != nil {
@@ -339,11 +469,8 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 
 		append := false
 
-		return getErr(method, send(&Request{
-			Method: &method,
-			Params: &RawTLSConfig{
-				Append: &append,
-			},
+		return getErr(method, rpc.Exec(ctx, t.getMessageHandler(), method, &RawTLSConfig{
+			Append: &append,
 		}))
 	}
 
@@ -371,7 +498,7 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 				return fmt.Errorf("missing required data")
 			}
 
-			data := splitByWidth(*config.Data, maxRPCChunkSize)
+			data := util.SplitByWidth(*config.Data, util.MaxRPCChunkSize)
 			counter := 0
 			append := true
 
@@ -383,12 +510,9 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 					append = false
 				}
 
-				err := send(&Request{
-					Method: &method,
-					Params: &RawTLSConfig{
-						Data:   &chunk,
-						Append: &append,
-					},
+				err := rpc.Exec(ctx, t.getMessageHandler(), method, &RawTLSConfig{
+					Data:   &chunk,
+					Append: &append,
 				})
 
 				if err != nil {
@@ -402,11 +526,8 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 
 		append := false
 
-		return getErr(method, send(&Request{
-			Method: &method,
-			Params: &RawTLSConfig{
-				Append: &append,
-			},
+		return getErr(method, rpc.Exec(ctx, t.getMessageHandler(), method, &RawTLSConfig{
+			Append: &append,
 		}))
 	}
 
@@ -434,7 +555,7 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 				return fmt.Errorf("missing required data")
 			}
 
-			data := splitByWidth(*config.Data, maxRPCChunkSize)
+			data := util.SplitByWidth(*config.Data, util.MaxRPCChunkSize)
 			counter := 0
 			append := true
 
@@ -446,12 +567,9 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 					append = false
 				}
 
-				err := send(&Request{
-					Method: &method,
-					Params: &RawTLSConfig{
-						Data:   &chunk,
-						Append: &append,
-					},
+				err := rpc.Exec(ctx, t.getMessageHandler(), method, &RawTLSConfig{
+					Data:   &chunk,
+					Append: &append,
 				})
 
 				if err != nil {
@@ -465,11 +583,8 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 
 		append := false
 
-		return getErr(method, send(&Request{
-			Method: &method,
-			Params: &RawTLSConfig{
-				Append: &append,
-			},
+		return getErr(method, rpc.Exec(ctx, t.getMessageHandler(), method, &RawTLSConfig{
+			Append: &append,
 		}))
 	}
 
@@ -516,25 +631,7 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 			zap.L().Debug("Auth is disabled")
 		}
 
-		respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-			Method: &method,
-			Params: raw,
-		})
-		if err != nil {
-			return getErr(method, err)
-		}
-
-		response := &SetConfigResponse{}
-		err = json.Unmarshal(respBytes, response)
-		if err != nil {
-			return getErr(method, err)
-		}
-
-		if response.Error != nil {
-			return response.Error
-		}
-
-		return nil
+		return getErr(method, rpc.Exec(ctx, t.getMessageHandler(), method, raw))
 	}
 
 	setBluetooth := func(config *BluetoothConfig) error {
@@ -683,6 +780,175 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 		return errors.ErrorOrNil()
 	}
 
+	setCover := func(config map[int]*CoverConfig) error {
+
+		var errors *multierror.Error
+
+		for _, v := range config {
+			zap.L().Debug(fmt.Sprintf("Setting config for cover %d", *v.ID))
+			err := t.Cover().SetConfig(ctx, v)
+			if err != nil {
+				errors = multierror.Append(errors, err)
+			}
+		}
+
+		return errors.ErrorOrNil()
+	}
+
+	setEM := func(config map[int]*EMConfig) error {
+
+		var errors *multierror.Error
+
+		for _, v := range config {
+			zap.L().Debug(fmt.Sprintf("Setting config for em %d", *v.ID))
+			err := t.EM().SetConfig(ctx, v)
+			if err != nil {
+				errors = multierror.Append(errors, err)
+			}
+		}
+
+		return errors.ErrorOrNil()
+	}
+
+	setEM1 := func(config map[int]*EM1Config) error {
+
+		var errors *multierror.Error
+
+		for _, v := range config {
+			zap.L().Debug(fmt.Sprintf("Setting config for em1 %d", *v.ID))
+			err := t.EM1().SetConfig(ctx, v)
+			if err != nil {
+				errors = multierror.Append(errors, err)
+			}
+		}
+
+		return errors.ErrorOrNil()
+	}
+
+	setPM1 := func(config map[int]*PM1Config) error {
+
+		var errors *multierror.Error
+
+		for _, v := range config {
+			zap.L().Debug(fmt.Sprintf("Setting config for pm1 %d", *v.ID))
+			err := t.PM1().SetConfig(ctx, v)
+			if err != nil {
+				errors = multierror.Append(errors, err)
+			}
+		}
+
+		return errors.ErrorOrNil()
+	}
+
+	setTemperature := func(config map[int]*TemperatureConfig) error {
+
+		var errors *multierror.Error
+
+		for _, v := range config {
+			zap.L().Debug(fmt.Sprintf("Setting config for temperature %d", *v.ID))
+			err := t.Temperature().SetConfig(ctx, v)
+			if err != nil {
+				errors = multierror.Append(errors, err)
+			}
+		}
+
+		return errors.ErrorOrNil()
+	}
+
+	setHumidity := func(config map[int]*HumidityConfig) error {
+
+		var errors *multierror.Error
+
+		for _, v := range config {
+			zap.L().Debug(fmt.Sprintf("Setting config for humidity %d", *v.ID))
+			err := t.Humidity().SetConfig(ctx, v)
+			if err != nil {
+				errors = multierror.Append(errors, err)
+			}
+		}
+
+		return errors.ErrorOrNil()
+	}
+
+	setIlluminance := func(config map[int]*IlluminanceConfig) error {
+
+		var errors *multierror.Error
+
+		for _, v := range config {
+			zap.L().Debug(fmt.Sprintf("Setting config for illuminance %d", *v.ID))
+			err := t.Illuminance().SetConfig(ctx, v)
+			if err != nil {
+				errors = multierror.Append(errors, err)
+			}
+		}
+
+		return errors.ErrorOrNil()
+	}
+
+	setSchedules := func(config []*ScheduleJob) error {
+
+		if config == nil {
+			zap.L().Debug("Schedules are not present and will not be changed")
+			return nil
+		}
+
+		if existingConfig.Schedules == nil {
+			zap.L().Warn(fmt.Sprintf("deviceID %s, deviceApp %s does not support Schedules; ignoring", *deviceInfo.ID, *deviceInfo.App))
+			return nil
+		}
+
+		_, err := t.Schedule().SetConfig(ctx, config)
+		return err
+	}
+
+	setWebhooks := func(config []*Webhook) error {
+
+		if config == nil {
+			zap.L().Debug("Webhooks are not present and will not be changed")
+			return nil
+		}
+
+		if existingConfig.Webhooks == nil {
+			zap.L().Warn(fmt.Sprintf("deviceID %s, deviceApp %s does not support Webhooks; ignoring", *deviceInfo.ID, *deviceInfo.App))
+			return nil
+		}
+
+		_, err := t.Webhook().SetConfig(ctx, config)
+		return err
+	}
+
+	setScripts := func(config []*Script) error {
+
+		if config == nil {
+			zap.L().Debug("Scripts are not present and will not be changed")
+			return nil
+		}
+
+		if existingConfig.Scripts == nil {
+			zap.L().Warn(fmt.Sprintf("deviceID %s, deviceApp %s does not support Scripts; ignoring", *deviceInfo.ID, *deviceInfo.App))
+			return nil
+		}
+
+		_, err := t.Script().SetScripts(ctx, config)
+		return err
+	}
+
+	setKVS := func(config map[string]interface{}) error {
+
+		if config == nil {
+			zap.L().Debug("KVS is not present and will not be changed")
+			return nil
+		}
+
+		if existingConfig.KVS == nil {
+			zap.L().Warn(fmt.Sprintf("deviceID %s, deviceApp %s does not support KVS; ignoring", *deviceInfo.ID, *deviceInfo.App))
+			return nil
+		}
+
+		_, err := t.KVS().SetConfig(ctx, config)
+		return err
+	}
+
 	var errors *multierror.Error
 
 	addError := func(err error) {
@@ -692,8 +958,6 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 		errors = multierror.Append(errors, err)
 	}
 
-	config = config.Clone()
-
 	addError(setUserCA(config.UserCA))
 	addError(setTLSClientCert(config.TLSClientCert))
 	addError(setTLSClientKey(config.TLSClientKey))
@@ -707,6 +971,17 @@ func (t *Client) SetConfig(ctx context.Context, config *Config, force bool) (*Co
 	addError(setLight(config.Light))
 	addError(setInput(config.Input))
 	addError(setSwitch(config.Switch))
+	addError(setCover(config.Cover))
+	addError(setEM(config.EM))
+	addError(setEM1(config.EM1))
+	addError(setPM1(config.PM1))
+	addError(setTemperature(config.Temperature))
+	addError(setHumidity(config.Humidity))
+	addError(setIlluminance(config.Illuminance))
+	addError(setSchedules(config.Schedules))
+	addError(setWebhooks(config.Webhooks))
+	addError(setScripts(config.Scripts))
+	addError(setKVS(config.KVS))
 	addError(setAuth(config.Auth))
 
 	if rebootRequired {
@@ -735,33 +1010,24 @@ func (t *Client) GetDeviceInfo(ctx context.Context) (*DeviceInfo, error) {
 
 	method := Component + ".GetDeviceInfo"
 
-	if t.deviceInfo != nil {
-		return t.deviceInfo.Clone(), nil
-	}
+	t.cacheMutex.Lock()
+	deviceInfo := t.deviceInfo
+	t.cacheMutex.Unlock()
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-	})
-	if err != nil {
-		return nil, getErr(method, err)
+	if deviceInfo != nil {
+		return deviceInfo.Clone(), nil
 	}
 
-	response := &DeviceInfoResponse{}
-	err = json.Unmarshal(respBytes, response)
+	result, err := rpc.Call[any, *DeviceInfo](ctx, t.getMessageHandler(), method, nil)
 	if err != nil {
 		return nil, getErr(method, err)
 	}
 
-	if response.Error != nil {
-		return nil, getErr(method, response.Error)
-	}
-
-	if response.Result == nil {
-		return nil, getErr(method, fmt.Errorf("result is missing from response"))
-	}
+	t.cacheMutex.Lock()
+	t.deviceInfo = result
+	t.cacheMutex.Unlock()
 
-	t.deviceInfo = response.Result
-	return t.deviceInfo.Clone(), nil
+	return result.Clone(), nil
 }
 
 // CheckForUpdate checks for new firmware version for the device and returns information about it.
@@ -802,25 +1068,7 @@ func (t *Client) Update(ctx context.Context, config *UpdateConfig) error {
 
 	method := Component + ".Update"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: config,
-	})
-	if err != nil {
-		return getErr(method, err)
-	}
-
-	response := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return err
-	}
-
-	if response.Error != nil {
-		return getErr(method, response.Error)
-	}
-
-	return nil
+	return getErr(method, rpc.Exec(ctx, t.getMessageHandler(), method, config))
 }
 
 // FactoryReset resets the configuration to its default state
@@ -828,24 +1076,7 @@ func (t *Client) FactoryReset(ctx context.Context) error {
 
 	method := Component + ".FactoryReset"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-	})
-	if err != nil {
-		return getErr(method, err)
-	}
-
-	response := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return err
-	}
-
-	if response.Error != nil {
-		return getErr(method, response.Error)
-	}
-
-	return nil
+	return getErr(method, rpc.Exec[any](ctx, t.getMessageHandler(), method, nil))
 }
 
 // ResetWiFiConfig resets the WiFi configuration of the device
@@ -853,24 +1084,7 @@ func (t *Client) ResetWiFiConfig(ctx context.Context) error {
 
 	method := Component + ".ResetWiFiConfig"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-	})
-	if err != nil {
-		return getErr(method, err)
-	}
-
-	response := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return getErr(method, err)
-	}
-
-	if response.Error != nil {
-		return getErr(method, response.Error)
-	}
-
-	return nil
+	return getErr(method, rpc.Exec[any](ctx, t.getMessageHandler(), method, nil))
 }
 
 // Reboot reboots the device
@@ -878,36 +1092,5 @@ func (t *Client) Reboot(ctx context.Context) error {
 
 	method := Component + ".Reboot"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-	})
-	if err != nil {
-		return getErr(method, err)
-	}
-
-	response := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return getErr(method, err)
-	}
-
-	if response.Error != nil {
-		return getErr(method, response.Error)
-	}
-
-	return nil
-}
-
-func splitByWidth(str string, size int) []string {
-	strLength := len(str)
-	var splited []string
-	var stop int
-	for i := 0; i < strLength; i += size {
-		stop = i + size
-		if stop > strLength {
-			stop = strLength
-		}
-		splited = append(splited, str[i:stop])
-	}
-	return splited
+	return getErr(method, rpc.Exec[any](ctx, t.getMessageHandler(), method, nil))
 }
@@ -0,0 +1,251 @@
+package shelly_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jodydadescott/shelly-client/sdk/client"
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	mqtt_types "github.com/jodydadescott/shelly-client/sdk/mqtt/types"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/shelly"
+)
+
+// TestSetConfigDoesNotChangeConfig checks that the config of the caller is not rendered
+// or sanitized by SetConfig
+func TestSetConfigDoesNotChangeConfig(t *testing.T) {
+
+	ctx := context.Background()
+
+	device := fake.NewDevice(&fake.DeviceConfig{Switch: 1})
+	c := client.NewWithMessageHandlerFactory(&client.Config{}, fake.New(nil, device))
+	defer c.Close()
+
+	enable := false
+	server := "broker:1883"
+	event := "switch.on"
+	name := "notify"
+	cid := 0
+
+	config := &shelly.Config{
+		// Sanatize removes the server of a disabled MQTT config
+		Mqtt: &mqtt_types.Config{Enable: &enable, Server: &server},
+		Webhooks: []*shelly.Webhook{
+			{CID: &cid, Event: &event, Name: &name, URLs: []string{"http://hub.lan/{{.DeviceID}}"}},
+		},
+	}
+
+	before, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.SetConfig(ctx, config, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(before) != string(after) {
+		t.Errorf("config changed by SetConfig\nbefore %s\nafter  %s", string(before), string(after))
+	}
+
+	hooks, err := c.Webhook().List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "http://hub.lan/" + device.ID()
+	if len(hooks.Hooks) != 1 || len(hooks.Hooks[0].URLs) != 1 || hooks.Hooks[0].URLs[0] != want {
+		t.Errorf("webhooks %s, want a webhook with URL %s", mustMarshal(hooks), want)
+	}
+}
+
+func TestGetComponents(t *testing.T) {
+
+	// The fake returns 8 components for each Shelly.GetComponents
+	tests := []struct {
+		name   string
+		config *shelly.ComponentsConfig
+		want   []string
+		pages  int
+	}{
+		{name: "all", pages: 3},
+		{name: "keys", config: &shelly.ComponentsConfig{Keys: []string{"switch:0", "switch:11"}}, want: []string{"switch:0", "switch:11"}, pages: 1},
+		{name: "missing key", config: &shelly.ComponentsConfig{Keys: []string{"switch:12"}}, want: []string{}, pages: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctx := context.Background()
+
+			device := fake.NewDevice(&fake.DeviceConfig{Switch: 12})
+			c := client.NewWithMessageHandlerFactory(&client.Config{}, fake.New(nil, device))
+			defer c.Close()
+
+			before := len(device.Calls())
+
+			components, err := c.GetComponents(ctx, tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pages := 0
+			for _, v := range device.Calls()[before:] {
+				if v == "Shelly.GetComponents" {
+					pages++
+				}
+			}
+
+			if pages != tt.pages {
+				t.Errorf("Shelly.GetComponents sent %d times, want %d", pages, tt.pages)
+			}
+
+			keys := make(map[string]bool)
+			for _, v := range components.Components {
+				if keys[v.Key] {
+					t.Errorf("component %s returned twice", v.Key)
+				}
+				keys[v.Key] = true
+			}
+
+			if tt.want == nil {
+				for i := 0; i < 12; i++ {
+					if key := fmt.Sprintf("switch:%d", i); !keys[key] {
+						t.Errorf("component %s not returned", key)
+					}
+				}
+				return
+			}
+
+			if len(keys) != len(tt.want) {
+				t.Errorf("components %v, want %v", keys, tt.want)
+			}
+
+			for _, v := range tt.want {
+				if !keys[v] {
+					t.Errorf("component %s not returned", v)
+				}
+			}
+		})
+	}
+}
+
+// TestSensors checks the status and config of the sensor components through the sensor
+// clients and the device config
+func TestSensors(t *testing.T) {
+
+	ctx := context.Background()
+
+	device := fake.NewDevice(&fake.DeviceConfig{Components: []string{"temperature:0", "humidity:0", "illuminance:0", "devicepower:0"}})
+	c := client.NewWithMessageHandlerFactory(&client.Config{}, fake.New(nil, device))
+	defer c.Close()
+
+	status, err := c.GetStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := status.Temperature[0]; v == nil || v.TC == nil || *v.TC != 21.5 {
+		t.Errorf("temperature status %s, want tC 21.5", mustMarshal(v))
+	}
+
+	if v := status.Humidity[0]; v == nil || v.Rh == nil || *v.Rh != 45 {
+		t.Errorf("humidity status %s, want rh 45", mustMarshal(v))
+	}
+
+	if v := status.Illuminance[0]; v == nil || v.Illumination == nil || *v.Illumination != "twilight" {
+		t.Errorf("illuminance status %s, want illumination twilight", mustMarshal(v))
+	}
+
+	if v := status.DevicePower[0]; v == nil || v.Battery == nil || v.Battery.Percent == nil || *v.Battery.Percent != 90 {
+		t.Errorf("devicepower status %s, want battery percent 90", mustMarshal(v))
+	}
+
+	power, err := c.DevicePower().GetStatus(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if power.External == nil || power.External.Present == nil || *power.External.Present {
+		t.Errorf("devicepower external %s, want not present", mustMarshal(power.External))
+	}
+
+	config, err := c.GetConfig(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.GetTemperature(0) == nil || config.GetHumidity(0) == nil || config.Illuminance[0] == nil {
+		t.Fatalf("config %s, want temperature, humidity and illuminance 0", mustMarshal(config))
+	}
+
+	offsetC := 1.5
+	offset := -2.0
+	darkThr := 50.0
+
+	config.GetTemperature(0).OffsetC = &offsetC
+	config.GetHumidity(0).Offset = &offset
+	config.Illuminance[0].DarkThr = &darkThr
+
+	_, err = c.SetConfig(ctx, config, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	temperature, err := c.Temperature().GetConfig(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if temperature.OffsetC == nil || *temperature.OffsetC != offsetC {
+		t.Errorf("temperature offset %s, want %v", mustMarshal(temperature.OffsetC), offsetC)
+	}
+
+	humidity, err := c.Humidity().GetConfig(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if humidity.Offset == nil || *humidity.Offset != offset {
+		t.Errorf("humidity offset %s, want %v", mustMarshal(humidity.Offset), offset)
+	}
+
+	illuminance, err := c.Illuminance().GetConfig(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if illuminance.DarkThr == nil || *illuminance.DarkThr != darkThr {
+		t.Errorf("illuminance dark threshold %s, want %v", mustMarshal(illuminance.DarkThr), darkThr)
+	}
+
+	// A sensor that is not present and a config without an ID fail
+	_, err = c.Temperature().GetStatus(ctx, 1)
+	if !errors.Is(err, msg_types.ErrNotFound) {
+		t.Errorf("error %v for a missing temperature, want ErrNotFound", err)
+	}
+
+	_, err = c.DevicePower().GetStatus(ctx, 1)
+	if !errors.Is(err, msg_types.ErrNotFound) {
+		t.Errorf("error %v for a missing devicepower, want ErrNotFound", err)
+	}
+
+	humidity.ID = nil
+	if err := c.Humidity().SetConfig(ctx, humidity); err == nil {
+		t.Error("error nil for a humidity config without an ID, want error")
+	}
+}
+
+func mustMarshal(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -5,6 +5,4 @@ const (
 
 	// ShellyUser is the default (and currently only supported) username
 	ShellyUser = "admin"
-
-	maxRPCChunkSize = 1000
 )
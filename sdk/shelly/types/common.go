@@ -3,13 +3,26 @@ package types
 import (
 	bluetooth_types "github.com/jodydadescott/shelly-client/sdk/bluetooth/types"
 	cloud_types "github.com/jodydadescott/shelly-client/sdk/cloud/types"
+	cover_types "github.com/jodydadescott/shelly-client/sdk/cover/types"
+	devicepower_types "github.com/jodydadescott/shelly-client/sdk/devicepower/types"
+	em_types "github.com/jodydadescott/shelly-client/sdk/em/types"
+	em1_types "github.com/jodydadescott/shelly-client/sdk/em1/types"
+	em1data_types "github.com/jodydadescott/shelly-client/sdk/em1data/types"
+	emdata_types "github.com/jodydadescott/shelly-client/sdk/emdata/types"
 	ethernet_types "github.com/jodydadescott/shelly-client/sdk/ethernet/types"
+	humidity_types "github.com/jodydadescott/shelly-client/sdk/humidity/types"
+	illuminance_types "github.com/jodydadescott/shelly-client/sdk/illuminance/types"
 	input_types "github.com/jodydadescott/shelly-client/sdk/input/types"
 	light_types "github.com/jodydadescott/shelly-client/sdk/light/types"
 	mqtt_types "github.com/jodydadescott/shelly-client/sdk/mqtt/types"
 	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	pm1_types "github.com/jodydadescott/shelly-client/sdk/pm1/types"
+	schedule_types "github.com/jodydadescott/shelly-client/sdk/schedule/types"
+	script_types "github.com/jodydadescott/shelly-client/sdk/script/types"
 	switch_types "github.com/jodydadescott/shelly-client/sdk/switchx/types"
 	system_types "github.com/jodydadescott/shelly-client/sdk/system/types"
+	temperature_types "github.com/jodydadescott/shelly-client/sdk/temperature/types"
+	webhook_types "github.com/jodydadescott/shelly-client/sdk/webhook/types"
 	websocket_types "github.com/jodydadescott/shelly-client/sdk/websocket/types"
 	wifi_types "github.com/jodydadescott/shelly-client/sdk/wifi/types"
 )
@@ -48,4 +61,38 @@ type InputConfig = input_types.Config
 type SwitchStatus = switch_types.Status
 type SwitchConfig = switch_types.Config
 
+type CoverStatus = cover_types.Status
+type CoverConfig = cover_types.Config
+
+type EMStatus = em_types.Status
+type EMConfig = em_types.Config
+
+type EM1Status = em1_types.Status
+type EM1Config = em1_types.Config
+
+type PM1Status = pm1_types.Status
+type PM1Config = pm1_types.Config
+
+type EMDataStatus = emdata_types.Status
+
+type EM1DataStatus = em1data_types.Status
+
+type TemperatureStatus = temperature_types.Status
+type TemperatureConfig = temperature_types.Config
+
+type HumidityStatus = humidity_types.Status
+type HumidityConfig = humidity_types.Config
+
+type IlluminanceStatus = illuminance_types.Status
+type IlluminanceConfig = illuminance_types.Config
+
+type DevicePowerStatus = devicepower_types.Status
+
+type ScheduleJob = schedule_types.Job
+
+type Script = script_types.Script
+
+type Webhook = webhook_types.Webhook
+type TemplateVars = webhook_types.TemplateVars
+
 type SystemAvailableUpdates = system_types.SystemAvailableUpdates
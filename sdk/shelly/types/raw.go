@@ -1,263 +1,250 @@
 package types
 
-// RawShellyStatus internal use only
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RawShellyStatus internal use only. The status of each component keyed by the component
+// key such as sys or switch:0.
 // https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Shelly
-type RawShellyStatus struct {
-	Bluetooth *BluetoothStatus `json:"ble,omitempty" yaml:"ble,omitempty"`
-	Cloud     *CloudStatus     `json:"cloud,omitempty" yaml:"cloud,omitempty"`
-	Mqtt      *MqttStatus      `json:"mqtt,omitempty" yaml:"mqtt,omitempty"`
-	Ethernet  *EthernetStatus  `json:"eth,omitempty" yaml:"eth,omitempty"`
-	System    *SystemStatus    `json:"sys,omitempty" yaml:"sys,omitempty"`
-	Wifi      *WifiStatus      `json:"wifi,omitempty" yaml:"wifi,omitempty"`
-	Websocket *WebsocketStatus `json:"ws,omitempty" yaml:"ws,omitempty"`
-	Light0    *LightStatus     `json:"light:0,omitempty" yaml:"light:0,omitempty"`
-	Light1    *LightStatus     `json:"light:1,omitempty" yaml:"light:1,omitempty"`
-	Light2    *LightStatus     `json:"light:2,omitempty" yaml:"light:2,omitempty"`
-	Light3    *LightStatus     `json:"light:3,omitempty" yaml:"light:3,omitempty"`
-	Light4    *LightStatus     `json:"light:4,omitempty" yaml:"light:4,omitempty"`
-	Light5    *LightStatus     `json:"light:5,omitempty" yaml:"light:5,omitempty"`
-	Light6    *LightStatus     `json:"light:6,omitempty" yaml:"light:6,omitempty"`
-	Light7    *LightStatus     `json:"light:7,omitempty" yaml:"light:7,omitempty"`
-	Input0    *InputStatus     `json:"input:0,omitempty" yaml:"input:0,omitempty"`
-	Input1    *InputStatus     `json:"input:1,omitempty" yaml:"input:1,omitempty"`
-	Input2    *InputStatus     `json:"input:2,omitempty" yaml:"input:2,omitempty"`
-	Input3    *InputStatus     `json:"input:3,omitempty" yaml:"input:3,omitempty"`
-	Input4    *InputStatus     `json:"input:4,omitempty" yaml:"input:4,omitempty"`
-	Input5    *InputStatus     `json:"input:5,omitempty" yaml:"input:5,omitempty"`
-	Input6    *InputStatus     `json:"input:6,omitempty" yaml:"input:6,omitempty"`
-	Input7    *InputStatus     `json:"input:7,omitempty" yaml:"input:7,omitempty"`
-	Switch0   *SwitchStatus    `json:"switch:0,omitempty" yaml:"switch:0,omitempty"`
-	Switch1   *SwitchStatus    `json:"switch:1,omitempty" yaml:"switch:1,omitempty"`
-	Switch2   *SwitchStatus    `json:"switch:2,omitempty" yaml:"switch:2,omitempty"`
-	Switch3   *SwitchStatus    `json:"switch:3,omitempty" yaml:"switch:3,omitempty"`
-	Switch4   *SwitchStatus    `json:"switch:4,omitempty" yaml:"switch:4,omitempty"`
-	Switch5   *SwitchStatus    `json:"switch:5,omitempty" yaml:"switch:5,omitempty"`
-	Switch6   *SwitchStatus    `json:"switch:6,omitempty" yaml:"switch:6,omitempty"`
-	Switch7   *SwitchStatus    `json:"switch:7,omitempty" yaml:"switch:7,omitempty"`
-}
+type RawShellyStatus map[string]json.RawMessage
 
-func (t *RawShellyStatus) Convert() *Status {
-
-	c := &Status{
-		Bluetooth: t.Bluetooth,
-		Cloud:     t.Cloud,
-		Mqtt:      t.Mqtt,
-		Ethernet:  t.Ethernet,
-		System:    t.System,
-		Wifi:      t.Wifi,
-		Light:     make(map[int]*LightStatus),
-		Input:     make(map[int]*InputStatus),
-		Switch:    make(map[int]*SwitchStatus),
-	}
+// Convert decodes the status of each known component. The status of components that are
+// not known is kept as raw JSON in Other.
+func (t RawShellyStatus) Convert() (*Status, error) {
 
-	if t.Light0 != nil {
-		c.Light[0] = t.Light0
-	}
-	if t.Light1 != nil {
-		c.Light[1] = t.Light1
-	}
-	if t.Light2 != nil {
-		c.Light[2] = t.Light2
-	}
-	if t.Light3 != nil {
-		c.Light[3] = t.Light3
-	}
-	if t.Light4 != nil {
-		c.Light[4] = t.Light4
-	}
-	if t.Light5 != nil {
-		c.Light[5] = t.Light5
-	}
-	if t.Light6 != nil {
-		c.Light[6] = t.Light6
-	}
-	if t.Light7 != nil {
-		c.Light[7] = t.Light7
-	}
+	c := &Status{}
 
-	if t.Input0 != nil {
-		c.Input[0] = t.Input0
-	}
-	if t.Input1 != nil {
-		c.Input[1] = t.Input1
-	}
-	if t.Input2 != nil {
-		c.Input[2] = t.Input2
-	}
-	if t.Input3 != nil {
-		c.Input[3] = t.Input3
-	}
-	if t.Input4 != nil {
-		c.Input[4] = t.Input4
-	}
-	if t.Input5 != nil {
-		c.Input[5] = t.Input5
-	}
-	if t.Input6 != nil {
-		c.Input[6] = t.Input6
-	}
-	if t.Input7 != nil {
-		c.Input[7] = t.Input7
-	}
+	for key, value := range t {
 
-	if t.Switch0 != nil {
-		c.Switch[0] = t.Switch0
-	}
-	if t.Switch1 != nil {
-		c.Switch[1] = t.Switch1
-	}
-	if t.Switch2 != nil {
-		c.Switch[2] = t.Switch2
-	}
-	if t.Switch3 != nil {
-		c.Switch[3] = t.Switch3
-	}
-	if t.Switch4 != nil {
-		c.Switch[4] = t.Switch4
-	}
-	if t.Switch5 != nil {
-		c.Switch[5] = t.Switch5
-	}
-	if t.Switch6 != nil {
-		c.Switch[6] = t.Switch6
-	}
-	if t.Switch7 != nil {
-		c.Switch[7] = t.Switch7
+		var err error
+
+		componentType, id, hasID := ParseComponentKey(key)
+
+		switch {
+
+		case key == "ble":
+			err = json.Unmarshal(value, &c.Bluetooth)
+
+		case key == "cloud":
+			err = json.Unmarshal(value, &c.Cloud)
+
+		case key == "mqtt":
+			err = json.Unmarshal(value, &c.Mqtt)
+
+		case key == "eth":
+			err = json.Unmarshal(value, &c.Ethernet)
+
+		case key == "sys":
+			err = json.Unmarshal(value, &c.System)
+
+		case key == "wifi":
+			err = json.Unmarshal(value, &c.Wifi)
+
+		case key == "ws":
+			err = json.Unmarshal(value, &c.Websocket)
+
+		case hasID && componentType == "light":
+			err = decodeComponent(&c.Light, id, value)
+
+		case hasID && componentType == "input":
+			err = decodeComponent(&c.Input, id, value)
+
+		case hasID && componentType == "switch":
+			err = decodeComponent(&c.Switch, id, value)
+
+		case hasID && componentType == "cover":
+			err = decodeComponent(&c.Cover, id, value)
+
+		case hasID && componentType == "em":
+			err = decodeComponent(&c.EM, id, value)
+
+		case hasID && componentType == "em1":
+			err = decodeComponent(&c.EM1, id, value)
+
+		case hasID && componentType == "pm1":
+			err = decodeComponent(&c.PM1, id, value)
+
+		case hasID && componentType == "emdata":
+			err = decodeComponent(&c.EMData, id, value)
+
+		case hasID && componentType == "em1data":
+			err = decodeComponent(&c.EM1Data, id, value)
+
+		case hasID && componentType == "temperature":
+			err = decodeComponent(&c.Temperature, id, value)
+
+		case hasID && componentType == "humidity":
+			err = decodeComponent(&c.Humidity, id, value)
+
+		case hasID && componentType == "illuminance":
+			err = decodeComponent(&c.Illuminance, id, value)
+
+		case hasID && componentType == "devicepower":
+			err = decodeComponent(&c.DevicePower, id, value)
+
+		default:
+			if c.Other == nil {
+				c.Other = make(map[string]json.RawMessage)
+			}
+			c.Other[key] = value
+
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("key %s; %w", key, err)
+		}
 	}
 
-	return c
+	return c, nil
 }
 
-// RawConfig internal use only
+// RawConfig internal use only. The config of each component keyed by the component key
+// such as sys or switch:0.
 // https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Shelly#configuration
-type RawConfig struct {
-	Bluetooth *BluetoothConfig `json:"ble,omitempty" yaml:"ble,omitempty"`
-	Cloud     *CloudConfig     `json:"cloud,omitempty" yaml:"cloud,omitempty"`
-	Mqtt      *MqttConfig      `json:"mqtt,omitempty" yaml:"mqtt,omitempty"`
-	Ethernet  *EthernetConfig  `json:"eth,omitempty" yaml:"eth,omitempty"`
-	System    *SystemConfig    `json:"sys,omitempty" yaml:"sys,omitempty"`
-	Wifi      *WifiConfig      `json:"wifi,omitempty" yaml:"wifi,omitempty"`
-	Websocket *WebsocketConfig `json:"ws,omitempty" yaml:"ws,omitempty"`
-	Light0    *LightConfig     `json:"light:0,omitempty" yaml:"light:0,omitempty"`
-	Light1    *LightConfig     `json:"light:1,omitempty" yaml:"light:1,omitempty"`
-	Light2    *LightConfig     `json:"light:2,omitempty" yaml:"light:2,omitempty"`
-	Light3    *LightConfig     `json:"light:3,omitempty" yaml:"light:3,omitempty"`
-	Light4    *LightConfig     `json:"light:4,omitempty" yaml:"light:4,omitempty"`
-	Light5    *LightConfig     `json:"light:5,omitempty" yaml:"light:5,omitempty"`
-	Light6    *LightConfig     `json:"light:6,omitempty" yaml:"light:6,omitempty"`
-	Light7    *LightConfig     `json:"light:7,omitempty" yaml:"light:7,omitempty"`
-	Input0    *InputConfig     `json:"input:0,omitempty" yaml:"input:0,omitempty"`
-	Input1    *InputConfig     `json:"input:1,omitempty" yaml:"input:1,omitempty"`
-	Input2    *InputConfig     `json:"input:2,omitempty" yaml:"input:2,omitempty"`
-	Input3    *InputConfig     `json:"input:3,omitempty" yaml:"input:3,omitempty"`
-	Input4    *InputConfig     `json:"input:4,omitempty" yaml:"input:4,omitempty"`
-	Input5    *InputConfig     `json:"input:5,omitempty" yaml:"input:5,omitempty"`
-	Input6    *InputConfig     `json:"input:6,omitempty" yaml:"input:6,omitempty"`
-	Input7    *InputConfig     `json:"input:7,omitempty" yaml:"input:7,omitempty"`
-	Switch0   *SwitchConfig    `json:"switch:0,omitempty" yaml:"switch:0,omitempty"`
-	Switch1   *SwitchConfig    `json:"switch:1,omitempty" yaml:"switch:1,omitempty"`
-	Switch2   *SwitchConfig    `json:"switch:2,omitempty" yaml:"switch:2,omitempty"`
-	Switch3   *SwitchConfig    `json:"switch:3,omitempty" yaml:"switch:3,omitempty"`
-	Switch4   *SwitchConfig    `json:"switch:4,omitempty" yaml:"switch:4,omitempty"`
-	Switch5   *SwitchConfig    `json:"switch:5,omitempty" yaml:"switch:5,omitempty"`
-	Switch6   *SwitchConfig    `json:"switch:6,omitempty" yaml:"switch:6,omitempty"`
-	Switch7   *SwitchConfig    `json:"switch:7,omitempty" yaml:"switch:7,omitempty"`
-}
+type RawConfig map[string]json.RawMessage
 
-func (t *RawConfig) Convert() *Config {
-
-	c := &Config{
-		Bluetooth: t.Bluetooth,
-		Cloud:     t.Cloud,
-		Mqtt:      t.Mqtt,
-		Ethernet:  t.Ethernet,
-		System:    t.System,
-		Wifi:      t.Wifi,
-		Websocket: t.Websocket,
-		Light:     make(map[int]*LightConfig),
-		Input:     make(map[int]*InputConfig),
-		Switch:    make(map[int]*SwitchConfig),
-	}
+// Convert decodes the config of each known component. The config of components that are
+// not known is kept as raw JSON in Other.
+func (t RawConfig) Convert() (*Config, error) {
 
-	if t.Light0 != nil {
-		c.Light[0] = t.Light0
-	}
-	if t.Light1 != nil {
-		c.Light[1] = t.Light1
-	}
-	if t.Light2 != nil {
-		c.Light[2] = t.Light2
-	}
-	if t.Light3 != nil {
-		c.Light[3] = t.Light3
-	}
-	if t.Light4 != nil {
-		c.Light[4] = t.Light4
-	}
-	if t.Light5 != nil {
-		c.Light[5] = t.Light5
-	}
-	if t.Light6 != nil {
-		c.Light[6] = t.Light6
-	}
-	if t.Light7 != nil {
-		c.Light[7] = t.Light7
-	}
+	c := &Config{}
 
-	if t.Input0 != nil {
-		c.Input[0] = t.Input0
-	}
-	if t.Input1 != nil {
-		c.Input[1] = t.Input1
-	}
-	if t.Input2 != nil {
-		c.Input[2] = t.Input2
-	}
-	if t.Input3 != nil {
-		c.Input[3] = t.Input3
-	}
-	if t.Input4 != nil {
-		c.Input[4] = t.Input4
-	}
-	if t.Input5 != nil {
-		c.Input[5] = t.Input5
-	}
-	if t.Input6 != nil {
-		c.Input[6] = t.Input6
-	}
-	if t.Input7 != nil {
-		c.Input[7] = t.Input7
-	}
+	for key, value := range t {
 
-	if t.Switch0 != nil {
-		c.Switch[0] = t.Switch0
-	}
-	if t.Switch1 != nil {
-		c.Switch[1] = t.Switch1
-	}
-	if t.Switch2 != nil {
-		c.Switch[2] = t.Switch2
+		var err error
+
+		componentType, id, hasID := ParseComponentKey(key)
+
+		switch {
+
+		case key == "ble":
+			err = json.Unmarshal(value, &c.Bluetooth)
+
+		case key == "cloud":
+			err = json.Unmarshal(value, &c.Cloud)
+
+		case key == "mqtt":
+			err = json.Unmarshal(value, &c.Mqtt)
+
+		case key == "eth":
+			err = json.Unmarshal(value, &c.Ethernet)
+
+		case key == "sys":
+			err = json.Unmarshal(value, &c.System)
+
+		case key == "wifi":
+			err = json.Unmarshal(value, &c.Wifi)
+
+		case key == "ws":
+			err = json.Unmarshal(value, &c.Websocket)
+
+		case hasID && componentType == "light":
+			err = decodeComponent(&c.Light, id, value)
+
+		case hasID && componentType == "input":
+			err = decodeComponent(&c.Input, id, value)
+
+		case hasID && componentType == "switch":
+			err = decodeComponent(&c.Switch, id, value)
+
+		case hasID && componentType == "cover":
+			err = decodeComponent(&c.Cover, id, value)
+
+		case hasID && componentType == "em":
+			err = decodeComponent(&c.EM, id, value)
+
+		case hasID && componentType == "em1":
+			err = decodeComponent(&c.EM1, id, value)
+
+		case hasID && componentType == "pm1":
+			err = decodeComponent(&c.PM1, id, value)
+
+		case hasID && componentType == "temperature":
+			err = decodeComponent(&c.Temperature, id, value)
+
+		case hasID && componentType == "humidity":
+			err = decodeComponent(&c.Humidity, id, value)
+
+		case hasID && componentType == "illuminance":
+			err = decodeComponent(&c.Illuminance, id, value)
+
+		default:
+			if c.Other == nil {
+				c.Other = make(map[string]json.RawMessage)
+			}
+			c.Other[key] = value
+
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("key %s; %w", key, err)
+		}
 	}
-	if t.Switch3 != nil {
-		c.Switch[3] = t.Switch3
+
+	return c, nil
+}
+
+// decodeComponent decodes value and adds it to the map with the id. The map is created
+// if it is nil.
+func decodeComponent[T any](m *map[int]*T, id int, value json.RawMessage) error {
+
+	v := new(T)
+
+	err := json.Unmarshal(value, v)
+	if err != nil {
+		return err
 	}
-	if t.Switch4 != nil {
-		c.Switch[4] = t.Switch4
+
+	if *m == nil {
+		*m = make(map[int]*T)
 	}
-	if t.Switch5 != nil {
-		c.Switch[5] = t.Switch5
+
+	(*m)[id] = v
+	return nil
+}
+
+// ParseComponentKey splits a component key such as switch:0 into the type switch and the
+// ID 0. If the key does not have an ID such as sys then the type is the key and hasID is
+// false.
+func ParseComponentKey(key string) (componentType string, id int, hasID bool) {
+
+	componentType, idString, found := strings.Cut(key, ":")
+	if !found {
+		return key, 0, false
 	}
-	if t.Switch6 != nil {
-		c.Switch[6] = t.Switch6
+
+	id, err := strconv.Atoi(idString)
+	if err != nil {
+		return key, 0, false
 	}
-	if t.Switch7 != nil {
-		c.Switch[7] = t.Switch7
+
+	return componentType, id, true
+}
+
+// getOtherByType returns the entries of other with the component type keyed by ID
+func getOtherByType(other map[string]json.RawMessage, componentType string) map[int]json.RawMessage {
+
+	var result map[int]json.RawMessage
+
+	for key, value := range other {
+		t, id, hasID := ParseComponentKey(key)
+		if !hasID || t != componentType {
+			continue
+		}
+		if result == nil {
+			result = make(map[int]json.RawMessage)
+		}
+		result[id] = value
 	}
 
-	return c
+	return result
 }
 
-// ShellyAuthConfig internal use only
+// RawAuthConfig internal use only
 // https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Shelly#configuration
 type RawAuthConfig struct {
 	// User is used by the following methods:
@@ -284,3 +271,29 @@ type RawTLSConfig struct {
 	// PutTLSClientKey : true if more data will be appended afterwards, default false
 	Append *bool `json:"append,omitempty" yaml:"append,omitempty"`
 }
+
+// RawComponentsParams internal use only
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Shelly#shellygetcomponents
+type RawComponentsParams struct {
+	// Offset index of the component from which to start generating the result. Optional
+	Offset *int `json:"offset,omitempty" yaml:"offset,omitempty"`
+	// Include one or more of status and config. Optional
+	Include []string `json:"include,omitempty" yaml:"include,omitempty"`
+	// DynamicOnly if true only dynamic components are returned. Optional
+	DynamicOnly *bool `json:"dynamic_only,omitempty" yaml:"dynamic_only,omitempty"`
+	// Keys component keys to return. Optional
+	Keys []string `json:"keys,omitempty" yaml:"keys,omitempty"`
+}
+
+// RawComponentsResult internal use only
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Shelly#shellygetcomponents
+type RawComponentsResult struct {
+	// Components the components of this page
+	Components []*Component `json:"components,omitempty" yaml:"components,omitempty"`
+	// CfgRev the config revision of the device
+	CfgRev int `json:"cfg_rev,omitempty" yaml:"cfg_rev,omitempty"`
+	// Offset the index of the first component of this page
+	Offset int `json:"offset,omitempty" yaml:"offset,omitempty"`
+	// Total the total number of components
+	Total int `json:"total,omitempty" yaml:"total,omitempty"`
+}
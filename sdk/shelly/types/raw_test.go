@@ -0,0 +1,144 @@
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jodydadescott/shelly-client/sdk/shelly/types"
+)
+
+func TestRawShellyStatusConvert(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		raw     string
+		check   func(*types.Status) bool
+		wantErr bool
+	}{
+		{
+			name:  "switch",
+			raw:   `{"switch:1":{"id":1,"output":true}}`,
+			check: func(s *types.Status) bool { return s.Switch[1] != nil && s.Switch[1].Output },
+		},
+		{
+			name:  "cover and sensor",
+			raw:   `{"cover:0":{"id":0,"state":"open"},"temperature:100":{"id":100,"tC":21.5}}`,
+			check: func(s *types.Status) bool { return s.Cover[0] != nil && s.Temperature[100] != nil },
+		},
+		{
+			name:  "energy",
+			raw:   `{"em:0":{"id":0},"em1data:1":{"id":1}}`,
+			check: func(s *types.Status) bool { return s.EM[0] != nil && s.EM1Data[1] != nil },
+		},
+		{
+			name:  "unknown component",
+			raw:   `{"boolean:200":{"id":200,"value":true}}`,
+			check: func(s *types.Status) bool { return string(s.Other["boolean:200"]) == `{"id":200,"value":true}` },
+		},
+		{
+			name:  "type without ID",
+			raw:   `{"switch":{"output":true}}`,
+			check: func(s *types.Status) bool { return s.Switch == nil && s.Other["switch"] != nil },
+		},
+		{
+			name:    "malformed",
+			raw:     `{"switch:0":{"output":"on"}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			raw := types.RawShellyStatus{}
+			err := json.Unmarshal([]byte(tt.raw), &raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			status, err := raw.Convert()
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("error nil, want error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.check(status) {
+				t.Errorf("status %s not converted from %s", mustMarshal(t, status), tt.raw)
+			}
+		})
+	}
+}
+
+func TestRawConfigConvert(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		raw     string
+		check   func(*types.Config) bool
+		wantErr bool
+	}{
+		{
+			name: "switch",
+			raw:  `{"switch:0":{"id":0,"name":"kitchen"}}`,
+			check: func(c *types.Config) bool {
+				return c.Switch[0] != nil && c.Switch[0].Name != nil && *c.Switch[0].Name == "kitchen"
+			},
+		},
+		{
+			name:  "service",
+			raw:   `{"mqtt":{"enable":true}}`,
+			check: func(c *types.Config) bool { return c.Mqtt != nil && c.Mqtt.Enable != nil && *c.Mqtt.Enable },
+		},
+		{
+			name:  "metering and sensor",
+			raw:   `{"pm1:0":{"id":0},"humidity:100":{"id":100}}`,
+			check: func(c *types.Config) bool { return c.PM1[0] != nil && c.Humidity[100] != nil },
+		},
+		{
+			// The status only components are not part of the config
+			name:  "energy data",
+			raw:   `{"emdata:0":{"id":0}}`,
+			check: func(c *types.Config) bool { return c.Other["emdata:0"] != nil },
+		},
+		{
+			name:    "malformed",
+			raw:     `{"sys":{"device":"x"}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			raw := types.RawConfig{}
+			err := json.Unmarshal([]byte(tt.raw), &raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := raw.Convert()
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("error nil, want error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.check(config) {
+				t.Errorf("config %s not converted from %s", mustMarshal(t, config), tt.raw)
+			}
+		})
+	}
+}
@@ -1,12 +1,17 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/jinzhu/copier"
 	"go.uber.org/zap"
 
+	kvs_types "github.com/jodydadescott/shelly-client/sdk/kvs/types"
+	schedule_types "github.com/jodydadescott/shelly-client/sdk/schedule/types"
+	script_types "github.com/jodydadescott/shelly-client/sdk/script/types"
 	"github.com/jodydadescott/shelly-client/sdk/util"
+	webhook_types "github.com/jodydadescott/shelly-client/sdk/webhook/types"
 )
 
 // Result internal use only
@@ -16,24 +21,36 @@ type Result struct {
 }
 
 // SetConfigResponse internal use only
+//
+// Deprecated: the client decodes the result with rpc.Call. This type is no longer used
+// and will be removed.
 type SetConfigResponse struct {
 	Response
 	Result *Result `json:"result,omitempty"`
 }
 
 // GetConfigResponse internal use only
+//
+// Deprecated: the client decodes the result with rpc.Call. This type is no longer used
+// and will be removed.
 type GetConfigResponse struct {
 	Response
 	Result *RawConfig `json:"result,omitempty"`
 }
 
 // GetStatusResponse internal use only
+//
+// Deprecated: the client decodes the result with rpc.Call. This type is no longer used
+// and will be removed.
 type GetStatusResponse struct {
 	Response
 	Result *RawShellyStatus `json:"result,omitempty"`
 }
 
 // DeviceInfoResponse internal use only
+//
+// Deprecated: the client decodes the result with rpc.Call. This type is no longer used
+// and will be removed.
 type DeviceInfoResponse struct {
 	Response
 	Result *DeviceInfo `json:"result,omitempty"`
@@ -46,6 +63,9 @@ type CheckForUpdateResponse struct {
 }
 
 // ListMethodsResponse internal use only
+//
+// Deprecated: the client decodes the result with rpc.Call. This type is no longer used
+// and will be removed.
 type ListMethodsResponse struct {
 	Response
 	Result *RPCMethods `json:"result,omitempty"`
@@ -63,18 +83,45 @@ func (t *ConfigReport) Clone() *ConfigReport {
 	return c
 }
 
-// Status status of all the components of the device.
+// Status status of all the components of the device. Components with an ID such as
+// switch:0 are keyed by ID. The status of components that are not modeled by this SDK
+// such as boolean:200 is kept as raw JSON in Other keyed by the component key.
 // https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Shelly
 type Status struct {
-	Bluetooth *BluetoothStatus      `json:"ble,omitempty" yaml:"ble,omitempty"`
-	Cloud     *CloudStatus          `json:"cloud,omitempty" yaml:"cloud,omitempty"`
-	Mqtt      *MqttStatus           `json:"mqtt,omitempty" yaml:"mqtt,omitempty"`
-	Ethernet  *EthernetStatus       `json:"eth,omitempty" yaml:"eth,omitempty"`
-	System    *SystemStatus         `json:"sys,omitempty" yaml:"sys,omitempty"`
-	Wifi      *WifiStatus           `json:"wifi,omitempty" yaml:"wifi,omitempty"`
-	Light     map[int]*LightStatus  `json:"light,omitempty" yaml:"light,omitempty"`
-	Input     map[int]*InputStatus  `json:"input,omitempty" yaml:"input,omitempty"`
-	Switch    map[int]*SwitchStatus `json:"switch,omitempty" yaml:"switch,omitempty"`
+	Bluetooth   *BluetoothStatus           `json:"ble,omitempty" yaml:"ble,omitempty"`
+	Cloud       *CloudStatus               `json:"cloud,omitempty" yaml:"cloud,omitempty"`
+	Mqtt        *MqttStatus                `json:"mqtt,omitempty" yaml:"mqtt,omitempty"`
+	Ethernet    *EthernetStatus            `json:"eth,omitempty" yaml:"eth,omitempty"`
+	System      *SystemStatus              `json:"sys,omitempty" yaml:"sys,omitempty"`
+	Wifi        *WifiStatus                `json:"wifi,omitempty" yaml:"wifi,omitempty"`
+	Websocket   *WebsocketStatus           `json:"ws,omitempty" yaml:"ws,omitempty"`
+	Light       map[int]*LightStatus       `json:"light,omitempty" yaml:"light,omitempty"`
+	Input       map[int]*InputStatus       `json:"input,omitempty" yaml:"input,omitempty"`
+	Switch      map[int]*SwitchStatus      `json:"switch,omitempty" yaml:"switch,omitempty"`
+	Cover       map[int]*CoverStatus       `json:"cover,omitempty" yaml:"cover,omitempty"`
+	EM          map[int]*EMStatus          `json:"em,omitempty" yaml:"em,omitempty"`
+	EM1         map[int]*EM1Status         `json:"em1,omitempty" yaml:"em1,omitempty"`
+	PM1         map[int]*PM1Status         `json:"pm1,omitempty" yaml:"pm1,omitempty"`
+	EMData      map[int]*EMDataStatus      `json:"emdata,omitempty" yaml:"emdata,omitempty"`
+	EM1Data     map[int]*EM1DataStatus     `json:"em1data,omitempty" yaml:"em1data,omitempty"`
+	Temperature map[int]*TemperatureStatus `json:"temperature,omitempty" yaml:"temperature,omitempty"`
+	Humidity    map[int]*HumidityStatus    `json:"humidity,omitempty" yaml:"humidity,omitempty"`
+	Illuminance map[int]*IlluminanceStatus `json:"illuminance,omitempty" yaml:"illuminance,omitempty"`
+	DevicePower map[int]*DevicePowerStatus `json:"devicepower,omitempty" yaml:"devicepower,omitempty"`
+	Other       map[string]json.RawMessage `json:"other,omitempty" yaml:"other,omitempty"`
+}
+
+// Clone return copy
+func (t *Status) Clone() *Status {
+	c := &Status{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// GetOther returns the raw status of the components of the type such as boolean that are not
+// modeled by this SDK keyed by ID
+func (t *Status) GetOther(componentType string) map[int]json.RawMessage {
+	return getOtherByType(t.Other, componentType)
 }
 
 // RPCMethods lists of all available RPC methods. It takes into account both ACL and authentication
@@ -93,27 +140,45 @@ func (t *RPCMethods) Clone() *RPCMethods {
 }
 
 // Config Shelly component config. The config is composed of each components config.
-// Shelly devices can have zero or more 'Light', 'Input' and 'Switch' types. Because these
-// are explicity named and not members of a JSON array we have statically created them.
-// This seemed to be a cleaner solution then a customized JSON/YAML encoder/decoder. We have
-// created 8 for each which is currently more then enough as the max for any Shelly product as
-// of today is 4.
+// Shelly devices can have zero or more 'Light', 'Input', 'Switch', 'Cover', 'EM', 'EM1',
+// 'PM1', 'Temperature', 'Humidity' and 'Illuminance' types keyed by ID. The offsets and
+// report thresholds of the sensors are part of their config.
+// The config of components that are not modeled by this SDK is kept as raw JSON in Other
+// keyed by the component key. Other is informational; it is not compared or set.
+// Schedules are the schedule jobs of the device. If Schedules is nil the schedules are not
+// managed; they are not compared or set. Set it to an empty list to delete all schedules.
+// Webhooks are managed the same way; see Render for the templates that can be used in URLs.
+// Scripts are also managed the same way and are matched by name; see LoadScripts.
+// KVS are keys of the key-value store that are written if they differ. Keys that are not
+// in KVS are left alone as the KVS is also written by scripts.
 // https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Shelly#configuration
 type Config struct {
-	Auth          *AuthConfig           `json:"auth,omitempty" yaml:"auth,omitempty"`
-	TLSClientCert *TLSConfig            `json:"tls_client_cert,omitempty" yaml:"tls_client_cert,omitempty"`
-	TLSClientKey  *TLSConfig            `json:"tls_client_key,omitempty" yaml:"tls_client_key,omitempty"`
-	UserCA        *TLSConfig            `json:"user_ca,omitempty" yaml:"user_ca,omitempty"`
-	Bluetooth     *BluetoothConfig      `json:"ble,omitempty" yaml:"ble,omitempty"`
-	Cloud         *CloudConfig          `json:"cloud,omitempty" yaml:"cloud,omitempty"`
-	Mqtt          *MqttConfig           `json:"mqtt,omitempty" yaml:"mqtt,omitempty"`
-	Ethernet      *EthernetConfig       `json:"eth,omitempty" yaml:"eth,omitempty"`
-	System        *SystemConfig         `json:"sys,omitempty" yaml:"sys,omitempty"`
-	Wifi          *WifiConfig           `json:"wifi,omitempty" yaml:"wifi,omitempty"`
-	Websocket     *WebsocketConfig      `json:"ws,omitempty" yaml:"ws,omitempty"`
-	Light         map[int]*LightConfig  `json:"light,omitempty" yaml:"light,omitempty"`
-	Input         map[int]*InputConfig  `json:"input,omitempty" yaml:"input,omitempty"`
-	Switch        map[int]*SwitchConfig `json:"switch,omitempty" yaml:"switch,omitempty"`
+	Auth          *AuthConfig                `json:"auth,omitempty" yaml:"auth,omitempty"`
+	TLSClientCert *TLSConfig                 `json:"tls_client_cert,omitempty" yaml:"tls_client_cert,omitempty"`
+	TLSClientKey  *TLSConfig                 `json:"tls_client_key,omitempty" yaml:"tls_client_key,omitempty"`
+	UserCA        *TLSConfig                 `json:"user_ca,omitempty" yaml:"user_ca,omitempty"`
+	Bluetooth     *BluetoothConfig           `json:"ble,omitempty" yaml:"ble,omitempty"`
+	Cloud         *CloudConfig               `json:"cloud,omitempty" yaml:"cloud,omitempty"`
+	Mqtt          *MqttConfig                `json:"mqtt,omitempty" yaml:"mqtt,omitempty"`
+	Ethernet      *EthernetConfig            `json:"eth,omitempty" yaml:"eth,omitempty"`
+	System        *SystemConfig              `json:"sys,omitempty" yaml:"sys,omitempty"`
+	Wifi          *WifiConfig                `json:"wifi,omitempty" yaml:"wifi,omitempty"`
+	Websocket     *WebsocketConfig           `json:"ws,omitempty" yaml:"ws,omitempty"`
+	Light         map[int]*LightConfig       `json:"light,omitempty" yaml:"light,omitempty"`
+	Input         map[int]*InputConfig       `json:"input,omitempty" yaml:"input,omitempty"`
+	Switch        map[int]*SwitchConfig      `json:"switch,omitempty" yaml:"switch,omitempty"`
+	Cover         map[int]*CoverConfig       `json:"cover,omitempty" yaml:"cover,omitempty"`
+	EM            map[int]*EMConfig          `json:"em,omitempty" yaml:"em,omitempty"`
+	EM1           map[int]*EM1Config         `json:"em1,omitempty" yaml:"em1,omitempty"`
+	PM1           map[int]*PM1Config         `json:"pm1,omitempty" yaml:"pm1,omitempty"`
+	Temperature   map[int]*TemperatureConfig `json:"temperature,omitempty" yaml:"temperature,omitempty"`
+	Humidity      map[int]*HumidityConfig    `json:"humidity,omitempty" yaml:"humidity,omitempty"`
+	Illuminance   map[int]*IlluminanceConfig `json:"illuminance,omitempty" yaml:"illuminance,omitempty"`
+	Schedules     []*ScheduleJob             `json:"schedules,omitempty" yaml:"schedules,omitempty"`
+	Webhooks      []*Webhook                 `json:"webhooks,omitempty" yaml:"webhooks,omitempty"`
+	Scripts       []*Script                  `json:"scripts,omitempty" yaml:"scripts,omitempty"`
+	KVS           map[string]interface{}     `json:"kvs,omitempty" yaml:"kvs,omitempty"`
+	Other         map[string]json.RawMessage `json:"other,omitempty" yaml:"other,omitempty"`
 }
 
 // Equals returns true if equal
@@ -190,94 +255,87 @@ func (t *Config) Equals(x *Config) bool {
 		result = false
 	}
 
-	compareLight := func() bool {
-
-		for i, a := range t.Light {
-			b := x.Light[i]
-			if !a.Equals(b) {
-				zap.L().Info(fmt.Sprintf("Config Light %d not equal", i))
-				return false
-			}
-		}
+	if !equalMap("Light", t.Light, x.Light) {
+		result = false
+	}
 
-		for i, a := range x.Light {
-			b := t.Light[i]
-			if !a.Equals(b) {
-				zap.L().Info(fmt.Sprintf("Config Light %d not equal", i))
-				return false
-			}
-		}
+	if !equalMap("Input", t.Input, x.Input) {
+		result = false
+	}
 
-		return true
+	if !equalMap("Switch", t.Switch, x.Switch) {
+		result = false
 	}
 
-	if !compareLight() {
-		zap.L().Info("Config Light")
+	if !equalMap("Cover", t.Cover, x.Cover) {
 		result = false
 	}
 
-	compareInput := func() bool {
+	if !equalMap("EM", t.EM, x.EM) {
+		result = false
+	}
 
-		for i, a := range t.Input {
-			b := x.Input[i]
-			if !a.Equals(b) {
-				zap.L().Info(fmt.Sprintf("Config Input %d not equal", i))
-				return false
-			}
-		}
+	if !equalMap("EM1", t.EM1, x.EM1) {
+		result = false
+	}
 
-		for i, a := range x.Input {
-			b := t.Input[i]
-			if !a.Equals(b) {
-				zap.L().Info(fmt.Sprintf("Config Input %d not equal", i))
-				return false
-			}
-		}
+	if !equalMap("PM1", t.PM1, x.PM1) {
+		result = false
+	}
 
-		return true
+	if !equalMap("Temperature", t.Temperature, x.Temperature) {
+		result = false
 	}
 
-	if !compareInput() {
-		zap.L().Info("Config Input")
+	if !equalMap("Humidity", t.Humidity, x.Humidity) {
 		result = false
 	}
 
-	compareSwitch := func() bool {
+	if !equalMap("Illuminance", t.Illuminance, x.Illuminance) {
+		result = false
+	}
 
-		for i, a := range t.Switch {
-			b := x.Switch[i]
-			if !a.Equals(b) {
-				zap.L().Info(fmt.Sprintf("Config Switch %d not equal", i))
-				return false
-			}
+	if t.Schedules != nil && x.Schedules != nil {
+		if !schedule_types.JobsEqual(t.Schedules, x.Schedules) {
+			zap.L().Info("Config Schedules not equal")
+			result = false
 		}
+	}
 
-		for i, a := range x.Switch {
-			b := t.Switch[i]
-			if !a.Equals(b) {
-				zap.L().Info(fmt.Sprintf("Config Switch %d not equal", i))
-				return false
-			}
+	if t.Webhooks != nil && x.Webhooks != nil {
+		if !webhook_types.WebhooksEqual(t.Webhooks, x.Webhooks) {
+			zap.L().Info("Config Webhooks not equal")
+			result = false
 		}
+	}
 
-		return true
+	if t.Scripts != nil && x.Scripts != nil {
+		if !script_types.ScriptsEqual(t.Scripts, x.Scripts) {
+			zap.L().Info("Config Scripts not equal")
+			result = false
+		}
 	}
 
-	if !compareSwitch() {
-		zap.L().Info("Config Switch")
-		result = false
+	if t.KVS != nil && x.KVS != nil {
+		if !kvs_types.Contains(t.KVS, x.KVS) {
+			zap.L().Info("Config KVS not equal")
+			result = false
+		}
 	}
 
 	return result
 }
 
-// Clone return copy
+// Clone return copy. The copy is deep so the clone can be rendered and sanitized without
+// changing the original.
 func (t *Config) Clone() *Config {
 	c := &Config{}
-	copier.Copy(&c, &t)
+	copier.CopyWithOption(&c, &t, copier.Option{DeepCopy: true})
 	return c
 }
 
+// Merge sets each value of t that is not set to the value of x and returns t. x is not
+// changed.
 func (t *Config) Merge(x *Config) *Config {
 
 	if x == nil {
@@ -372,48 +430,54 @@ func (t *Config) Merge(x *Config) *Config {
 		}
 	}
 
-	if t.Light != nil {
-		if x.Light == nil {
-			x.Light = make(map[int]*LightConfig)
-		}
-		for _, j := range t.Light {
-			k := x.Light[*j.ID]
-			if k == nil {
-				x.Light[*j.ID] = j.Clone()
-			} else {
-				k.Merge(j)
-			}
+	t.Light = mergeMap(t.Light, x.Light, func(v *LightConfig) *int { return v.ID })
+
+	t.Input = mergeMap(t.Input, x.Input, func(v *InputConfig) *int { return v.ID })
+
+	t.Switch = mergeMap(t.Switch, x.Switch, func(v *SwitchConfig) *int { return v.ID })
+
+	if t.Schedules == nil && x.Schedules != nil {
+		t.Schedules = make([]*ScheduleJob, 0, len(x.Schedules))
+		for _, j := range x.Schedules {
+			t.Schedules = append(t.Schedules, j.Clone())
 		}
 	}
 
-	if t.Input != nil {
-		if x.Input == nil {
-			x.Input = make(map[int]*InputConfig)
-		}
-		for _, j := range t.Input {
-			k := x.Input[*j.ID]
-			if k == nil {
-				x.Input[*j.ID] = j.Clone()
-			} else {
-				k.Merge(j)
-			}
+	if t.Webhooks == nil && x.Webhooks != nil {
+		t.Webhooks = make([]*Webhook, 0, len(x.Webhooks))
+		for _, j := range x.Webhooks {
+			t.Webhooks = append(t.Webhooks, j.Clone())
 		}
 	}
 
-	if t.Switch != nil {
-		if x.Switch == nil {
-			x.Switch = make(map[int]*SwitchConfig)
+	if t.Scripts == nil && x.Scripts != nil {
+		t.Scripts = make([]*Script, 0, len(x.Scripts))
+		for _, j := range x.Scripts {
+			t.Scripts = append(t.Scripts, j.Clone())
 		}
-		for _, j := range t.Switch {
-			k := x.Switch[*j.ID]
-			if k == nil {
-				x.Switch[*j.ID] = j.Clone()
-			} else {
-				k.Merge(j)
-			}
+	}
+
+	if t.KVS == nil && x.KVS != nil {
+		t.KVS = make(map[string]interface{}, len(x.KVS))
+		for k, v := range x.KVS {
+			t.KVS[k] = v
 		}
 	}
 
+	t.Cover = mergeMap(t.Cover, x.Cover, func(v *CoverConfig) *int { return v.ID })
+
+	t.EM = mergeMap(t.EM, x.EM, func(v *EMConfig) *int { return v.ID })
+
+	t.EM1 = mergeMap(t.EM1, x.EM1, func(v *EM1Config) *int { return v.ID })
+
+	t.PM1 = mergeMap(t.PM1, x.PM1, func(v *PM1Config) *int { return v.ID })
+
+	t.Temperature = mergeMap(t.Temperature, x.Temperature, func(v *TemperatureConfig) *int { return v.ID })
+
+	t.Humidity = mergeMap(t.Humidity, x.Humidity, func(v *HumidityConfig) *int { return v.ID })
+
+	t.Illuminance = mergeMap(t.Illuminance, x.Illuminance, func(v *IlluminanceConfig) *int { return v.ID })
+
 	return t
 }
 
@@ -431,9 +495,61 @@ func (t *Config) Sanatize() *Config {
 	t.Wifi.Sanatize()
 	t.Websocket.Sanatize()
 	t.TLSClientCert.Sanatize()
+
+	for _, v := range t.Webhooks {
+		v.Sanatize()
+	}
+
+	for _, v := range t.Scripts {
+		v.Sanatize()
+	}
+
 	return t
 }
 
+// LoadScripts reads the code of each script that does not have code from its file. A
+// relative file is relative to dir such as the directory of the file the config was read
+// from. SetConfig does not read files so it must be called before SetConfig.
+func (t *Config) LoadScripts(dir string) error {
+
+	for _, v := range t.Scripts {
+		err := v.Load(dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Render executes the templates in the config such as {{.DeviceID}} in the URLs of the
+// webhooks. Configs shared by many devices can use this to reference each device.
+func (t *Config) Render(vars *TemplateVars) error {
+
+	if t.Webhooks == nil {
+		return nil
+	}
+
+	webhooks := make([]*Webhook, 0, len(t.Webhooks))
+
+	for _, v := range t.Webhooks {
+		webhook, err := v.Render(vars)
+		if err != nil {
+			return err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	t.Webhooks = webhooks
+	return nil
+}
+
+// GetOther returns the raw config of the components of the type such as boolean that are not
+// modeled by this SDK keyed by ID
+func (t *Config) GetOther(componentType string) map[int]json.RawMessage {
+	return getOtherByType(t.Other, componentType)
+}
+
 // GetLight returns Light with specified ID, otherwise nil
 func (t *Config) GetLight(id int) *LightConfig {
 	for _, v := range t.Light {
@@ -464,6 +580,76 @@ func (t *Config) GetSwitch(id int) *SwitchConfig {
 	return nil
 }
 
+// GetCover returns Cover with specified ID, otherwise nil
+func (t *Config) GetCover(id int) *CoverConfig {
+	for _, v := range t.Cover {
+		if *v.ID == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// GetEM returns EM with specified ID, otherwise nil
+func (t *Config) GetEM(id int) *EMConfig {
+	for _, v := range t.EM {
+		if *v.ID == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// GetEM1 returns EM1 with specified ID, otherwise nil
+func (t *Config) GetEM1(id int) *EM1Config {
+	for _, v := range t.EM1 {
+		if *v.ID == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// GetPM1 returns PM1 with specified ID, otherwise nil
+func (t *Config) GetPM1(id int) *PM1Config {
+	for _, v := range t.PM1 {
+		if *v.ID == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// GetTemperature returns Temperature with specified ID, otherwise nil
+func (t *Config) GetTemperature(id int) *TemperatureConfig {
+	for _, v := range t.Temperature {
+		if *v.ID == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// GetHumidity returns Humidity with specified ID, otherwise nil
+func (t *Config) GetHumidity(id int) *HumidityConfig {
+	for _, v := range t.Humidity {
+		if *v.ID == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// GetIlluminance returns Illuminance with specified ID, otherwise nil
+func (t *Config) GetIlluminance(id int) *IlluminanceConfig {
+	for _, v := range t.Illuminance {
+		if *v.ID == id {
+			return v
+		}
+	}
+	return nil
+}
+
 // DeviceInfo Shelly component top level device info
 // https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Shelly#shellygetdeviceinfo
 type DeviceInfo struct {
@@ -723,3 +909,139 @@ func (t *ComponentReport) Clone() *ComponentReport {
 	copier.Copy(&c, &t)
 	return c
 }
+
+// ComponentsConfig selects the components returned by GetComponents
+type ComponentsConfig struct {
+	// IncludeStatus if true the status of each component is included
+	IncludeStatus bool `json:"includeStatus,omitempty" yaml:"includeStatus,omitempty"`
+	// IncludeConfig if true the config of each component is included
+	IncludeConfig bool `json:"includeConfig,omitempty" yaml:"includeConfig,omitempty"`
+	// DynamicOnly if true only dynamic components such as boolean:200 are returned
+	DynamicOnly bool `json:"dynamicOnly,omitempty" yaml:"dynamicOnly,omitempty"`
+	// Keys if set only the components with these keys are returned
+	Keys []string `json:"keys,omitempty" yaml:"keys,omitempty"`
+}
+
+// Clone return copy
+func (t *ComponentsConfig) Clone() *ComponentsConfig {
+	c := &ComponentsConfig{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Components all components of the device
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Shelly#shellygetcomponents
+type Components struct {
+	// CfgRev the config revision of the device
+	CfgRev int `json:"cfg_rev,omitempty" yaml:"cfg_rev,omitempty"`
+	// Components the components of the device
+	Components []*Component `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+// Clone return copy
+func (t *Components) Clone() *Components {
+	c := &Components{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Keys returns the key of each component
+func (t *Components) Keys() []string {
+	var keys []string
+	for _, component := range t.Components {
+		keys = append(keys, component.Key)
+	}
+	return keys
+}
+
+// Component a component of the device. The status, config and attrs are raw JSON as the
+// component may not be modeled by this SDK.
+type Component struct {
+	// Key the component key such as switch:0 or sys
+	Key string `json:"key" yaml:"key"`
+	// Status the status of the component if requested
+	Status json.RawMessage `json:"status,omitempty" yaml:"status,omitempty"`
+	// Config the config of the component if requested
+	Config json.RawMessage `json:"config,omitempty" yaml:"config,omitempty"`
+	// Attrs the attributes of dynamic components
+	Attrs json.RawMessage `json:"attrs,omitempty" yaml:"attrs,omitempty"`
+}
+
+// Clone return copy
+func (t *Component) Clone() *Component {
+	c := &Component{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Type returns the type of the component such as switch for switch:0
+func (t *Component) Type() string {
+	componentType, _, _ := ParseComponentKey(t.Key)
+	return componentType
+}
+
+// ID returns the ID of the component such as 0 for switch:0. If the component does not
+// have an ID such as sys then hasID is false.
+func (t *Component) ID() (id int, hasID bool) {
+	_, id, hasID = ParseComponentKey(t.Key)
+	return id, hasID
+}
+
+// equalMap returns true if each component config of a is equal to the config with the
+// same ID in b and the reverse
+func equalMap[K comparable, V interface{ Equals(V) bool }](name string, a, b map[K]V) bool {
+
+	for i, v := range a {
+		if !v.Equals(b[i]) {
+			zap.L().Info(fmt.Sprintf("Config %s %v not equal", name, i))
+			return false
+		}
+	}
+
+	for i, v := range b {
+		if !v.Equals(a[i]) {
+			zap.L().Info(fmt.Sprintf("Config %s %v not equal", name, i))
+			return false
+		}
+	}
+
+	return true
+}
+
+// mergeMap returns a new map of the component configs of t and x keyed by ID. A config in
+// both is a clone of the config of t merged with the config of x. A config without an ID
+// is skipped. Neither t nor x is changed.
+func mergeMap[V interface {
+	comparable
+	Clone() V
+	Merge(V)
+}](t, x map[int]V, id func(V) *int) map[int]V {
+
+	if t == nil && x == nil {
+		return nil
+	}
+
+	var zero V
+
+	result := make(map[int]V, len(t))
+
+	for _, v := range t {
+		if v == zero || id(v) == nil {
+			continue
+		}
+		result[*id(v)] = v.Clone()
+	}
+
+	for _, v := range x {
+		if v == zero || id(v) == nil {
+			continue
+		}
+		if k, ok := result[*id(v)]; ok {
+			k.Merge(v)
+		} else {
+			result[*id(v)] = v.Clone()
+		}
+	}
+
+	return result
+}
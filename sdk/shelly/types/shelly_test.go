@@ -0,0 +1,112 @@
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jodydadescott/shelly-client/sdk/shelly/types"
+)
+
+func TestConfigMerge(t *testing.T) {
+
+	id0, id1 := 0, 1
+	hall, garage, porch := "hall", "garage", "porch"
+	invert := true
+
+	tests := []struct {
+		name string
+		t    map[int]*types.CoverConfig
+		x    map[int]*types.CoverConfig
+		want map[int]*types.CoverConfig
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "from x",
+			x:    map[int]*types.CoverConfig{0: {ID: &id0, Name: &hall}},
+			want: map[int]*types.CoverConfig{0: {ID: &id0, Name: &hall}},
+		},
+		{
+			name: "t is kept",
+			t:    map[int]*types.CoverConfig{0: {ID: &id0, Name: &hall}},
+			x:    map[int]*types.CoverConfig{0: {ID: &id0, Name: &garage, InvertDirections: &invert}},
+			want: map[int]*types.CoverConfig{0: {ID: &id0, Name: &hall, InvertDirections: &invert}},
+		},
+		{
+			name: "both",
+			t:    map[int]*types.CoverConfig{0: {ID: &id0, Name: &hall}},
+			x:    map[int]*types.CoverConfig{1: {ID: &id1, Name: &porch}},
+			want: map[int]*types.CoverConfig{0: {ID: &id0, Name: &hall}, 1: {ID: &id1, Name: &porch}},
+		},
+		{
+			name: "without ID",
+			t:    map[int]*types.CoverConfig{0: {Name: &hall}, 1: nil},
+			x:    map[int]*types.CoverConfig{0: {ID: &id0, Name: &garage}, 1: {Name: &porch}},
+			want: map[int]*types.CoverConfig{0: {ID: &id0, Name: &garage}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			config := &types.Config{Cover: tt.t}
+			x := &types.Config{Cover: tt.x}
+
+			before := mustMarshal(t, x)
+
+			config.Merge(x)
+
+			if got, want := mustMarshal(t, config.Cover), mustMarshal(t, tt.want); got != want {
+				t.Errorf("cover %s, want %s", got, want)
+			}
+
+			if after := mustMarshal(t, x); after != before {
+				t.Errorf("x changed by Merge\nbefore %s\nafter  %s", before, after)
+			}
+		})
+	}
+}
+
+func TestConfigEquals(t *testing.T) {
+
+	id0 := 0
+	hall, garage := "hall", "garage"
+
+	tests := []struct {
+		name string
+		a    map[int]*types.CoverConfig
+		b    map[int]*types.CoverConfig
+		want bool
+	}{
+		{name: "nil", want: true},
+		{name: "equal", a: map[int]*types.CoverConfig{0: {ID: &id0, Name: &hall}}, b: map[int]*types.CoverConfig{0: {ID: &id0, Name: &hall}}, want: true},
+		{name: "not equal", a: map[int]*types.CoverConfig{0: {ID: &id0, Name: &hall}}, b: map[int]*types.CoverConfig{0: {ID: &id0, Name: &garage}}},
+		{name: "missing in a", b: map[int]*types.CoverConfig{0: {ID: &id0}}},
+		{name: "missing in b", a: map[int]*types.CoverConfig{0: {ID: &id0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			a := &types.Config{Cover: tt.a}
+			b := &types.Config{Cover: tt.b}
+
+			if got := a.Equals(b); got != tt.want {
+				t.Errorf("Equals %t, want %t", got, tt.want)
+			}
+
+			if got := b.Equals(a); got != tt.want {
+				t.Errorf("reverse Equals %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -2,12 +2,14 @@ package switchx
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 
 	"go.uber.org/zap"
 
 	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
 	"github.com/jodydadescott/shelly-client/sdk/switchx/types"
 )
 
@@ -17,9 +19,16 @@ type Request = msg_types.Request
 
 type Config = types.Config
 type Status = types.Status
+type Params = types.Params
+type Result = types.Result
+
+// Deprecated: GetStatusResponse is no longer used by the client.
 type GetStatusResponse = types.GetStatusResponse
+
+// Deprecated: GetConfigResponse is no longer used by the client.
 type GetConfigResponse = types.GetConfigResponse
-type Params = types.Params
+
+// Deprecated: SetConfigResponse is no longer used by the client.
 type SetConfigResponse = types.SetConfigResponse
 
 // New returns new instance of client
@@ -32,10 +41,14 @@ func New(messageHandlerFactory MessageHandlerFactory) *Client {
 // Client the component client
 type Client struct {
 	MessageHandlerFactory
-	_messageHandler MessageHandler
+	messageHandlerMutex sync.Mutex
+	_messageHandler     MessageHandler
 }
 
 func (t *Client) getMessageHandler() MessageHandler {
+	t.messageHandlerMutex.Lock()
+	defer t.messageHandlerMutex.Unlock()
+
 	if t._messageHandler != nil {
 		return t._messageHandler
 	}
@@ -48,6 +61,13 @@ func getErr(method string, err error) error {
 	if err == nil {
 		return nil
 	}
+
+	// An error returned by the device already has the method and device ID
+	rpcErr := &msg_types.Error{}
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
 	return fmt.Errorf("component %s, method %s, error %w", Component, method, err)
 }
 
@@ -56,32 +76,15 @@ func (t *Client) GetStatus(ctx context.Context, id int) (*Status, error) {
 
 	method := Component + ".GetStatus"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: &Params{
-			ID: id,
-		},
+	result, err := rpc.Call[*Params, *Status](ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
 	})
 
 	if err != nil {
 		return nil, getErr(method, err)
 	}
 
-	response := &GetStatusResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return nil, getErr(method, err)
-	}
-
-	if response.Error != nil {
-		return nil, getErr(method, response.Error)
-	}
-
-	if response.Result == nil {
-		return response.Result, getErr(method, fmt.Errorf("result is missing from response"))
-	}
-
-	return response.Result, nil
+	return result, nil
 }
 
 // GetConfig returns component config or error
@@ -89,31 +92,15 @@ func (t *Client) GetConfig(ctx context.Context, id int) (*Config, error) {
 
 	method := Component + ".GetConfig"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: &Params{
-			ID: id,
-		},
+	result, err := rpc.Call[*Params, *Config](ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
 	})
-	if err != nil {
-		return nil, getErr(method, err)
-	}
 
-	response := &GetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
 	if err != nil {
 		return nil, getErr(method, err)
 	}
 
-	if response.Error != nil {
-		return nil, getErr(method, response.Error)
-	}
-
-	if response.Result == nil {
-		return response.Result, getErr(method, fmt.Errorf("result is missing from response"))
-	}
-
-	return response.Result, nil
+	return result, nil
 }
 
 // SetConfig applies config to device component
@@ -133,33 +120,12 @@ func (t *Client) SetConfig(ctx context.Context, config *Config) error {
 		return fmt.Errorf("config ID is nil")
 	}
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: &Params{
-			ID:     *config.ID,
-			Config: config,
-		},
+	_, err := rpc.Call[*Params, *Result](ctx, t.getMessageHandler(), method, &Params{
+		ID:     *config.ID,
+		Config: config,
 	})
 
-	if err != nil {
-		return getErr(method, err)
-	}
-
-	response := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return getErr(method, err)
-	}
-
-	if response.Error != nil {
-		return getErr(method, response.Error)
-	}
-
-	if response.Result == nil {
-		return getErr(method, fmt.Errorf("result is missing from response"))
-	}
-
-	return nil
+	return getErr(method, err)
 }
 
 // Set sets switch to on/off
@@ -167,36 +133,12 @@ func (t *Client) Set(ctx context.Context, id int, on *bool) error {
 
 	method := Component + ".Set"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: &Params{
-			ID: id,
-			On: on,
-		},
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
+		On: on,
 	})
 
-	if err != nil {
-		return getErr(method, err)
-	}
-
-	rawResponse := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, rawResponse)
-
-	if err != nil {
-		return getErr(method, err)
-	}
-
-	response := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return getErr(method, err)
-	}
-
-	if response.Error != nil {
-		return getErr(method, response.Error)
-	}
-
-	return nil
+	return getErr(method, err)
 }
 
 // Toggle toggles switch. If switch is on it will be turned off. If switch is off it will be turned on.
@@ -204,33 +146,9 @@ func (t *Client) Toggle(ctx context.Context, id int) error {
 
 	method := Component + ".Toggle"
 
-	respBytes, err := t.getMessageHandler().Send(ctx, &Request{
-		Method: &method,
-		Params: &Params{
-			ID: id,
-		},
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID: id,
 	})
 
-	if err != nil {
-		return getErr(method, err)
-	}
-
-	rawResponse := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, rawResponse)
-
-	if err != nil {
-		return getErr(method, err)
-	}
-
-	response := &SetConfigResponse{}
-	err = json.Unmarshal(respBytes, response)
-	if err != nil {
-		return getErr(method, err)
-	}
-
-	if response.Error != nil {
-		return getErr(method, response.Error)
-	}
-
-	return nil
+	return getErr(method, err)
 }
@@ -0,0 +1,154 @@
+package switchx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/switchx"
+)
+
+func newClient(t *testing.T) (*switchx.Client, *fake.Device) {
+	device := fake.NewDevice(&fake.DeviceConfig{Switch: 2})
+	factory := fake.New(nil, device)
+	t.Cleanup(factory.Close)
+	return switchx.New(factory), device
+}
+
+func TestSet(t *testing.T) {
+
+	ctx := context.Background()
+	client, _ := newClient(t)
+
+	on := true
+
+	err := client.Set(ctx, 1, &on)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := client.GetStatus(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !status.Output {
+		t.Errorf("output is off after Set on")
+	}
+
+	err = client.Toggle(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err = client.GetStatus(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Output {
+		t.Errorf("output is on after Toggle")
+	}
+
+	status, err = client.GetStatus(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Output {
+		t.Errorf("output of switch 0 changed")
+	}
+}
+
+func TestConfig(t *testing.T) {
+
+	ctx := context.Background()
+	client, _ := newClient(t)
+
+	config, err := client.GetConfig(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := "kitchen"
+	config.Name = &name
+
+	err = client.SetConfig(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err = client.GetConfig(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Name == nil || *config.Name != name {
+		t.Errorf("name %v, want %s", config.Name, name)
+	}
+
+	err = client.SetConfig(ctx, &switchx.Config{Name: &name})
+	if err == nil {
+		t.Errorf("SetConfig without ID did not fail")
+	}
+}
+
+func TestErrors(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		fault  *fake.Fault
+		id     int
+		method string
+		want   error
+	}{
+		{name: "not found", id: 5, method: "Switch.GetStatus", want: msg_types.ErrNotFound},
+		{name: "failed precondition", fault: &fake.Fault{Method: "Switch.Set", Code: msg_types.ErrorCodeFailedPrecondition}, method: "Switch.Set", want: msg_types.ErrFailedPrecondition},
+		{name: "unavailable", fault: &fake.Fault{Code: msg_types.ErrorCodeUnAvailable}, method: "Switch.GetStatus", want: msg_types.ErrUnavailable},
+		{name: "invalid argument", fault: &fake.Fault{Code: msg_types.ErrorCodeInvalidArgument}, method: "Switch.GetConfig", want: msg_types.ErrInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctx := context.Background()
+			client, device := newClient(t)
+
+			if tt.fault != nil {
+				device.InjectFault(tt.fault)
+			}
+
+			var err error
+
+			switch tt.method {
+			case "Switch.GetStatus":
+				_, err = client.GetStatus(ctx, tt.id)
+			case "Switch.GetConfig":
+				_, err = client.GetConfig(ctx, tt.id)
+			case "Switch.Set":
+				on := true
+				err = client.Set(ctx, tt.id, &on)
+			}
+
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error %v, want %v", err, tt.want)
+			}
+
+			rpcErr := &msg_types.Error{}
+			if !errors.As(err, &rpcErr) {
+				t.Fatalf("error %v is not an Error", err)
+			}
+
+			if rpcErr.Method != tt.method || rpcErr.DeviceID != device.ID() {
+				t.Errorf("error method %s, device ID %s, want %s, %s", rpcErr.Method, rpcErr.DeviceID, tt.method, device.ID())
+			}
+
+			// The error from the device is returned as is and not wrapped again
+			if err != rpcErr {
+				t.Errorf("error %v is wrapped", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+package temperature
+
+const (
+	Component = "Temperature"
+)
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+)
+
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+// Params internal use only
+type Params struct {
+	ID     int     `json:"id" yaml:"id"`
+	Config *Config `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// Result internal use only
+type Result struct {
+	RestartRequired *bool  `json:"restart_required,omitempty"`
+	Error           *Error `json:"error,omitempty"`
+}
+
+// Status status of the Temperature component contains the temperature measured by a sensor
+// such as the sensor of the H&T Plus or a DS18B20 on the sensor add-on (temperature:100)
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Temperature#status
+type Status struct {
+	// ID Id of the Temperature component instance
+	ID *int `json:"id" yaml:"id"`
+	// TC temperature in Celsius (null if the temperature is out of the measurement range)
+	TC *float64 `json:"tC,omitempty" yaml:"tC,omitempty"`
+	// TF temperature in Fahrenheit (null if the temperature is out of the measurement range)
+	TF *float64 `json:"tF,omitempty" yaml:"tF,omitempty"`
+	// Errors error conditions such as read or out_of_range (shown if at least one
+	// error is present)
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Clone return copy
+func (t *Status) Clone() *Status {
+	c := &Status{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Config configuration of the Temperature component. The offset calibrates the sensor and
+// the threshold sets the change that is reported.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Temperature#configuration
+type Config struct {
+	// ID Id of the Temperature component instance
+	ID *int `json:"id" yaml:"id"`
+	// Name of the Temperature instance
+	Name *string `json:"name,omitempty" yaml:"name,omitempty"`
+	// ReportThrC temperature change in Celsius that triggers a status update
+	ReportThrC *float64 `json:"report_thr_C,omitempty" yaml:"report_thr_C,omitempty"`
+	// OffsetC offset in Celsius added to the measured temperature
+	OffsetC *float64 `json:"offset_C,omitempty" yaml:"offset_C,omitempty"`
+}
+
+// Clone return copy
+func (t *Config) Clone() *Config {
+	c := &Config{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Equals returns true if equal
+func (t *Config) Equals(x *Config) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("Config receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("Config receiver is not nil but input is")
+		return false
+	}
+
+	if !util.CompareInt(t.ID, x.ID) {
+		zap.L().Info("Config ID not equal")
+		return false
+	}
+
+	if !util.CompareString(t.Name, x.Name) {
+		zap.L().Info("Config Name not equal")
+		return false
+	}
+
+	if !util.CompareFloat64(t.ReportThrC, x.ReportThrC) {
+		zap.L().Info("Config ReportThrC not equal")
+		return false
+	}
+
+	if !util.CompareFloat64(t.OffsetC, x.OffsetC) {
+		zap.L().Info("Config OffsetC not equal")
+		return false
+	}
+
+	return true
+}
+
+func (t *Config) Merge(x *Config) {
+
+	if x == nil {
+		return
+	}
+
+	if t.ID == nil {
+		t.ID = x.ID
+	}
+
+	if t.Name == nil {
+		t.Name = x.Name
+	}
+
+	if t.ReportThrC == nil {
+		t.ReportThrC = x.ReportThrC
+	}
+
+	if t.OffsetC == nil {
+		t.OffsetC = x.OffsetC
+	}
+}
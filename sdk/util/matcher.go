@@ -0,0 +1,47 @@
+package util
+
+// Matcher matches desired items to the existing items of a device such as schedule jobs,
+// webhooks or scripts so that the device can be converged. Each existing item is matched
+// at most once. The existing items that are not matched are to be deleted; they are
+// deleted before items are created as the device has a limit on the number of items.
+type Matcher[T any] struct {
+	existing []T
+	used     []bool
+}
+
+// NewMatcher returns a Matcher for the existing items
+func NewMatcher[T any](existing []T) *Matcher[T] {
+	return &Matcher[T]{
+		existing: existing,
+		used:     make([]bool, len(existing)),
+	}
+}
+
+// Match returns the first existing item that is not matched yet for which fn is true and
+// marks it as matched. Returns false if there is none.
+func (t *Matcher[T]) Match(fn func(T) bool) (T, bool) {
+
+	for i, v := range t.existing {
+		if !t.used[i] && fn(v) {
+			t.used[i] = true
+			return v, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+// Unmatched returns the existing items that were not matched
+func (t *Matcher[T]) Unmatched() []T {
+
+	var unmatched []T
+
+	for i, v := range t.existing {
+		if !t.used[i] {
+			unmatched = append(unmatched, v)
+		}
+	}
+
+	return unmatched
+}
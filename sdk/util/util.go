@@ -1,5 +1,10 @@
 package util
 
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
 func CompareBool(a, b *bool) bool {
 
 	if a == nil {
@@ -98,3 +103,64 @@ func CompareStringSlice(a, b []string) bool {
 
 	return true
 }
+
+// StringKeys returns v with each map[interface{}]interface{} such as those decoded by
+// yaml.v2 converted to map[string]interface{} so it can be encoded as JSON. Slices and
+// maps are converted recursively.
+func StringKeys(v interface{}) interface{} {
+
+	switch x := v.(type) {
+
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, v := range x {
+			m[fmt.Sprint(k)] = StringKeys(v)
+		}
+		return m
+
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, v := range x {
+			m[k] = StringKeys(v)
+		}
+		return m
+
+	case []interface{}:
+		s := make([]interface{}, len(x))
+		for i, v := range x {
+			s[i] = StringKeys(v)
+		}
+		return s
+
+	}
+
+	return v
+}
+
+// MaxRPCChunkSize is the largest chunk of data such as script code or a PEM file that is
+// sent in a single request
+const MaxRPCChunkSize = 1000
+
+// SplitByWidth splits str into chunks of at most size bytes. A chunk does not end in the
+// middle of a multi-byte character so each chunk is valid UTF-8 if str is.
+func SplitByWidth(str string, size int) []string {
+
+	var chunks []string
+
+	for len(str) > 0 {
+
+		stop := size
+		if stop >= len(str) {
+			stop = len(str)
+		} else {
+			for stop > 1 && !utf8.RuneStart(str[stop]) {
+				stop--
+			}
+		}
+
+		chunks = append(chunks, str[:stop])
+		str = str[stop:]
+	}
+
+	return chunks
+}
@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/rpc"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+	"github.com/jodydadescott/shelly-client/sdk/webhook/types"
+)
+
+type MessageHandlerFactory = msg_types.MessageHandlerFactory
+type MessageHandler = msg_types.MessageHandler
+type Request = msg_types.Request
+
+type Webhook = types.Webhook
+type Hooks = types.Hooks
+type TemplateVars = types.TemplateVars
+type Params = types.Params
+type Result = types.Result
+type ListSupportedResult = types.ListSupportedResult
+
+// New returns new instance of client
+func New(messageHandlerFactory MessageHandlerFactory) *Client {
+	return &Client{
+		MessageHandlerFactory: messageHandlerFactory,
+	}
+}
+
+// Client the component client
+type Client struct {
+	MessageHandlerFactory
+	messageHandlerMutex sync.Mutex
+	_messageHandler     MessageHandler
+}
+
+func (t *Client) getMessageHandler() MessageHandler {
+	t.messageHandlerMutex.Lock()
+	defer t.messageHandlerMutex.Unlock()
+
+	if t._messageHandler != nil {
+		return t._messageHandler
+	}
+
+	t._messageHandler = t.NewHandle(Component)
+	return t._messageHandler
+}
+
+func getErr(method string, id *int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// An error returned by the device already has the method and device ID
+	rpcErr := &msg_types.Error{}
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
+	if id == nil {
+		return fmt.Errorf("component %s, method %s, error %w", Component, method, err)
+	}
+
+	return fmt.Errorf("component %s, method %s, id %d, error %w", Component, method, *id, err)
+}
+
+// ListSupported returns the events that can be used to trigger a webhook such as
+// input.button_push
+func (t *Client) ListSupported(ctx context.Context) ([]string, error) {
+
+	method := Component + ".ListSupported"
+
+	result, err := rpc.Call[any, *ListSupportedResult](ctx, t.getMessageHandler(), method, nil)
+	if err != nil {
+		return nil, getErr(method, nil, err)
+	}
+
+	return result.Events(), nil
+}
+
+// List returns all webhooks of the device
+func (t *Client) List(ctx context.Context) (*Hooks, error) {
+
+	method := Component + ".List"
+
+	result, err := rpc.Call[any, *Hooks](ctx, t.getMessageHandler(), method, nil)
+	if err != nil {
+		return nil, getErr(method, nil, err)
+	}
+
+	return result, nil
+}
+
+// Create creates the webhook and returns the ID assigned by the device. The ID of the
+// webhook is ignored.
+func (t *Client) Create(ctx context.Context, webhook *Webhook) (int, error) {
+
+	method := Component + ".Create"
+
+	if webhook == nil || webhook.Event == nil {
+		return 0, fmt.Errorf("webhook event is required")
+	}
+
+	webhook = webhook.Clone()
+	webhook.ID = nil
+
+	result, err := rpc.Call[*Webhook, *Result](ctx, t.getMessageHandler(), method, webhook)
+	if err != nil {
+		return 0, getErr(method, nil, err)
+	}
+
+	if result.ID == nil {
+		return 0, getErr(method, nil, fmt.Errorf("result is missing id"))
+	}
+
+	return *result.ID, nil
+}
+
+// Update replaces the webhook with the ID of the webhook
+func (t *Client) Update(ctx context.Context, webhook *Webhook) error {
+
+	method := Component + ".Update"
+
+	if webhook == nil || webhook.ID == nil {
+		return fmt.Errorf("webhook ID is required")
+	}
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, webhook)
+
+	return getErr(method, webhook.ID, err)
+}
+
+// Delete deletes the webhook with the ID
+func (t *Client) Delete(ctx context.Context, id int) error {
+
+	method := Component + ".Delete"
+
+	err := rpc.Exec(ctx, t.getMessageHandler(), method, &Params{
+		ID: &id,
+	})
+
+	return getErr(method, &id, err)
+}
+
+// DeleteAll deletes all webhooks of the device
+func (t *Client) DeleteAll(ctx context.Context) error {
+
+	method := Component + ".DeleteAll"
+
+	err := rpc.Exec[any](ctx, t.getMessageHandler(), method, nil)
+
+	return getErr(method, nil, err)
+}
+
+// SetConfig converges the webhooks of the device to webhooks. Webhooks that are equal to
+// an existing webhook are left alone. A webhook is updated in place if an existing webhook
+// has the same event, cid and name. The remaining webhooks are created and the remaining
+// existing webhooks are deleted. Returns true if the device was changed.
+func (t *Client) SetConfig(ctx context.Context, webhooks []*Webhook) (bool, error) {
+
+	existing, err := t.List(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, v := range existing.Hooks {
+		v.Sanatize()
+	}
+
+	matcher := util.NewMatcher(existing.Hooks)
+	var pending []*Webhook
+
+	for _, webhook := range webhooks {
+		webhook = webhook.Clone()
+		webhook.Sanatize()
+		if _, ok := matcher.Match(webhook.Equals); !ok {
+			pending = append(pending, webhook)
+		}
+	}
+
+	var updates []*Webhook
+	var creates []*Webhook
+
+	for _, webhook := range pending {
+
+		match, ok := matcher.Match(func(v *Webhook) bool { return webhook.Key() == v.Key() })
+
+		if !ok {
+			creates = append(creates, webhook)
+			continue
+		}
+
+		webhook.ID = match.ID
+		updates = append(updates, webhook)
+	}
+
+	var errors *multierror.Error
+	changed := false
+
+	for _, v := range matcher.Unmatched() {
+		if v.ID == nil {
+			continue
+		}
+		zap.L().Debug(fmt.Sprintf("Deleting webhook %d %s", *v.ID, v.Key()))
+		changed = true
+		err := t.Delete(ctx, *v.ID)
+		if err != nil {
+			errors = multierror.Append(errors, err)
+		}
+	}
+
+	for _, v := range updates {
+		zap.L().Debug(fmt.Sprintf("Updating webhook %d %s", *v.ID, v.Key()))
+		changed = true
+		err := t.Update(ctx, v)
+		if err != nil {
+			errors = multierror.Append(errors, err)
+		}
+	}
+
+	for _, v := range creates {
+		zap.L().Debug(fmt.Sprintf("Creating webhook %s", v.Key()))
+		changed = true
+		_, err := t.Create(ctx, v)
+		if err != nil {
+			errors = multierror.Append(errors, err)
+		}
+	}
+
+	return changed, errors.ErrorOrNil()
+}
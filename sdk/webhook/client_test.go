@@ -0,0 +1,116 @@
+package webhook_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jodydadescott/shelly-client/sdk/fake"
+	"github.com/jodydadescott/shelly-client/sdk/webhook"
+	"github.com/jodydadescott/shelly-client/sdk/webhook/types"
+)
+
+func TestSetConfig(t *testing.T) {
+
+	str := func(s string) *string { return &s }
+	cid := 0
+
+	on := func(urls ...string) *types.Webhook {
+		return &types.Webhook{CID: &cid, Event: str("switch.on"), Name: str("on"), URLs: urls}
+	}
+
+	off := func(urls ...string) *types.Webhook {
+		return &types.Webhook{CID: &cid, Event: str("switch.off"), Name: str("off"), URLs: urls}
+	}
+
+	tests := []struct {
+		name     string
+		existing []*types.Webhook
+		webhooks []*types.Webhook
+		want     []*types.Webhook
+		// calls the methods sent to converge the webhooks
+		calls []string
+	}{
+		{
+			name:     "keep",
+			existing: []*types.Webhook{on("http://hub.lan/on")},
+			webhooks: []*types.Webhook{on("http://hub.lan/on")},
+			want:     []*types.Webhook{on("http://hub.lan/on")},
+		},
+		{
+			name:     "update",
+			existing: []*types.Webhook{on("http://hub.lan/on")},
+			webhooks: []*types.Webhook{on("http://hub.lan/switch/on")},
+			want:     []*types.Webhook{on("http://hub.lan/switch/on")},
+			calls:    []string{"Webhook.Update"},
+		},
+		{
+			name:     "create",
+			webhooks: []*types.Webhook{on("http://hub.lan/on")},
+			want:     []*types.Webhook{on("http://hub.lan/on")},
+			calls:    []string{"Webhook.Create"},
+		},
+		{
+			name:     "delete",
+			existing: []*types.Webhook{on("http://hub.lan/on"), off("http://hub.lan/off")},
+			webhooks: []*types.Webhook{off("http://hub.lan/off")},
+			want:     []*types.Webhook{off("http://hub.lan/off")},
+			calls:    []string{"Webhook.Delete"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctx := context.Background()
+
+			device := fake.NewDevice(&fake.DeviceConfig{Switch: 1})
+			factory := fake.New(nil, device)
+			defer factory.Close()
+
+			client := webhook.New(factory)
+
+			for _, v := range tt.existing {
+				_, err := client.Create(ctx, v)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			before := len(device.Calls())
+
+			changed, err := client.SetConfig(ctx, tt.webhooks)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if want := len(tt.calls) > 0; changed != want {
+				t.Errorf("changed %t, want %t", changed, want)
+			}
+
+			var calls []string
+			for _, v := range device.Calls()[before:] {
+				if v != "Webhook.List" {
+					calls = append(calls, v)
+				}
+			}
+
+			if len(calls) != len(tt.calls) || (len(calls) > 0 && calls[0] != tt.calls[0]) {
+				t.Errorf("calls %v, want %v", calls, tt.calls)
+			}
+
+			hooks, err := client.List(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The device returns the values it uses for the values that are not set
+			for _, v := range tt.want {
+				v.Sanatize()
+			}
+
+			if !types.WebhooksEqual(hooks.Hooks, tt.want) {
+				t.Errorf("webhooks not equal")
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+package types
+
+const (
+	// DefaultSslCa the CA used to verify https URLs if SslCa is not set
+	DefaultSslCa = "ca.pem"
+)
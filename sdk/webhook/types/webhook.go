@@ -0,0 +1,305 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/template"
+
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+
+	msg_types "github.com/jodydadescott/shelly-client/sdk/msghandlers/types"
+	"github.com/jodydadescott/shelly-client/sdk/util"
+)
+
+type Request = msg_types.Request
+type Response = msg_types.Response
+type Error = msg_types.Error
+
+// Params internal use only
+type Params struct {
+	ID *int `json:"id,omitempty" yaml:"id,omitempty"`
+}
+
+// Result internal use only
+type Result struct {
+	ID  *int `json:"id,omitempty"`
+	Rev *int `json:"rev,omitempty"`
+}
+
+// ListSupportedResult internal use only. Older firmware returns HookTypes; newer firmware
+// returns Types keyed by event.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Webhook#webhooklistsupported
+type ListSupportedResult struct {
+	HookTypes []string               `json:"hook_types,omitempty"`
+	Types     map[string]interface{} `json:"types,omitempty"`
+}
+
+// Events returns the supported events sorted
+func (t *ListSupportedResult) Events() []string {
+
+	events := append([]string{}, t.HookTypes...)
+
+	for event := range t.Types {
+		events = append(events, event)
+	}
+
+	sort.Strings(events)
+	return events
+}
+
+// Hooks the webhooks of the device
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Webhook#webhooklist
+type Hooks struct {
+	// Hooks the webhooks
+	Hooks []*Webhook `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	// Rev the current revision of the webhooks
+	Rev *int `json:"rev,omitempty" yaml:"rev,omitempty"`
+}
+
+// Clone return copy
+func (t *Hooks) Clone() *Hooks {
+	c := &Hooks{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Webhook a webhook. When the event occurs on the component with the cid each URL is
+// requested. The URLs may reference the device with the template fields {{.DeviceID}} and
+// {{.Hostname}}; see Render. Placeholders used by the device such as ${ev.id} are sent
+// as is.
+// https://shelly-api-docs.shelly.cloud/gen2/ComponentsAndServices/Webhook#webhookcreate
+type Webhook struct {
+	// ID Id assigned to the webhook by the device. This is ignored by Equals.
+	ID *int `json:"id,omitempty" yaml:"id,omitempty"`
+	// CID Id of the component instance that triggers the event such as 0 for input:0
+	CID *int `json:"cid,omitempty" yaml:"cid,omitempty"`
+	// Enable true to enable the webhook, false otherwise. Defaults to true.
+	Enable *bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// Event the event such as input.button_push. See ListSupported.
+	Event *string `json:"event,omitempty" yaml:"event,omitempty"`
+	// Name of the webhook
+	Name *string `json:"name,omitempty" yaml:"name,omitempty"`
+	// SslCa the CA used to verify https URLs: ca.pem, user_ca.pem or * to not verify
+	SslCa *string `json:"ssl_ca,omitempty" yaml:"ssl_ca,omitempty"`
+	// URLs requested when the event occurs
+	URLs []string `json:"urls,omitempty" yaml:"urls,omitempty"`
+	// ActiveBetween the time of day the webhook is active such as ["22:00", "06:00"]
+	ActiveBetween []string `json:"active_between,omitempty" yaml:"active_between,omitempty"`
+	// Condition a JavaScript expression that must be true for the URLs to be requested
+	Condition *string `json:"condition,omitempty" yaml:"condition,omitempty"`
+	// RepeatPeriod minimum seconds between two invocations; 0 for no limit
+	RepeatPeriod *int `json:"repeat_period,omitempty" yaml:"repeat_period,omitempty"`
+}
+
+// Clone return copy
+func (t *Webhook) Clone() *Webhook {
+	c := &Webhook{}
+	copier.Copy(&c, &t)
+	return c
+}
+
+// Sanatize sets the values the device uses when they are not set
+func (t *Webhook) Sanatize() {
+
+	if t == nil {
+		return
+	}
+
+	if t.Enable == nil {
+		tmp := true
+		t.Enable = &tmp
+	}
+
+	if t.CID == nil {
+		tmp := 0
+		t.CID = &tmp
+	}
+
+	if t.SslCa == nil {
+		tmp := DefaultSslCa
+		t.SslCa = &tmp
+	}
+
+	if t.RepeatPeriod == nil {
+		tmp := 0
+		t.RepeatPeriod = &tmp
+	}
+
+	if t.Name != nil && *t.Name == "" {
+		t.Name = nil
+	}
+
+	if t.Condition != nil && *t.Condition == "" {
+		t.Condition = nil
+	}
+}
+
+// Key returns the event, cid and name of the webhook. Webhooks with the same key are
+// considered the same webhook when webhooks are reconciled.
+func (t *Webhook) Key() string {
+
+	event := ""
+	if t.Event != nil {
+		event = *t.Event
+	}
+
+	cid := 0
+	if t.CID != nil {
+		cid = *t.CID
+	}
+
+	name := ""
+	if t.Name != nil {
+		name = *t.Name
+	}
+
+	return fmt.Sprintf("%s/%d/%s", event, cid, name)
+}
+
+// Equals returns true if equal. The ID is not compared.
+func (t *Webhook) Equals(x *Webhook) bool {
+
+	if t == nil {
+		if x == nil {
+			return true
+		}
+
+		zap.L().Info("Webhook receiver is nil but input is not")
+		return false
+	}
+
+	if x == nil {
+		zap.L().Info("Webhook receiver is not nil but input is")
+		return false
+	}
+
+	if !util.CompareInt(t.CID, x.CID) {
+		zap.L().Info("Webhook CID not equal")
+		return false
+	}
+
+	if !util.CompareBool(t.Enable, x.Enable) {
+		zap.L().Info("Webhook Enable not equal")
+		return false
+	}
+
+	if !util.CompareString(t.Event, x.Event) {
+		zap.L().Info("Webhook Event not equal")
+		return false
+	}
+
+	if !util.CompareString(t.Name, x.Name) {
+		zap.L().Info("Webhook Name not equal")
+		return false
+	}
+
+	if !util.CompareString(t.SslCa, x.SslCa) {
+		zap.L().Info("Webhook SslCa not equal")
+		return false
+	}
+
+	if !compareOrdered(t.URLs, x.URLs) {
+		zap.L().Info("Webhook URLs not equal")
+		return false
+	}
+
+	if !compareOrdered(t.ActiveBetween, x.ActiveBetween) {
+		zap.L().Info("Webhook ActiveBetween not equal")
+		return false
+	}
+
+	if !util.CompareString(t.Condition, x.Condition) {
+		zap.L().Info("Webhook Condition not equal")
+		return false
+	}
+
+	if !util.CompareInt(t.RepeatPeriod, x.RepeatPeriod) {
+		zap.L().Info("Webhook RepeatPeriod not equal")
+		return false
+	}
+
+	return true
+}
+
+// Render returns a copy of the webhook with the templates in the URLs executed with vars
+func (t *Webhook) Render(vars *TemplateVars) (*Webhook, error) {
+
+	c := t.Clone()
+	c.URLs = nil
+
+	for _, v := range t.URLs {
+
+		tmpl, err := template.New("url").Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("webhook %s url %s; %w", t.Key(), v, err)
+		}
+
+		var b bytes.Buffer
+		err = tmpl.Execute(&b, vars)
+		if err != nil {
+			return nil, fmt.Errorf("webhook %s url %s; %w", t.Key(), v, err)
+		}
+
+		c.URLs = append(c.URLs, b.String())
+	}
+
+	return c, nil
+}
+
+// TemplateVars the fields that can be referenced in the URLs of a webhook such as
+// http://collector/{{.DeviceID}}
+type TemplateVars struct {
+	// DeviceID the ID of the device such as shellyplus1pm-a8032ab12345
+	DeviceID string
+	// Hostname the hostname used to connect to the device
+	Hostname string
+}
+
+// WebhooksEqual returns true if a and b contain the same webhooks in any order. The IDs
+// are not compared.
+func WebhooksEqual(a, b []*Webhook) bool {
+
+	if len(a) != len(b) {
+		zap.L().Info(fmt.Sprintf("Webhooks count %d not equal to %d", len(a), len(b)))
+		return false
+	}
+
+	used := make([]bool, len(b))
+
+	for _, j := range a {
+
+		found := false
+
+		for i, k := range b {
+			if !used[i] && j.Equals(k) {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			zap.L().Info(fmt.Sprintf("Webhook %s not found", j.Key()))
+			return false
+		}
+	}
+
+	return true
+}
+
+func compareOrdered(a, b []string) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}